@@ -0,0 +1,83 @@
+// Package backends holds the JSON implementations jsonbench compares and
+// checks against each other: encoding/json, encoding/json/v2 when the
+// toolchain has it, a hand-rolled decoder and a simdjson-style tape.
+package backends
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Backend is a JSON implementation the harness can run and check.
+//
+// Decode returns the generic representation used by encoding/json:
+// nil, bool, float64, string, []interface{} and map[string]interface{}.
+type Backend interface {
+	Name() string
+	Valid(data []byte) bool
+	Decode(data []byte) (interface{}, error)
+}
+
+// Encoder is implemented by backends that can also serialize the generic
+// representation returned by Decode.
+type Encoder interface {
+	Encode(v interface{}) ([]byte, error)
+}
+
+// EncodeGeneric serializes v with b when it is an Encoder and with
+// encoding/json otherwise
+func EncodeGeneric(b Backend, v interface{}) ([]byte, error) {
+	if enc, ok := b.(Encoder); ok {
+		return enc.Encode(v)
+	}
+	return json.Marshal(v)
+}
+
+var registered []Backend
+
+// Register adds a backend to the list used by every command
+func Register(b Backend) {
+	registered = append(registered, b)
+}
+
+// All returns the registered backends, in registration order
+func All() []Backend {
+	return registered
+}
+
+// ErrUnknownBackend is returned by Lookup for names no backend has, in
+// particular backends not compiled into this build
+var ErrUnknownBackend = errors.New("unknown backend")
+
+// Lookup returns the registered backend with the given name
+func Lookup(name string) (Backend, error) {
+	for _, b := range registered {
+		if b.Name() == name {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownBackend, name)
+}
+
+// Stdlib is the standard library encoding/json package
+type Stdlib struct{}
+
+func (Stdlib) Name() string { return "encoding/json" }
+
+func (Stdlib) Valid(data []byte) bool { return json.Valid(data) }
+
+// snippet:stdlib-decode encoding/json
+func (Stdlib) Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// snippet:stdlib-decode
+
+func (Stdlib) Encode(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func init() {
+	Register(Stdlib{})
+}
@@ -0,0 +1,23 @@
+//go:build goexperiment.jsonv2 && !tinygo
+
+package backends
+
+// jsonv2Backend is the experimental encoding/json/v2 package, available
+// when building with GOEXPERIMENT=jsonv2
+type jsonv2Backend struct{}
+
+func (jsonv2Backend) Name() string { return "encoding/json/v2" }
+
+func (jsonv2Backend) Valid(data []byte) bool { return jsonv2Valid(data) }
+
+func (jsonv2Backend) Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	err := jsonv2Unmarshal(data, &v)
+	return v, err
+}
+
+func (jsonv2Backend) Encode(v interface{}) ([]byte, error) { return jsonv2Marshal(v) }
+
+func init() {
+	Register(jsonv2Backend{})
+}
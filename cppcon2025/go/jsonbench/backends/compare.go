@@ -0,0 +1,95 @@
+package backends
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CompareOptions controls how decoded values are compared
+type CompareOptions struct {
+	// Tolerance is the relative difference allowed between two numbers;
+	// zero requires them to be identical.
+	Tolerance float64
+	// ExactNumbers compares json.Number values as exact decimals instead
+	// of after rounding to float64
+	ExactNumbers bool
+}
+
+// numbersEqual reports whether x and y match under the options
+func (o CompareOptions) numbersEqual(x, y float64) bool {
+	if x == y {
+		return true
+	}
+	if o.Tolerance == 0 || math.IsNaN(x) || math.IsNaN(y) {
+		return false
+	}
+	return math.Abs(x-y) <= o.Tolerance*math.Max(math.Abs(x), math.Abs(y))
+}
+
+// Diff compares two decoded documents and describes the first
+// difference, or returns the empty string when they are equivalent.
+// Objects are compared by key, so member order does not matter.
+func Diff(path string, a, b interface{}, opt CompareOptions) string {
+	switch x := a.(type) {
+	case nil:
+		if b != nil {
+			return fmt.Sprintf("%s: null vs %v", path, b)
+		}
+	case bool:
+		if y, ok := b.(bool); !ok || x != y {
+			return fmt.Sprintf("%s: %v vs %v", path, x, b)
+		}
+	case float64:
+		if y, ok := b.(float64); !ok || !opt.numbersEqual(x, y) {
+			return fmt.Sprintf("%s: %v vs %v", path, x, b)
+		}
+	case json.Number:
+		if y, ok := b.(json.Number); !ok || !numbersEqualExact(x, y) {
+			return fmt.Sprintf("%s: %v vs %v", path, x, b)
+		}
+	case string:
+		if y, ok := b.(string); !ok || x != y {
+			return fmt.Sprintf("%s: %q vs %v", path, x, b)
+		}
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: array vs %T", path, b)
+		}
+		if len(x) != len(y) {
+			return fmt.Sprintf("%s: %d elements vs %d", path, len(x), len(y))
+		}
+		for i := range x {
+			if d := Diff(fmt.Sprintf("%s[%d]", path, i), x[i], y[i], opt); d != "" {
+				return d
+			}
+		}
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: object vs %T", path, b)
+		}
+		if len(x) != len(y) {
+			return fmt.Sprintf("%s: %d members vs %d", path, len(x), len(y))
+		}
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			yv, ok := y[k]
+			if !ok {
+				return fmt.Sprintf("%s: key %q missing", path, k)
+			}
+			if d := Diff(path+"."+k, x[k], yv, opt); d != "" {
+				return d
+			}
+		}
+	default:
+		return fmt.Sprintf("%s: unexpected type %T", path, a)
+	}
+	return ""
+}
@@ -0,0 +1,503 @@
+package backends
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// DuplicatePolicy says what the hand-rolled decoder does when an object
+// repeats a key
+type DuplicatePolicy int
+
+const (
+	LastWins         DuplicatePolicy = iota // keep the last value, like encoding/json
+	FirstWins                               // keep the first value
+	RejectDuplicates                        // fail with an error
+)
+
+func (p DuplicatePolicy) String() string {
+	switch p {
+	case LastWins:
+		return "last-wins"
+	case FirstWins:
+		return "first-wins"
+	case RejectDuplicates:
+		return "error"
+	}
+	return fmt.Sprintf("DuplicatePolicy(%d)", int(p))
+}
+
+// UTF8Mode says what the hand-rolled decoder does with invalid UTF-8 in
+// strings
+type UTF8Mode int
+
+const (
+	ReplaceInvalid UTF8Mode = iota // substitute U+FFFD, like encoding/json
+	RejectInvalid                  // fail with an error
+	PassInvalid                    // copy the bytes through unchanged
+)
+
+func (m UTF8Mode) String() string {
+	switch m {
+	case ReplaceInvalid:
+		return "replace"
+	case RejectInvalid:
+		return "strict"
+	case PassInvalid:
+		return "pass-through"
+	}
+	return fmt.Sprintf("UTF8Mode(%d)", int(m))
+}
+
+// DefaultMaxDepth is the nesting limit used when none is configured; it
+// matches encoding/json
+const DefaultMaxDepth = 10000
+
+// DecodeOptions configures the hand-rolled decoder
+type DecodeOptions struct {
+	DuplicateKeys DuplicatePolicy
+	// MaxDepth limits how deeply arrays and objects may nest: zero selects
+	// DefaultMaxDepth and a negative value removes the limit
+	MaxDepth int
+	UTF8     UTF8Mode
+	// SyntaxOnly checks the grammar without converting numbers, so that
+	// out-of-range numbers are accepted like json.Valid does
+	SyntaxOnly bool
+	// InternKeys makes the objects of a document share one string per
+	// distinct key, instead of allocating every key again
+	InternKeys bool
+}
+
+// maxInternedKeys bounds the keys interned per document, for documents
+// whose keys never repeat
+const maxInternedKeys = 4096
+
+// ErrDepth is wrapped by the SyntaxError of a document nested deeper than
+// the limit of DecodeOptions.MaxDepth
+var ErrDepth = errors.New("exceeded max depth")
+
+// SyntaxError reports malformed input and where it was found
+type SyntaxError struct {
+	msg    string
+	Offset int
+	err    error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.msg)
+}
+
+func (e *SyntaxError) Unwrap() error { return e.err }
+
+// Decoder is a hand-rolled recursive descent parser producing the same
+// generic values as encoding/json
+type Decoder struct {
+	data     []byte
+	pos      int
+	depth    int
+	maxDepth int
+	opts     DecodeOptions
+	// keys holds the interned keys, with InternKeys
+	keys map[string]string
+}
+
+// Decode parses a complete document
+func Decode(data []byte, opts DecodeOptions) (interface{}, error) {
+	d := NewDecoder(data, opts)
+	v, err := d.Value()
+	if err != nil {
+		return nil, err
+	}
+	d.skipWhitespace()
+	if d.pos != len(d.data) {
+		return nil, d.Errorf("unexpected data after top-level value")
+	}
+	return v, nil
+}
+
+func (d *Decoder) Errorf(format string, args ...interface{}) error {
+	return &SyntaxError{msg: fmt.Sprintf(format, args...), Offset: d.pos}
+}
+
+func (d *Decoder) skipWhitespace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// Value parses the value starting at the current position
+func (d *Decoder) Value() (interface{}, error) {
+	if d.pos >= len(d.data) {
+		return nil, d.Errorf("unexpected end of input")
+	}
+	switch c := d.data[d.pos]; {
+	case c == '{':
+		return d.object()
+	case c == '[':
+		return d.array()
+	case c == '"':
+		return d.String()
+	case c == 't':
+		return true, d.Literal("true")
+	case c == 'f':
+		return false, d.Literal("false")
+	case c == 'n':
+		return nil, d.Literal("null")
+	case c == '-' || (c >= '0' && c <= '9'):
+		return d.Number()
+	default:
+		return nil, d.Errorf("invalid character %q looking for a value", c)
+	}
+}
+
+// enter records one more level of nesting and enforces the limit
+func (d *Decoder) enter() error {
+	d.depth++
+	if d.maxDepth > 0 && d.depth > d.maxDepth {
+		return &SyntaxError{msg: fmt.Sprintf("%v %d", ErrDepth, d.maxDepth), Offset: d.pos, err: ErrDepth}
+	}
+	return nil
+}
+
+func (d *Decoder) Literal(word string) error {
+	if len(d.data)-d.pos < len(word) || string(d.data[d.pos:d.pos+len(word)]) != word {
+		return d.Errorf("invalid literal, expected %s", word)
+	}
+	d.pos += len(word)
+	return nil
+}
+
+func (d *Decoder) object() (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	d.pos++ // '{'
+	obj := make(map[string]interface{})
+	d.skipWhitespace()
+	if d.pos < len(d.data) && d.data[d.pos] == '}' {
+		d.pos++
+		d.depth--
+		return obj, nil
+	}
+	for {
+		if d.pos >= len(d.data) || d.data[d.pos] != '"' {
+			return nil, d.Errorf("expected string key")
+		}
+		key, err := d.key()
+		if err != nil {
+			return nil, err
+		}
+		d.skipWhitespace()
+		if d.pos >= len(d.data) || d.data[d.pos] != ':' {
+			return nil, d.Errorf("expected ':' after object key")
+		}
+		d.pos++
+		d.skipWhitespace()
+		v, err := d.Value()
+		if err != nil {
+			return nil, err
+		}
+		if _, seen := obj[key]; !seen || d.opts.DuplicateKeys == LastWins {
+			obj[key] = v
+		} else if d.opts.DuplicateKeys == RejectDuplicates {
+			return nil, d.Errorf("duplicate object key %q", key)
+		}
+		d.skipWhitespace()
+		if d.pos >= len(d.data) {
+			return nil, d.Errorf("unexpected end of input in object")
+		}
+		switch d.data[d.pos] {
+		case ',':
+			d.pos++
+			d.skipWhitespace()
+		case '}':
+			d.pos++
+			d.depth--
+			return obj, nil
+		default:
+			return nil, d.Errorf("expected ',' or '}' in object")
+		}
+	}
+}
+
+// key parses an object key. With InternKeys, a plain ASCII key already
+// seen is looked up by its bytes, which allocates nothing, and returned
+// as the string of its first occurrence.
+func (d *Decoder) key() (string, error) {
+	if d.keys == nil {
+		return d.String()
+	}
+	start := d.pos + 1
+	for i := start; i < len(d.data); i++ {
+		c := d.data[i]
+		if c == '"' {
+			if k, ok := d.keys[string(d.data[start:i])]; ok {
+				d.pos = i + 1
+				return k, nil
+			}
+			break
+		}
+		if c == '\\' || c < 0x20 || c >= utf8.RuneSelf {
+			break
+		}
+	}
+	k, err := d.String()
+	if err == nil && len(d.keys) < maxInternedKeys {
+		d.keys[k] = k
+	}
+	return k, err
+}
+
+func (d *Decoder) array() (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	d.pos++ // '['
+	arr := []interface{}{}
+	d.skipWhitespace()
+	if d.pos < len(d.data) && d.data[d.pos] == ']' {
+		d.pos++
+		d.depth--
+		return arr, nil
+	}
+	for {
+		v, err := d.Value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+		d.skipWhitespace()
+		if d.pos >= len(d.data) {
+			return nil, d.Errorf("unexpected end of input in array")
+		}
+		switch d.data[d.pos] {
+		case ',':
+			d.pos++
+			d.skipWhitespace()
+		case ']':
+			d.pos++
+			d.depth--
+			return arr, nil
+		default:
+			return nil, d.Errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+// String parses a quoted string. Plain ASCII strings are sliced straight
+// out of the input; anything else goes through the slow path.
+func (d *Decoder) String() (string, error) {
+	d.pos++ // '"'
+	start := d.pos
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		if c == '"' {
+			s := string(d.data[start:d.pos])
+			d.pos++
+			return s, nil
+		}
+		if c == '\\' || c < 0x20 || c >= utf8.RuneSelf {
+			return d.slowString(start)
+		}
+		d.pos++
+	}
+	return "", d.Errorf("unterminated string")
+}
+
+// slowString handles escapes and non-ASCII bytes. By default invalid UTF-8
+// and lone surrogates become U+FFFD, as in encoding/json; strict mode
+// rejects both, pass-through mode keeps invalid bytes as they are (lone
+// surrogates still become U+FFFD since they have no UTF-8 encoding).
+func (d *Decoder) slowString(start int) (string, error) {
+	buf := append([]byte(nil), d.data[start:d.pos]...)
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			d.pos++
+			return string(buf), nil
+		case c < 0x20:
+			return "", d.Errorf("control character %#x in string", c)
+		case c == '\\':
+			d.pos++
+			if d.pos >= len(d.data) {
+				return "", d.Errorf("unterminated string")
+			}
+			e := d.data[d.pos]
+			d.pos++
+			switch e {
+			case '"', '\\', '/':
+				buf = append(buf, e)
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'u':
+				r, err := d.hex4()
+				if err != nil {
+					return "", err
+				}
+				if utf16.IsSurrogate(r) {
+					r2 := utf8.RuneError
+					if d.pos+1 < len(d.data) && d.data[d.pos] == '\\' && d.data[d.pos+1] == 'u' {
+						save := d.pos
+						d.pos += 2
+						low, err := d.hex4()
+						if err != nil {
+							return "", err
+						}
+						if r2 = utf16.DecodeRune(r, low); r2 == utf8.RuneError {
+							d.pos = save // not a pair, decode the second escape on its own
+						}
+					}
+					if r2 == utf8.RuneError && d.opts.UTF8 == RejectInvalid {
+						return "", d.Errorf("lone surrogate in string")
+					}
+					r = r2
+				}
+				buf = utf8.AppendRune(buf, r)
+			default:
+				return "", d.Errorf("invalid escape character %q", e)
+			}
+		case c < utf8.RuneSelf:
+			buf = append(buf, c)
+			d.pos++
+		default:
+			r, size := utf8.DecodeRune(d.data[d.pos:])
+			if r == utf8.RuneError && size == 1 && d.opts.UTF8 != PassInvalid {
+				if d.opts.UTF8 == RejectInvalid {
+					return "", d.Errorf("invalid UTF-8 in string")
+				}
+				buf = utf8.AppendRune(buf, utf8.RuneError)
+			} else {
+				buf = append(buf, d.data[d.pos:d.pos+size]...)
+			}
+			d.pos += size
+		}
+	}
+	return "", d.Errorf("unterminated string")
+}
+
+func (d *Decoder) hex4() (rune, error) {
+	if len(d.data)-d.pos < 4 {
+		return 0, d.Errorf("short unicode escape")
+	}
+	var r rune
+	for _, c := range d.data[d.pos : d.pos+4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, d.Errorf("invalid unicode escape")
+		}
+		r = r<<4 | rune(c)
+	}
+	d.pos += 4
+	return r, nil
+}
+
+// Number checks the JSON number grammar and converts with strconv
+func (d *Decoder) Number() (interface{}, error) {
+	start := d.pos
+	if d.data[d.pos] == '-' {
+		d.pos++
+	}
+	switch {
+	case d.pos < len(d.data) && d.data[d.pos] == '0':
+		d.pos++
+	case d.pos < len(d.data) && d.data[d.pos] >= '1' && d.data[d.pos] <= '9':
+		d.digits()
+	default:
+		return nil, d.Errorf("invalid number")
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '.' {
+		d.pos++
+		if d.digits() == 0 {
+			return nil, d.Errorf("expected digit after decimal point")
+		}
+	}
+	if d.pos < len(d.data) && (d.data[d.pos] == 'e' || d.data[d.pos] == 'E') {
+		d.pos++
+		if d.pos < len(d.data) && (d.data[d.pos] == '+' || d.data[d.pos] == '-') {
+			d.pos++
+		}
+		if d.digits() == 0 {
+			return nil, d.Errorf("expected digit in exponent")
+		}
+	}
+	if d.opts.SyntaxOnly {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(string(d.data[start:d.pos]), 64)
+	if err != nil {
+		return nil, &SyntaxError{msg: "number out of float64 range", Offset: start}
+	}
+	return f, nil
+}
+
+func (d *Decoder) digits() int {
+	n := 0
+	for d.pos < len(d.data) && d.data[d.pos] >= '0' && d.data[d.pos] <= '9' {
+		d.pos++
+		n++
+	}
+	return n
+}
+
+// Handrolled exposes the hand-rolled decoder as a backend
+type Handrolled struct {
+	Options DecodeOptions
+}
+
+func (b Handrolled) Name() string {
+	name := "handrolled"
+	if b.Options.DuplicateKeys != LastWins {
+		name += "/" + b.Options.DuplicateKeys.String()
+	}
+	if b.Options.UTF8 != ReplaceInvalid {
+		name += "/utf8=" + b.Options.UTF8.String()
+	}
+	switch {
+	case b.Options.MaxDepth < 0:
+		name += "/depth=unlimited"
+	case b.Options.MaxDepth > 0:
+		name += fmt.Sprintf("/depth=%d", b.Options.MaxDepth)
+	}
+	if b.Options.InternKeys {
+		name += "/intern"
+	}
+	return name
+}
+
+func (b Handrolled) Valid(data []byte) bool {
+	opts := b.Options
+	opts.SyntaxOnly = true
+	_, err := Decode(data, opts)
+	return err == nil
+}
+
+func (b Handrolled) Decode(data []byte) (interface{}, error) {
+	return Decode(data, b.Options)
+}
+
+func init() {
+	Register(Handrolled{})
+}
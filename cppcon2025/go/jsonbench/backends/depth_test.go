@@ -0,0 +1,69 @@
+package backends
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// nested returns depth nested arrays and objects around a number
+func nested(depth int) []byte {
+	var b strings.Builder
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			b.WriteByte('[')
+		} else {
+			b.WriteString(`{"a":`)
+		}
+	}
+	b.WriteByte('1')
+	for i := depth - 1; i >= 0; i-- {
+		if i%2 == 0 {
+			b.WriteByte(']')
+		} else {
+			b.WriteByte('}')
+		}
+	}
+	return []byte(b.String())
+}
+
+// isDepthError tells the nesting errors of each backend from its other
+// errors: the standard library packages only say so in the message
+var isDepthError = map[string]func(error) bool{
+	"encoding/json": func(err error) bool {
+		return strings.Contains(err.Error(), "exceeded max depth")
+	},
+	"encoding/json/v2": func(err error) bool {
+		return strings.Contains(err.Error(), "exceeded max depth")
+	},
+	"handrolled": func(err error) bool { return errors.Is(err, ErrDepth) },
+	"tape":       func(err error) bool { return errors.Is(err, ErrDepth) },
+}
+
+// TestDepth documents where each backend stops: every one accepts
+// DefaultMaxDepth levels, like encoding/json, and fails one deeper with
+// its depth error rather than running out of stack
+func TestDepth(t *testing.T) {
+	type limit struct {
+		b     Backend
+		limit int
+	}
+	limits := []limit{{Handrolled{Options: DecodeOptions{MaxDepth: 100}}, 100}}
+	for _, b := range All() {
+		limits = append(limits, limit{b, DefaultMaxDepth})
+	}
+	for _, l := range limits {
+		name := strings.SplitN(l.b.Name(), "/depth=", 2)[0]
+		isDepth := isDepthError[name]
+		if isDepth == nil {
+			t.Errorf("%s: no depth error known for the backend", l.b.Name())
+			continue
+		}
+		if _, err := l.b.Decode(nested(l.limit)); err != nil {
+			t.Errorf("%s: %d levels: %v", l.b.Name(), l.limit, err)
+		}
+		if _, err := l.b.Decode(nested(l.limit + 1)); err == nil || !isDepth(err) {
+			t.Errorf("%s: %d levels: error %v, want a depth error", l.b.Name(), l.limit+1, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package backends
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+)
+
+// Equal reports whether two JSON documents hold the same value, ignoring
+// whitespace and the order of object members. Numbers are compared as
+// float64, like encoding/json decodes them.
+func Equal(a, b []byte) bool {
+	eq, err := EqualWith(a, b, CompareOptions{})
+	return eq && err == nil
+}
+
+// EqualWith is Equal with explicit comparison options. It returns an error
+// when either document is not valid JSON.
+func EqualWith(a, b []byte, opt CompareOptions) (bool, error) {
+	va, err := decodeForCompare(a, opt)
+	if err != nil {
+		return false, err
+	}
+	vb, err := decodeForCompare(b, opt)
+	if err != nil {
+		return false, err
+	}
+	return Diff("$", va, vb, opt) == "", nil
+}
+
+// decodeForCompare decodes with encoding/json, keeping the literal text of
+// numbers when they are compared exactly
+func decodeForCompare(data []byte, opt CompareOptions) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if opt.ExactNumbers {
+		dec.UseNumber()
+	}
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	// More is false before a closing bracket, so only EOF ends the
+	// document
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, &SyntaxError{msg: "unexpected data after top-level value", Offset: int(dec.InputOffset())}
+	}
+	return v, nil
+}
+
+// numbersEqualExact compares two number literals as exact decimals, so
+// that 1e2 equals 100 but 9007199254740993 differs from 9007199254740992
+func numbersEqualExact(x, y json.Number) bool {
+	rx, okx := new(big.Rat).SetString(string(x))
+	ry, oky := new(big.Rat).SetString(string(y))
+	return okx && oky && rx.Cmp(ry) == 0
+}
@@ -0,0 +1,28 @@
+package backends
+
+import "testing"
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{`{"a":1,"b":[true,null]}`, ` { "b" : [ true , null ] , "a" : 1.0 } `, true},
+		{`[1,2]`, `[2,1]`, false},
+		{`"x"`, `"y"`, false},
+		{`1`, `1 `, true},
+		// Trailing data makes a document invalid, closing brackets included
+		{`1 ]`, `1`, false},
+		{`{} }`, `{}`, false},
+		{`[] 2`, `[]`, false},
+		{`{}`, `{`, false},
+	}
+	for _, tt := range tests {
+		if got := Equal([]byte(tt.a), []byte(tt.b)); got != tt.want {
+			t.Errorf("Equal(%#q, %#q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := Equal([]byte(tt.b), []byte(tt.a)); got != tt.want {
+			t.Errorf("Equal(%#q, %#q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package backends
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var fuzzSeeds = []string{
+	`{}`,
+	`[]`,
+	`null`,
+	`{"user":{"id":1,"screen_name":"simdjson","verified":true}}`,
+	`[1.5e300, -0, 18446744073709551616, "é😀"]`,
+	`[[[[[[[[[[]]]]]]]]]]`,
+	`{"a":`,
+	`"\x"`,
+	`{"a":1,"a":2}`,
+	"[\"\xff\"]",
+	`"\ud800"`,
+}
+
+// strictBackends reject duplicate keys and invalid UTF-8, lone surrogate
+// escapes included, which encoding/json accepts. The fuzz targets compare
+// them only on documents without either, which the hand-rolled decoder
+// with strictOptions accepts.
+var strictBackends = map[string]bool{"encoding/json/v2": true}
+
+var strictOptions = DecodeOptions{DuplicateKeys: RejectDuplicates, UTF8: RejectInvalid, SyntaxOnly: true}
+
+// FuzzParse feeds arbitrary bytes to every backend: none may panic and all
+// must agree with encoding/json on whether the input is valid JSON, and on
+// whether it decodes (numbers out of the float64 range are valid JSON that
+// encoding/json refuses to decode). Strict backends are exempt on the
+// inputs they are documented to reject.
+//
+//	go test -fuzz=FuzzParse
+func FuzzParse(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		want := json.Valid(data)
+		var v interface{}
+		wantErr := json.Unmarshal(data, &v)
+		_, strictErr := Decode(data, strictOptions)
+		for _, b := range All() {
+			if strictBackends[b.Name()] && want && strictErr != nil {
+				continue
+			}
+			if got := b.Valid(data); got != want {
+				t.Errorf("%s: Valid = %v, encoding/json says %v", b.Name(), got, want)
+			}
+			if _, err := b.Decode(data); (err == nil) != (wantErr == nil) {
+				t.Errorf("%s: Decode error = %v, encoding/json error = %v", b.Name(), err, wantErr)
+			}
+		}
+	})
+}
+
+// FuzzDifferential decodes the same input with every pair of compiled-in
+// backends and fails when they disagree, either on validity or on the
+// decoded value; strict backends are exempt as in FuzzParse. The fuzzer
+// minimizes the offending input and stores it under
+// testdata/fuzz/FuzzDifferential.
+//
+//	go test -fuzz=FuzzDifferential
+func FuzzDifferential(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, strictErr := Decode(data, strictOptions)
+		for i, a := range All() {
+			va, erra := a.Decode(data)
+			for _, b := range All()[i+1:] {
+				if strictErr != nil && (strictBackends[a.Name()] || strictBackends[b.Name()]) {
+					continue
+				}
+				vb, errb := b.Decode(data)
+				if (erra == nil) != (errb == nil) {
+					t.Fatalf("%s and %s disagree on %q:\n  %s: %v\n  %s: %v",
+						a.Name(), b.Name(), data, a.Name(), erra, b.Name(), errb)
+				}
+				if erra != nil {
+					continue
+				}
+				if d := Diff("$", va, vb, CompareOptions{}); d != "" {
+					t.Fatalf("%s and %s decode %q differently: %s", a.Name(), b.Name(), data, d)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,19 @@
+//go:build goexperiment.jsonv2 && !go1.27 && !tinygo
+
+package backends
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+)
+
+// Before Go 1.27 the v2 packages are experimental and any language
+// version may use them
+
+func jsonv2Valid(data []byte) bool { return jsontext.Value(data).IsValid() }
+
+func jsonv2Unmarshal(data []byte, v interface{}) error { return jsonv2.Unmarshal(data, v) }
+
+func jsonv2Marshal(v interface{}) ([]byte, error) {
+	return jsonv2.Marshal(v, jsonv2.Deterministic(true))
+}
@@ -0,0 +1,20 @@
+//go:build goexperiment.jsonv2 && go1.27 && !tinygo
+
+package backends
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+)
+
+// From Go 1.27 the v2 API is only available to files built for go1.27 or
+// later, which the build constraint declares; the module itself asks for
+// an older version
+
+func jsonv2Valid(data []byte) bool { return jsontext.Value(data).IsValid() }
+
+func jsonv2Unmarshal(data []byte, v interface{}) error { return jsonv2.Unmarshal(data, v) }
+
+func jsonv2Marshal(v interface{}) ([]byte, error) {
+	return jsonv2.Marshal(v, jsonv2.Deterministic(true))
+}
@@ -0,0 +1,212 @@
+package backends
+
+import "unicode/utf8"
+
+// Streaming access to the hand-rolled decoder: instead of building the
+// generic value, a caller walks the document and picks the values it
+// needs, which is how On-Demand parsing works in simdjson.
+
+// Members calls member for each key of the object at the current
+// position, which must consume the value
+func (d *Decoder) Members(member func(key []byte) error) error {
+	if d.pos >= len(d.data) || d.data[d.pos] != '{' {
+		return d.Errorf("expected object")
+	}
+	if err := d.enter(); err != nil {
+		return err
+	}
+	d.pos++
+	d.skipWhitespace()
+	if d.pos < len(d.data) && d.data[d.pos] == '}' {
+		d.pos++
+		d.depth--
+		return nil
+	}
+	var key []byte
+	for {
+		if d.pos >= len(d.data) || d.data[d.pos] != '"' {
+			return d.Errorf("expected string key")
+		}
+		var err error
+		if key, err = d.StringBytes(key[:0]); err != nil {
+			return err
+		}
+		d.skipWhitespace()
+		if d.pos >= len(d.data) || d.data[d.pos] != ':' {
+			return d.Errorf("expected ':' after object key")
+		}
+		d.pos++
+		d.skipWhitespace()
+		if err := member(key); err != nil {
+			return err
+		}
+		d.skipWhitespace()
+		if d.pos >= len(d.data) {
+			return d.Errorf("unexpected end of input in object")
+		}
+		switch d.data[d.pos] {
+		case ',':
+			d.pos++
+			d.skipWhitespace()
+		case '}':
+			d.pos++
+			d.depth--
+			return nil
+		default:
+			return d.Errorf("expected ',' or '}' in object")
+		}
+	}
+}
+
+// Elements calls element for each value of the array at the current
+// position, which must consume it
+func (d *Decoder) Elements(element func() error) error {
+	if d.pos >= len(d.data) || d.data[d.pos] != '[' {
+		return d.Errorf("expected array")
+	}
+	if err := d.enter(); err != nil {
+		return err
+	}
+	d.pos++
+	d.skipWhitespace()
+	if d.pos < len(d.data) && d.data[d.pos] == ']' {
+		d.pos++
+		d.depth--
+		return nil
+	}
+	for {
+		if err := element(); err != nil {
+			return err
+		}
+		d.skipWhitespace()
+		if d.pos >= len(d.data) {
+			return d.Errorf("unexpected end of input in array")
+		}
+		switch d.data[d.pos] {
+		case ',':
+			d.pos++
+			d.skipWhitespace()
+		case ']':
+			d.pos++
+			d.depth--
+			return nil
+		default:
+			return d.Errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+// StringBytes appends the string at the current position to buf. Plain
+// ASCII is copied straight from the input; the rest takes the slow path.
+func (d *Decoder) StringBytes(buf []byte) ([]byte, error) {
+	if d.pos >= len(d.data) || d.data[d.pos] != '"' {
+		return nil, d.Errorf("expected string")
+	}
+	start := d.pos + 1
+	for i := start; i < len(d.data); i++ {
+		c := d.data[i]
+		if c == '"' {
+			d.pos = i + 1
+			return append(buf, d.data[start:i]...), nil
+		}
+		if c == '\\' || c < 0x20 || c >= utf8.RuneSelf {
+			break
+		}
+	}
+	s, err := d.String()
+	return append(buf, s...), err
+}
+
+// Uint64 parses a non-negative integer
+func (d *Decoder) Uint64() (uint64, error) {
+	start := d.pos
+	var v uint64
+	for d.pos < len(d.data) && d.data[d.pos] >= '0' && d.data[d.pos] <= '9' {
+		digit := uint64(d.data[d.pos] - '0')
+		if v > (1<<64-1-digit)/10 {
+			return 0, &SyntaxError{msg: "number out of uint64 range", Offset: start}
+		}
+		v = v*10 + digit
+		d.pos++
+	}
+	switch {
+	case d.pos == start:
+		return 0, d.Errorf("expected unsigned integer")
+	case d.pos-start > 1 && d.data[start] == '0':
+		return 0, &SyntaxError{msg: "invalid number", Offset: start}
+	case d.pos < len(d.data) && (d.data[d.pos] == '.' || d.data[d.pos] == 'e' || d.data[d.pos] == 'E'):
+		return 0, d.Errorf("expected unsigned integer")
+	}
+	return v, nil
+}
+
+// Skip checks and discards the value at the current position
+func (d *Decoder) Skip() error {
+	if d.pos >= len(d.data) {
+		return d.Errorf("unexpected end of input")
+	}
+	switch c := d.data[d.pos]; {
+	case c == '{':
+		return d.Members(func([]byte) error { return d.Skip() })
+	case c == '[':
+		return d.Elements(d.Skip)
+	case c == '"':
+		_, err := d.String()
+		return err
+	case c == 't':
+		return d.Literal("true")
+	case c == 'f':
+		return d.Literal("false")
+	case c == 'n':
+		return d.Literal("null")
+	case c == '-' || (c >= '0' && c <= '9'):
+		syntaxOnly := d.opts.SyntaxOnly
+		d.opts.SyntaxOnly = true
+		_, err := d.Number()
+		d.opts.SyntaxOnly = syntaxOnly
+		return err
+	default:
+		return d.Errorf("invalid character %q looking for a value", c)
+	}
+}
+
+// NewDecoder returns a decoder positioned at the first value of data
+func NewDecoder(data []byte, opts DecodeOptions) *Decoder {
+	d := &Decoder{data: data, opts: opts, maxDepth: opts.MaxDepth}
+	if d.maxDepth == 0 {
+		d.maxDepth = DefaultMaxDepth
+	}
+	if opts.InternKeys {
+		d.keys = make(map[string]string)
+	}
+	d.skipWhitespace()
+	return d
+}
+
+// Peek returns the first byte of the value at the current position, or 0
+// at the end of the input
+func (d *Decoder) Peek() byte {
+	if d.pos >= len(d.data) {
+		return 0
+	}
+	return d.data[d.pos]
+}
+
+// Offset returns the current position in the input
+func (d *Decoder) Offset() int { return d.pos }
+
+// SkipRaw checks the value at the current position and returns its text
+func (d *Decoder) SkipRaw() ([]byte, error) {
+	start := d.pos
+	err := d.Skip()
+	return d.data[start:d.pos], err
+}
+
+// End checks that nothing but whitespace follows the top-level value
+func (d *Decoder) End() error {
+	d.skipWhitespace()
+	if d.pos != len(d.data) {
+		return d.Errorf("unexpected data after top-level value")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package backends
+
+import "fmt"
+
+// The passes of a two-stage parser like simdjson, built from the pieces
+// of the hand-rolled decoder so that each can be timed alone: the
+// structural index of stage 1, then the number and string conversions of
+// stage 2. The hand-rolled decoder interleaves them in one pass, where
+// timing each number or string would cost more than converting it.
+
+// StructuralIndex appends to idx the offset of every structural character
+// of data outside strings ({ } [ ] : ,), of every opening quote and of
+// the first byte of every other scalar, which is simdjson's stage 1. It
+// checks only that strings are closed.
+func StructuralIndex(data []byte, idx []uint32) ([]uint32, error) {
+	inScalar := false
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '{', '}', '[', ']', ':', ',':
+			idx = append(idx, uint32(i))
+			inScalar = false
+		case ' ', '\t', '\n', '\r':
+			inScalar = false
+		case '"':
+			idx = append(idx, uint32(i))
+			inScalar = false
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(data) {
+				return idx, fmt.Errorf("unterminated string at offset %d", idx[len(idx)-1])
+			}
+		default:
+			if !inScalar {
+				idx = append(idx, uint32(i))
+				inScalar = true
+			}
+		}
+	}
+	return idx, nil
+}
+
+// ParseNumbers converts every number of an indexed document to a
+// float64, as the hand-rolled decoder does, and returns their number
+func ParseNumbers(data []byte, idx []uint32) (int, error) {
+	d := &Decoder{data: data}
+	n := 0
+	for _, off := range idx {
+		if c := data[off]; c != '-' && (c < '0' || c > '9') {
+			continue
+		}
+		d.pos = int(off)
+		if _, err := d.Number(); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// ParseStrings unescapes every string, keys included, of an indexed
+// document, as the hand-rolled decoder does, and returns their number
+func ParseStrings(data []byte, idx []uint32) (int, error) {
+	d := &Decoder{data: data}
+	n := 0
+	for _, off := range idx {
+		if data[off] != '"' {
+			continue
+		}
+		d.pos = int(off)
+		if _, err := d.String(); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,308 @@
+package backends
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+// A document in simdjson's tape format: parsed once into a flat array of
+// 64-bit words, it can be traversed any number of times without parsing
+// again. Each word holds a type character in its top byte and a 56-bit
+// payload:
+//
+//	r    root, at both ends: the first points past the last
+//	{ [  start of a container: count<<32 | index past the matching end
+//	} ]  end of a container: index of the matching start
+//	"    string: offset of its uint32 length, bytes and NUL in strings
+//	l u d  int64, uint64, double: the value is the next word
+//	t f n  true, false, null
+//
+// Counts above TapeMaxCount saturate, as in simdjson. Package dom builds
+// the same tape with simdjson's two stages.
+type Tape struct {
+	Words   []uint64
+	Strings []byte
+}
+
+// TapeMaxCount is the largest count a container word holds
+const TapeMaxCount = 0xffffff
+
+const tapePayload = 1<<56 - 1
+
+// BuildTape parses data into t, reusing its storage
+func BuildTape(data []byte, t *Tape) error {
+	t.Words = append(t.Words[:0], 'r'<<56)
+	t.Strings = t.Strings[:0]
+	d := &Decoder{data: data, maxDepth: DefaultMaxDepth}
+	d.skipWhitespace()
+	if err := t.value(d); err != nil {
+		return err
+	}
+	d.skipWhitespace()
+	if d.pos != len(d.data) {
+		return d.Errorf("unexpected data after top-level value")
+	}
+	t.Words[0] |= uint64(len(t.Words))
+	t.Words = append(t.Words, 'r'<<56)
+	return nil
+}
+
+func (t *Tape) value(d *Decoder) error {
+	if d.pos >= len(d.data) {
+		return d.Errorf("unexpected end of input")
+	}
+	switch c := d.data[d.pos]; {
+	case c == '{':
+		open := len(t.Words)
+		t.Words = append(t.Words, '{'<<56)
+		count := 0
+		err := d.Members(func(key []byte) error {
+			t.string(key)
+			count++
+			return t.value(d)
+		})
+		if err != nil {
+			return err
+		}
+		t.Close(open, '}', count)
+	case c == '[':
+		open := len(t.Words)
+		t.Words = append(t.Words, '['<<56)
+		count := 0
+		err := d.Elements(func() error {
+			count++
+			return t.value(d)
+		})
+		if err != nil {
+			return err
+		}
+		t.Close(open, ']', count)
+	case c == '"':
+		// The bytes go straight into the string buffer after their length
+		start := len(t.Strings)
+		s, err := d.StringBytes(append(t.Strings, 0, 0, 0, 0))
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint32(s[start:], uint32(len(s)-start-4))
+		t.Strings = append(s, 0)
+		t.Words = append(t.Words, '"'<<56|uint64(start))
+	case c == 't':
+		t.Words = append(t.Words, 't'<<56)
+		return d.Literal("true")
+	case c == 'f':
+		t.Words = append(t.Words, 'f'<<56)
+		return d.Literal("false")
+	case c == 'n':
+		t.Words = append(t.Words, 'n'<<56)
+		return d.Literal("null")
+	case c == '-' || (c >= '0' && c <= '9'):
+		return t.number(d)
+	default:
+		return d.Errorf("invalid character %q looking for a value", c)
+	}
+	return nil
+}
+
+// string appends a key, which the decoder has already unescaped
+func (t *Tape) string(s []byte) {
+	start := len(t.Strings)
+	t.Strings = binary.LittleEndian.AppendUint32(t.Strings, uint32(len(s)))
+	t.Strings = append(append(t.Strings, s...), 0)
+	t.Words = append(t.Words, '"'<<56|uint64(start))
+}
+
+// Close appends the end of the container that starts at word open and
+// records its count, saturated, and where it ends in its start
+func (t *Tape) Close(open int, end byte, count int) {
+	t.Words = append(t.Words, uint64(end)<<56|uint64(open))
+	t.Words[open] |= uint64(min(count, TapeMaxCount))<<32 | uint64(len(t.Words))
+}
+
+// Next returns the index of the value after the one at word i, jumping
+// over containers
+func (t *Tape) Next(i int) int {
+	switch byte(t.Words[i] >> 56) {
+	case '{', '[':
+		return int(uint32(t.Words[i]))
+	case 'l', 'u', 'd':
+		return i + 2
+	}
+	return i + 1
+}
+
+// Count returns the count of the container at word i, which saturates at
+// TapeMaxCount
+func (t *Tape) Count(i int) int { return int(t.Words[i] >> 32 & TapeMaxCount) }
+
+// StringAt returns the string at word i in place, without copying
+func (t *Tape) StringAt(i int) []byte {
+	start := int(t.Words[i] & tapePayload)
+	n := int(binary.LittleEndian.Uint32(t.Strings[start:]))
+	return t.Strings[start+4 : start+4+n]
+}
+
+// number stores integers as int64, or uint64 when too large, and anything
+// else, or integers beyond uint64, as doubles, which is simdjson's choice
+func (t *Tape) number(d *Decoder) error {
+	start := d.pos
+	d.opts.SyntaxOnly = true
+	_, err := d.Number()
+	d.opts.SyntaxOnly = false
+	if err != nil {
+		return err
+	}
+	text := string(d.data[start:d.pos])
+	integer := true
+	for i := 0; i < len(text); i++ {
+		if c := text[i]; c == '.' || c == 'e' || c == 'E' {
+			integer = false
+			break
+		}
+	}
+	if integer {
+		if v, err := strconv.ParseInt(text, 10, 64); err == nil {
+			t.Words = append(t.Words, 'l'<<56, uint64(v))
+			return nil
+		}
+		if v, err := strconv.ParseUint(text, 10, 64); err == nil {
+			t.Words = append(t.Words, 'u'<<56, v)
+			return nil
+		}
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return &SyntaxError{msg: "number out of float64 range", Offset: start}
+	}
+	t.Words = append(t.Words, 'd'<<56, math.Float64bits(f))
+	return nil
+}
+
+// TapeRef is a value on a tape; the zero TapeRef, which Get returns for
+// a missing key, has no kind and no fields or elements
+type TapeRef struct {
+	t *Tape
+	i int
+}
+
+// Root returns the top-level value
+func (t *Tape) Root() TapeRef { return TapeRef{t, 1} }
+
+func (r TapeRef) kind() byte {
+	if r.t == nil {
+		return 0
+	}
+	return byte(r.t.Words[r.i] >> 56)
+}
+
+// Bytes returns a string value in place, without copying
+func (r TapeRef) Bytes() []byte {
+	if r.kind() != '"' {
+		return nil
+	}
+	return r.t.StringAt(r.i)
+}
+
+func (r TapeRef) Uint64() uint64 {
+	switch r.kind() {
+	case 'l', 'u':
+		return r.t.Words[r.i+1]
+	case 'd':
+		return uint64(math.Float64frombits(r.t.Words[r.i+1]))
+	}
+	return 0
+}
+
+func (r TapeRef) float64() float64 {
+	switch r.kind() {
+	case 'l':
+		return float64(int64(r.t.Words[r.i+1]))
+	case 'u':
+		return float64(r.t.Words[r.i+1])
+	case 'd':
+		return math.Float64frombits(r.t.Words[r.i+1])
+	}
+	return 0
+}
+
+// Get returns the value of key in an object; the last one if it repeats,
+// as encoding/json takes
+func (r TapeRef) Get(key string) (TapeRef, bool) {
+	var found TapeRef
+	ok := false
+	r.fields(func(k []byte, v TapeRef) {
+		if string(k) == key {
+			found, ok = v, true
+		}
+	})
+	return found, ok
+}
+
+// fields calls fn with each key and value of an object
+func (r TapeRef) fields(fn func(key []byte, v TapeRef)) {
+	if r.kind() != '{' {
+		return
+	}
+	for i := r.i + 1; byte(r.t.Words[i]>>56) != '}'; {
+		k, v := TapeRef{r.t, i}, TapeRef{r.t, i + 1}
+		fn(k.Bytes(), v)
+		i = r.t.Next(i + 1)
+	}
+}
+
+// Elements calls fn with each element of an array
+func (r TapeRef) Elements(fn func(v TapeRef)) {
+	if r.kind() != '[' {
+		return
+	}
+	for i := r.i + 1; byte(r.t.Words[i]>>56) != ']'; {
+		v := TapeRef{r.t, i}
+		fn(v)
+		i = r.t.Next(i)
+	}
+}
+
+// Value converts r to the generic values encoding/json produces
+func (r TapeRef) Value() interface{} {
+	switch r.kind() {
+	case '{':
+		m := make(map[string]interface{}, r.t.Count(r.i))
+		r.fields(func(k []byte, v TapeRef) { m[string(k)] = v.Value() })
+		return m
+	case '[':
+		a := make([]interface{}, 0, r.t.Count(r.i))
+		r.Elements(func(v TapeRef) { a = append(a, v.Value()) })
+		return a
+	case '"':
+		return string(r.Bytes())
+	case 'l', 'u', 'd':
+		return r.float64()
+	case 't':
+		return true
+	case 'f':
+		return false
+	}
+	return nil
+}
+
+// tapeBackend decodes by building a tape and converting it, so every
+// command checks the tape against the other backends. The tape is built
+// with the hand-rolled decoder's scanner, so validity is the same as its.
+type tapeBackend struct{}
+
+func (tapeBackend) Name() string { return "tape" }
+
+func (tapeBackend) Valid(data []byte) bool { return Handrolled{}.Valid(data) }
+
+func (tapeBackend) Decode(data []byte) (interface{}, error) {
+	var t Tape
+	if err := BuildTape(data, &t); err != nil {
+		return nil, err
+	}
+	return t.Root().Value(), nil
+}
+
+func init() {
+	Register(tapeBackend{})
+}
@@ -0,0 +1,45 @@
+package bench
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/resultschema"
+)
+
+// ErrNoCounters is returned by OpenCounters when the hardware counters
+// cannot be read: outside Linux, or when the kernel refuses perf events
+var ErrNoCounters = errors.New("hardware counters unavailable")
+
+// CounterValues are hardware event counts over a benchmark loop
+type CounterValues = resultschema.Counters
+
+// CounterSummary describes the counters for a loop over the given number
+// of bytes
+func CounterSummary(v CounterValues, bytes float64) string {
+	return fmt.Sprintf("IPC %.2f, %.2f ins/byte, %.2f cycles/byte, branch miss %.2f%%, cache miss %.2f%%",
+		v.IPC(), v.Instructions/bytes, v.Cycles/bytes, 100*v.BranchMissRate(), 100*v.CacheMissRate())
+}
+
+// MeasureCounters runs measure on a locked OS thread with the hardware
+// counters enabled around the timed loop. Only the benchmark thread is
+// counted; work done by GC background workers on other threads is not.
+// The caller must have locked its goroutine to the thread before opening
+// c, and keep it locked until c is closed.
+func MeasureCounters(c *Counters, data []byte, iterations int, fn func([]byte) error) (float64, CounterValues, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := fn(data); err != nil {
+		return 0, CounterValues{}, err
+	}
+	if err := c.Start(); err != nil {
+		return 0, CounterValues{}, err
+	}
+	speed, err := Measure(data, iterations, fn)
+	v, cerr := c.Stop()
+	if err == nil {
+		err = cerr
+	}
+	return speed, v, err
+}
@@ -0,0 +1,144 @@
+//go:build !tinygo
+
+package bench
+
+import (
+	"encoding/binary"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// perfEventAttr mirrors struct perf_event_attr (PERF_ATTR_SIZE_VER5)
+type perfEventAttr struct {
+	Type             uint32
+	Size             uint32
+	Config           uint64
+	SamplePeriod     uint64
+	SampleType       uint64
+	ReadFormat       uint64
+	Flags            uint64
+	WakeupEvents     uint32
+	BpType           uint32
+	Config1          uint64
+	Config2          uint64
+	BranchSampleType uint64
+	SampleRegsUser   uint64
+	SampleStackUser  uint32
+	ClockID          int32
+	SampleRegsIntr   uint64
+	AuxWatermark     uint32
+	SampleMaxStack   uint16
+	_                uint16
+}
+
+const (
+	perfTypeHardware = 0
+
+	perfCountHWCPUCycles       = 0
+	perfCountHWInstructions    = 1
+	perfCountHWCacheReferences = 2
+	perfCountHWCacheMisses     = 3
+	perfCountHWBranchInstr     = 4
+	perfCountHWBranchMisses    = 5
+
+	perfFormatTotalTimeEnabled = 1 << 0
+	perfFormatTotalTimeRunning = 1 << 1
+
+	perfAttrDisabled      = 1 << 0
+	perfAttrExcludeKernel = 1 << 5
+	perfAttrExcludeHV     = 1 << 6
+
+	perfFlagFDCloexec = 1 << 3
+
+	perfEventIocEnable  = 0x2400
+	perfEventIocDisable = 0x2401
+	perfEventIocReset   = 0x2403
+)
+
+// counterConfigs lists the events in the order of the CounterValues fields
+var counterConfigs = []uint64{
+	perfCountHWCPUCycles,
+	perfCountHWInstructions,
+	perfCountHWBranchInstr,
+	perfCountHWBranchMisses,
+	perfCountHWCacheReferences,
+	perfCountHWCacheMisses,
+}
+
+// Counters is a set of hardware counters for the calling thread, user
+// space only so that it works with the default perf_event_paranoid setting.
+// Callers must lock the goroutine to its thread with runtime.LockOSThread
+// before opening the counters and keep it there until closing them.
+type Counters struct {
+	fds []int
+}
+
+func OpenCounters() (*Counters, error) {
+	c := &Counters{}
+	for _, config := range counterConfigs {
+		attr := perfEventAttr{
+			Type:       perfTypeHardware,
+			Config:     config,
+			ReadFormat: perfFormatTotalTimeEnabled | perfFormatTotalTimeRunning,
+			Flags:      perfAttrDisabled | perfAttrExcludeKernel | perfAttrExcludeHV,
+		}
+		attr.Size = uint32(unsafe.Sizeof(attr))
+		fd, _, errno := syscall.Syscall6(syscall.SYS_PERF_EVENT_OPEN,
+			uintptr(unsafe.Pointer(&attr)), 0, ^uintptr(0), ^uintptr(0), perfFlagFDCloexec, 0)
+		if errno != 0 {
+			c.Close()
+			return nil, fmt.Errorf("%w: perf_event_open: %w", ErrNoCounters, errno)
+		}
+		c.fds = append(c.fds, int(fd))
+	}
+	return c, nil
+}
+
+func (c *Counters) ioctlAll(req uintptr) error {
+	for _, fd := range c.fds {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, 0); errno != 0 {
+			return fmt.Errorf("perf ioctl: %w", errno)
+		}
+	}
+	return nil
+}
+
+// Start resets and enables the counters
+func (c *Counters) Start() error {
+	if err := c.ioctlAll(perfEventIocReset); err != nil {
+		return err
+	}
+	return c.ioctlAll(perfEventIocEnable)
+}
+
+// Stop disables the counters and reads them, scaling each value when the
+// kernel had to multiplex the events
+func (c *Counters) Stop() (CounterValues, error) {
+	var v CounterValues
+	if err := c.ioctlAll(perfEventIocDisable); err != nil {
+		return v, err
+	}
+	fields := []*float64{&v.Cycles, &v.Instructions, &v.Branches, &v.BranchMisses, &v.CacheReferences, &v.CacheMisses}
+	var buf [24]byte
+	for i, fd := range c.fds {
+		if _, err := syscall.Read(fd, buf[:]); err != nil {
+			return v, fmt.Errorf("reading counter: %w", err)
+		}
+		value := float64(binary.LittleEndian.Uint64(buf[0:]))
+		enabled := float64(binary.LittleEndian.Uint64(buf[8:]))
+		running := float64(binary.LittleEndian.Uint64(buf[16:]))
+		if running > 0 && running < enabled {
+			value *= enabled / running
+		}
+		*fields[i] = value
+	}
+	return v, nil
+}
+
+func (c *Counters) Close() {
+	for _, fd := range c.fds {
+		syscall.Close(fd)
+	}
+	c.fds = nil
+}
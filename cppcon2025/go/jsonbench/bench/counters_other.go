@@ -0,0 +1,18 @@
+//go:build !linux || tinygo
+
+package bench
+
+import "fmt"
+
+// Counters is only implemented on Linux
+type Counters struct{}
+
+func OpenCounters() (*Counters, error) {
+	return nil, fmt.Errorf("%w: only supported on Linux with the gc toolchain", ErrNoCounters)
+}
+
+func (c *Counters) Start() error { return nil }
+
+func (c *Counters) Stop() (CounterValues, error) { return CounterValues{}, nil }
+
+func (c *Counters) Close() {}
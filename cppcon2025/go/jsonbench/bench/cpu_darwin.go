@@ -0,0 +1,14 @@
+//go:build !tinygo
+
+package bench
+
+import "syscall"
+
+// sysctlCPUModel returns the brand string, such as "Apple M2 Max"
+func sysctlCPUModel() string {
+	model, err := syscall.Sysctl("machdep.cpu.brand_string")
+	if err != nil {
+		return ""
+	}
+	return model
+}
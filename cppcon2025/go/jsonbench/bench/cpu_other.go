@@ -0,0 +1,5 @@
+//go:build !darwin || tinygo
+
+package bench
+
+func sysctlCPUModel() string { return "" }
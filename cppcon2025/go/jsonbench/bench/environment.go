@@ -0,0 +1,76 @@
+package bench
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/resultschema"
+)
+
+// CurrentEnvironment describes this machine and Go version; the
+// fingerprint hashes the machine fields only
+func CurrentEnvironment() resultschema.Environment {
+	e := resultschema.Environment{
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+		CPU:     cpuModel(),
+		NumCPU:  runtime.NumCPU(),
+		Runtime: runtime.Version(),
+	}
+	e.Hostname, _ = os.Hostname()
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%d", e.Hostname, e.OS, e.Arch, e.CPU, e.NumCPU)))
+	e.Fingerprint = fmt.Sprintf("%x", sum[:6])
+	return e
+}
+
+// armParts names the Arm Ltd. cores (CPU implementer 0x41) found in cloud
+// machines, whose /proc/cpuinfo has no model name
+var armParts = map[string]string{
+	"0xd0c": "Neoverse-N1 (Graviton2)",
+	"0xd40": "Neoverse-V1 (Graviton3)",
+	"0xd49": "Neoverse-N2",
+	"0xd4f": "Neoverse-V2 (Graviton4)",
+}
+
+// cpuModel reads the processor name from sysctl on macOS or /proc/cpuinfo
+// elsewhere, where available
+func cpuModel() string {
+	if model := sysctlCPUModel(); model != "" {
+		return model
+	}
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	var implementer, part string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		key, value, ok := strings.Cut(s.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "model name":
+			return value
+		case "CPU implementer":
+			implementer = value
+		case "CPU part":
+			part = value
+		}
+	}
+	if implementer == "0x41" {
+		if name, ok := armParts[part]; ok {
+			return "ARM " + name
+		}
+	}
+	if part != "" {
+		return fmt.Sprintf("implementer %s part %s", implementer, part)
+	}
+	return ""
+}
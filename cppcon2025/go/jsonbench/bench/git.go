@@ -0,0 +1,22 @@
+//go:build !tinygo
+
+package bench
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// GitCommit returns the short hash of the checked out commit, with a
+// "-dirty" suffix when there are local changes, or "" outside a repository
+func GitCommit() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	commit := strings.TrimSpace(string(out))
+	if status, err := exec.Command("git", "status", "--porcelain").Output(); err == nil && len(status) > 0 {
+		commit += "-dirty"
+	}
+	return commit
+}
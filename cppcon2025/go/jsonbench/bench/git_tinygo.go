@@ -0,0 +1,5 @@
+//go:build tinygo
+
+package bench
+
+func GitCommit() string { return "" }
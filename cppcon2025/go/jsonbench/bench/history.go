@@ -0,0 +1,48 @@
+package bench
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// AppendHistory adds one run to the JSONL history file
+func AppendHistory(path string, rf ResultFile) error {
+	line, err := json.Marshal(rf)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadHistory loads every run recorded in a JSONL history file
+func ReadHistory(path string) ([]ResultFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var runs []ResultFile
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<24)
+	for n := 1; s.Scan(); n++ {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		var rf ResultFile
+		if err := json.Unmarshal(s.Bytes(), &rf); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		runs = append(runs, rf)
+	}
+	return runs, s.Err()
+}
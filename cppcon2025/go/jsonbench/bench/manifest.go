@@ -0,0 +1,188 @@
+package bench
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/resultschema"
+)
+
+// Manifest records how a run was made: the command line, the value of
+// every flag, the environment variables that tune the Go runtime, the
+// binary and how it was built, the machine and its CPU frequency
+// governor, and the exact datasets. It is written next to the results of
+// the run, so a number on a slide can be traced back to what produced it.
+type Manifest struct {
+	Time        time.Time                `json:"time"`
+	Commit      string                   `json:"commit,omitempty"`
+	Args        []string                 `json:"args"`
+	Flags       map[string]string        `json:"flags"`
+	Env         map[string]string        `json:"env,omitempty"`
+	Build       Build                    `json:"build"`
+	Environment resultschema.Environment `json:"environment"`
+	Governor    string                   `json:"cpu_governor,omitempty"`
+	Datasets    []Dataset                `json:"datasets"`
+}
+
+// Build describes the binary that made a run, from the build information
+// the Go toolchain embeds in it: the toolchain, the module and the hashes
+// of its dependencies, and the build settings such as -tags, -gcflags,
+// CGO_ENABLED, GOAMD64 and the VCS revision.
+type Build struct {
+	GoVersion string            `json:"go_version"`
+	Main      Module            `json:"main"`
+	Deps      []Module          `json:"deps,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+}
+
+// Module is a module version and its go.sum hash
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+}
+
+// CurrentBuild returns the build information of the running binary, with
+// only the toolchain version when there is none, as under go run of a
+// file outside a module or in some TinyGo builds
+func CurrentBuild() Build {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Build{GoVersion: runtime.Version()}
+	}
+	b := Build{
+		GoVersion: info.GoVersion,
+		Main:      Module{Path: info.Main.Path, Version: info.Main.Version, Sum: info.Main.Sum},
+		Settings:  map[string]string{},
+	}
+	for _, m := range info.Deps {
+		if m.Replace != nil {
+			m = m.Replace
+		}
+		b.Deps = append(b.Deps, Module{Path: m.Path, Version: m.Version, Sum: m.Sum})
+	}
+	for _, s := range info.Settings {
+		b.Settings[s.Key] = s.Value
+	}
+	return b
+}
+
+// cpuGovernor returns the cpufreq scaling governors of the CPUs, such as
+// "performance" or "powersave" on Linux, or "" where there is none. A
+// powersave governor can cost a benchmark more than any code change.
+func cpuGovernor() string {
+	paths, _ := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*/cpufreq/scaling_governor")
+	var governors []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		g := strings.TrimSpace(string(data))
+		if !slices.Contains(governors, g) {
+			governors = append(governors, g)
+		}
+	}
+	return strings.Join(governors, ",")
+}
+
+// Dataset identifies one input document by content
+type Dataset struct {
+	Path   string `json:"path"`
+	Bytes  int    `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// NewDataset checksums the contents of the document read from path
+func NewDataset(path string, data []byte) Dataset {
+	sum := sha256.Sum256(data)
+	return Dataset{Path: path, Bytes: len(data), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// runtimeVariables change the behavior or the code generation of Go
+// programs, and so the numbers they measure
+var runtimeVariables = []string{"GOGC", "GOMEMLIMIT", "GOMAXPROCS", "GODEBUG", "GOEXPERIMENT", "GOAMD64", "GOARM64"}
+
+// RuntimeEnv returns the runtime variables set in the environment
+func RuntimeEnv() map[string]string {
+	env := map[string]string{}
+	for _, name := range runtimeVariables {
+		if v, ok := os.LookupEnv(name); ok {
+			env[name] = v
+		}
+	}
+	return env
+}
+
+// runDirName names the directory of a run by its UTC start time and
+// commit, so that listing the results directory sorts runs by date
+func runDirName(t time.Time, commit string) string {
+	if commit == "" {
+		commit = "nocommit"
+	}
+	return t.UTC().Format("20060102T150405Z") + "-" + commit
+}
+
+// WriteRun creates a directory for the run under root, named after the
+// time and commit of the manifest, and writes manifest.json and
+// results.json to it. It returns the directory.
+func WriteRun(root string, m Manifest, rf ResultFile) (string, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", err
+	}
+	name := runDirName(m.Time, m.Commit)
+	dir := filepath.Join(root, name)
+	for i := 2; ; i++ {
+		err := os.Mkdir(dir, 0o755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+		dir = filepath.Join(root, fmt.Sprintf("%s-%d", name, i))
+	}
+	if err := WriteManifest(filepath.Join(dir, "manifest.json"), m); err != nil {
+		return "", err
+	}
+	return dir, WriteResults(filepath.Join(dir, "results.json"), rf)
+}
+
+// ManifestPath returns where the manifest of a results file goes:
+// results.json has results.manifest.json next to it
+func ManifestPath(results string) string {
+	return strings.TrimSuffix(results, filepath.Ext(results)) + ".manifest.json"
+}
+
+// WriteManifest writes m as indented JSON
+func WriteManifest(path string, m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ReadManifest loads the manifest of a run directory
+func ReadManifest(dir string) (Manifest, error) {
+	var m Manifest
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("%s: %w", dir, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,19 @@
+package bench
+
+import "github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/resultschema"
+
+// Result and ResultFile are the shared result schema; bench -o writes a
+// ResultFile, report, chart and aggregate read them, and each line of the
+// results.jsonl history holds one
+type (
+	Result     = resultschema.Result
+	ResultFile = resultschema.File
+)
+
+func WriteResults(path string, rf ResultFile) error {
+	return resultschema.Write(path, rf)
+}
+
+func ReadResults(path string) (ResultFile, error) {
+	return resultschema.Read(path)
+}
@@ -0,0 +1,184 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/resultschema"
+)
+
+// Options configures Run; apart from Files, which is required, the zero
+// value benchmarks every backend with the defaults of the bench command
+type Options struct {
+	// Files are the documents to decode
+	Files []string
+	// Backends are the names of the backends to measure, all if empty
+	Backends []string
+	// Iterations is the number of decodes per measurement, 100 if zero
+	Iterations int
+	// Count repeats each measurement and reports the median, 1 if zero
+	Count int
+	// Counters reads the hardware counters around each loop (Linux only)
+	Counters bool
+	// Language labels the result file, "Go" if empty
+	Language string
+	// Start, if set, is called before measuring each backend on each
+	// dataset, and Progress after it, with the error that made the
+	// measurement fail if any
+	Start    func(dataset, backend string)
+	Progress func(r Result, err error)
+}
+
+// Failure is a measurement that did not complete
+type Failure struct {
+	Dataset string
+	Backend string
+	Err     error
+}
+
+// Report is the outcome of Run: the result file the bench command
+// writes, the manifest of the run and the measurements that failed
+type Report struct {
+	Results  ResultFile
+	Manifest Manifest
+	Failures []Failure
+}
+
+// Run benchmarks the backends decoding each document. A backend failing
+// on a document is recorded in the report and does not stop the run;
+// reading a document, an unknown backend or ctx being done does. The
+// manifest has no command line or flags, which only the caller knows.
+func Run(ctx context.Context, opt Options) (Report, error) {
+	if len(opt.Files) == 0 {
+		return Report{}, errors.New("bench: no files to decode")
+	}
+	if opt.Iterations <= 0 {
+		opt.Iterations = 100
+	}
+	if opt.Count <= 0 {
+		opt.Count = 1
+	}
+	if opt.Language == "" {
+		opt.Language = "Go"
+	}
+	selected := backends.All()
+	if len(opt.Backends) > 0 {
+		selected = selected[:0:0]
+		for _, name := range opt.Backends {
+			b, err := backends.Lookup(name)
+			if err != nil {
+				return Report{}, err
+			}
+			selected = append(selected, b)
+		}
+	}
+
+	var counters *Counters
+	if opt.Counters {
+		// The counters count the thread that opens them, so every
+		// measurement has to run on it
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		c, err := OpenCounters()
+		if err != nil {
+			return Report{}, err
+		}
+		defer c.Close()
+		counters = c
+	}
+
+	now := time.Now().UTC()
+	env := CurrentEnvironment()
+	rep := Report{
+		Results: ResultFile{
+			Language:    opt.Language,
+			Time:        &now,
+			Commit:      GitCommit(),
+			Environment: &env,
+		},
+	}
+	rep.Manifest = Manifest{
+		Time:        now,
+		Commit:      rep.Results.Commit,
+		Env:         RuntimeEnv(),
+		Build:       CurrentBuild(),
+		Environment: env,
+		Governor:    cpuGovernor(),
+	}
+	for _, file := range opt.Files {
+		if err := ctx.Err(); err != nil {
+			return rep, err
+		}
+		data, err := datasets.Read(file)
+		if err != nil {
+			return rep, err
+		}
+		rep.Manifest.Datasets = append(rep.Manifest.Datasets, NewDataset(file, data))
+		dataset := datasets.Name(file)
+		for _, b := range selected {
+			if err := ctx.Err(); err != nil {
+				return rep, err
+			}
+			if opt.Start != nil {
+				opt.Start(dataset, b.Name())
+			}
+			r, err := measureBackend(ctx, b, data, opt, counters)
+			if ctx.Err() != nil {
+				return rep, ctx.Err()
+			}
+			r.Dataset = dataset
+			if err != nil {
+				rep.Failures = append(rep.Failures, Failure{Dataset: dataset, Backend: b.Name(), Err: err})
+			} else {
+				rep.Results.Results = append(rep.Results.Results, r)
+			}
+			if opt.Progress != nil {
+				opt.Progress(r, err)
+			}
+		}
+	}
+	return rep, nil
+}
+
+// measureBackend measures b decoding data opt.Count times; a decode after
+// ctx is done fails, which ends the measurement
+func measureBackend(ctx context.Context, b backends.Backend, data []byte, opt Options, counters *Counters) (Result, error) {
+	decode := func(data []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, err := b.Decode(data)
+		return err
+	}
+	r := Result{
+		Backend:    b.Name(),
+		Bytes:      len(data),
+		Iterations: opt.Iterations,
+	}
+	var samples []float64
+	for i := 0; i < opt.Count; i++ {
+		if err := ctx.Err(); err != nil {
+			return r, err
+		}
+		var speed float64
+		var err error
+		if counters != nil {
+			var v CounterValues
+			speed, v, err = MeasureCounters(counters, data, opt.Iterations, decode)
+			r.Counters = &v
+		} else {
+			speed, err = Measure(data, opt.Iterations, decode)
+		}
+		if err != nil {
+			return r, err
+		}
+		samples = append(samples, speed)
+	}
+	r.Stats = resultschema.NewStats(samples)
+	r.MBPerSec = r.Stats.MedianMBPerSec
+	return r, nil
+}
@@ -0,0 +1,28 @@
+// Package bench measures throughput and records it: result files and
+// their history, the machine they were measured on and, on Linux, the
+// hardware counters of the benchmark loop. Run is the whole benchmark of
+// the bench command.
+package bench
+
+import (
+	"fmt"
+	"time"
+)
+
+// Measure runs fn once to warm up, then iterations times, and returns the
+// throughput over data in MB/s
+func Measure(data []byte, iterations int, fn func([]byte) error) (float64, error) {
+	if err := fn(data); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		if err := fn(data); err != nil {
+			return 0, fmt.Errorf("iteration %d: %w", i, err)
+		}
+	}
+	seconds := time.Since(start).Seconds()
+	return float64(len(data)) * float64(iterations) / 1e6 / seconds, nil
+}
+
+func Milliseconds(d time.Duration) float64 { return d.Seconds() * 1000 }
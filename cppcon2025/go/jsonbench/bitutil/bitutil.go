@@ -0,0 +1,73 @@
+// Package bitutil holds the bit tricks of stage 1, apart from the scanner
+// so that they can be reused and shown one at a time: a carry-less
+// multiplication in plain Go, the prefix xor that turns quote positions
+// into string masks, iterating over the set bits of a mask with trailing
+// zero counts, and the SWAR comparisons that classify 8 bytes in a
+// uint64. Each works on one 64-bit word, bit i standing for byte i of a
+// block.
+package bitutil
+
+import "math/bits"
+
+// CarrylessMul returns the 128-bit carry-less product of a and b, the
+// result of PCLMULQDQ on x86 and PMULL on ARM: a long multiplication in
+// base 2 whose partial products are added with xor, so that no carry
+// crosses from one bit to the next. It shifts a once per set bit of b.
+func CarrylessMul(a, b uint64) (hi, lo uint64) {
+	for ; b != 0; b &= b - 1 {
+		i := uint(bits.TrailingZeros64(b))
+		lo ^= a << i
+		// For i = 0, a shift by 64 is 0 in Go
+		hi ^= a >> (64 - i)
+	}
+	return hi, lo
+}
+
+// PrefixXor sets bit i to the parity of bits 0 to i of x: in a mask of
+// unescaped quotes, the bytes from each opening quote up to the byte
+// before its closing one. It is the low word of CarrylessMul(x, ^0),
+// which the vector implementations of simdjson compute with one
+// instruction; six shifts do it in Go.
+func PrefixXor(x uint64) uint64 {
+	x ^= x << 1
+	x ^= x << 2
+	x ^= x << 4
+	x ^= x << 8
+	x ^= x << 16
+	x ^= x << 32
+	return x
+}
+
+// AppendSetBits appends base plus the position of every set bit of x to
+// dst, lowest first: the trailing zero count is the position of the
+// lowest bit, and x & (x-1) clears it, so the loop runs once per bit and
+// never branches on the bits themselves
+func AppendSetBits(dst []uint32, base uint32, x uint64) []uint32 {
+	for ; x != 0; x &= x - 1 {
+		dst = append(dst, base+uint32(bits.TrailingZeros64(x)))
+	}
+	return dst
+}
+
+// Ones has the low bit of every byte set; c * Ones repeats c in every
+// byte
+const Ones = 0x0101010101010101
+
+// ZeroBytes sets the high bit of every zero byte of v, exactly: unlike
+// the shorter (v - Ones) &^ v form, no borrow reaches the next byte
+func ZeroBytes(v uint64) uint64 {
+	const low7 = 0x7F7F7F7F7F7F7F7F
+	return ^((v&low7 + low7) | v | low7)
+}
+
+// Equal sets the high bit of every byte of v that is c, and only of those
+func Equal(v uint64, c byte) uint64 {
+	return ZeroBytes(v ^ uint64(c)*Ones)
+}
+
+// MoveMask gathers the high bits of the bytes of v into its low 8 bits,
+// as PMOVMSKB does: the multiplication shifts each into the top byte,
+// without carries since every partial product lands on its own bit
+func MoveMask(v uint64) uint64 {
+	return (v >> 7 & Ones) * 0x0102040810204080 >> 56
+}
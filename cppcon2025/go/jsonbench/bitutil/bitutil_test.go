@@ -0,0 +1,145 @@
+package bitutil
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// words returns random words of every density, with the edge cases first
+func words(n int) []uint64 {
+	r := rand.New(rand.NewSource(1))
+	w := []uint64{0, 1, 1 << 63, ^uint64(0), 0xAAAAAAAAAAAAAAAA}
+	for len(w) < n {
+		// Sparse, like quotes, and dense, like structural characters
+		w = append(w, r.Uint64()&r.Uint64()&r.Uint64(), r.Uint64())
+	}
+	return w
+}
+
+func TestCarrylessMul(t *testing.T) {
+	ws := words(200)
+	for _, a := range ws {
+		for _, b := range ws[:20] {
+			// Bit by bit: bit k of the product is the parity of the pairs
+			// of bits i of a and j of b with i + j = k
+			var want [2]uint64
+			for i := 0; i < 64; i++ {
+				for j := 0; j < 64; j++ {
+					if (a>>i)&(b>>j)&1 != 0 {
+						want[(i+j)/64] ^= 1 << ((i + j) % 64)
+					}
+				}
+			}
+			if hi, lo := CarrylessMul(a, b); hi != want[1] || lo != want[0] {
+				t.Fatalf("CarrylessMul(%#x, %#x) = %#x, %#x, want %#x, %#x", a, b, hi, lo, want[1], want[0])
+			}
+		}
+	}
+}
+
+func TestPrefixXor(t *testing.T) {
+	for _, x := range words(1000) {
+		var want, parity uint64
+		for i := 0; i < 64; i++ {
+			parity ^= x >> i & 1
+			want |= parity << i
+		}
+		if got := PrefixXor(x); got != want {
+			t.Fatalf("PrefixXor(%#x) = %#x, want %#x", x, got, want)
+		}
+		if _, lo := CarrylessMul(x, ^uint64(0)); lo != want {
+			t.Fatalf("CarrylessMul(%#x, ^0) = %#x, want the prefix xor %#x", x, lo, want)
+		}
+	}
+}
+
+func TestAppendSetBits(t *testing.T) {
+	for _, x := range words(1000) {
+		want := []uint32{7}
+		for i := 0; i < 64; i++ {
+			if x>>i&1 != 0 {
+				want = append(want, 100+uint32(i))
+			}
+		}
+		if got := AppendSetBits([]uint32{7}, 100, x); !slices.Equal(got, want) {
+			t.Fatalf("AppendSetBits(%#x) = %v, want %v", x, got, want)
+		}
+	}
+}
+
+func TestEqualMoveMask(t *testing.T) {
+	for _, v := range words(1000) {
+		for _, c := range []byte{0, '"', '\\', 0x7F, 0x80, 0xFF, byte(v)} {
+			var want, mask uint64
+			for i := 0; i < 8; i++ {
+				if byte(v>>(8*i)) == c {
+					want |= 0x80 << (8 * i)
+					mask |= 1 << i
+				}
+			}
+			if got := Equal(v, c); got != want {
+				t.Fatalf("Equal(%#x, %#x) = %#x, want %#x", v, c, got, want)
+			}
+			if got := MoveMask(Equal(v, c)); got != mask {
+				t.Fatalf("MoveMask(Equal(%#x, %#x)) = %#x, want %#x", v, c, got, mask)
+			}
+		}
+		var mask uint64
+		for i := 0; i < 8; i++ {
+			mask |= (v >> (8*i + 7) & 1) << i
+		}
+		if got := MoveMask(v); got != mask {
+			t.Fatalf("MoveMask(%#x) = %#x, want %#x", v, got, mask)
+		}
+	}
+}
+
+var sink uint64
+
+func BenchmarkPrefixXor(b *testing.B) {
+	ws := words(1024)
+	for i := 0; i < b.N; i++ {
+		sink ^= PrefixXor(ws[i%len(ws)])
+	}
+}
+
+// BenchmarkCarrylessMul is the prefix xor as simdjson computes it, by a
+// multiplication with all ones, which in Go costs a shift per bit
+func BenchmarkCarrylessMul(b *testing.B) {
+	ws := words(1024)
+	for i := 0; i < b.N; i++ {
+		_, lo := CarrylessMul(ws[i%len(ws)], ^uint64(0))
+		sink ^= lo
+	}
+}
+
+func BenchmarkAppendSetBits(b *testing.B) {
+	ws := words(1024)
+	dst := make([]uint32, 0, 64)
+	for i := 0; i < b.N; i++ {
+		dst = AppendSetBits(dst[:0], 0, ws[i%len(ws)])
+	}
+}
+
+// BenchmarkAppendSetBitsLoop tests every bit instead, for comparison
+func BenchmarkAppendSetBitsLoop(b *testing.B) {
+	ws := words(1024)
+	dst := make([]uint32, 0, 64)
+	for i := 0; i < b.N; i++ {
+		dst = dst[:0]
+		x := ws[i%len(ws)]
+		for j := uint32(0); j < 64; j++ {
+			if x>>j&1 != 0 {
+				dst = append(dst, j)
+			}
+		}
+	}
+}
+
+func BenchmarkMoveMaskEqual(b *testing.B) {
+	ws := words(1024)
+	for i := 0; i < b.N; i++ {
+		sink ^= MoveMask(Equal(ws[i%len(ws)], '"'))
+	}
+}
@@ -0,0 +1,86 @@
+//go:build js && wasm
+
+// Browser demo: parses twitter.json in the page and reports MB/s.
+// Build with GOOS=js GOARCH=wasm, see README.md.
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+	"time"
+)
+
+type TwitterUser struct {
+	ID             uint64 `json:"id"`
+	Name           string `json:"name"`
+	ScreenName     string `json:"screen_name"`
+	Location       string `json:"location"`
+	Description    string `json:"description"`
+	FollowersCount uint64 `json:"followers_count"`
+	FriendsCount   uint64 `json:"friends_count"`
+	Verified       bool   `json:"verified"`
+	StatusesCount  uint64 `json:"statuses_count"`
+}
+
+type Status struct {
+	User TwitterUser `json:"user"`
+}
+
+type TwitterData struct {
+	Statuses []Status `json:"statuses"`
+}
+
+// measure parses data once to warm up, then iterations times, and returns
+// the throughput in MB/s
+func measure(data []byte, iterations int, parse func([]byte) error) (float64, error) {
+	if err := parse(data); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		if err := parse(data); err != nil {
+			return 0, err
+		}
+	}
+	seconds := time.Since(start).Seconds()
+	return float64(len(data)) * float64(iterations) / 1e6 / seconds, nil
+}
+
+// parseTwitter is exposed to JavaScript as jsonbenchParse(bytes, iterations).
+// It returns {typed, generic} in MB/s, or {error} if the document does not
+// parse.
+func parseTwitter(this js.Value, args []js.Value) interface{} {
+	if len(args) != 2 {
+		return map[string]interface{}{"error": "usage: jsonbenchParse(bytes, iterations)"}
+	}
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+	iterations := args[1].Int()
+
+	typed, err := measure(data, iterations, func(b []byte) error {
+		var v TwitterData
+		return json.Unmarshal(b, &v)
+	})
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+	generic, err := measure(data, iterations, func(b []byte) error {
+		var v interface{}
+		return json.Unmarshal(b, &v)
+	})
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+	return map[string]interface{}{
+		"bytes":   len(data),
+		"typed":   typed,
+		"generic": generic,
+	}
+}
+
+func main() {
+	js.Global().Set("jsonbenchParse", js.FuncOf(parseTwitter))
+	js.Global().Call("jsonbenchReady")
+	// Keep the Go program alive so the page can call it again
+	select {}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// accessTask reads some fields of twitter.json once in each representation
+type accessTask struct {
+	name string
+	// flat reads the fields in place from a FlatBuffer
+	flat func(t flatTwitterData) uint64
+	// typed reads them from the decoded structs
+	typed func(t *TwitterData) uint64
+}
+
+var accessTasks = []accessTask{
+	{
+		name: "last screen_name",
+		flat: func(t flatTwitterData) uint64 {
+			u, _ := t.Statuses(t.StatusesLength() - 1).User()
+			return uint64(len(u.ScreenName()))
+		},
+		typed: func(t *TwitterData) uint64 {
+			return uint64(len(t.Statuses[len(t.Statuses)-1].User.ScreenName))
+		},
+	},
+	{
+		name: "sum followers_count",
+		flat: func(t flatTwitterData) uint64 {
+			var sum uint64
+			for i := 0; i < t.StatusesLength(); i++ {
+				u, _ := t.Statuses(i).User()
+				sum += u.FollowersCount()
+			}
+			return sum
+		},
+		typed: func(t *TwitterData) uint64 {
+			var sum uint64
+			for _, s := range t.Statuses {
+				sum += s.User.FollowersCount
+			}
+			return sum
+		},
+	},
+}
+
+// accessFlags are the flags of access
+type accessFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *accessFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("access")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to read")
+	opts.iterations = fs.Int("n", 1000, "number of iterations")
+	return fs
+}
+
+// runAccess contrasts reading a few fields from a FlatBuffer, which needs
+// no parsing at all, with decoding the JSON first. simdjson's On-Demand API
+// sits in between: it also only materializes the fields it is asked for,
+// but still has to scan the text to find them.
+func runAccess(args []string) error {
+	var opts accessFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var twitter TwitterData
+	if err := json.Unmarshal(data, &twitter); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if len(twitter.Statuses) == 0 {
+		return fmt.Errorf("%s: no statuses", *opts.file)
+	}
+	// The JSON side decodes the typed subset, re-encoded, so both read the
+	// same logical document
+	typedJSON, err := json.Marshal(&twitter)
+	if err != nil {
+		return err
+	}
+	flat, err := flatbuffersCodec{}.Marshal(&twitter)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-22s %16s %16s %10s\n", "task", "flatbuffers µs", "json+decode µs", "speedup")
+	for _, task := range accessTasks {
+		var want, got uint64
+		flatSpeed, err := bench.Measure(flat, *opts.iterations, func(b []byte) error {
+			got = task.flat(flatTwitterData{flatRoot(b)})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		jsonSpeed, err := bench.Measure(typedJSON, *opts.iterations, func(b []byte) error {
+			var t TwitterData
+			if err := json.Unmarshal(b, &t); err != nil {
+				return err
+			}
+			want = task.typed(&t)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("%s: flatbuffers read %d, json %d", task.name, got, want)
+		}
+		flatMicros := float64(len(flat)) / flatSpeed
+		jsonMicros := float64(len(typedJSON)) / jsonSpeed
+		fmt.Printf("%-22s %16.3f %16.1f %9.0fx\n", task.name, flatMicros, jsonMicros, jsonMicros/flatMicros)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// aggregated is one row of the cross-language table
+type aggregated struct {
+	Language string
+	Dataset  string
+	Backend  string
+	MBPerSec float64
+}
+
+var (
+	// | **simdjson (manual)** | 4.36 GB/s | 138.04 μs | Hand-written parsing code |
+	markdownRow = regexp.MustCompile(`^\|\s*\**([^|*]+?)\**\s*\|\s*([0-9.]+)\s*(GB|MB)/s\s*\|`)
+	// bench_simdjson_to                  :  3598.34 MB/s   0.93 Ms/s
+	benchLine = regexp.MustCompile(`^(bench_\S+)\s*:\s*([0-9.]+)\s*(GB|MB)/s`)
+)
+
+// readTextResults extracts throughputs from the text output of the other
+// benchmarks in the talks repo: markdown tables and "bench_x : N MB/s"
+// lines
+func readTextResults(path, language, dataset string) ([]aggregated, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var rows []aggregated
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		m := markdownRow.FindStringSubmatch(line)
+		if m == nil {
+			m = benchLine.FindStringSubmatch(line)
+		}
+		if m == nil {
+			continue
+		}
+		speed, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		if m[3] == "GB" {
+			speed *= 1000
+		}
+		rows = append(rows, aggregated{Language: language, Dataset: dataset, Backend: strings.TrimSpace(m[1]), MBPerSec: speed})
+	}
+	return rows, s.Err()
+}
+
+// aggregateFlags are the flags of aggregate
+type aggregateFlags struct {
+	dataset  *string
+	baseline *string
+}
+
+func (opts *aggregateFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("aggregate")
+	opts.dataset = fs.String("dataset", "twitter.json", "dataset name for text files")
+	opts.baseline = fs.String("baseline", report.BaselineBackend, "backend the speedup column is relative to")
+	return fs
+}
+
+// Combine results from the Go harness and from the C++, Rust or Python
+// benchmarks into one markdown table. Result files in the shared JSON
+// schema carry their language; text files take it from a "language="
+// prefix, for example C++=../../data/parsingtwitterapplem2max.txt.
+func runAggregate(args []string) error {
+	var opts aggregateFlags
+	fs := opts.flags()
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return withKind(errUsage, errors.New("aggregate: expected at least one result file"))
+	}
+
+	var rows []aggregated
+	for _, arg := range fs.Args() {
+		language, path, ok := strings.Cut(arg, "=")
+		if !ok {
+			language, path = "", arg
+		}
+		if strings.HasSuffix(path, ".json") {
+			rf, err := bench.ReadResults(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if language == "" {
+				language = rf.Language
+			}
+			for _, r := range rf.Results {
+				rows = append(rows, aggregated{Language: language, Dataset: r.Dataset, Backend: r.Backend, MBPerSec: r.MBPerSec})
+			}
+			continue
+		}
+		if language == "" {
+			return fmt.Errorf("%s: text results need a language= prefix", path)
+		}
+		list, err := readTextResults(path, language, *opts.dataset)
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return fmt.Errorf("%s: no results found", path)
+		}
+		rows = append(rows, list...)
+	}
+
+	printAggregated(rows, *opts.baseline)
+	return nil
+}
+
+// printAggregated prints rows as a markdown table, fastest first within
+// each dataset, with the speedup over the baseline backend
+func printAggregated(rows []aggregated, baseline string) {
+	base := map[string]float64{}
+	for _, r := range rows {
+		if r.Backend == baseline {
+			base[r.Dataset] = r.MBPerSec
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Dataset != rows[j].Dataset {
+			return rows[i].Dataset < rows[j].Dataset
+		}
+		return rows[i].MBPerSec > rows[j].MBPerSec
+	})
+	fmt.Printf("| Dataset | Language | Library | Throughput | vs %s |\n", baseline)
+	fmt.Println("|---------|----------|---------|------------|------|")
+	for _, r := range rows {
+		speedup := "-"
+		if b := base[r.Dataset]; b > 0 {
+			speedup = fmt.Sprintf("%.2fx", r.MBPerSec/b)
+		}
+		fmt.Printf("| %s | %s | %s | %s | %s |\n", r.Dataset, r.Language, r.Backend, formatThroughput(r.MBPerSec), speedup)
+	}
+}
+
+// formatThroughput prints MB/s like the slides do, switching to GB/s
+// above 1000 MB/s
+func formatThroughput(mbPerSec float64) string {
+	if mbPerSec >= 1000 {
+		return fmt.Sprintf("%.2f GB/s", mbPerSec/1000)
+	}
+	return fmt.Sprintf("%.0f MB/s", mbPerSec)
+}
+
+// pivot is a throughput table with one row per dataset and backend and one
+// column per run, such as a toolchain or a machine. Rows and columns keep
+// the order they were first added in.
+type pivot struct {
+	columns []string
+	rows    []string
+	seen    map[string]bool
+	cells   map[string]float64
+}
+
+func (p *pivot) add(column, row string, mbPerSec float64) {
+	if p.cells == nil {
+		p.seen = map[string]bool{}
+		p.cells = map[string]float64{}
+	}
+	if !p.seen["column\x00"+column] {
+		p.seen["column\x00"+column] = true
+		p.columns = append(p.columns, column)
+	}
+	if !p.seen["row\x00"+row] {
+		p.seen["row\x00"+row] = true
+		p.rows = append(p.rows, row)
+	}
+	p.cells[column+"\x00"+row] = mbPerSec
+}
+
+// print writes the table in markdown; rowHeader names the columns that
+// make up a row, such as "Dataset | Library"
+func (p *pivot) print(rowHeader string) {
+	fmt.Printf("| %s | %s |\n", rowHeader, strings.Join(p.columns, " | "))
+	fmt.Printf("|%s\n", strings.Repeat("------|", strings.Count(rowHeader, "|")+1+len(p.columns)))
+	for _, row := range p.rows {
+		values := make([]string, len(p.columns))
+		for i, c := range p.columns {
+			values[i] = "-"
+			if v, ok := p.cells[c+"\x00"+row]; ok {
+				values[i] = formatThroughput(v)
+			}
+		}
+		fmt.Printf("| %s | %s |\n", row, strings.Join(values, " | "))
+	}
+}
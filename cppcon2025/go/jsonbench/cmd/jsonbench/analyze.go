@@ -0,0 +1,325 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// twitterAggregates are the numbers the analyze command computes over
+// the statuses of twitter.json
+type twitterAggregates struct {
+	statuses  int
+	followers uint64
+	verified  int
+	hashtags  map[string]int
+}
+
+func (a *twitterAggregates) reset() {
+	*a = twitterAggregates{hashtags: make(map[string]int, len(a.hashtags))}
+}
+
+// topHashtags returns the n most used hashtags, ties broken by text
+func (a *twitterAggregates) topHashtags(n int) []string {
+	tags := make([]string, 0, len(a.hashtags))
+	for t := range a.hashtags {
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if a.hashtags[tags[i]] != a.hashtags[tags[j]] {
+			return a.hashtags[tags[i]] > a.hashtags[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	if len(tags) > n {
+		tags = tags[:n]
+	}
+	return tags
+}
+
+// analyzeData is the part of twitter.json the aggregates need, for the
+// typed decoding
+type analyzeData struct {
+	Statuses []struct {
+		User struct {
+			FollowersCount uint64 `json:"followers_count"`
+			Verified       bool   `json:"verified"`
+		} `json:"user"`
+		Entities struct {
+			Hashtags []struct {
+				Text string `json:"text"`
+			} `json:"hashtags"`
+		} `json:"entities"`
+	} `json:"statuses"`
+}
+
+func (t *analyzeData) aggregate(a *twitterAggregates) {
+	a.reset()
+	for _, s := range t.Statuses {
+		a.statuses++
+		a.followers += s.User.FollowersCount
+		if s.User.Verified {
+			a.verified++
+		}
+		for _, h := range s.Entities.Hashtags {
+			a.hashtags[h.Text]++
+		}
+	}
+}
+
+// aggregateGeneric computes the aggregates over a decoded generic value
+func aggregateGeneric(v interface{}, a *twitterAggregates) {
+	a.reset()
+	root, _ := v.(map[string]interface{})
+	statuses, _ := root["statuses"].([]interface{})
+	for _, s := range statuses {
+		s, _ := s.(map[string]interface{})
+		a.statuses++
+		if user, ok := s["user"].(map[string]interface{}); ok {
+			if f, ok := user["followers_count"].(float64); ok {
+				a.followers += uint64(f)
+			}
+			if user["verified"] == true {
+				a.verified++
+			}
+		}
+		entities, _ := s["entities"].(map[string]interface{})
+		hashtags, _ := entities["hashtags"].([]interface{})
+		for _, h := range hashtags {
+			h, _ := h.(map[string]interface{})
+			if text, ok := h["text"].(string); ok {
+				a.hashtags[text]++
+			}
+		}
+	}
+}
+
+// aggregateLazy computes the aggregates straight from the bytes with the
+// hand-rolled decoder, skipping every value they do not need
+func aggregateLazy(data []byte, a *twitterAggregates) error {
+	a.reset()
+	d := backends.NewDecoder(data, backends.DecodeOptions{})
+	var text []byte
+	err := d.Members(func(key []byte) error {
+		if string(key) != "statuses" {
+			return d.Skip()
+		}
+		return d.Elements(func() error {
+			a.statuses++
+			return d.Members(func(key []byte) error {
+				switch string(key) {
+				case "user":
+					return d.Members(func(key []byte) error {
+						switch {
+						case string(key) == "followers_count":
+							f, err := d.Uint64()
+							a.followers += f
+							return err
+						case string(key) == "verified" && d.Peek() == 't':
+							a.verified++
+							return d.Literal("true")
+						}
+						return d.Skip()
+					})
+				case "entities":
+					return d.Members(func(key []byte) error {
+						if string(key) != "hashtags" {
+							return d.Skip()
+						}
+						return d.Elements(func() error {
+							return d.Members(func(key []byte) error {
+								if string(key) != "text" {
+									return d.Skip()
+								}
+								var err error
+								if text, err = d.StringBytes(text[:0]); err == nil {
+									a.hashtags[string(text)]++
+								}
+								return err
+							})
+						})
+					})
+				}
+				return d.Skip()
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+// analyzeMethod gets a document ready with prepare, which decodes it or
+// not, and then computes the aggregates with each call of the pass it
+// returns
+type analyzeMethod struct {
+	name    string
+	prepare func(data []byte) (pass func(*twitterAggregates) error, err error)
+}
+
+func analyzeMethods() []analyzeMethod {
+	methods := []analyzeMethod{
+		{"structs (encoding/json)", func(data []byte) (func(*twitterAggregates) error, error) {
+			var t analyzeData
+			if err := json.Unmarshal(data, &t); err != nil {
+				return nil, err
+			}
+			return func(a *twitterAggregates) error {
+				t.aggregate(a)
+				return nil
+			}, nil
+		}},
+		{"lazy (hand-rolled)", func(data []byte) (func(*twitterAggregates) error, error) {
+			return func(a *twitterAggregates) error {
+				return aggregateLazy(data, a)
+			}, nil
+		}},
+	}
+	for _, b := range backends.All() {
+		b := b
+		methods = append(methods, analyzeMethod{b.Name() + " generic", func(data []byte) (func(*twitterAggregates) error, error) {
+			v, err := b.Decode(data)
+			if err != nil {
+				return nil, err
+			}
+			return func(a *twitterAggregates) error {
+				aggregateGeneric(v, a)
+				return nil
+			}, nil
+		}})
+	}
+	return methods
+}
+
+// analyzeFlags are the flags of analyze
+type analyzeFlags struct {
+	file       *string
+	passList   *string
+	iterations *int
+	top        *int
+}
+
+func (opts *analyzeFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("analyze")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to analyze")
+	opts.passList = fs.String("passes", "1,2,4,8,16,32", "comma-separated numbers of passes over the document")
+	opts.iterations = fs.Int("n", 50, "number of iterations")
+	opts.top = fs.Int("top", 5, "number of hashtags to list")
+	return fs
+}
+
+// runAnalyze computes the aggregates of twitter.json with every method,
+// once and then as if several analyses asked the same document: decoding
+// pays off once the document is traversed often enough, while lazy
+// extraction pays for the parse on every pass
+func runAnalyze(args []string) error {
+	var opts analyzeFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	var passes []int
+	for _, s := range strings.Split(*opts.passList, ",") {
+		p, err := strconv.Atoi(s)
+		if err != nil || p < 1 {
+			return fmt.Errorf("-passes: bad number of passes %q", s)
+		}
+		passes = append(passes, p)
+	}
+	if *opts.iterations < 1 {
+		return errors.New("analyze: -n must be at least 1")
+	}
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+
+	// Every method must find the same aggregates
+	methods := analyzeMethods()
+	var want twitterAggregates
+	usable := methods[:0]
+	for i, m := range methods {
+		pass, err := m.prepare(data)
+		if err == nil {
+			var got twitterAggregates
+			if err = pass(&got); err == nil && i > 0 && !sameAggregates(&got, &want) {
+				return fmt.Errorf("%s and %s disagree", m.name, methods[0].name)
+			}
+			if i == 0 {
+				want = got
+			}
+		}
+		if err != nil {
+			if i == 0 {
+				return fmt.Errorf("%s: %w", *opts.file, err)
+			}
+			// Not every backend decodes every document
+			continue
+		}
+		usable = append(usable, m)
+	}
+	fmt.Printf("%s: %d statuses, %d followers in total, %d verified users\n",
+		*opts.file, want.statuses, want.followers, want.verified)
+	for _, t := range want.topHashtags(*opts.top) {
+		fmt.Printf("  #%s %d\n", t, want.hashtags[t])
+	}
+	fmt.Println()
+
+	fmt.Print("| Method |")
+	for _, p := range passes {
+		fmt.Printf(" %d× µs |", p)
+	}
+	fmt.Print("\n|---|")
+	fmt.Println(strings.Repeat("---:|", len(passes)))
+	best := make([]time.Duration, len(passes))
+	fastest := make([]string, len(passes))
+	for _, m := range usable {
+		fmt.Printf("| %s |", m.name)
+		for i, p := range passes {
+			var a twitterAggregates
+			start := time.Now()
+			for it := 0; it < *opts.iterations; it++ {
+				pass, err := m.prepare(data)
+				if err != nil {
+					return fmt.Errorf("%s: %w", m.name, err)
+				}
+				for j := 0; j < p; j++ {
+					if err := pass(&a); err != nil {
+						return fmt.Errorf("%s: %w", m.name, err)
+					}
+				}
+			}
+			elapsed := time.Since(start) / time.Duration(*opts.iterations)
+			if fastest[i] == "" || elapsed < best[i] {
+				best[i], fastest[i] = elapsed, m.name
+			}
+			fmt.Printf(" %.1f |", elapsed.Seconds()*1e6)
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+	for i, p := range passes {
+		fmt.Printf("fastest for %d× : %s\n", p, fastest[i])
+	}
+	return nil
+}
+
+func sameAggregates(a, b *twitterAggregates) bool {
+	if a.statuses != b.statuses || a.followers != b.followers || a.verified != b.verified || len(a.hashtags) != len(b.hashtags) {
+		return false
+	}
+	for t, n := range a.hashtags {
+		if b.hashtags[t] != n {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// Columns in the Apache Arrow memory layout: a string array is one buffer
+// of bytes and len+1 int32 offsets into it, a uint64 array a buffer of
+// values. Neither column has nulls, so both omit the validity bitmap, as
+// Arrow allows. Filling them straight from the JSON text is the
+// structure-of-arrays style of parsing: no per-record structs, and one
+// allocation per column instead of one per string.
+
+type arrowStringArray struct {
+	offsets []int32
+	data    []byte
+}
+
+func (a *arrowStringArray) reset() {
+	a.offsets = append(a.offsets[:0], 0)
+	a.data = a.data[:0]
+}
+
+func (a *arrowStringArray) Len() int { return len(a.offsets) - 1 }
+
+func (a *arrowStringArray) Value(i int) string {
+	return string(a.data[a.offsets[i]:a.offsets[i+1]])
+}
+
+func (a *arrowStringArray) append(s []byte) {
+	a.data = append(a.data, s...)
+	a.offsets = append(a.offsets, int32(len(a.data)))
+}
+
+type arrowUint64Array struct {
+	values []uint64
+}
+
+// userColumns holds, for every status, two fields of its user
+type userColumns struct {
+	screenName     arrowStringArray
+	followersCount arrowUint64Array
+}
+
+func (c *userColumns) reset() {
+	c.screenName.reset()
+	c.followersCount.values = c.followersCount.values[:0]
+}
+
+// columnsFromStructs converts decoded structs to the columns, the path
+// that needs encoding/json first
+func columnsFromStructs(t *TwitterData, c *userColumns) {
+	c.reset()
+	for i := range t.Statuses {
+		u := &t.Statuses[i].User
+		c.screenName.append([]byte(u.ScreenName))
+		c.followersCount.values = append(c.followersCount.values, u.FollowersCount)
+	}
+}
+
+// decodeUserColumns parses twitter.json straight into the columns. The
+// other values are checked and skipped, and a user without one of the
+// fields gets "" or 0, as decoding into the structs gives.
+func decodeUserColumns(data []byte, c *userColumns) error {
+	c.reset()
+	d := backends.NewDecoder(data, backends.DecodeOptions{})
+	err := d.Members(func(key []byte) error {
+		if string(key) != "statuses" {
+			return d.Skip()
+		}
+		return d.Elements(func() error {
+			var name []byte
+			var followers uint64
+			err := d.Members(func(key []byte) error {
+				if string(key) != "user" {
+					return d.Skip()
+				}
+				return d.Members(func(key []byte) error {
+					var err error
+					switch string(key) {
+					case "screen_name":
+						name, err = d.StringBytes(name[:0])
+					case "followers_count":
+						followers, err = d.Uint64()
+					default:
+						err = d.Skip()
+					}
+					return err
+				})
+			})
+			c.screenName.append(name)
+			c.followersCount.values = append(c.followersCount.values, followers)
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+// arrowFlags are the flags of arrow
+type arrowFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *arrowFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("arrow")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to decode")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	return fs
+}
+
+// runArrow compares filling the columns directly from twitter.json with
+// decoding into the structs and converting them
+func runArrow(args []string) error {
+	var opts arrowFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var direct, converted userColumns
+	if err := decodeUserColumns(data, &direct); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	var twitter TwitterData
+	if err := json.Unmarshal(data, &twitter); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	columnsFromStructs(&twitter, &converted)
+	if direct.screenName.Len() != converted.screenName.Len() {
+		return fmt.Errorf("direct decoding found %d statuses, encoding/json %d", direct.screenName.Len(), converted.screenName.Len())
+	}
+	for i := 0; i < direct.screenName.Len(); i++ {
+		if direct.screenName.Value(i) != converted.screenName.Value(i) || direct.followersCount.values[i] != converted.followersCount.values[i] {
+			return fmt.Errorf("status %d: direct decoding and encoding/json disagree", i)
+		}
+	}
+
+	methods := []struct {
+		name string
+		fn   func([]byte) error
+	}{
+		{"structs + convert", func(b []byte) error {
+			var t TwitterData
+			if err := json.Unmarshal(b, &t); err != nil {
+				return err
+			}
+			columnsFromStructs(&t, &converted)
+			return nil
+		}},
+		{"direct to columns", func(b []byte) error {
+			return decodeUserColumns(b, &direct)
+		}},
+	}
+	fmt.Printf("%s: %d rows of screen_name and followers_count\n\n", *opts.file, direct.screenName.Len())
+	fmt.Println("| Method | MB/s | µs |")
+	fmt.Println("|---|---:|---:|")
+	for _, m := range methods {
+		speed, err := bench.Measure(data, *opts.iterations, m.fn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		fmt.Printf("| %s | %.2f | %.1f |\n", m.name, speed, float64(len(data))/speed)
+	}
+	return nil
+}
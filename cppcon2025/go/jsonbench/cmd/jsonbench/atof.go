@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/number"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+)
+
+// numberLiterals returns the number literals of a document, as they
+// appear in it, found at its structural offsets
+func numberLiterals(data []byte) ([]string, error) {
+	idx, err := scanner.Index(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	var literals []string
+	for _, off := range idx {
+		if c := data[off]; c != '-' && (c < '0' || c > '9') {
+			continue
+		}
+		end := int(off)
+		for end < len(data) && strings.IndexByte("+-.0123456789eE", data[end]) >= 0 {
+			end++
+		}
+		literals = append(literals, string(data[off:end]))
+	}
+	return literals, nil
+}
+
+// atofFlags are the flags of atof
+type atofFlags struct {
+	file       *string
+	iterations *int
+	seed       *int64
+}
+
+func (opts *atofFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("atof")
+	opts.file = fs.String("file", "../canada.json", "document whose numbers are parsed (canada-like coordinates if it is missing)")
+	opts.iterations = fs.Int("n", 20, "number of iterations")
+	opts.seed = fs.Int64("seed", 1, "seed of the canada-like coordinates")
+	return fs
+}
+
+func runAtof(args []string) error {
+	var opts atofFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	var literals []string
+	source := *opts.file
+	data, err := datasets.Read(*opts.file)
+	switch {
+	case err == nil:
+		if literals, err = numberLiterals(data); err != nil {
+			return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+		}
+	case errors.Is(err, os.ErrNotExist):
+		// Printed with 17 digits, as canada.json's coordinates are
+		for _, f := range canadaLikeNumbers(111126, *opts.seed) {
+			literals = append(literals, strconv.FormatFloat(f, 'g', 17, 64))
+		}
+		source = "canada-like coordinates"
+	default:
+		return err
+	}
+	if len(literals) == 0 {
+		return fmt.Errorf("%s: no numbers", *opts.file)
+	}
+
+	// number must agree with strconv to the bit
+	raw := make([][]byte, len(literals))
+	size := 0
+	for i, lit := range literals {
+		raw[i] = []byte(lit)
+		size += len(lit)
+		want, err := strconv.ParseFloat(lit, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", lit, err)
+		}
+		got, n, err := number.ParseFloat(raw[i])
+		if err != nil || n != len(lit) || math.Float64bits(got) != math.Float64bits(want) {
+			return withKind(errMismatch, fmt.Errorf("number parses %s as %v, strconv as %v", lit, got, want))
+		}
+	}
+
+	methods := []struct {
+		name  string
+		parse func(i int) (float64, error)
+	}{
+		{"strconv.ParseFloat", func(i int) (float64, error) { return strconv.ParseFloat(literals[i], 64) }},
+		{"number.ParseFloat", func(i int) (float64, error) {
+			f, _, err := number.ParseFloat(raw[i])
+			return f, err
+		}},
+	}
+	fmt.Printf("%s: %d numbers, %.1f bytes on average\n\n", source, len(literals), float64(size)/float64(len(literals)))
+	fmt.Println("| Method | ns/number | M numbers/s | MB/s |")
+	fmt.Println("|---|---:|---:|---:|")
+	for _, m := range methods {
+		start := time.Now()
+		for it := 0; it < *opts.iterations; it++ {
+			for i := range literals {
+				if _, err := m.parse(i); err != nil {
+					return err
+				}
+			}
+		}
+		elapsed := time.Since(start).Seconds()
+		count := float64(len(literals) * *opts.iterations)
+		fmt.Printf("| %s | %.2f | %.1f | %.1f |\n", m.name, elapsed*1e9/count, count/1e6/elapsed, float64(size**opts.iterations)/1e6/elapsed)
+	}
+	return nil
+}
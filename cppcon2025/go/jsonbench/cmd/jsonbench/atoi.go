@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+var errBadUint = errors.New("invalid unsigned integer")
+
+// parseUintLoop converts a string of decimal digits one byte at a time,
+// as the hand-rolled decoder does
+func parseUintLoop(b []byte) (uint64, error) {
+	if len(b) == 0 {
+		return 0, errBadUint
+	}
+	var v uint64
+	for _, c := range b {
+		digit := uint64(c - '0')
+		if digit > 9 || v > (1<<64-1-digit)/10 {
+			return 0, errBadUint
+		}
+		v = v*10 + digit
+	}
+	return v, nil
+}
+
+// eightDigits reports whether the 8 bytes of chunk, loaded as a little
+// endian word, are all ASCII digits: each byte's high nibble is 3 and
+// adding 6 does not carry into it
+func eightDigits(chunk uint64) bool {
+	return chunk&0xF0F0F0F0F0F0F0F0 == 0x3030303030303030 &&
+		(chunk+0x0606060606060606)&0xF0F0F0F0F0F0F0F0 == 0x3030303030303030
+}
+
+// parseEightDigits converts 8 ASCII digits, the first one in the low
+// byte, by combining neighbouring digits, then pairs, then quads
+func parseEightDigits(chunk uint64) uint64 {
+	chunk -= 0x3030303030303030
+	chunk = (chunk*10 + chunk>>8) & 0x00FF00FF00FF00FF
+	chunk = (chunk*100 + chunk>>16) & 0x0000FFFF0000FFFF
+	return (chunk*10000 + chunk>>32) & 0xFFFFFFFF
+}
+
+// parseUintSWAR converts decimal digits 8 at a time with SWAR arithmetic
+// (SIMD within a register) and finishes the last few with the loop
+func parseUintSWAR(b []byte) (uint64, error) {
+	for len(b) > 1 && b[0] == '0' {
+		b = b[1:]
+	}
+	if len(b) == 0 || len(b) > 20 {
+		return 0, errBadUint
+	}
+	var v uint64
+	i := 0
+	// Two chunks are at most 16 digits, which cannot overflow
+	for ; i+8 <= len(b) && i < 16; i += 8 {
+		chunk := binary.LittleEndian.Uint64(b[i:])
+		if !eightDigits(chunk) {
+			return 0, errBadUint
+		}
+		v = v*100000000 + parseEightDigits(chunk)
+	}
+	for ; i < len(b); i++ {
+		digit := uint64(b[i] - '0')
+		if digit > 9 {
+			return 0, errBadUint
+		}
+		hi, lo := bits.Mul64(v, 10)
+		lo, carry := bits.Add64(lo, digit, 0)
+		if hi|carry != 0 {
+			return 0, errBadUint
+		}
+		v = lo
+	}
+	return v, nil
+}
+
+// collectIDs returns the literals of the integer "id" and "*_id" members
+// of a document, as they appear in it
+func collectIDs(data []byte) ([]string, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var ids []string
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]interface{}:
+			for k, e := range v {
+				if n, ok := e.(json.Number); ok && (k == "id" || strings.HasSuffix(k, "_id")) && strings.Trim(string(n), "0123456789") == "" {
+					ids = append(ids, string(n))
+				}
+				walk(e)
+			}
+		}
+	}
+	walk(doc)
+	return ids, nil
+}
+
+// atoiFlags are the flags of atoi
+type atoiFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *atoiFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("atoi")
+	opts.file = fs.String("file", "../twitter.json", "document whose integer ids are parsed")
+	opts.iterations = fs.Int("n", 2000, "number of iterations")
+	return fs
+}
+
+func runAtoi(args []string) error {
+	var opts atoiFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	ids, err := collectIDs(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("%s: no integer ids", *opts.file)
+	}
+	literals := make([][]byte, len(ids))
+	digits := 0
+	for i, id := range ids {
+		literals[i] = []byte(id)
+		digits += len(id)
+		want, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		if v, err := parseUintLoop(literals[i]); err != nil || v != want {
+			return fmt.Errorf("the byte loop parses %s as %d", id, v)
+		}
+		if v, err := parseUintSWAR(literals[i]); err != nil || v != want {
+			return fmt.Errorf("SWAR parses %s as %d", id, v)
+		}
+	}
+
+	methods := []struct {
+		name  string
+		parse func(i int) (uint64, error)
+	}{
+		{"strconv.ParseUint", func(i int) (uint64, error) { return strconv.ParseUint(ids[i], 10, 64) }},
+		{"byte loop", func(i int) (uint64, error) { return parseUintLoop(literals[i]) }},
+		{"SWAR, 8 digits at a time", func(i int) (uint64, error) { return parseUintSWAR(literals[i]) }},
+	}
+	fmt.Printf("%s: %d integer ids, %.1f digits on average\n\n", *opts.file, len(ids), float64(digits)/float64(len(ids)))
+	fmt.Println("| Method | ns/number | M numbers/s |")
+	fmt.Println("|---|---:|---:|")
+	for _, m := range methods {
+		start := time.Now()
+		for it := 0; it < *opts.iterations; it++ {
+			for i := range ids {
+				if _, err := m.parse(i); err != nil {
+					return err
+				}
+			}
+		}
+		elapsed := time.Since(start).Seconds()
+		count := float64(len(ids) * *opts.iterations)
+		fmt.Printf("| %s | %.2f | %.1f |\n", m.name, elapsed*1e9/count, count/1e6/elapsed)
+	}
+	return nil
+}
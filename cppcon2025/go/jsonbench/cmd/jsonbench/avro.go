@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Avro binary encoding of the data model, following the schemas in
+// twitter.avsc and players.avsc. A datum carries no field names or types:
+// records are their fields in schema order, so the reader needs the
+// writer's schema, here compiled into the code as it would be by a code
+// generator such as hamba/avro's. Avro has no unsigned types, so the
+// uint64 fields are written as longs, and empty and nil arrays both
+// decode as nil.
+
+type avroCodec struct{}
+
+func (avroCodec) Name() string { return "avro" }
+
+func (avroCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case *TwitterData:
+		return v.appendAvro(nil), nil
+	case *Players:
+		return v.appendAvro(nil), nil
+	}
+	return nil, fmt.Errorf("avro: unsupported type %T", v)
+}
+
+func (avroCodec) Unmarshal(data []byte, v interface{}) error {
+	r := &avroReader{data: data}
+	var err error
+	switch v := v.(type) {
+	case *TwitterData:
+		err = v.decodeAvro(r)
+	case *Players:
+		err = v.decodeAvro(r)
+	default:
+		return fmt.Errorf("avro: unsupported type %T", v)
+	}
+	if err == nil && r.pos != len(data) {
+		err = fmt.Errorf("avro: %d bytes of trailing data", len(data)-r.pos)
+	}
+	return err
+}
+
+func init() {
+	registerCodec(avroCodec{})
+}
+
+// Avro longs are zig-zag varints, the encoding of binary.AppendVarint
+
+func appendAvroString(b []byte, s string) []byte {
+	return append(binary.AppendVarint(b, int64(len(s))), s...)
+}
+
+func appendAvroBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+func appendAvroDouble(b []byte, f float64) []byte {
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(f))
+}
+
+// appendAvroArray writes n items as a single block followed by the empty
+// block that ends the array
+func appendAvroArray(b []byte, n int, item func(b []byte, i int) []byte) []byte {
+	if n > 0 {
+		b = binary.AppendVarint(b, int64(n))
+		for i := 0; i < n; i++ {
+			b = item(b, i)
+		}
+	}
+	return append(b, 0)
+}
+
+var errAvroShort = errors.New("avro: unexpected end of data")
+
+// avroReader decodes values from data in schema order
+type avroReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *avroReader) long() (int64, error) {
+	v, n := binary.Varint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, errAvroShort
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *avroReader) string() (string, error) {
+	n, err := r.long()
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || n > int64(len(r.data)-r.pos) {
+		return "", errAvroShort
+	}
+	s := string(r.data[r.pos : r.pos+int(n)])
+	r.pos += int(n)
+	return s, nil
+}
+
+func (r *avroReader) bool() (bool, error) {
+	if r.pos >= len(r.data) {
+		return false, errAvroShort
+	}
+	c := r.data[r.pos]
+	r.pos++
+	if c > 1 {
+		return false, fmt.Errorf("avro: invalid boolean 0x%02x at offset %d", c, r.pos-1)
+	}
+	return c == 1, nil
+}
+
+func (r *avroReader) double() (float64, error) {
+	if len(r.data)-r.pos < 8 {
+		return 0, errAvroShort
+	}
+	f := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+	r.pos += 8
+	return f, nil
+}
+
+// array reads the blocks of an array, calling item for each element. A
+// negative count is followed by the block size in bytes, which is not
+// needed here. Every item takes at least one byte, so longer blocks are
+// rejected before growing the slices.
+func (r *avroReader) array(item func() error) error {
+	for {
+		count, err := r.long()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			count = -count
+			if _, err := r.long(); err != nil {
+				return err
+			}
+		}
+		if count > int64(len(r.data)-r.pos) {
+			return errAvroShort
+		}
+		for i := int64(0); i < count; i++ {
+			if err := item(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (u *TwitterUser) appendAvro(b []byte) []byte {
+	b = binary.AppendVarint(b, int64(u.ID))
+	b = appendAvroString(b, u.Name)
+	b = appendAvroString(b, u.ScreenName)
+	b = appendAvroString(b, u.Location)
+	b = appendAvroString(b, u.Description)
+	b = binary.AppendVarint(b, int64(u.FollowersCount))
+	b = binary.AppendVarint(b, int64(u.FriendsCount))
+	b = appendAvroBool(b, u.Verified)
+	return binary.AppendVarint(b, int64(u.StatusesCount))
+}
+
+func (u *TwitterUser) decodeAvro(r *avroReader) error {
+	var err error
+	var v int64
+	if v, err = r.long(); err != nil {
+		return err
+	}
+	u.ID = uint64(v)
+	if u.Name, err = r.string(); err != nil {
+		return err
+	}
+	if u.ScreenName, err = r.string(); err != nil {
+		return err
+	}
+	if u.Location, err = r.string(); err != nil {
+		return err
+	}
+	if u.Description, err = r.string(); err != nil {
+		return err
+	}
+	if v, err = r.long(); err != nil {
+		return err
+	}
+	u.FollowersCount = uint64(v)
+	if v, err = r.long(); err != nil {
+		return err
+	}
+	u.FriendsCount = uint64(v)
+	if u.Verified, err = r.bool(); err != nil {
+		return err
+	}
+	if v, err = r.long(); err != nil {
+		return err
+	}
+	u.StatusesCount = uint64(v)
+	return nil
+}
+
+func (t *TwitterData) appendAvro(b []byte) []byte {
+	return appendAvroArray(b, len(t.Statuses), func(b []byte, i int) []byte {
+		return t.Statuses[i].User.appendAvro(b)
+	})
+}
+
+func (t *TwitterData) decodeAvro(r *avroReader) error {
+	t.Statuses = nil
+	return r.array(func() error {
+		var s Status
+		if err := s.User.decodeAvro(r); err != nil {
+			return err
+		}
+		t.Statuses = append(t.Statuses, s)
+		return nil
+	})
+}
+
+func (p *Player) appendAvro(b []byte) []byte {
+	b = appendAvroString(b, p.Username)
+	b = binary.AppendVarint(b, int64(p.Level))
+	b = appendAvroDouble(b, p.Health)
+	return appendAvroArray(b, len(p.Inventory), func(b []byte, i int) []byte {
+		return appendAvroString(b, p.Inventory[i])
+	})
+}
+
+func (p *Player) decodeAvro(r *avroReader) error {
+	var err error
+	if p.Username, err = r.string(); err != nil {
+		return err
+	}
+	level, err := r.long()
+	if err != nil {
+		return err
+	}
+	p.Level = int(level)
+	if p.Health, err = r.double(); err != nil {
+		return err
+	}
+	p.Inventory = nil
+	return r.array(func() error {
+		item, err := r.string()
+		if err != nil {
+			return err
+		}
+		p.Inventory = append(p.Inventory, item)
+		return nil
+	})
+}
+
+func (ps *Players) appendAvro(b []byte) []byte {
+	return appendAvroArray(b, len(*ps), func(b []byte, i int) []byte {
+		return (*ps)[i].appendAvro(b)
+	})
+}
+
+func (ps *Players) decodeAvro(r *avroReader) error {
+	*ps = nil
+	return r.array(func() error {
+		var p Player
+		if err := p.decodeAvro(r); err != nil {
+			return err
+		}
+		*ps = append(*ps, p)
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// attachmentString leaves the payload as the base64 string
+type attachmentString struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Mime string `json:"mime"`
+	Data string `json:"data"`
+}
+
+// attachmentRaw keeps the payload as the raw JSON string, quotes
+// included, so that it can be decoded without an intermediate string
+type attachmentRaw struct {
+	ID   int             `json:"id"`
+	Name string          `json:"name"`
+	Mime string          `json:"mime"`
+	Data json.RawMessage `json:"data"`
+}
+
+// base64Flags are the flags of base64
+type base64Flags struct {
+	n          *int
+	seed       *int64
+	iterations *int
+	write      *string
+}
+
+func (opts *base64Flags) flags() *flag.FlagSet {
+	fs := newFlagSet("base64")
+	opts.n = fs.Int("records", 1000, "number of attachments to generate")
+	opts.seed = fs.Int64("seed", 1, "seed of the generated payloads")
+	opts.iterations = fs.Int("n", 20, "number of iterations")
+	opts.write = fs.String("write", "", "write the dataset to this file and exit")
+	return fs
+}
+
+func runBase64(args []string) error {
+	var opts base64Flags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Attachments(*opts.n, *opts.seed)
+	if err != nil {
+		return err
+	}
+	if *opts.write != "" {
+		if err := os.WriteFile(*opts.write, data, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %d attachments, %d bytes\n", *opts.write, *opts.n, len(data))
+		return nil
+	}
+
+	var want []datasets.Attachment
+	if err := json.Unmarshal(data, &want); err != nil {
+		return err
+	}
+	payload := 0
+	for _, a := range want {
+		payload += len(a.Data)
+	}
+	// Every method fills payloads; they must all match the []byte fields
+	payloads := make([][]byte, len(want))
+	var buf []byte
+	methods := []struct {
+		name string
+		fn   func([]byte) error
+	}{
+		{"[]byte fields", func(b []byte) error {
+			var recs []datasets.Attachment
+			if err := json.Unmarshal(b, &recs); err != nil {
+				return err
+			}
+			for i, r := range recs {
+				payloads[i] = r.Data
+			}
+			return nil
+		}},
+		{"string fields + DecodeString", func(b []byte) error {
+			var recs []attachmentString
+			if err := json.Unmarshal(b, &recs); err != nil {
+				return err
+			}
+			for i, r := range recs {
+				var err error
+				if payloads[i], err = base64.StdEncoding.DecodeString(r.Data); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"RawMessage + Decode into one buffer", func(b []byte) error {
+			var recs []attachmentRaw
+			if err := json.Unmarshal(b, &recs); err != nil {
+				return err
+			}
+			// The payloads share one buffer, which holds them all
+			buf = buf[:0]
+			for i, r := range recs {
+				if len(r.Data) < 2 || r.Data[0] != '"' || bytes.IndexByte(r.Data[1:len(r.Data)-1], '\\') >= 0 {
+					return errors.New("data is not a plain JSON string")
+				}
+				start := len(buf)
+				var err error
+				if buf, err = base64.StdEncoding.AppendDecode(buf, r.Data[1:len(r.Data)-1]); err != nil {
+					return err
+				}
+				payloads[i] = buf[start:len(buf):len(buf)]
+			}
+			return nil
+		}},
+	}
+
+	fmt.Printf("attachments: %d records, %d bytes of JSON carrying %d bytes of payload\n\n", len(want), len(data), payload)
+	fmt.Println("| Method | MB/s | µs |")
+	fmt.Println("|---|---:|---:|")
+	for _, m := range methods {
+		for i := range payloads {
+			payloads[i] = nil
+		}
+		if err := m.fn(data); err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		for i, a := range want {
+			if !bytes.Equal(payloads[i], a.Data) {
+				return fmt.Errorf("%s: the payload of record %d differs", m.name, i)
+			}
+		}
+		speed, err := bench.Measure(data, *opts.iterations, m.fn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		fmt.Printf("| %s | %.2f | %.1f |\n", m.name, speed, float64(len(data))/speed)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/dom"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+)
+
+// batchFlags are the flags of batch
+type batchFlags struct {
+	file       *string
+	docs       *int
+	iterations *int
+}
+
+func (opts *batchFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("batch")
+	opts.file = fs.String("file", "../twitter.json", "document whose records are the small documents")
+	opts.docs = fs.Int("docs", 10000, "number of documents per iteration")
+	opts.iterations = fs.Int("n", 20, "number of iterations")
+	return fs
+}
+
+// Parse thousands of tweet-sized documents per iteration, where the cost
+// of setting up a parse matters more than GB/s: a parser created for
+// every document against one whose buffers are reused, as simdjson
+// recommends, with the amortized cost per document
+func runBatch(args []string) error {
+	var opts batchFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	recs, err := datasets.Records(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	if *opts.docs <= 0 {
+		return withKind(errUsage, fmt.Errorf("-docs must be positive"))
+	}
+	// Compact records, cycled up to -docs, as a service would receive them
+	batch := make([][]byte, *opts.docs)
+	total := 0
+	for i := range batch {
+		b, err := json.Marshal(recs[i%len(recs)])
+		if err != nil {
+			return err
+		}
+		batch[i] = b
+		total += len(b)
+	}
+
+	var parser dom.Parser
+	var idx []uint32
+	methods := []struct {
+		name  string
+		parse func([]byte) error
+	}{
+		{"encoding/json, interface{}", func(b []byte) error {
+			var v interface{}
+			return json.Unmarshal(b, &v)
+		}},
+		{"hand-rolled, interface{}", func(b []byte) error {
+			_, err := backends.Decode(b, backends.DecodeOptions{})
+			return err
+		}},
+		{"dom, new parser per document", func(b []byte) error {
+			var p dom.Parser
+			_, err := p.Parse(b)
+			return err
+		}},
+		{"dom, reused parser", func(b []byte) error {
+			_, err := parser.Parse(b)
+			return err
+		}},
+		{"stage 1, new index per document", func(b []byte) error {
+			_, err := scanner.Index(b, nil)
+			return err
+		}},
+		{"stage 1, reused index", func(b []byte) (err error) {
+			idx, err = scanner.Index(b, idx[:0])
+			return err
+		}},
+	}
+
+	// The reused parser must build the document a fresh one does
+	for i, b := range batch {
+		var want, got checksum
+		var fresh dom.Parser
+		root, err := fresh.Parse(b)
+		if err != nil {
+			return fmt.Errorf("document %d: %w", i, withKind(errDataset, err))
+		}
+		want.dom(root)
+		if root, err = parser.Parse(b); err != nil {
+			return fmt.Errorf("document %d: %w", i, withKind(errDataset, err))
+		}
+		if got.dom(root); got != want {
+			return withKind(errMismatch, fmt.Errorf("document %d: the reused parser gives %+v, a new one %+v", i, got, want))
+		}
+	}
+
+	fmt.Printf("%s: %d documents of %d bytes on average per iteration\n\n", datasets.Name(*opts.file), len(batch), total/len(batch))
+	fmt.Println("| Method | ns/document | allocs/document | MB/s | speedup |")
+	fmt.Println("|---|---:|---:|---:|---:|")
+	base := 0.0
+	for _, m := range methods {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for it := 0; it < *opts.iterations; it++ {
+			for _, b := range batch {
+				if err := m.parse(b); err != nil {
+					return fmt.Errorf("%s: %w", m.name, err)
+				}
+			}
+		}
+		elapsed := time.Since(start).Seconds()
+		runtime.ReadMemStats(&after)
+		n := float64(len(batch)) * float64(*opts.iterations)
+		speed := float64(total) * float64(*opts.iterations) / 1e6 / elapsed
+		if base == 0 {
+			base = speed
+		}
+		fmt.Printf("| %s | %.0f | %.1f | %.1f | %.1f× |\n", m.name, elapsed*1e9/n,
+			float64(after.Mallocs-before.Mallocs)/n, speed, speed/base)
+	}
+	return nil
+}
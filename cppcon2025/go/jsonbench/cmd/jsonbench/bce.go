@@ -0,0 +1,208 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bceLine matches a bounds check reported by -d=ssa/check_bce
+var bceLine = regexp.MustCompile(`^(.+\.go):(\d+):\d+: Found Is(Slice)?InBounds$`)
+
+// boundsChecks compiles pkg in src with -d=ssa/check_bce and returns the
+// number of bounds checks left in each function, keyed by file and name
+func boundsChecks(src, pkg string) (map[string]int, error) {
+	out, err := execIn(src, nil, []string{"go", "list", "-f", "{{.ImportPath}}", pkg})
+	if err != nil {
+		return nil, err
+	}
+	path := strings.TrimSpace(string(out))
+	cmd := exec.Command("go", "build", "-gcflags="+path+"=-d=ssa/check_bce/debug=1", "-o", os.DevNull, pkg)
+	cmd.Dir = src
+	diag, err := cmd.CombinedOutput()
+	if err != nil && !bytes.Contains(diag, []byte("Found Is")) {
+		return nil, fmt.Errorf("go build %s: %w\n%s", pkg, err, diag)
+	}
+
+	// The compiler reports lines; the functions come from the sources
+	funcs := map[string]func(line int) string{}
+	enclosing := func(file string) func(int) string {
+		if f, ok := funcs[file]; ok {
+			return f
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, filepath.Join(src, file), nil, 0)
+		find := func(line int) string {
+			if err != nil {
+				return "?"
+			}
+			for _, d := range f.Decls {
+				fd, ok := d.(*ast.FuncDecl)
+				if !ok || line < fset.Position(fd.Pos()).Line || line > fset.Position(fd.End()).Line {
+					continue
+				}
+				if fd.Recv != nil && len(fd.Recv.List) == 1 {
+					return receiverName(fd.Recv.List[0].Type) + "." + fd.Name.Name
+				}
+				return fd.Name.Name
+			}
+			return "(package level)"
+		}
+		funcs[file] = find
+		return find
+	}
+
+	checks := map[string]int{}
+	sc := bufio.NewScanner(bytes.NewReader(diag))
+	for sc.Scan() {
+		m := bceLine.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		checks[m[1]+"\x00"+enclosing(m[1])(line)]++
+	}
+	return checks, nil
+}
+
+// receiverName prints the receiver type of a method, without its pointer
+func receiverName(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	}
+	return "?"
+}
+
+// bceFlags are the flags of bce
+type bceFlags struct {
+	files      *string
+	iterations *int
+	count      *int
+	src        *string
+	pkg        *string
+	top        *int
+	keep       *string
+}
+
+func (opts *bceFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("bce")
+	opts.files = fs.String("file", "../twitter.json", "comma-separated list of JSON documents")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	opts.count = fs.Int("count", 5, "repeat each measurement and report the median")
+	opts.src = fs.String("src", ".", "directory of the jsonbench module")
+	opts.pkg = fs.String("pkg", "./backends", "package whose bounds checks are reported")
+	opts.top = fs.Int("top", 15, "number of functions listed")
+	opts.keep = fs.String("keep", "", "keep the binaries and results in this directory")
+	return fs
+}
+
+// Report where the compiler left bounds checks in the decoders, then
+// benchmark a build with them and one without (-B) to put a number on
+// what they cost
+func runBCE(args []string) error {
+	var opts bceFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	checks, err := boundsChecks(*opts.src, *opts.pkg)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		file, fn string
+		n        int
+	}
+	var entries []entry
+	perFile := map[string]int{}
+	total := 0
+	for k, n := range checks {
+		file, fn, _ := strings.Cut(k, "\x00")
+		entries = append(entries, entry{file, fn, n})
+		perFile[file] += n
+		total += n
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].n != entries[j].n {
+			return entries[i].n > entries[j].n
+		}
+		return entries[i].file+entries[i].fn < entries[j].file+entries[j].fn
+	})
+	fmt.Printf("%s: %d bounds checks left by the compiler in %d functions\n\n", *opts.pkg, total, len(entries))
+	var names []string
+	for f := range perFile {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+	fmt.Println("| File | checks |")
+	fmt.Println("|---|---:|")
+	for _, f := range names {
+		fmt.Printf("| %s | %d |\n", f, perFile[f])
+	}
+	fmt.Println()
+	fmt.Println("| Function | File | checks |")
+	fmt.Println("|---|---|---:|")
+	for _, e := range entries[:min(*opts.top, len(entries))] {
+		fmt.Printf("| %s | %s | %d |\n", e.fn, e.file, e.n)
+	}
+
+	dir := *opts.keep
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "jsonbench-bce")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return err
+	}
+	benchArgs := []string{"-file", *opts.files, "-n", strconv.Itoa(*opts.iterations), "-count", strconv.Itoa(*opts.count)}
+	fmt.Println("\nbuilding and benchmarking with bounds checks")
+	checked, err := buildAndBench(dir, *opts.src, "go", nil, nil, benchArgs, "jsonbench-checked")
+	if err != nil {
+		return err
+	}
+	fmt.Println("rebuilding with -gcflags=all=-B, without them, and benchmarking again")
+	unchecked, err := buildAndBench(dir, *opts.src, "go", nil, []string{"-gcflags=all=-B"}, benchArgs, "jsonbench-unchecked")
+	if err != nil {
+		return err
+	}
+
+	before := map[string]float64{}
+	for _, r := range checked.Results {
+		before[r.Dataset+"\x00"+r.Backend] = r.MBPerSec
+	}
+	fmt.Println()
+	fmt.Println("| Dataset | Library | Checked | Unchecked (-B) | Speedup |")
+	fmt.Println("|---------|---------|---------|----------------|---------|")
+	for _, r := range unchecked.Results {
+		b := before[r.Dataset+"\x00"+r.Backend]
+		speedup := "-"
+		if b > 0 {
+			speedup = fmt.Sprintf("%.2fx", r.MBPerSec/b)
+		}
+		fmt.Printf("| %s | %s | %s | %s | %s |\n", r.Dataset, r.Backend, formatThroughput(b), formatThroughput(r.MBPerSec), speedup)
+	}
+	return nil
+}
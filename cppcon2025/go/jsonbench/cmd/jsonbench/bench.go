@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// resultLanguage labels the result files, so that aggregate keeps each
+// toolchain in its own row
+var resultLanguage = "Go"
+
+// benchFlags are the flags of bench
+type benchFlags struct {
+	files       *string
+	iterations  *int
+	count       *int
+	out         *string
+	history     *string
+	runs        *string
+	useCounters *bool
+	flamegraph  *string
+	progress    *bool
+	dryRun      *bool
+	prof        *profileFlags
+}
+
+func (opts *benchFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("bench")
+	opts.files = fs.String("file", "../twitter.json", "comma-separated list of JSON documents, - for standard input")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	opts.count = fs.Int("count", 1, "repeat each measurement and report the median")
+	opts.out = fs.String("o", "", "write the results as JSON to this file")
+	opts.history = fs.String("history", "results.jsonl", "append the run to this JSONL history file (empty to disable)")
+	opts.runs = fs.String("results", "results", "write the results and a manifest of the run to a new directory under this one (empty to disable)")
+	opts.useCounters = fs.Bool("counters", false, "report hardware counters (Linux only)")
+	opts.flamegraph = fs.String("flamegraph", "", "profile the benchmark loop and render it to this SVG file")
+	opts.progress = fs.Bool("progress", false, "show a progress bar with the time left on stderr")
+	opts.dryRun = fs.Bool("dry-run", false, "print the datasets, backends and iterations that would run, with a time estimate, and exit")
+	opts.prof = addProfileFlags(fs)
+	return fs
+}
+
+// Benchmark every backend decoding each dataset and optionally save the
+// results for the report command
+func runBench(args []string) error {
+	var opts benchFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	if *opts.dryRun {
+		return benchPlan{
+			files:      strings.Split(*opts.files, ","),
+			iterations: *opts.iterations,
+			count:      *opts.count,
+			history:    *opts.history,
+			results:    *opts.runs,
+			out:        *opts.out,
+		}.print()
+	}
+
+	// Both write a CPU profile, and only one can run at a time
+	if *opts.flamegraph != "" && opts.prof.cpuProfile != "" {
+		return withKind(errUsage, errors.New("-flamegraph and -cpuprofile cannot be combined; -flamegraph keeps its profile next to the SVG"))
+	}
+	stop, err := opts.prof.start()
+	defer stop()
+	if err != nil {
+		return err
+	}
+
+	if *opts.flamegraph != "" {
+		f, err := startFlamegraph(*opts.flamegraph)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := stopFlamegraph(f, *opts.flamegraph); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
+		}()
+	}
+
+	all := backends.All()
+	last := all[len(all)-1].Name()
+	var dataset []bench.Result
+	inputs := strings.Split(*opts.files, ",")
+	// Without -progress the bar draws nothing
+	bar := report.NewProgressBar(io.Discard, len(inputs)*len(all))
+	if *opts.progress {
+		bar = report.NewProgressBar(os.Stderr, len(inputs)*len(all))
+	}
+	defer bar.Clear()
+	rep, err := bench.Run(context.Background(), bench.Options{
+		Files:      inputs,
+		Iterations: *opts.iterations,
+		Count:      *opts.count,
+		Counters:   *opts.useCounters,
+		Language:   resultLanguage,
+		Start: func(dataset, backend string) {
+			bar.Start(dataset + " " + backend)
+		},
+		Progress: func(r bench.Result, err error) {
+			bar.Clear()
+			defer bar.Step()
+			if err != nil {
+				fmt.Printf("%-16s %-20s error: %v\n", r.Dataset, r.Backend, err)
+			} else {
+				fmt.Printf("%-16s %-20s %8.2f MB/s\n", r.Dataset, r.Backend, r.MBPerSec)
+				if r.Counters != nil {
+					fmt.Printf("%-16s %-20s %s\n", "", "", bench.CounterSummary(*r.Counters, float64(r.Bytes)*float64(r.Iterations)))
+				}
+				dataset = append(dataset, r)
+			}
+			// Chart each dataset once its last backend is measured
+			if r.Backend == last {
+				if len(dataset) > 1 {
+					fmt.Println()
+					report.PrintBarChart(os.Stdout, dataset)
+					if summary := report.SpeedupSummary(dataset, report.BaselineBackend); summary != "" {
+						fmt.Printf("\n%s: %s\n", r.Dataset, summary)
+					}
+					fmt.Println()
+				}
+				dataset = nil
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	rf := rep.Results
+	manifest := rep.Manifest
+	manifest.Args = os.Args
+	manifest.Flags = map[string]string{}
+	fs.VisitAll(func(f *flag.Flag) { manifest.Flags[f.Name] = f.Value.String() })
+	if *opts.history != "" {
+		if err := bench.AppendHistory(*opts.history, rf); err != nil {
+			return err
+		}
+	}
+	if *opts.runs != "" {
+		dir, err := bench.WriteRun(*opts.runs, manifest, rf)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "results and manifest written to", dir)
+	}
+	if *opts.out != "" {
+		if err := bench.WriteManifest(bench.ManifestPath(*opts.out), manifest); err != nil {
+			return err
+		}
+		if err := bench.WriteResults(*opts.out, rf); err != nil {
+			return err
+		}
+	}
+	// The results of the other backends are saved, but a backend that
+	// failed fails the run
+	if n := len(rep.Failures); n > 0 {
+		f := rep.Failures[0]
+		return withKind(errMismatch, fmt.Errorf("%s on %s failed (%d failures in all): %w", f.Backend, f.Dataset, n, f.Err))
+	}
+	return nil
+}
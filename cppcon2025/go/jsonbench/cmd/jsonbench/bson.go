@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// BSON (https://bsonspec.org) encoding of the data model with the MongoDB
+// driver's bson.Marshal and the structs' bson tags: uint64 as int64, int
+// as int32 when it fits, nil slices as null. BSON documents are the top
+// level, so Players is wrapped as {"players": [...]}.
+
+type bsonCodec struct{}
+
+// bsonPlayers is the document Players is wrapped in
+type bsonPlayers struct {
+	Players Players `bson:"players"`
+}
+
+func (bsonCodec) Name() string { return "bson" }
+
+func (bsonCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case *TwitterData:
+		return bson.Marshal(v)
+	case *Players:
+		return bson.Marshal(bsonPlayers{*v})
+	}
+	return nil, fmt.Errorf("bson: unsupported type %T", v)
+}
+
+func (bsonCodec) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *TwitterData:
+		return bson.Unmarshal(data, v)
+	case *Players:
+		var w bsonPlayers
+		err := bson.Unmarshal(data, &w)
+		*v = w.Players
+		return err
+	}
+	return fmt.Errorf("bson: unsupported type %T", v)
+}
+
+func init() {
+	registerCodec(bsonCodec{})
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// canonicalize rewrites a document in the RFC 8785 JSON Canonicalization
+// Scheme: no whitespace, object keys sorted by UTF-16 code units, numbers
+// in their shortest round-trip ECMAScript form and minimal string escaping.
+// JCS requires I-JSON input, so duplicate keys and invalid UTF-8 are
+// rejected.
+func canonicalize(data []byte) ([]byte, error) {
+	v, err := backends.Decode(data, backends.DecodeOptions{DuplicateKeys: backends.RejectDuplicates, UTF8: backends.RejectInvalid})
+	if err != nil {
+		return nil, err
+	}
+	return appendCanonical(nil, v)
+}
+
+func appendCanonical(buf []byte, v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case bool:
+		return strconv.AppendBool(buf, x), nil
+	case float64:
+		return appendES6Number(buf, x)
+	case string:
+		return appendCanonicalString(buf, x), nil
+	case []interface{}:
+		buf = append(buf, '[')
+		for i, e := range x {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			var err error
+			if buf, err = appendCanonical(buf, e); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, ']'), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+		buf = append(buf, '{')
+		for i, k := range keys {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendCanonicalString(buf, k)
+			buf = append(buf, ':')
+			var err error
+			if buf, err = appendCanonical(buf, x[k]); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, '}'), nil
+	}
+	return nil, fmt.Errorf("cannot canonicalize %T", v)
+}
+
+// lessUTF16 orders strings by their UTF-16 code units, as JCS requires;
+// this differs from byte order for characters beyond the BMP
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// appendCanonicalString escapes only what JSON requires: the quote, the
+// backslash and control characters, using the short forms when they exist
+func appendCanonicalString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			buf = append(buf, '\\', c)
+		case c == '\b':
+			buf = append(buf, '\\', 'b')
+		case c == '\t':
+			buf = append(buf, '\\', 't')
+		case c == '\n':
+			buf = append(buf, '\\', 'n')
+		case c == '\f':
+			buf = append(buf, '\\', 'f')
+		case c == '\r':
+			buf = append(buf, '\\', 'r')
+		case c < 0x20:
+			buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return append(buf, '"')
+}
+
+// appendES6Number formats f like ECMAScript's Number.prototype.toString,
+// which JCS adopts: shortest round-trip digits, plain notation for
+// exponents from -6 to 20 and "e+"/"e-" notation otherwise
+func appendES6Number(buf []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("%v has no JSON representation", f)
+	}
+	if f == 0 {
+		return append(buf, '0'), nil // also for -0
+	}
+	if f < 0 {
+		buf = append(buf, '-')
+		f = -f
+	}
+	// FormatFloat gives d.ddde±x; the value is 0.dddd × 10^n with n = x+1
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(e, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	x, _ := strconv.Atoi(exp)
+	n, k := x+1, len(digits)
+	switch {
+	case k <= n && n <= 21:
+		buf = append(buf, digits...)
+		buf = append(buf, strings.Repeat("0", n-k)...)
+	case 0 < n && n <= 21:
+		buf = append(buf, digits[:n]...)
+		buf = append(buf, '.')
+		buf = append(buf, digits[n:]...)
+	case -6 < n && n <= 0:
+		buf = append(buf, "0."...)
+		buf = append(buf, strings.Repeat("0", -n)...)
+		buf = append(buf, digits...)
+	default:
+		buf = append(buf, digits[0])
+		if k > 1 {
+			buf = append(buf, '.')
+			buf = append(buf, digits[1:]...)
+		}
+		buf = append(buf, 'e')
+		if n-1 >= 0 {
+			buf = append(buf, '+')
+		}
+		buf = strconv.AppendInt(buf, int64(n-1), 10)
+	}
+	return buf, nil
+}
+
+// canonicalFlags are the flags of canonical
+type canonicalFlags struct {
+	file       *string
+	throughput *bool
+	iterations *int
+	prof       *profileFlags
+}
+
+func (opts *canonicalFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("canonical")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to canonicalize")
+	opts.throughput = fs.Bool("bench", false, "report throughput instead of printing the result")
+	opts.iterations = fs.Int("n", 100, "number of iterations with -bench")
+	opts.prof = addProfileFlags(fs)
+	return fs
+}
+
+// Print the canonical form of a document, or benchmark canonicalization
+func runCanonical(args []string) error {
+	var opts canonicalFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	stop, err := opts.prof.start()
+	defer stop()
+	if err != nil {
+		return err
+	}
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	if *opts.throughput {
+		speed, err := bench.Measure(data, *opts.iterations, func(b []byte) error {
+			_, err := canonicalize(b)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("canonicalized %s at %.2f MB/s\n", *opts.file, speed)
+		return nil
+	}
+	out, err := canonicalize(data)
+	if err != nil {
+		return err
+	}
+	if !backends.Equal(data, out) {
+		return fmt.Errorf("canonical form does not match the input")
+	}
+	os.Stdout.Write(out)
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestES6Number checks appendES6Number against the IEEE 754 sample values
+// of RFC 8785 Appendix B, and a few more at the notation boundaries
+func TestES6Number(t *testing.T) {
+	for _, c := range []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+		{math.Float64bits(1e20), "100000000000000000000"},
+		{math.Float64bits(1e-7), "1e-7"},
+		{math.Float64bits(4.5), "4.5"},
+	} {
+		f := math.Float64frombits(c.bits)
+		got, err := appendES6Number(nil, f)
+		if err != nil || string(got) != c.want {
+			t.Errorf("appendES6Number(%v) = %q, %v, want %q", f, got, err, c.want)
+		}
+	}
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if got, err := appendES6Number(nil, f); err == nil {
+			t.Errorf("appendES6Number(%v) = %q, want an error", f, got)
+		}
+	}
+}
+
+// TestCanonicalize checks canonicalize against the examples of RFC 8785:
+// the §3.2.2 serialization of primitives and the §3.2.3 sorting by UTF-16
+// code units, where the emoji's surrogates sort before U+FB33
+func TestCanonicalize(t *testing.T) {
+	for _, c := range []struct {
+		in, want string
+	}{
+		{
+			`{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			`{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{`[-0, 1e21, 1E-7, 5e-324]`, `[0,1e+21,1e-7,5e-324]`},
+	} {
+		got, err := canonicalize([]byte(c.in))
+		if err != nil || string(got) != c.want {
+			t.Errorf("canonicalize(%q) = %q, %v, want %q", c.in, got, err, c.want)
+		}
+	}
+	for _, in := range []string{`{"a":1,"a":2}`, "\"\xff\""} {
+		if got, err := canonicalize([]byte(in)); err == nil {
+			t.Errorf("canonicalize(%q) = %q, want an error", in, got)
+		}
+	}
+}
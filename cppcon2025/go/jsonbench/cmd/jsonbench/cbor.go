@@ -0,0 +1,574 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// CBOR (RFC 8949) encoding of the data model, written by hand like the
+// MessagePack one: structs are maps keyed by the JSON field names, the
+// default of fxamacker/cbor for structs without keyasint tags.
+
+type cborCodec struct{}
+
+func (cborCodec) Name() string { return "cbor" }
+
+func (cborCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case *TwitterData:
+		return v.appendCBOR(nil), nil
+	case *Players:
+		return v.appendCBOR(nil), nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported type %T", v)
+}
+
+func (cborCodec) Unmarshal(data []byte, v interface{}) error {
+	r := &cborReader{data: data}
+	var err error
+	switch v := v.(type) {
+	case *TwitterData:
+		err = v.decodeCBOR(r)
+	case *Players:
+		err = v.decodeCBOR(r)
+	default:
+		return fmt.Errorf("cbor: unsupported type %T", v)
+	}
+	if err == nil && r.pos != len(data) {
+		err = fmt.Errorf("cbor: %d bytes of trailing data", len(data)-r.pos)
+	}
+	return err
+}
+
+func init() {
+	registerCodec(cborCodec{})
+}
+
+// CBOR major types
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+const (
+	cborFalse      = 0xf4
+	cborTrue       = 0xf5
+	cborNull       = 0xf6
+	cborFloat64    = 0xfb
+	cborBreak      = 0xff
+	cborIndefinite = 31
+)
+
+// appendCBORHead writes a major type and its argument in the shortest form
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), n)
+}
+
+func appendCBORString(b []byte, s string) []byte {
+	return append(appendCBORHead(b, cborText, uint64(len(s))), s...)
+}
+
+func appendCBORInt(b []byte, i int64) []byte {
+	if i < 0 {
+		return appendCBORHead(b, cborNegint, uint64(-1-i))
+	}
+	return appendCBORHead(b, cborUint, uint64(i))
+}
+
+// appendCBORArray writes an array header, or null for a nil slice so that
+// decoding gives back nil
+func appendCBORArray(b []byte, n int, isNil bool) []byte {
+	if isNil {
+		return append(b, cborNull)
+	}
+	return appendCBORHead(b, cborArray, uint64(n))
+}
+
+func appendCBORFloat(b []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, cborFloat64), math.Float64bits(f))
+}
+
+func appendCBORBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, cborTrue)
+	}
+	return append(b, cborFalse)
+}
+
+var errCBORShort = errors.New("cbor: unexpected end of data")
+
+// cborReader decodes values from data in order
+type cborReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *cborReader) typeError(want string, at int) error {
+	return fmt.Errorf("cbor: expected %s, found 0x%02x at offset %d", want, r.data[at], at)
+}
+
+// take returns the next n bytes
+func (r *cborReader) take(n uint64) ([]byte, error) {
+	if n > uint64(len(r.data)-r.pos) {
+		return nil, errCBORShort
+	}
+	b := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// head reads the initial byte and argument of the next item, skipping
+// tags, which the data model has no use for. indefinite is set for
+// indefinite-length strings, arrays and maps.
+func (r *cborReader) head() (major byte, n uint64, indefinite bool, err error) {
+	for {
+		if r.pos >= len(r.data) {
+			return 0, 0, false, errCBORShort
+		}
+		c := r.data[r.pos]
+		r.pos++
+		major, info := c>>5, c&0x1f
+		var b []byte
+		switch {
+		case info < 24:
+			n = uint64(info)
+		case info == 24:
+			b, err = r.take(1)
+		case info == 25:
+			b, err = r.take(2)
+		case info == 26:
+			b, err = r.take(4)
+		case info == 27:
+			b, err = r.take(8)
+		case info == cborIndefinite && major >= cborBytes && major <= cborMap:
+			indefinite = true
+		case info == cborIndefinite && major == cborSimple:
+			// The break stop code, checked by the callers
+		default:
+			return 0, 0, false, fmt.Errorf("cbor: invalid initial byte 0x%02x at offset %d", c, r.pos-1)
+		}
+		if err != nil {
+			return 0, 0, false, err
+		}
+		switch len(b) {
+		case 1:
+			n = uint64(b[0])
+		case 2:
+			n = uint64(binary.BigEndian.Uint16(b))
+		case 4:
+			n = uint64(binary.BigEndian.Uint32(b))
+		case 8:
+			n = binary.BigEndian.Uint64(b)
+		}
+		if major != cborTag {
+			return major, n, indefinite, nil
+		}
+	}
+}
+
+// length reads an array or map header and returns its length, -1 for an
+// indefinite length, or -2 for null. Every element takes at least one
+// byte, so longer arrays are rejected before allocating them.
+func (r *cborReader) length(major byte, want string) (int, error) {
+	start := r.pos
+	m, n, indefinite, err := r.head()
+	switch {
+	case err != nil:
+		return 0, err
+	case m == cborSimple && n == cborNull&0x1f:
+		return -2, nil
+	case m != major:
+		return 0, r.typeError(want, start)
+	case indefinite:
+		return -1, nil
+	case n > uint64(len(r.data)-r.pos):
+		return 0, errCBORShort
+	}
+	return int(n), nil
+}
+
+// more reports whether element i of a container of length n follows,
+// consuming the break code that ends an indefinite-length one. A missing
+// break is left for the next read to report.
+func (r *cborReader) more(i, n int) bool {
+	if n >= 0 {
+		return i < n
+	}
+	if r.pos < len(r.data) && r.data[r.pos] == cborBreak {
+		r.pos++
+		return false
+	}
+	return true
+}
+
+func (r *cborReader) mapHeader() (int, error) {
+	n, err := r.length(cborMap, "map")
+	if err == nil && n == -2 {
+		return 0, r.typeError("map", r.pos-1)
+	}
+	return n, err
+}
+
+// arrayHeader is like mapHeader but accepts null, returned as -2
+func (r *cborReader) arrayHeader() (int, error) {
+	return r.length(cborArray, "array")
+}
+
+// textBytes returns the bytes of a definite-length text string without
+// copying them
+func (r *cborReader) textBytes() ([]byte, error) {
+	start := r.pos
+	major, n, indefinite, err := r.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != cborText {
+		return nil, r.typeError("text string", start)
+	}
+	if indefinite {
+		return nil, fmt.Errorf("cbor: indefinite-length string at offset %d is not supported", start)
+	}
+	return r.take(n)
+}
+
+func (r *cborReader) string() (string, error) {
+	b, err := r.textBytes()
+	return string(b), err
+}
+
+func (r *cborReader) int() (int64, error) {
+	start := r.pos
+	major, n, _, err := r.head()
+	switch {
+	case err != nil:
+		return 0, err
+	case major == cborUint && n <= math.MaxInt64:
+		return int64(n), nil
+	case major == cborNegint && n <= math.MaxInt64:
+		return -1 - int64(n), nil
+	}
+	r.pos = start
+	return 0, r.typeError("integer", start)
+}
+
+func (r *cborReader) uint() (uint64, error) {
+	start := r.pos
+	major, n, _, err := r.head()
+	if err != nil {
+		return 0, err
+	}
+	if major != cborUint {
+		r.pos = start
+		return 0, r.typeError("unsigned integer", start)
+	}
+	return n, nil
+}
+
+func (r *cborReader) float() (float64, error) {
+	if r.pos >= len(r.data) {
+		return 0, errCBORShort
+	}
+	start := r.pos
+	switch r.data[r.pos] {
+	case cborFloat64:
+		b, err := r.take(9)
+		if err != nil {
+			return 0, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b[1:])), nil
+	case 0xfa:
+		b, err := r.take(5)
+		if err != nil {
+			return 0, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b[1:]))), nil
+	case 0xf9:
+		b, err := r.take(3)
+		if err != nil {
+			return 0, err
+		}
+		return halfToFloat(binary.BigEndian.Uint16(b[1:])), nil
+	}
+	i, err := r.int()
+	if err != nil {
+		return 0, r.typeError("number", start)
+	}
+	return float64(i), nil
+}
+
+// halfToFloat converts an IEEE 754 half-precision value
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		f = math.Inf(1)
+		if mant != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
+
+func (r *cborReader) bool() (bool, error) {
+	if r.pos >= len(r.data) {
+		return false, errCBORShort
+	}
+	switch r.data[r.pos] {
+	case cborFalse:
+		r.pos++
+		return false, nil
+	case cborTrue:
+		r.pos++
+		return true, nil
+	}
+	return false, r.typeError("bool", r.pos)
+}
+
+// skip steps over one item of any type, for unknown map keys
+func (r *cborReader) skip() error {
+	start := r.pos
+	major, n, indefinite, err := r.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case cborUint, cborNegint:
+		return nil
+	case cborBytes, cborText:
+		if !indefinite {
+			_, err := r.take(n)
+			return err
+		}
+		// Chunks until the break code
+		for r.more(0, -1) {
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case cborArray, cborMap:
+		items := n
+		if major == cborMap {
+			items *= 2
+		}
+		for i := uint64(0); indefinite && r.more(0, -1) || !indefinite && i < items; i++ {
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// Simple values and floats: the argument was the whole payload, except
+	// for a stray break code
+	if r.data[start] == cborBreak {
+		return r.typeError("item", start)
+	}
+	return nil
+}
+
+func (u *TwitterUser) appendCBOR(b []byte) []byte {
+	b = appendCBORHead(b, cborMap, 9)
+	b = appendCBORHead(appendCBORString(b, "id"), cborUint, u.ID)
+	b = appendCBORString(appendCBORString(b, "name"), u.Name)
+	b = appendCBORString(appendCBORString(b, "screen_name"), u.ScreenName)
+	b = appendCBORString(appendCBORString(b, "location"), u.Location)
+	b = appendCBORString(appendCBORString(b, "description"), u.Description)
+	b = appendCBORHead(appendCBORString(b, "followers_count"), cborUint, u.FollowersCount)
+	b = appendCBORHead(appendCBORString(b, "friends_count"), cborUint, u.FriendsCount)
+	b = appendCBORBool(appendCBORString(b, "verified"), u.Verified)
+	return appendCBORHead(appendCBORString(b, "statuses_count"), cborUint, u.StatusesCount)
+}
+
+func (u *TwitterUser) decodeCBOR(r *cborReader) error {
+	n, err := r.mapHeader()
+	for i := 0; err == nil && r.more(i, n); i++ {
+		var key []byte
+		if key, err = r.textBytes(); err != nil {
+			break
+		}
+		switch string(key) {
+		case "id":
+			u.ID, err = r.uint()
+		case "name":
+			u.Name, err = r.string()
+		case "screen_name":
+			u.ScreenName, err = r.string()
+		case "location":
+			u.Location, err = r.string()
+		case "description":
+			u.Description, err = r.string()
+		case "followers_count":
+			u.FollowersCount, err = r.uint()
+		case "friends_count":
+			u.FriendsCount, err = r.uint()
+		case "verified":
+			u.Verified, err = r.bool()
+		case "statuses_count":
+			u.StatusesCount, err = r.uint()
+		default:
+			err = r.skip()
+		}
+	}
+	return err
+}
+
+func (s *Status) appendCBOR(b []byte) []byte {
+	b = appendCBORString(appendCBORHead(b, cborMap, 1), "user")
+	return s.User.appendCBOR(b)
+}
+
+func (s *Status) decodeCBOR(r *cborReader) error {
+	n, err := r.mapHeader()
+	for i := 0; err == nil && r.more(i, n); i++ {
+		var key []byte
+		if key, err = r.textBytes(); err != nil {
+			break
+		}
+		if string(key) == "user" {
+			err = s.User.decodeCBOR(r)
+		} else {
+			err = r.skip()
+		}
+	}
+	return err
+}
+
+func (t *TwitterData) appendCBOR(b []byte) []byte {
+	b = appendCBORString(appendCBORHead(b, cborMap, 1), "statuses")
+	b = appendCBORArray(b, len(t.Statuses), t.Statuses == nil)
+	for i := range t.Statuses {
+		b = t.Statuses[i].appendCBOR(b)
+	}
+	return b
+}
+
+func (t *TwitterData) decodeCBOR(r *cborReader) error {
+	n, err := r.mapHeader()
+	for i := 0; err == nil && r.more(i, n); i++ {
+		var key []byte
+		if key, err = r.textBytes(); err != nil {
+			break
+		}
+		if string(key) != "statuses" {
+			err = r.skip()
+			continue
+		}
+		var count int
+		if count, err = r.arrayHeader(); err != nil || count == -2 {
+			t.Statuses = nil
+			continue
+		}
+		t.Statuses = make([]Status, 0, max(count, 0))
+		for j := 0; r.more(j, count); j++ {
+			var s Status
+			if err = s.decodeCBOR(r); err != nil {
+				break
+			}
+			t.Statuses = append(t.Statuses, s)
+		}
+	}
+	return err
+}
+
+func (p *Player) appendCBOR(b []byte) []byte {
+	b = appendCBORHead(b, cborMap, 4)
+	b = appendCBORString(appendCBORString(b, "username"), p.Username)
+	b = appendCBORInt(appendCBORString(b, "level"), int64(p.Level))
+	b = appendCBORFloat(appendCBORString(b, "health"), p.Health)
+	b = appendCBORString(b, "inventory")
+	b = appendCBORArray(b, len(p.Inventory), p.Inventory == nil)
+	for _, item := range p.Inventory {
+		b = appendCBORString(b, item)
+	}
+	return b
+}
+
+func (p *Player) decodeCBOR(r *cborReader) error {
+	n, err := r.mapHeader()
+	for i := 0; err == nil && r.more(i, n); i++ {
+		var key []byte
+		if key, err = r.textBytes(); err != nil {
+			break
+		}
+		switch string(key) {
+		case "username":
+			p.Username, err = r.string()
+		case "level":
+			var level int64
+			level, err = r.int()
+			p.Level = int(level)
+		case "health":
+			p.Health, err = r.float()
+		case "inventory":
+			var count int
+			if count, err = r.arrayHeader(); err != nil || count == -2 {
+				p.Inventory = nil
+				continue
+			}
+			p.Inventory = make([]string, 0, max(count, 0))
+			for j := 0; r.more(j, count); j++ {
+				var item string
+				if item, err = r.string(); err != nil {
+					break
+				}
+				p.Inventory = append(p.Inventory, item)
+			}
+		default:
+			err = r.skip()
+		}
+	}
+	return err
+}
+
+func (ps *Players) appendCBOR(b []byte) []byte {
+	b = appendCBORArray(b, len(*ps), *ps == nil)
+	for i := range *ps {
+		b = (*ps)[i].appendCBOR(b)
+	}
+	return b
+}
+
+func (ps *Players) decodeCBOR(r *cborReader) error {
+	count, err := r.arrayHeader()
+	if err != nil || count == -2 {
+		*ps = nil
+		return err
+	}
+	*ps = make(Players, 0, max(count, 0))
+	for i := 0; r.more(i, count); i++ {
+		var p Player
+		if err := p.decodeCBOR(r); err != nil {
+			return err
+		}
+		*ps = append(*ps, p)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// countStructural is the Go version of the C structural character count
+func countStructural(data []byte) int {
+	count := 0
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+			count++
+		case '{', '}', '[', ']', ',', ':':
+			count++
+		}
+	}
+	return count
+}
+
+// nsPerOp benchmarks fn with the testing package's calibration
+func nsPerOp(fn func()) time.Duration {
+	r := testing.Benchmark(func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			fn()
+		}
+	})
+	return time.Duration(r.NsPerOp())
+}
+
+// cgoFlags are the flags of cgo
+type cgoFlags struct {
+	file         *string
+	simdjsonGBps *float64
+	backendName  *string
+	calls        *int
+}
+
+func (opts *cgoFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("cgo")
+	opts.file = fs.String("file", "../twitter.json", "dataset whose records build the documents")
+	opts.simdjsonGBps = fs.Float64("simdjson-gbps", 4.0, "simdjson throughput in GB/s on this machine")
+	opts.backendName = fs.String("backend", "encoding/json", "Go backend to compare with")
+	opts.calls = fs.Int("calls", 1, "cgo calls per document, e.g. one per field extracted from C++")
+	return fs
+}
+
+// Measure the fixed cost of a cgo call and find the document size above
+// which handing the document to C++ simdjson would beat decoding it in Go.
+// simdjson itself is modelled by its throughput (-simdjson-gbps); the
+// overhead and the Go side are measured.
+func runCgo(args []string) error {
+	var opts cgoFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	b, err := backends.Lookup(*opts.backendName)
+	if err != nil {
+		return err
+	}
+	overhead, err := cgoCallOverhead()
+	if err != nil {
+		return err
+	}
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	recs, err := datasets.Records(data)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("cgo call overhead: %v per call\n", overhead)
+
+	// C and Go running the same simple loop separate the cost of the
+	// call from the quality of the code on either side
+	doc := datasets.ScaledDocument(recs, 1<<20)
+	goScan := nsPerOp(func() { countStructural(doc) })
+	cScan := nsPerOp(func() { cgoCountStructural(doc) })
+	fmt.Printf("structural count over %d bytes: Go %v, C via cgo %v\n\n", len(doc), goScan, cScan)
+
+	fmt.Printf("%10s %14s %18s  %s\n", "bytes", b.Name(), "cgo+simdjson", "faster")
+	breakEven := 0
+	for size := 16; size <= 1<<24; size *= 4 {
+		doc := datasets.ScaledDocument(recs, size)
+		if size < len(recs[0]) {
+			doc = datasets.ScaledDocument([]json.RawMessage{json.RawMessage(`{"id":1}`)}, size)
+		}
+		goTime := nsPerOp(func() { b.Decode(doc) })
+		cgoTime := time.Duration(*opts.calls)*overhead + time.Duration(float64(len(doc))/(*opts.simdjsonGBps))
+		winner := b.Name()
+		if cgoTime < goTime {
+			winner = "cgo+simdjson"
+			if breakEven == 0 {
+				breakEven = len(doc)
+			}
+		}
+		fmt.Printf("%10d %14v %18v  %s\n", len(doc), goTime, cgoTime, winner)
+	}
+	if breakEven > 0 {
+		fmt.Printf("\ncalling simdjson through cgo pays off from about %d bytes with %d call(s) per document\n", breakEven, *opts.calls)
+	}
+	return nil
+}
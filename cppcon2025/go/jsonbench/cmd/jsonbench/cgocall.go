@@ -0,0 +1,61 @@
+//go:build cgo && !tinygo
+
+package main
+
+/*
+#include <stddef.h>
+
+static void jsonbench_noop(void) {}
+
+// count the structural characters of a JSON document, ignoring strings
+static size_t jsonbench_count_structural(const char *p, size_t n) {
+	size_t count = 0;
+	int in_string = 0;
+	for (size_t i = 0; i < n; i++) {
+		char c = p[i];
+		if (in_string) {
+			if (c == '\\') {
+				i++;
+			} else if (c == '"') {
+				in_string = 0;
+			}
+			continue;
+		}
+		switch (c) {
+		case '"':
+			in_string = 1;
+			count++;
+			break;
+		case '{': case '}': case '[': case ']': case ',': case ':':
+			count++;
+			break;
+		}
+	}
+	return count;
+}
+*/
+import "C"
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// cgoCallOverhead measures the cost of calling an empty C function
+func cgoCallOverhead() (time.Duration, error) {
+	r := testing.Benchmark(func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			C.jsonbench_noop()
+		}
+	})
+	return time.Duration(r.NsPerOp()), nil
+}
+
+// cgoCountStructural counts structural characters in C
+func cgoCountStructural(data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+	return int(C.jsonbench_count_structural((*C.char)(unsafe.Pointer(&data[0])), C.size_t(len(data))))
+}
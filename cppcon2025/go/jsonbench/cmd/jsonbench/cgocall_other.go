@@ -0,0 +1,14 @@
+//go:build !cgo && !tinygo
+
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+func cgoCallOverhead() (time.Duration, error) {
+	return 0, errors.New("built without cgo (CGO_ENABLED=0)")
+}
+
+func cgoCountStructural(data []byte) int { return 0 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// chartFlags are the flags of chart
+type chartFlags struct {
+	kind    *string
+	out     *string
+	title   *string
+	cssFile *string
+}
+
+func (opts *chartFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("chart")
+	opts.kind = fs.String("type", "bar", "bar (bench result files) or line (sweep output)")
+	opts.out = fs.String("o", "chart.svg", "SVG file to write")
+	opts.title = fs.String("title", "Go JSON decoding throughput", "chart title")
+	opts.cssFile = fs.String("css", "", "stylesheet replacing the default chart style")
+	return fs
+}
+
+// Render bench results as a bar chart, or a sweep table as a line chart,
+// to a standalone SVG file sized for slides
+func runChart(args []string) error {
+	var opts chartFlags
+	fs := opts.flags()
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return withKind(errUsage, errors.New("chart: expected at least one result or sweep file"))
+	}
+
+	css := report.DefaultChartCSS
+	if *opts.cssFile != "" {
+		data, err := os.ReadFile(*opts.cssFile)
+		if err != nil {
+			return err
+		}
+		css = string(data)
+	}
+	var svg string
+	switch *opts.kind {
+	case "bar":
+		var categories []string
+		var list []report.Series
+		index := map[string]int{}
+		for _, path := range fs.Args() {
+			rf, err := bench.ReadResults(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			for _, r := range rf.Results {
+				c := indexOf(categories, r.Dataset)
+				if c < 0 {
+					categories = append(categories, r.Dataset)
+					c = len(categories) - 1
+				}
+				i, ok := index[r.Backend]
+				if !ok {
+					i = len(list)
+					index[r.Backend] = i
+					list = append(list, report.Series{Name: r.Backend})
+				}
+				for len(list[i].Values) <= c {
+					list[i].Values = append(list[i].Values, 0)
+				}
+				list[i].Values[c] = r.MBPerSec
+			}
+		}
+		svg = report.BarChartSVG(*opts.title, css, "MB/s", categories, list)
+	case "line":
+		xs, list, err := report.ReadSweep(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		svg = report.LineChartSVG(*opts.title, css, "MB/s", xs, list)
+	default:
+		return fmt.Errorf("unknown chart type %q", *opts.kind)
+	}
+	return os.WriteFile(*opts.out, []byte(svg), 0o644)
+}
+
+func indexOf(list []string, s string) int {
+	for i, x := range list {
+		if x == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// flagList returns the flags of a flag set in the order PrintDefaults
+// uses
+func flagList(fs *flag.FlagSet) []*flag.Flag {
+	var list []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) { list = append(list, f) })
+	return list
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// singleQuote quotes s for the shells, replacing each quote with the
+// given escape
+func singleQuote(s, quote string) string {
+	return "'" + strings.ReplaceAll(s, "'", quote) + "'"
+}
+
+func writeBashCompletion(w io.Writer) {
+	var names []string
+	for _, c := range allCommands() {
+		names = append(names, c.name)
+	}
+	fmt.Fprintln(w, "# bash completion for jsonbench; generated by 'jsonbench completion bash'")
+	fmt.Fprintln(w, "_jsonbench() {")
+	fmt.Fprintln(w, `	local cur=${COMP_WORDS[COMP_CWORD]} flags=""`)
+	fmt.Fprintf(w, "\tlocal commands=%s\n", singleQuote(strings.Join(names, " "), `'\''`))
+	fmt.Fprintln(w, `	if [ "$COMP_CWORD" -eq 1 ] || { [ "$COMP_CWORD" -eq 2 ] && [ "${COMP_WORDS[1]}" = help ]; }; then`)
+	fmt.Fprintln(w, `		COMPREPLY=($(compgen -W "help $commands" -- "$cur"))`)
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, `	case ${COMP_WORDS[1]} in`)
+	for _, c := range allCommands() {
+		var flags []string
+		for _, f := range flagList(c.flags()) {
+			flags = append(flags, "-"+f.Name)
+		}
+		if len(flags) > 0 {
+			fmt.Fprintf(w, "\t%s) flags=%s ;;\n", c.name, singleQuote(strings.Join(flags, " "), `'\''`))
+		}
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, `	if [[ $cur == -* ]]; then`)
+	fmt.Fprintln(w, `		COMPREPLY=($(compgen -W "$flags" -- "$cur"))`)
+	fmt.Fprintln(w, "\telse")
+	fmt.Fprintln(w, `		COMPREPLY=($(compgen -f -- "$cur"))`)
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -o filenames -F _jsonbench jsonbench")
+}
+
+// zshEscape escapes the characters _describe and _arguments give a
+// meaning to
+func zshEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ":", `\:`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+func writeZshCompletion(w io.Writer) {
+	quote := func(s string) string { return singleQuote(s, `'\''`) }
+	fmt.Fprintln(w, "#compdef jsonbench")
+	fmt.Fprintln(w, "# zsh completion for jsonbench; generated by 'jsonbench completion zsh'")
+	fmt.Fprintln(w, "_jsonbench() {")
+	fmt.Fprintln(w, "\tlocal -a commands")
+	fmt.Fprintln(w, "\tcommands=(")
+	fmt.Fprintln(w, "\t\t'help:print the usage or the flags of a command'")
+	for _, c := range allCommands() {
+		fmt.Fprintf(w, "\t\t%s\n", quote(zshEscape(c.name)+":"+zshEscape(c.summary)))
+	}
+	fmt.Fprintln(w, "\t)")
+	fmt.Fprintln(w, "\tif (( CURRENT == 2 )); then")
+	fmt.Fprintln(w, "\t\t_describe command commands")
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, "\tcase $words[2] in")
+	fmt.Fprintln(w, "\thelp) _describe command commands ;;")
+	for _, c := range allCommands() {
+		fmt.Fprintf(w, "\t%s)\n\t\t_arguments", c.name)
+		for _, f := range flagList(c.flags()) {
+			name, usage := flag.UnquoteUsage(f)
+			spec := "-" + f.Name + "[" + zshEscape(usage) + "]"
+			switch {
+			case isBoolFlag(f):
+			case name == "string":
+				spec += ":file:_files"
+			default:
+				spec += ":" + zshEscape(name) + ":"
+			}
+			fmt.Fprintf(w, " \\\n\t\t\t%s", quote(spec))
+		}
+		fmt.Fprintln(w, " \\\n\t\t\t'*:file:_files' ;;")
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, `_jsonbench "$@"`)
+}
+
+func writeFishCompletion(w io.Writer) {
+	quote := func(s string) string { return singleQuote(strings.ReplaceAll(s, `\`, `\\`), `\'`) }
+	fmt.Fprintln(w, "# fish completion for jsonbench; generated by 'jsonbench completion fish'")
+	fmt.Fprintln(w, "complete -c jsonbench -f")
+	fmt.Fprintln(w, "complete -c jsonbench -n __fish_use_subcommand -a help -d 'print the usage or the flags of a command'")
+	for _, c := range allCommands() {
+		fmt.Fprintf(w, "complete -c jsonbench -n __fish_use_subcommand -a %s -d %s\n", c.name, quote(c.summary))
+		fmt.Fprintf(w, "complete -c jsonbench -n '__fish_seen_subcommand_from help' -a %s -d %s\n", c.name, quote(c.summary))
+	}
+	for _, c := range allCommands() {
+		for _, f := range flagList(c.flags()) {
+			name, usage := flag.UnquoteUsage(f)
+			fmt.Fprintf(w, "complete -c jsonbench -n '__fish_seen_subcommand_from %s' -o %s -d %s", c.name, f.Name, quote(usage))
+			switch {
+			case isBoolFlag(f):
+			case name == "string":
+				fmt.Fprint(w, " -r -F")
+			default:
+				fmt.Fprint(w, " -x")
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// completionFlags are the flags of completion, which has none
+type completionFlags struct{}
+
+func (*completionFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("completion")
+	return fs
+}
+
+// Print a completion script built from the command tables and the flag
+// set of every command
+func runCompletion(args []string) error {
+	var opts completionFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	shells := map[string]func(io.Writer){
+		"bash": writeBashCompletion,
+		"zsh":  writeZshCompletion,
+		"fish": writeFishCompletion,
+	}
+	write, ok := shells[fs.Arg(0)]
+	if fs.NArg() != 1 || !ok {
+		fs.Usage()
+		return withKind(errUsage, errors.New("completion: expected one of bash, zsh or fish"))
+	}
+	write(os.Stdout)
+	return nil
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+)
+
+// examplePlayer is the player of json.go, the config example of the talk
+var examplePlayer = Player{
+	Username:  "hero123",
+	Level:     42,
+	Health:    95.5,
+	Inventory: []string{"sword", "shield", "potion"},
+}
+
+// playersConfig is a config file listing players, in the shape TOML can
+// express: a table holding an array of tables
+type playersConfig struct {
+	Players Players `json:"players" yaml:"players" toml:"players"`
+}
+
+// appendConfigString writes a double-quoted string that is valid in both
+// YAML and TOML, with \uXXXX escapes for control characters
+func appendConfigString(b []byte, s string) []byte {
+	b = append(b, '"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b = append(b, '\\', byte(r))
+		case r < ' ' || r == 0x7f:
+			b = append(b, fmt.Sprintf(`\u%04x`, r)...)
+		case r == utf8.RuneError:
+			b = append(b, `\ufffd`...)
+		default:
+			b = utf8.AppendRune(b, r)
+		}
+	}
+	return append(b, '"')
+}
+
+// appendConfigFloat writes f with a decimal point, so TOML and YAML read
+// it back as a float
+func appendConfigFloat(b []byte, f float64) []byte {
+	start := len(b)
+	b = strconv.AppendFloat(b, f, 'g', -1, 64)
+	for _, c := range b[start:] {
+		if c == '.' || c == 'e' {
+			return b
+		}
+	}
+	return append(b, ".0"...)
+}
+
+func (p *Player) appendYAML(b []byte, indent string) []byte {
+	b = append(append(b, indent...), "username: "...)
+	b = append(appendConfigString(b, p.Username), '\n')
+	b = append(append(b, indent...), "level: "...)
+	b = append(strconv.AppendInt(b, int64(p.Level), 10), '\n')
+	b = append(append(b, indent...), "health: "...)
+	b = append(appendConfigFloat(b, p.Health), '\n')
+	b = append(append(b, indent...), "inventory:"...)
+	if len(p.Inventory) == 0 {
+		return append(b, " []\n"...)
+	}
+	b = append(b, '\n')
+	for _, item := range p.Inventory {
+		b = append(append(b, indent...), "  - "...)
+		b = append(appendConfigString(b, item), '\n')
+	}
+	return b
+}
+
+func (c *playersConfig) appendYAML(b []byte) []byte {
+	b = append(b, "players:\n"...)
+	for i := range c.Players {
+		// The first field shares the line of the item's dash
+		item := c.Players[i].appendYAML(nil, "    ")
+		b = append(append(b, "  - "...), item[4:]...)
+	}
+	return b
+}
+
+func (p *Player) appendTOML(b []byte) []byte {
+	b = append(b, "username = "...)
+	b = append(appendConfigString(b, p.Username), '\n')
+	b = append(b, "level = "...)
+	b = append(strconv.AppendInt(b, int64(p.Level), 10), '\n')
+	b = append(b, "health = "...)
+	b = append(appendConfigFloat(b, p.Health), '\n')
+	b = append(b, "inventory = ["...)
+	for i, item := range p.Inventory {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = appendConfigString(b, item)
+	}
+	return append(b, "]\n"...)
+}
+
+func (c *playersConfig) appendTOML(b []byte) []byte {
+	for i := range c.Players {
+		if i > 0 {
+			b = append(b, '\n')
+		}
+		b = append(b, "[[players]]\n"...)
+		b = c.Players[i].appendTOML(b)
+	}
+	return b
+}
+
+// bindPlayer copies a generic player mapping into p, as the reflection
+// step of a YAML or TOML library would. Numbers may be int64, from those
+// parsers, or float64, from encoding/json.
+func bindPlayer(v interface{}, p *Player) error {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("player is %T, want a mapping", v)
+	}
+	*p = Player{}
+	for key, value := range m {
+		switch key {
+		case "username":
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("username is %T, want a string", value)
+			}
+			p.Username = s
+		case "level":
+			switch n := value.(type) {
+			case int64:
+				p.Level = int(n)
+			case float64:
+				if n != math.Trunc(n) || math.Abs(n) > math.MaxInt32 {
+					return fmt.Errorf("level %v is not an integer", n)
+				}
+				p.Level = int(n)
+			default:
+				return fmt.Errorf("level is %T, want an integer", value)
+			}
+		case "health":
+			switch n := value.(type) {
+			case int64:
+				p.Health = float64(n)
+			case float64:
+				p.Health = n
+			default:
+				return fmt.Errorf("health is %T, want a number", value)
+			}
+		case "inventory":
+			items, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("inventory is %T, want a sequence", value)
+			}
+			p.Inventory = make([]string, len(items))
+			for i, item := range items {
+				if p.Inventory[i], ok = item.(string); !ok {
+					return fmt.Errorf("inventory item %d is %T, want a string", i, item)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func bindPlayersConfig(v interface{}, c *playersConfig) error {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("config is %T, want a mapping", v)
+	}
+	list, ok := m["players"].([]interface{})
+	if !ok {
+		return fmt.Errorf("players is %T, want a sequence", m["players"])
+	}
+	c.Players = make(Players, len(list))
+	for i, item := range list {
+		if err := bindPlayer(item, &c.Players[i]); err != nil {
+			return fmt.Errorf("player %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// configFormat decodes a config document of one format into a Player or
+// a playersConfig
+type configFormat struct {
+	name string
+	// doc is the format of the document it reads
+	doc    string
+	decode func(data []byte, v interface{}) error
+}
+
+// genericConfigFormat parses into generic values, then binds them
+func genericConfigFormat(name, doc string, parse func([]byte) (interface{}, error)) configFormat {
+	return configFormat{name, doc, func(data []byte, v interface{}) error {
+		tree, err := parse(data)
+		if err != nil {
+			return err
+		}
+		switch v := v.(type) {
+		case *Player:
+			return bindPlayer(tree, v)
+		case *playersConfig:
+			return bindPlayersConfig(tree, v)
+		}
+		return fmt.Errorf("unsupported type %T", v)
+	}}
+}
+
+var configFormats = []configFormat{
+	{"json", "json", json.Unmarshal},
+	genericConfigFormat("json generic", "json", func(data []byte) (interface{}, error) {
+		var v interface{}
+		err := json.Unmarshal(data, &v)
+		return v, err
+	}),
+	{"yaml", "yaml", yaml.Unmarshal},
+	{"toml", "toml", toml.Unmarshal},
+	genericConfigFormat("yaml subset", "yaml", parseYAML),
+	genericConfigFormat("toml subset", "toml", parseTOML),
+}
+
+// configFlags are the flags of config
+type configFlags struct {
+	players    *int
+	seed       *int64
+	iterations *int
+	dump       *bool
+}
+
+func (opts *configFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("config")
+	opts.players = fs.Int("players", 100, "number of players in the player list")
+	opts.seed = fs.Int64("seed", 1, "seed of the player list")
+	opts.iterations = fs.Int("n", 1000, "number of iterations")
+	opts.dump = fs.Bool("print", false, "print the documents instead of timing them")
+	return fs
+}
+
+// runConfig measures how much slower the human-friendly config formats are
+// to load than JSON: json.go's player, and a list of players, written as
+// JSON, YAML and TOML and decoded into the structs by encoding/json,
+// gopkg.in/yaml.v3 and BurntSushi/toml. The subset parsers, and "json
+// generic", go through generic values and bind them instead.
+func runConfig(args []string) error {
+	var opts configFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	list := playersConfig{samplePlayers(*opts.players, *opts.seed)}
+	playerJSON, err := json.MarshalIndent(&examplePlayer, "", "  ")
+	if err != nil {
+		return err
+	}
+	listJSON, err := json.MarshalIndent(&list, "", "  ")
+	if err != nil {
+		return err
+	}
+	inputs := []struct {
+		name  string
+		value interface{}
+		fresh func() interface{}
+		docs  map[string][]byte
+	}{
+		{"player", &examplePlayer, func() interface{} { return new(Player) }, map[string][]byte{
+			"json": playerJSON,
+			"yaml": examplePlayer.appendYAML(nil, ""),
+			"toml": examplePlayer.appendTOML(nil),
+		}},
+		{"players", &list, func() interface{} { return new(playersConfig) }, map[string][]byte{
+			"json": listJSON,
+			"yaml": list.appendYAML(nil),
+			"toml": list.appendTOML(nil),
+		}},
+	}
+
+	for _, d := range inputs {
+		if *opts.dump {
+			for _, format := range []string{"json", "yaml", "toml"} {
+				fmt.Printf("# %s.%s\n%s\n", d.name, format, d.docs[format])
+			}
+			continue
+		}
+		fmt.Printf("%s:\n", d.name)
+		fmt.Printf("  %-14s %8s %10s %10s %8s\n", "format", "bytes", "MB/s", "µs", "vs json")
+		var baseline float64
+		for _, f := range configFormats {
+			doc := d.docs[f.doc]
+			back := d.fresh()
+			if err := f.decode(doc, back); err != nil {
+				return fmt.Errorf("%s: decoding %s: %w", f.name, d.name, err)
+			}
+			if !reflect.DeepEqual(back, d.value) {
+				return fmt.Errorf("%s: %s does not decode to the original", f.name, d.name)
+			}
+			speed, err := bench.Measure(doc, *opts.iterations, func(b []byte) error {
+				return f.decode(b, d.fresh())
+			})
+			if err != nil {
+				return err
+			}
+			micros := float64(len(doc)) / speed
+			if baseline == 0 {
+				baseline = micros
+			}
+			fmt.Printf("  %-14s %8d %10.2f %10.2f %7.2fx\n", f.name, len(doc), speed, micros, micros/baseline)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+)
+
+// conformanceCounts tallies the outcome of one backend over the corpus
+type conformanceCounts struct {
+	yPass, yFail     int // y_ files must be accepted
+	nPass, nFail     int // n_ files must be rejected
+	iAccept, iReject int // i_ files are implementation defined
+	crashes          int
+	failures         []string
+}
+
+// tryDecode decodes data and turns a panic into a crash report
+func tryDecode(b backends.Backend, data []byte) (accepted, crashed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			accepted, crashed = false, true
+		}
+	}()
+	_, err := b.Decode(data)
+	return err == nil, false
+}
+
+// conformanceFlags are the flags of conformance
+type conformanceFlags struct {
+	dir     *string
+	verbose *bool
+}
+
+func (opts *conformanceFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("conformance")
+	opts.dir = fs.String("dir", "JSONTestSuite/test_parsing", "JSONTestSuite test_parsing directory")
+	opts.verbose = fs.Bool("v", false, "list every failing file")
+	return fs
+}
+
+// Run every backend over the JSONTestSuite test_parsing directory
+// (https://github.com/nst/JSONTestSuite) and print a pass/fail matrix
+func runConformance(args []string) error {
+	var opts conformanceFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	entries, err := os.ReadDir(*opts.dir)
+	if err != nil {
+		return fmt.Errorf("reading corpus: %w", err)
+	}
+	type testFile struct {
+		name string
+		data []byte
+	}
+	var files []testFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(*opts.dir, name))
+		if err != nil {
+			return err
+		}
+		files = append(files, testFile{name, data})
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no .json files in %s", *opts.dir)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	fmt.Printf("%-16s %7s %7s %7s %7s %9s %9s %7s\n",
+		"backend", "y_pass", "y_fail", "n_pass", "n_fail", "i_accept", "i_reject", "crash")
+	for _, b := range backends.All() {
+		var c conformanceCounts
+		for _, f := range files {
+			accepted, crashed := tryDecode(b, f.data)
+			if crashed {
+				c.crashes++
+				c.failures = append(c.failures, f.name+" (panic)")
+				continue
+			}
+			switch f.name[0] {
+			case 'y':
+				if accepted {
+					c.yPass++
+				} else {
+					c.yFail++
+					c.failures = append(c.failures, f.name)
+				}
+			case 'n':
+				if accepted {
+					c.nFail++
+					c.failures = append(c.failures, f.name)
+				} else {
+					c.nPass++
+				}
+			case 'i':
+				if accepted {
+					c.iAccept++
+				} else {
+					c.iReject++
+				}
+			}
+		}
+		fmt.Printf("%-16s %7d %7d %7d %7d %9d %9d %7d\n", b.Name(),
+			c.yPass, c.yFail, c.nPass, c.nFail, c.iAccept, c.iReject, c.crashes)
+		if *opts.verbose {
+			for _, name := range c.failures {
+				fmt.Println("    fail:", name)
+			}
+		}
+	}
+	return nil
+}
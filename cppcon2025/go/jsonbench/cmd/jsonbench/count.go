@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/dom"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/ondemand"
+)
+
+// Two questions about twitter.json that need none of its values decoded:
+// how many statuses it has, and how many bytes its string values hold
+// (unescaped, keys excluded). Each counter answers one of them.
+type counter func(data []byte) (int, error)
+
+// countMethod answers both questions one way
+type countMethod struct {
+	name                  string
+	statuses, stringBytes counter
+}
+
+// genericStringBytes sums the string values under v
+func genericStringBytes(v interface{}) int {
+	n := 0
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, e := range v {
+			n += genericStringBytes(e)
+		}
+	case []interface{}:
+		for _, e := range v {
+			n += genericStringBytes(e)
+		}
+	case string:
+		n = len(v)
+	}
+	return n
+}
+
+// countTokens reads the tokens of data with encoding/json and returns the
+// number of elements of the top-level "statuses" array and the bytes of
+// the string values. The decoder returns keys and values alike, so each
+// open object records whether a key comes next.
+func countTokens(data []byte) (statuses, stringBytes int, err error) {
+	type frame struct{ object, key bool }
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var stack []frame
+	inStatuses, nextStatuses := -1, false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return statuses, stringBytes, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			if len(stack) == inStatuses {
+				inStatuses = -1
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if n := len(stack); n > 0 && stack[n-1].object {
+			if stack[n-1].key {
+				stack[n-1].key = false
+				nextStatuses = n == 1 && tok == "statuses"
+				continue
+			}
+			stack[n-1].key = true
+		}
+		if len(stack) == inStatuses {
+			statuses++
+		}
+		switch tok := tok.(type) {
+		case json.Delim:
+			stack = append(stack, frame{object: tok == '{', key: tok == '{'})
+			if nextStatuses && tok == '[' {
+				inStatuses = len(stack)
+			}
+		case string:
+			stringBytes += len(tok)
+		}
+		nextStatuses = false
+	}
+}
+
+// lazyStringBytes sums the string values of the value at the position of
+// d, skipping everything else without building it
+func lazyStringBytes(d *backends.Decoder, buf *[]byte) (int, error) {
+	n := 0
+	var walk func() error
+	walk = func() error {
+		switch d.Peek() {
+		case '{':
+			return d.Members(func([]byte) error { return walk() })
+		case '[':
+			return d.Elements(walk)
+		case '"':
+			s, err := d.StringBytes((*buf)[:0])
+			*buf, n = s, n+len(s)
+			return err
+		}
+		return d.Skip()
+	}
+	if err := walk(); err != nil {
+		return 0, err
+	}
+	return n, d.End()
+}
+
+// onDemandStringBytes sums the string values under v; the iterator skips
+// the values left unread
+func onDemandStringBytes(v ondemand.Value) (int, error) {
+	t, err := v.Type()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	switch t {
+	case ondemand.ObjectType:
+		o := v.GetObject()
+		for o.Next() {
+			m, err := onDemandStringBytes(o.Value())
+			if err != nil {
+				return 0, err
+			}
+			n += m
+		}
+		return n, o.Err()
+	case ondemand.ArrayType:
+		a := v.GetArray()
+		for a.Next() {
+			m, err := onDemandStringBytes(a.Value())
+			if err != nil {
+				return 0, err
+			}
+			n += m
+		}
+		return n, a.Err()
+	case ondemand.StringType:
+		s, err := v.GetString()
+		return len(s), err
+	}
+	return 0, nil
+}
+
+// domStringBytes sums the string values under e
+func domStringBytes(e dom.Element) int {
+	n := 0
+	switch e.Type() {
+	case dom.ObjectType:
+		o, _ := e.GetObject()
+		o.Each(func(_ []byte, v dom.Element) bool {
+			n += domStringBytes(v)
+			return true
+		})
+	case dom.ArrayType:
+		a, _ := e.GetArray()
+		a.Each(func(v dom.Element) bool {
+			n += domStringBytes(v)
+			return true
+		})
+	case dom.StringType:
+		s, _ := e.GetStringBytes()
+		n = len(s)
+	}
+	return n
+}
+
+func countMethods() []countMethod {
+	var odParser ondemand.Parser
+	var domParser dom.Parser
+	var buf []byte
+	return []countMethod{
+		{"interface{}, encoding/json",
+			func(data []byte) (int, error) {
+				var v struct{ Statuses []interface{} }
+				err := json.Unmarshal(data, &v)
+				return len(v.Statuses), err
+			},
+			func(data []byte) (int, error) {
+				var v interface{}
+				err := json.Unmarshal(data, &v)
+				return genericStringBytes(v), err
+			}},
+		{"tokens, encoding/json",
+			func(data []byte) (int, error) {
+				n, _, err := countTokens(data)
+				return n, err
+			},
+			func(data []byte) (int, error) {
+				_, n, err := countTokens(data)
+				return n, err
+			}},
+		{"lazy, hand-rolled",
+			func(data []byte) (int, error) {
+				d := backends.NewDecoder(data, backends.DecodeOptions{SyntaxOnly: true})
+				n := 0
+				err := d.Members(func(key []byte) error {
+					if string(key) != "statuses" {
+						return d.Skip()
+					}
+					return d.Elements(func() error {
+						n++
+						return d.Skip()
+					})
+				})
+				if err == nil {
+					err = d.End()
+				}
+				return n, err
+			},
+			func(data []byte) (int, error) {
+				return lazyStringBytes(backends.NewDecoder(data, backends.DecodeOptions{SyntaxOnly: true}), &buf)
+			}},
+		{"On-Demand",
+			func(data []byte) (int, error) {
+				doc, err := odParser.Iterate(data)
+				if err != nil {
+					return 0, err
+				}
+				statuses := doc.Field("statuses").GetArray()
+				n := 0
+				for statuses.Next() {
+					n++
+				}
+				return n, statuses.Err()
+			},
+			func(data []byte) (int, error) {
+				doc, err := odParser.Iterate(data)
+				if err != nil {
+					return 0, err
+				}
+				return onDemandStringBytes(doc.Value)
+			}},
+		{"dom",
+			func(data []byte) (int, error) {
+				root, err := domParser.Parse(data)
+				if err != nil {
+					return 0, err
+				}
+				statuses, err := root.Field("statuses").GetArray()
+				return statuses.Len(), err
+			},
+			func(data []byte) (int, error) {
+				root, err := domParser.Parse(data)
+				return domStringBytes(root), err
+			}},
+	}
+}
+
+// countFlags are the flags of count
+type countFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *countFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("count")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to count in")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	return fs
+}
+
+// Answer both questions with every method and time them; the methods
+// must agree on the answers
+func runCount(args []string) error {
+	var opts countFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	methods := countMethods()
+	var want [2]int
+	for i, m := range methods {
+		for q, c := range []counter{m.statuses, m.stringBytes} {
+			got, err := c(data)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", m.name, *opts.file, withKind(errDataset, err))
+			}
+			if i == 0 {
+				want[q] = got
+			} else if got != want[q] {
+				return withKind(errMismatch, fmt.Errorf("%s: counts %d, %s %d", m.name, got, methods[0].name, want[q]))
+			}
+		}
+	}
+	fmt.Printf("%s: %d bytes, %d statuses, %d bytes of string values\n\n", datasets.Name(*opts.file), len(data), want[0], want[1])
+
+	fmt.Println("| Method | count statuses MB/s | sum string bytes MB/s |")
+	fmt.Println("|---|---:|---:|")
+	for _, m := range methods {
+		var speeds [2]float64
+		for q, c := range []counter{m.statuses, m.stringBytes} {
+			if speeds[q], err = bench.Measure(data, *opts.iterations, func(data []byte) error {
+				_, err := c(data)
+				return err
+			}); err != nil {
+				return fmt.Errorf("%s: %w", m.name, err)
+			}
+		}
+		fmt.Printf("| %s | %.1f | %.1f |\n", m.name, speeds[0], speeds[1])
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+)
+
+// machineLabel names the machine of a result file in the arch table, such
+// as "Apple M2 Max (arm64)"
+func machineLabel(rf bench.ResultFile) string {
+	if rf.Environment == nil {
+		return ""
+	}
+	e := rf.Environment
+	switch {
+	case e.CPU != "" && e.Arch != "":
+		return fmt.Sprintf("%s (%s)", e.CPU, e.Arch)
+	case e.CPU != "":
+		return e.CPU
+	case e.Arch != "":
+		return e.OS + "/" + e.Arch
+	}
+	return ""
+}
+
+// archFlags are the flags of arch
+type archFlags struct {
+	dataset  *string
+	language *string
+}
+
+func (opts *archFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("arch")
+	opts.dataset = fs.String("dataset", "twitter.json", "dataset name for text files")
+	opts.language = fs.String("language", "C++", "language of text files")
+	return fs
+}
+
+func runArch(args []string) error {
+	var opts archFlags
+	fs := opts.flags()
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return withKind(errUsage, errors.New("arch: expected at least one result file"))
+	}
+
+	// Files are added oldest first, so the latest run of a machine wins
+	type labelled struct {
+		machine string
+		rf      bench.ResultFile
+	}
+	var files []labelled
+	var table pivot
+	for _, arg := range fs.Args() {
+		machine, path, ok := strings.Cut(arg, "=")
+		if !ok {
+			machine, path = "", arg
+		}
+		if !strings.HasSuffix(path, ".json") {
+			if machine == "" {
+				return fmt.Errorf("%s: text results need a machine= prefix", path)
+			}
+			rows, err := readTextResults(path, *opts.language, *opts.dataset)
+			if err != nil {
+				return err
+			}
+			for _, r := range rows {
+				table.add(machine, r.Dataset+" | "+r.Language+" | "+r.Backend, r.MBPerSec)
+			}
+			continue
+		}
+		rf, err := bench.ReadResults(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if machine == "" {
+			machine = machineLabel(rf)
+		}
+		if machine == "" {
+			return fmt.Errorf("%s: no machine recorded, use a machine= prefix", path)
+		}
+		files = append(files, labelled{machine, rf})
+	}
+	// Files without a time keep their place before the others
+	sort.SliceStable(files, func(i, j int) bool {
+		ti, tj := files[i].rf.Time, files[j].rf.Time
+		return tj != nil && (ti == nil || ti.Before(*tj))
+	})
+	for _, f := range files {
+		for _, r := range f.rf.Results {
+			table.add(f.machine, r.Dataset+" | "+f.rf.Language+" | "+r.Backend, r.MBPerSec)
+		}
+	}
+	table.print("Dataset | Language | Library")
+	return nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// demoStage is one step of the live demo: what it shows, and the work
+// it times, which returns a one-line result for the audience
+type demoStage struct {
+	title   string
+	explain string
+	run     func(data []byte) (string, error)
+}
+
+// extractTopUser finds the most followed user among the decoded
+// statuses
+func extractTopUser(td *TwitterData) string {
+	var top TwitterUser
+	verified := 0
+	for _, s := range td.Statuses {
+		if s.User.FollowersCount > top.FollowersCount {
+			top = s.User
+		}
+		if s.User.Verified {
+			verified++
+		}
+	}
+	return fmt.Sprintf("@%s has the most followers (%d); %d of %d users are verified",
+		top.ScreenName, top.FollowersCount, verified, len(td.Statuses))
+}
+
+func demoStages(file string) []demoStage {
+	var td TwitterData
+	return []demoStage{
+		{
+			"read the file",
+			"The whole document is read into memory. Every later stage works on these bytes, so this is the floor: nothing is faster than the copy from the page cache.",
+			func([]byte) (string, error) {
+				data, err := datasets.Read(file)
+				return fmt.Sprintf("%d bytes", len(data)), err
+			},
+		},
+		{
+			"validate",
+			"json.Valid looks at every byte and builds nothing. It is the cost of the grammar alone, the lower bound for any parser that checks its input.",
+			func(data []byte) (string, error) {
+				if !json.Valid(data) {
+					return "", fmt.Errorf("%s is not valid JSON", file)
+				}
+				return "valid", nil
+			},
+		},
+		{
+			"parse into structs",
+			"json.Unmarshal walks the document again, this time filling TwitterData by reflection: field names are matched at run time and every value we do not need is still parsed and skipped.",
+			func(data []byte) (string, error) {
+				td = TwitterData{}
+				if err := json.Unmarshal(data, &td); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d statuses", len(td.Statuses)), nil
+			},
+		},
+		{
+			"extract from the structs",
+			"With the structs decoded, extraction is ordinary Go code over a few hundred values: it costs next to nothing compared with getting there.",
+			func([]byte) (string, error) {
+				return extractTopUser(&td), nil
+			},
+		},
+		{
+			"extract on demand",
+			"The On-Demand way: one pass that reads only the fields we need and skips everything else without building it. No structs, no reflection, no second pass.",
+			func(data []byte) (string, error) {
+				var top TwitterUser
+				verified, users := 0, 0
+				// snippet:on-demand-extract
+				d := backends.NewDecoder(data, backends.DecodeOptions{})
+				err := d.Members(func(key []byte) error {
+					if string(key) != "statuses" {
+						return d.Skip()
+					}
+					return d.Elements(func() error {
+						return d.Members(func(key []byte) error {
+							if string(key) != "user" {
+								return d.Skip()
+							}
+							users++
+							var u TwitterUser
+							err := d.Members(func(key []byte) error {
+								switch {
+								case string(key) == "followers_count":
+									var err error
+									u.FollowersCount, err = d.Uint64()
+									return err
+								case string(key) == "screen_name":
+									var err error
+									u.ScreenName, err = d.String()
+									return err
+								case string(key) == "verified" && d.Peek() == 't':
+									verified++
+									return d.Literal("true")
+								}
+								return d.Skip()
+							})
+							if u.FollowersCount > top.FollowersCount {
+								top = u
+							}
+							return err
+						})
+					})
+				})
+				if err == nil {
+					err = d.End()
+				}
+				// snippet:on-demand-extract
+				return fmt.Sprintf("@%s has the most followers (%d); %d of %d users are verified",
+					top.ScreenName, top.FollowersCount, verified, users), err
+			},
+		},
+	}
+}
+
+// wrapText breaks s into lines of at most width bytes, each indented
+func wrapText(s, indent string, width int) string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, indent+line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, indent+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// demoFlags are the flags of demo
+type demoFlags struct {
+	file       *string
+	iterations *int
+	pause      *bool
+	delay      *time.Duration
+	width      *int
+}
+
+func (opts *demoFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("demo")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to walk through")
+	opts.iterations = fs.Int("n", 20, "iterations timed per stage")
+	opts.pause = fs.Bool("pause", true, "wait for Enter before each stage")
+	opts.delay = fs.Duration("delay", 0, "without -pause, wait this long before each stage")
+	opts.width = fs.Int("width", 72, "width of the explanations")
+	return fs
+}
+
+// Run the path from bytes on disk to an answer one stage at a time, with
+// a pause before each stage, its timing and a short explanation, for
+// presenting on stage
+func runDemo(args []string) error {
+	var opts demoFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	stages := demoStages(*opts.file)
+	keys := bufio.NewReader(os.Stdin)
+	times := make([]time.Duration, len(stages))
+	fmt.Printf("From %s (%d bytes) to an answer: who has the most followers?\n", datasets.Name(*opts.file), len(data))
+	for i, s := range stages {
+		fmt.Printf("\n[%d/%d] %s\n%s\n", i+1, len(stages), s.title, wrapText(s.explain, "      ", *opts.width))
+		if *opts.pause {
+			fmt.Print("      press Enter to run ")
+			if _, err := keys.ReadString('\n'); err != nil {
+				// Without a terminal, the demo runs through
+				*opts.pause = false
+				fmt.Println()
+			}
+		} else {
+			time.Sleep(*opts.delay)
+		}
+		var result string
+		speed, err := bench.Measure(data, *opts.iterations, func(data []byte) error {
+			var err error
+			result, err = s.run(data)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.title, err)
+		}
+		times[i] = time.Duration(float64(len(data)) / (speed * 1e6) * float64(time.Second))
+		fmt.Printf("      -> %s\n      %v per run, %.0f MB/s\n", result, times[i].Round(time.Microsecond), speed)
+	}
+
+	// The structs path is read, validate, parse and extract; on demand is
+	// read and one pass
+	structs := times[0] + times[1] + times[2] + times[3]
+	onDemand := times[0] + times[4]
+	fmt.Printf("\nread, validate, parse and extract: %v\n", structs.Round(time.Microsecond))
+	fmt.Printf("read and extract on demand:        %v (%.1fx faster)\n",
+		onDemand.Round(time.Microsecond), float64(structs)/float64(onDemand))
+	return nil
+}
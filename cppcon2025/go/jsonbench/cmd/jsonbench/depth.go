@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+)
+
+// deepNesting generates depth nested arrays around a single number,
+// alternating with objects so both container paths are exercised
+func deepNesting(depth int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			buf.WriteByte('[')
+		} else {
+			buf.WriteString(`{"a":`)
+		}
+	}
+	buf.WriteByte('1')
+	for i := depth - 1; i >= 0; i-- {
+		if i%2 == 0 {
+			buf.WriteByte(']')
+		} else {
+			buf.WriteByte('}')
+		}
+	}
+	return buf.Bytes()
+}
+
+// depthOutcome decodes data and names the result: ok, error or panic
+func depthOutcome(b backends.Backend, data []byte) (outcome string) {
+	defer func() {
+		if r := recover(); r != nil {
+			outcome = "panic"
+		}
+	}()
+	if _, err := b.Decode(data); err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// depthFlags are the flags of depth
+type depthFlags struct {
+	maxDepth *int
+}
+
+func (opts *depthFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("depth")
+	opts.maxDepth = fs.Int("max-depth", 1000, "nesting limit for the configured hand-rolled decoder")
+	return fs
+}
+
+// Document at which nesting depth each backend stops decoding, and whether
+// it does so with an error or a panic
+func runDepth(args []string) error {
+	var opts depthFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	depths := []int{100, 1000, 1001, 10000, 10001, 100000, 1000000}
+	list := append([]backends.Backend{}, backends.All()...)
+	list = append(list,
+		backends.Handrolled{Options: backends.DecodeOptions{MaxDepth: *opts.maxDepth}},
+		backends.Handrolled{Options: backends.DecodeOptions{MaxDepth: -1}})
+
+	fmt.Printf("%-28s", "backend")
+	for _, depth := range depths {
+		fmt.Printf(" %8d", depth)
+	}
+	fmt.Println()
+	for _, b := range list {
+		fmt.Printf("%-28s", b.Name())
+		for _, depth := range depths {
+			fmt.Printf(" %8s", depthOutcome(b, deepNesting(depth)))
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/dom"
+)
+
+// checksum is what a traversal computes over every value of a document,
+// whatever the order of the fields: the number of values, of string
+// bytes (keys included) and the xor of the bits of every number
+type checksum struct {
+	values, stringBytes int
+	numbers             uint64
+}
+
+func (c *checksum) generic(v interface{}) {
+	c.values++
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			c.stringBytes += len(k)
+			c.generic(e)
+		}
+	case []interface{}:
+		for _, e := range v {
+			c.generic(e)
+		}
+	case string:
+		c.stringBytes += len(v)
+	case float64:
+		c.numbers ^= math.Float64bits(v)
+	}
+}
+
+func (c *checksum) dom(e dom.Element) {
+	c.values++
+	switch e.Type() {
+	case dom.ObjectType:
+		o, _ := e.GetObject()
+		o.Each(func(k []byte, v dom.Element) bool {
+			c.stringBytes += len(k)
+			c.dom(v)
+			return true
+		})
+	case dom.ArrayType:
+		a, _ := e.GetArray()
+		a.Each(func(v dom.Element) bool {
+			c.dom(v)
+			return true
+		})
+	case dom.StringType:
+		s, _ := e.GetStringBytes()
+		c.stringBytes += len(s)
+	case dom.Int64Type, dom.Uint64Type, dom.DoubleType:
+		f, _ := e.GetDouble()
+		c.numbers ^= math.Float64bits(f)
+	}
+}
+
+// domFlags are the flags of dom
+type domFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *domFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("dom")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to parse")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	return fs
+}
+
+// Time building a DOM and traversing all of it: simdjson's tape, built
+// by stage 2 from the structural index, against the map[string]interface{}
+// values of encoding/json and the hand-rolled decoder. Every traversal
+// must compute the same checksum.
+func runDOM(args []string) error {
+	var opts domFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var parser dom.Parser
+	var root dom.Element
+	var generic interface{}
+	methods := []struct {
+		name     string
+		build    func([]byte) error
+		traverse func(*checksum)
+	}{
+		{"encoding/json, interface{}", func(b []byte) error {
+			generic = nil
+			return json.Unmarshal(b, &generic)
+		}, func(c *checksum) { c.generic(generic) }},
+		{"hand-rolled, interface{}", func(b []byte) (err error) {
+			generic, err = backends.Decode(b, backends.DecodeOptions{})
+			return err
+		}, func(c *checksum) { c.generic(generic) }},
+		{"dom, tape and strings", func(b []byte) (err error) {
+			root, err = parser.Parse(b)
+			return err
+		}, func(c *checksum) { c.dom(root) }},
+	}
+
+	var want checksum
+	fmt.Println("| Method | build µs | traverse µs | total MB/s | speedup |")
+	fmt.Println("|---|---:|---:|---:|---:|")
+	base := 0.0
+	for i, m := range methods {
+		if err := m.build(data); err != nil {
+			return fmt.Errorf("%s: %s: %w", m.name, *opts.file, withKind(errDataset, err))
+		}
+		var got checksum
+		m.traverse(&got)
+		if i == 0 {
+			want = got
+		} else if got != want {
+			return withKind(errMismatch, fmt.Errorf("%s: traversal gives %+v, encoding/json %+v", m.name, got, want))
+		}
+		var build, traverse time.Duration
+		for it := 0; it < *opts.iterations; it++ {
+			start := time.Now()
+			if err := m.build(data); err != nil {
+				return err
+			}
+			mid := time.Now()
+			m.traverse(new(checksum))
+			build, traverse = build+mid.Sub(start), traverse+time.Since(mid)
+		}
+		buildMicros := build.Seconds() * 1e6 / float64(*opts.iterations)
+		traverseMicros := traverse.Seconds() * 1e6 / float64(*opts.iterations)
+		speed := float64(len(data)) / (buildMicros + traverseMicros)
+		if base == 0 {
+			base = speed
+		}
+		fmt.Printf("| %s | %.1f | %.1f | %.1f | %.1f× |\n", m.name, buildMicros, traverseMicros, speed, speed/base)
+	}
+	doc := parser.Document()
+	fmt.Printf("\n%s: %d bytes, %d values, %d tape words, %d string bytes\n",
+		datasets.Name(*opts.file), len(data), want.values, len(doc.Words), len(doc.Strings))
+	return nil
+}
@@ -0,0 +1,233 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// throttledWriter sends at most bytesPerSec, in small flushed chunks, so
+// that a loopback download behaves like one over a real network
+type throttledWriter struct {
+	w           io.Writer
+	bytesPerSec float64
+	start       time.Time
+	sent        int
+}
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > 16<<10 {
+			chunk = chunk[:16<<10]
+		}
+		n, err := t.w.Write(chunk)
+		written += n
+		t.sent += n
+		if err != nil {
+			return written, err
+		}
+		if f, ok := t.w.(http.Flusher); ok {
+			f.Flush()
+		}
+		p = p[n:]
+		if t.bytesPerSec > 0 {
+			due := t.start.Add(time.Duration(float64(t.sent) / t.bytesPerSec * float64(time.Second)))
+			time.Sleep(time.Until(due))
+		}
+	}
+	return written, nil
+}
+
+// documentHandler serves doc, or its gzip-compressed form to clients that
+// accept gzip, at mbps megabits per second (0 for no limit)
+func documentHandler(doc, gzipped []byte, mbps float64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := doc
+		w.Header().Set("Content-Type", "application/json")
+		if gzipped != nil && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			body = gzipped
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		tw := &throttledWriter{w: w, bytesPerSec: mbps * 1e6 / 8, start: time.Now()}
+		tw.Write(body)
+	})
+}
+
+// fetchResult is the timing of one download and decode
+type fetchResult struct {
+	firstRecord time.Duration
+	total       time.Duration
+	records     int
+	wireBytes   int64
+	jsonBytes   int64
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// fetchRecords downloads url and decodes its top-level array of statuses,
+// either one record at a time as the bytes arrive or after reading the
+// whole body
+func fetchRecords(client *http.Client, url string, gzipped, stream bool) (fetchResult, error) {
+	var r fetchResult
+	start := time.Now()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return r, err
+	}
+	if gzipped {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return r, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return r, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	wire := &countingReader{r: resp.Body}
+	body := &countingReader{r: wire}
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(wire)
+		if err != nil {
+			return r, err
+		}
+		body.r = zr
+	}
+
+	if stream {
+		dec := json.NewDecoder(body)
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return r, fmt.Errorf("%s: expected a top-level array", url)
+		}
+		for dec.More() {
+			var s Status
+			if err := dec.Decode(&s); err != nil {
+				return r, err
+			}
+			if r.records == 0 {
+				r.firstRecord = time.Since(start)
+			}
+			r.records++
+		}
+		if _, err := dec.Token(); err != nil {
+			return r, err
+		}
+	} else {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return r, err
+		}
+		var statuses []Status
+		if err := json.Unmarshal(data, &statuses); err != nil {
+			return r, err
+		}
+		r.records = len(statuses)
+		r.firstRecord = time.Since(start)
+	}
+	r.total = time.Since(start)
+	r.wireBytes, r.jsonBytes = wire.n, body.n
+	return r, nil
+}
+
+// downloadFlags are the flags of download
+type downloadFlags struct {
+	url     *string
+	file    *string
+	size    *string
+	gzipped *bool
+	mbps    *float64
+}
+
+func (opts *downloadFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("download")
+	opts.url = fs.String("url", "", "fetch this array of statuses instead of serving one locally")
+	opts.file = fs.String("file", "../twitter.json", "document whose statuses make up the served array")
+	opts.size = fs.String("size", "64MB", "size of the served array")
+	opts.gzipped = fs.Bool("gzip", false, "request, and serve, a gzip-compressed response")
+	opts.mbps = fs.Float64("mbps", 1000, "bandwidth of the local server in megabits per second (0 for no limit)")
+	return fs
+}
+
+// runDownload compares decoding a large response while it downloads with
+// downloading it first
+func runDownload(args []string) error {
+	var opts downloadFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	if *opts.url == "" {
+		data, err := datasets.Read(*opts.file)
+		if err != nil {
+			return err
+		}
+		recs, err := datasets.Records(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *opts.file, err)
+		}
+		n, err := datasets.ParseSize(*opts.size)
+		if err != nil {
+			return fmt.Errorf("-size: %w", err)
+		}
+		doc := datasets.ScaledDocument(recs, n)
+		var compressed []byte
+		if *opts.gzipped {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			zw.Write(doc)
+			zw.Close()
+			compressed = buf.Bytes()
+		}
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		server := &http.Server{Handler: documentHandler(doc, compressed, *opts.mbps)}
+		go server.Serve(ln)
+		defer server.Close()
+		*opts.url = "http://" + ln.Addr().String() + "/"
+	}
+
+	// The transport must not decompress by itself, so that both the wire
+	// and the JSON bytes can be counted
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	fmt.Println("| Method | first record ms | total ms | MB/s of JSON | records | wire bytes |")
+	fmt.Println("|---|---:|---:|---:|---:|---:|")
+	for _, m := range []struct {
+		name   string
+		stream bool
+	}{{"download, then parse", false}, {"parse while downloading", true}} {
+		r, err := fetchRecords(client, *opts.url, *opts.gzipped, m.stream)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		fmt.Printf("| %s | %.1f | %.1f | %.2f | %d | %d |\n", m.name, bench.Milliseconds(r.firstRecord), bench.Milliseconds(r.total),
+			float64(r.jsonBytes)/1e6/r.total.Seconds(), r.records, r.wireBytes)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+)
+
+// thirdPartyDecoders are the JSON decoders of the libraries the module
+// depends on for other experiments, each decoding into a generic object
+var thirdPartyDecoders = []struct {
+	name   string
+	decode func(data []byte) (interface{}, error)
+}{
+	{"mongo-driver ExtJSON", func(data []byte) (interface{}, error) {
+		var m bson.M
+		err := bson.UnmarshalExtJSON(data, false, &m)
+		return map[string]interface{}(m), err
+	}},
+	{"protojson structpb", func(data []byte) (interface{}, error) {
+		var s structpb.Struct
+		err := protojson.Unmarshal(data, &s)
+		return s.AsMap(), err
+	}},
+	{"yaml.v3", func(data []byte) (interface{}, error) {
+		var m map[string]interface{}
+		err := yaml.Unmarshal(data, &m)
+		return m, err
+	}},
+}
+
+// duplicateBehavior decodes an object that repeats a key and names what
+// the decoder did with it
+func duplicateBehavior(decode func(data []byte) (interface{}, error)) string {
+	v, err := decode([]byte(`{"key":"first","key":"last"}`))
+	if err != nil {
+		return "error"
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Sprintf("unexpected result %v", v)
+	}
+	switch obj["key"] {
+	case "first":
+		return "first-wins"
+	case "last":
+		return "last-wins"
+	}
+	return fmt.Sprintf("unexpected value %v", obj["key"])
+}
+
+// dupKeysFlags are the flags of dupkeys, which has none
+type dupKeysFlags struct{}
+
+func (*dupKeysFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("dupkeys")
+	return fs
+}
+
+// Report how every backend, the hand-rolled decoder under each of its
+// policies and the third-party decoders handle duplicate object keys. Parsers that disagree here can
+// be tricked into validating one value and acting on another.
+func runDupKeys(args []string) error {
+	var opts dupKeysFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	for _, b := range backends.All() {
+		fmt.Printf("%-24s %s\n", b.Name(), duplicateBehavior(b.Decode))
+	}
+	for _, p := range []backends.DuplicatePolicy{backends.FirstWins, backends.RejectDuplicates} {
+		b := backends.Handrolled{Options: backends.DecodeOptions{DuplicateKeys: p}}
+		fmt.Printf("%-24s %s\n", b.Name(), duplicateBehavior(b.Decode))
+	}
+	for _, d := range thirdPartyDecoders {
+		fmt.Printf("%-24s %s\n", d.name, duplicateBehavior(d.decode))
+	}
+	return nil
+}
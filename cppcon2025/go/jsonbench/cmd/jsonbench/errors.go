@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+)
+
+// The kinds of failure a script wrapping jsonbench may want to tell
+// apart; each has its own exit code
+var (
+	errUsage       = errors.New("invalid usage")
+	errDataset     = errors.New("dataset missing")
+	errUnavailable = errors.New("not available")
+	errMismatch    = errors.New("results disagree")
+	errRegression  = errors.New("throughput regression")
+)
+
+// Exit codes; flag parsing errors also exit with exitUsage
+const (
+	exitFailure     = 1
+	exitUsage       = 2
+	exitDataset     = 3
+	exitUnavailable = 4
+	exitMismatch    = 5
+	exitRegression  = 6
+)
+
+// exitCodes describes the exit codes for the usage and the man page
+var exitCodes = []struct {
+	code    int
+	meaning string
+}{
+	{exitFailure, "any other error"},
+	{exitUsage, "invalid usage: unknown command, bad flag or missing operand"},
+	{exitDataset, "a dataset is missing or could not be fetched"},
+	{exitUnavailable, "a backend or feature is not available in this build or on this machine"},
+	{exitMismatch, "results disagree with encoding/json or a self-check failed"},
+	{exitRegression, "a throughput regression was found"},
+}
+
+// kindError gives err the kind of failure kind, keeping its message
+type kindError struct {
+	kind, err error
+}
+
+func (e kindError) Error() string   { return e.err.Error() }
+func (e kindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// withKind marks err as a failure of the given kind
+func withKind(kind, err error) error {
+	return kindError{kind, err}
+}
+
+// exitCode maps the error a command returned to the exit code of the
+// process. Errors from the packages count by their own sentinels: a
+// missing file is a missing dataset, an unknown backend or missing
+// hardware counters an unavailable feature.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, errUnavailable), errors.Is(err, backends.ErrUnknownBackend), errors.Is(err, bench.ErrNoCounters):
+		return exitUnavailable
+	case errors.Is(err, errDataset), errors.Is(err, fs.ErrNotExist):
+		return exitDataset
+	case errors.Is(err, errMismatch):
+		return exitMismatch
+	case errors.Is(err, errRegression):
+		return exitRegression
+	}
+	return exitFailure
+}
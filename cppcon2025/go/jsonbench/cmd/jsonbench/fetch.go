@@ -0,0 +1,59 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// fetchFlags are the flags of fetch
+type fetchFlags struct {
+	dir   *string
+	names *string
+	url   *string
+	force *bool
+}
+
+func (opts *fetchFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("fetch")
+	opts.dir = fs.String("dir", "..", "directory to write the documents to")
+	opts.names = fs.String("datasets", strings.Join(datasets.Corpus, ","), "comma-separated list of documents to download")
+	opts.url = fs.String("url", datasets.CorpusURL, "directory URL the documents are downloaded from")
+	opts.force = fs.Bool("force", false, "download documents that are already present again")
+	return fs
+}
+
+// Download the benchmark documents next to twitter.json, where the
+// commands look for them, skipping those already there
+func runFetch(args []string) error {
+	var opts fetchFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	if err := os.MkdirAll(*opts.dir, 0o755); err != nil {
+		return err
+	}
+	for _, name := range strings.Split(*opts.names, ",") {
+		path := filepath.Join(*opts.dir, name)
+		status := "present"
+		if _, err := os.Stat(path); err != nil || *opts.force {
+			if path, err = datasets.Fetch(*opts.url, name, *opts.dir); err != nil {
+				return withKind(errDataset, err)
+			}
+			status = "downloaded"
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-20s %-10s %10d bytes  sha256 %x\n", name, status, len(data), sha256.Sum256(data))
+	}
+	return nil
+}
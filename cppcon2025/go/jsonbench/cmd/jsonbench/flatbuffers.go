@@ -0,0 +1,422 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// FlatBuffers encoding of the data model, with the tables of twitter.fbs.
+// The accessors read fields in place, like the code flatc generates, so a
+// reader pays only for the fields it touches; Unmarshal copies everything
+// out to compare with the other formats on equal terms.
+//
+// The builder writes front to back: a table comes first and the strings,
+// vectors and tables it refers to follow it, so every offset points
+// forward as the format requires.
+
+type flatbuffersCodec struct{}
+
+func (flatbuffersCodec) Name() string { return "flatbuffers" }
+
+func (flatbuffersCodec) Marshal(v interface{}) ([]byte, error) {
+	b := &flatBuilder{}
+	switch v := v.(type) {
+	case *TwitterData:
+		return b.finish(v.buildFlat), nil
+	case *Players:
+		return b.finish(v.buildFlat), nil
+	}
+	return nil, fmt.Errorf("flatbuffers: unsupported type %T", v)
+}
+
+var errFlatMalformed = errors.New("flatbuffers: malformed buffer")
+
+// Unmarshal turns the out-of-range reads of a malformed buffer into an error;
+// the accessors themselves trust the buffer, as flatc's do without a
+// verifier
+func (flatbuffersCodec) Unmarshal(data []byte, v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errFlatMalformed
+		}
+	}()
+	root := flatRoot(data)
+	switch v := v.(type) {
+	case *TwitterData:
+		*v = flatTwitterData{root}.unpack()
+	case *Players:
+		*v = flatPlayers{root}.unpack()
+	default:
+		return fmt.Errorf("flatbuffers: unsupported type %T", v)
+	}
+	return nil
+}
+
+func init() {
+	registerCodec(flatbuffersCodec{})
+}
+
+// flatBuilder appends tables, strings and vectors to buf
+type flatBuilder struct {
+	buf []byte
+}
+
+func (b *flatBuilder) align(n int) {
+	for len(b.buf)%n != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// patch stores at pos the offset from pos to target
+func (b *flatBuilder) patch(pos, target int) {
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(target-pos))
+}
+
+// finish writes the root offset followed by the root table
+func (b *flatBuilder) finish(root func(b *flatBuilder) int) []byte {
+	b.buf = append(b.buf[:0], 0, 0, 0, 0)
+	b.patch(0, root(b))
+	return b.buf
+}
+
+// flatField is one field of a table under construction: a scalar of size
+// 1, 4 or 8 bytes, or, when ref is set, an offset to the object ref writes
+type flatField struct {
+	slot  int
+	size  int
+	value uint64
+	ref   func(b *flatBuilder) int
+}
+
+// table writes a vtable, then the table, then the objects its fields refer
+// to, and returns the position of the table
+func (b *flatBuilder) table(slots int, fields []flatField) int {
+	// Inline layout: the vtable offset, then the fields from the largest to
+	// the smallest so that each is aligned
+	sorted := append([]flatField(nil), fields...)
+	for i := range sorted {
+		if sorted[i].ref != nil {
+			sorted[i].size = 4
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].size > sorted[j].size })
+	offsets := make([]int, len(sorted))
+	size := 4
+	for i, f := range sorted {
+		for size%f.size != 0 {
+			size++
+		}
+		offsets[i] = size
+		size += f.size
+	}
+
+	b.align(2)
+	vtable := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(4+2*slots))
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(size))
+	entries := make([]uint16, slots)
+	for i, f := range sorted {
+		entries[f.slot] = uint16(offsets[i])
+	}
+	for _, e := range entries {
+		b.buf = binary.LittleEndian.AppendUint16(b.buf, e)
+	}
+
+	b.align(8)
+	table := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[table:], uint32(int32(table-vtable)))
+	for i, f := range sorted {
+		at := b.buf[table+offsets[i]:]
+		switch f.size {
+		case 1:
+			at[0] = byte(f.value)
+		case 4:
+			binary.LittleEndian.PutUint32(at, uint32(f.value))
+		case 8:
+			binary.LittleEndian.PutUint64(at, f.value)
+		}
+	}
+	for i, f := range sorted {
+		if f.ref != nil {
+			b.patch(table+offsets[i], f.ref(b))
+		}
+	}
+	return table
+}
+
+func (b *flatBuilder) string(s string) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(s)))
+	b.buf = append(append(b.buf, s...), 0)
+	return pos
+}
+
+// vector writes a vector of n offsets to the objects elem writes
+func (b *flatBuilder) vector(n int, elem func(b *flatBuilder, i int) int) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(n))
+	b.buf = append(b.buf, make([]byte, 4*n)...)
+	for i := 0; i < n; i++ {
+		b.patch(pos+4+4*i, elem(b, i))
+	}
+	return pos
+}
+
+// Field helpers leave out default values, like flatc's builders
+
+func flatUint(fields []flatField, slot int, v uint64, size int) []flatField {
+	if v == 0 {
+		return fields
+	}
+	return append(fields, flatField{slot: slot, size: size, value: v})
+}
+
+func flatString(fields []flatField, slot int, s string) []flatField {
+	if s == "" {
+		return fields
+	}
+	return append(fields, flatField{slot: slot, ref: func(b *flatBuilder) int { return b.string(s) }})
+}
+
+func boolToUint(v bool) uint64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func (u *TwitterUser) buildFlat(b *flatBuilder) int {
+	fields := make([]flatField, 0, 9)
+	fields = flatUint(fields, 0, u.ID, 8)
+	fields = flatString(fields, 1, u.Name)
+	fields = flatString(fields, 2, u.ScreenName)
+	fields = flatString(fields, 3, u.Location)
+	fields = flatString(fields, 4, u.Description)
+	fields = flatUint(fields, 5, u.FollowersCount, 8)
+	fields = flatUint(fields, 6, u.FriendsCount, 8)
+	fields = flatUint(fields, 7, boolToUint(u.Verified), 1)
+	fields = flatUint(fields, 8, u.StatusesCount, 8)
+	return b.table(9, fields)
+}
+
+func (s *Status) buildFlat(b *flatBuilder) int {
+	return b.table(1, []flatField{{slot: 0, ref: s.User.buildFlat}})
+}
+
+func (t *TwitterData) buildFlat(b *flatBuilder) int {
+	var fields []flatField
+	if t.Statuses != nil {
+		fields = append(fields, flatField{slot: 0, ref: func(b *flatBuilder) int {
+			return b.vector(len(t.Statuses), func(b *flatBuilder, i int) int { return t.Statuses[i].buildFlat(b) })
+		}})
+	}
+	return b.table(1, fields)
+}
+
+func (p *Player) buildFlat(b *flatBuilder) int {
+	fields := make([]flatField, 0, 4)
+	fields = flatString(fields, 0, p.Username)
+	fields = flatUint(fields, 1, uint64(p.Level), 8)
+	fields = flatUint(fields, 2, math.Float64bits(p.Health), 8)
+	if p.Inventory != nil {
+		fields = append(fields, flatField{slot: 3, ref: func(b *flatBuilder) int {
+			return b.vector(len(p.Inventory), func(b *flatBuilder, i int) int { return b.string(p.Inventory[i]) })
+		}})
+	}
+	return b.table(4, fields)
+}
+
+func (ps *Players) buildFlat(b *flatBuilder) int {
+	var fields []flatField
+	if *ps != nil {
+		fields = append(fields, flatField{slot: 0, ref: func(b *flatBuilder) int {
+			return b.vector(len(*ps), func(b *flatBuilder, i int) int { return (*ps)[i].buildFlat(b) })
+		}})
+	}
+	return b.table(1, fields)
+}
+
+// flatTable reads the fields of the table at pos in buf
+type flatTable struct {
+	buf []byte
+	pos int
+}
+
+func flatRoot(buf []byte) flatTable {
+	return flatTable{buf, int(binary.LittleEndian.Uint32(buf))}
+}
+
+func (t flatTable) u32(at int) int { return int(binary.LittleEndian.Uint32(t.buf[at:])) }
+
+// field returns the position of a field, or 0 when it is absent
+func (t flatTable) field(slot int) int {
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	entry := 4 + 2*slot
+	if entry >= int(binary.LittleEndian.Uint16(t.buf[vtable:])) {
+		return 0
+	}
+	if off := binary.LittleEndian.Uint16(t.buf[vtable+entry:]); off != 0 {
+		return t.pos + int(off)
+	}
+	return 0
+}
+
+func (t flatTable) uint64(slot int) uint64 {
+	if at := t.field(slot); at != 0 {
+		return binary.LittleEndian.Uint64(t.buf[at:])
+	}
+	return 0
+}
+
+func (t flatTable) bool(slot int) bool {
+	if at := t.field(slot); at != 0 {
+		return t.buf[at] != 0
+	}
+	return false
+}
+
+// deref follows the offset stored at a field, or returns 0
+func (t flatTable) deref(slot int) int {
+	if at := t.field(slot); at != 0 {
+		return at + t.u32(at)
+	}
+	return 0
+}
+
+// stringAt returns the bytes of the string at pos, without copying them
+func (t flatTable) stringAt(pos int) []byte {
+	n := t.u32(pos)
+	return t.buf[pos+4 : pos+4+n]
+}
+
+func (t flatTable) string(slot int) []byte {
+	if pos := t.deref(slot); pos != 0 {
+		return t.stringAt(pos)
+	}
+	return nil
+}
+
+// vector returns the position of a vector's first element and its length;
+// present is false when the field is absent
+func (t flatTable) vector(slot int) (first, n int, present bool) {
+	pos := t.deref(slot)
+	if pos == 0 {
+		return 0, 0, false
+	}
+	n = t.u32(pos)
+	if n > (len(t.buf)-pos-4)/4 {
+		panic(errFlatMalformed)
+	}
+	return pos + 4, n, true
+}
+
+// elem follows the i-th offset of a vector starting at first
+func (t flatTable) elem(first, i int) int {
+	at := first + 4*i
+	return at + t.u32(at)
+}
+
+// The typed accessors, named like flatc's
+
+type flatTwitterUser struct{ flatTable }
+
+func (u flatTwitterUser) ID() uint64             { return u.uint64(0) }
+func (u flatTwitterUser) Name() []byte           { return u.string(1) }
+func (u flatTwitterUser) ScreenName() []byte     { return u.string(2) }
+func (u flatTwitterUser) Location() []byte       { return u.string(3) }
+func (u flatTwitterUser) Description() []byte    { return u.string(4) }
+func (u flatTwitterUser) FollowersCount() uint64 { return u.uint64(5) }
+func (u flatTwitterUser) FriendsCount() uint64   { return u.uint64(6) }
+func (u flatTwitterUser) Verified() bool         { return u.bool(7) }
+func (u flatTwitterUser) StatusesCount() uint64  { return u.uint64(8) }
+
+func (u flatTwitterUser) unpack() TwitterUser {
+	return TwitterUser{
+		ID:             u.ID(),
+		Name:           string(u.Name()),
+		ScreenName:     string(u.ScreenName()),
+		Location:       string(u.Location()),
+		Description:    string(u.Description()),
+		FollowersCount: u.FollowersCount(),
+		FriendsCount:   u.FriendsCount(),
+		Verified:       u.Verified(),
+		StatusesCount:  u.StatusesCount(),
+	}
+}
+
+type flatStatus struct{ flatTable }
+
+// User returns the status's user; ok is false when it is absent
+func (s flatStatus) User() (user flatTwitterUser, ok bool) {
+	pos := s.deref(0)
+	return flatTwitterUser{flatTable{s.buf, pos}}, pos != 0
+}
+
+func (s flatStatus) unpack() Status {
+	var st Status
+	if u, ok := s.User(); ok {
+		st.User = u.unpack()
+	}
+	return st
+}
+
+type flatTwitterData struct{ flatTable }
+
+func (t flatTwitterData) StatusesLength() int {
+	_, n, _ := t.vector(0)
+	return n
+}
+
+func (t flatTwitterData) Statuses(i int) flatStatus {
+	first, _, _ := t.vector(0)
+	return flatStatus{flatTable{t.buf, t.elem(first, i)}}
+}
+
+func (t flatTwitterData) unpack() TwitterData {
+	first, n, present := t.vector(0)
+	if !present {
+		return TwitterData{}
+	}
+	statuses := make([]Status, n)
+	for i := range statuses {
+		statuses[i] = flatStatus{flatTable{t.buf, t.elem(first, i)}}.unpack()
+	}
+	return TwitterData{Statuses: statuses}
+}
+
+type flatPlayer struct{ flatTable }
+
+func (p flatPlayer) Username() []byte { return p.string(0) }
+func (p flatPlayer) Level() int64     { return int64(p.uint64(1)) }
+func (p flatPlayer) Health() float64  { return math.Float64frombits(p.uint64(2)) }
+
+func (p flatPlayer) unpack() Player {
+	player := Player{Username: string(p.Username()), Level: int(p.Level()), Health: p.Health()}
+	if first, n, present := p.vector(3); present {
+		player.Inventory = make([]string, n)
+		for i := range player.Inventory {
+			player.Inventory[i] = string(p.stringAt(p.elem(first, i)))
+		}
+	}
+	return player
+}
+
+type flatPlayers struct{ flatTable }
+
+func (ps flatPlayers) unpack() Players {
+	first, n, present := ps.vector(0)
+	if !present {
+		return nil
+	}
+	players := make(Players, n)
+	for i := range players {
+		players[i] = flatPlayer{flatTable{ps.buf, ps.elem(first, i)}}.unpack()
+	}
+	return players
+}
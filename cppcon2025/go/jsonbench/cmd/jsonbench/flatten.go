@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// Flattening turns a document into key/value pairs with one key per
+// leaf: "user.screen_name", "entities.hashtags[0].text". A '.', '[' or
+// '\' inside a member name is escaped with '\', so unflatten can rebuild
+// the document. Empty objects and arrays are leaves themselves.
+
+// flatPair is a leaf of a flattened document
+type flatPair struct {
+	key   string
+	value interface{}
+}
+
+// flatten appends the leaves of v to pairs, with object members in
+// sorted order
+func flatten(pairs []flatPair, v interface{}) []flatPair {
+	return appendFlat(pairs, make([]byte, 0, 64), v)
+}
+
+func appendFlat(pairs []flatPair, key []byte, v interface{}) []flatPair {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				child := key
+				if len(child) > 0 {
+					child = append(child, '.')
+				}
+				pairs = appendFlat(pairs, appendFlatKey(child, k), v[k])
+			}
+			return pairs
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, e := range v {
+				child := append(key, '[')
+				child = append(strconv.AppendInt(child, int64(i), 10), ']')
+				pairs = appendFlat(pairs, child, e)
+			}
+			return pairs
+		}
+	}
+	return append(pairs, flatPair{string(key), v})
+}
+
+func appendFlatKey(b []byte, k string) []byte {
+	for i := 0; i < len(k); i++ {
+		switch k[i] {
+		case '.', '[', '\\':
+			b = append(b, '\\')
+		}
+		b = append(b, k[i])
+	}
+	return b
+}
+
+// flatSegment is one step of a flattened key: an object member or, when
+// index is not negative, an array element
+type flatSegment struct {
+	name  string
+	index int
+}
+
+// parseFlatKey splits a flattened key into its segments. The empty key
+// is the whole document, so a top-level member named "" holding a leaf
+// cannot be told apart from it.
+func parseFlatKey(key string) ([]flatSegment, error) {
+	if key == "" {
+		return nil, nil
+	}
+	var segments []flatSegment
+	var name []byte
+	i := 0
+	readName := key[0] != '['
+	for {
+		if readName {
+			name = name[:0]
+			for i < len(key) && key[i] != '.' && key[i] != '[' {
+				if key[i] == '\\' {
+					if i+1 == len(key) {
+						return nil, fmt.Errorf("key %q ends with an escape", key)
+					}
+					i++
+				}
+				name = append(name, key[i])
+				i++
+			}
+			segments = append(segments, flatSegment{string(name), -1})
+		}
+		if i == len(key) {
+			return segments, nil
+		}
+		if key[i] == '.' {
+			i++
+			readName = true
+			continue
+		}
+		end := strings.IndexByte(key[i:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("key %q has an unterminated index", key)
+		}
+		digits := key[i+1 : i+end]
+		n, err := strconv.Atoi(digits)
+		if err != nil || n < 0 || digits != strconv.Itoa(n) {
+			return nil, fmt.Errorf("key %q has a bad index %q", key, digits)
+		}
+		segments = append(segments, flatSegment{index: n})
+		i += end + 1
+		if i < len(key) && key[i] != '.' && key[i] != '[' {
+			return nil, fmt.Errorf("key %q has data after an index", key)
+		}
+		readName = false
+	}
+}
+
+// flatNode is a document under construction by unflatten
+type flatNode struct {
+	set      bool
+	value    interface{}
+	members  map[string]*flatNode
+	elements []*flatNode
+}
+
+// unflatten rebuilds the document of pairs, in any order. Arrays must
+// end up without holes, and no key may be both a leaf and a prefix of
+// another.
+func unflatten(pairs []flatPair) (interface{}, error) {
+	root := &flatNode{}
+	for _, p := range pairs {
+		segments, err := parseFlatKey(p.key)
+		if err != nil {
+			return nil, err
+		}
+		n := root
+		for _, s := range segments {
+			if n.set {
+				return nil, fmt.Errorf("%s: a prefix is a leaf", p.key)
+			}
+			if s.index < 0 {
+				if n.elements != nil {
+					return nil, fmt.Errorf("%s: member %q of an array", p.key, s.name)
+				}
+				if n.members == nil {
+					n.members = map[string]*flatNode{}
+				}
+				c := n.members[s.name]
+				if c == nil {
+					c = &flatNode{}
+					n.members[s.name] = c
+				}
+				n = c
+				continue
+			}
+			if n.members != nil {
+				return nil, fmt.Errorf("%s: index %d of an object", p.key, s.index)
+			}
+			for len(n.elements) <= s.index {
+				n.elements = append(n.elements, nil)
+			}
+			if n.elements[s.index] == nil {
+				n.elements[s.index] = &flatNode{}
+			}
+			n = n.elements[s.index]
+		}
+		if n.set || n.members != nil || n.elements != nil {
+			return nil, fmt.Errorf("%s: set twice or also a prefix", p.key)
+		}
+		n.set, n.value = true, p.value
+	}
+	return root.build("")
+}
+
+func (n *flatNode) build(key string) (interface{}, error) {
+	switch {
+	case n.set:
+		return n.value, nil
+	case n.members != nil:
+		m := make(map[string]interface{}, len(n.members))
+		for k, c := range n.members {
+			v, err := c.build(k)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	case n.elements != nil:
+		arr := make([]interface{}, len(n.elements))
+		for i, c := range n.elements {
+			if c == nil {
+				return nil, fmt.Errorf("%s: element %d is missing", key, i)
+			}
+			v, err := c.build(key)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+		}
+		return arr, nil
+	}
+	return nil, errors.New("no pairs")
+}
+
+// csvTable flattens each record into a row. The columns are the union
+// of the keys, sorted; strings are written as they are, other leaves as
+// JSON, and a key a record lacks gives an empty cell.
+func csvTable(recs []interface{}) (header []string, rows [][]string, err error) {
+	flat := make([][]flatPair, len(recs))
+	columns := map[string]int{}
+	for i, r := range recs {
+		flat[i] = flatten(nil, r)
+		for _, p := range flat[i] {
+			columns[p.key] = 0
+		}
+	}
+	header = make([]string, 0, len(columns))
+	for k := range columns {
+		header = append(header, k)
+	}
+	sort.Strings(header)
+	for i, k := range header {
+		columns[k] = i
+	}
+	rows = make([][]string, len(recs))
+	for i, pairs := range flat {
+		row := make([]string, len(header))
+		for _, p := range pairs {
+			if s, ok := p.value.(string); ok {
+				row[columns[p.key]] = s
+				continue
+			}
+			b, err := json.Marshal(p.value)
+			if err != nil {
+				return nil, nil, err
+			}
+			row[columns[p.key]] = string(b)
+		}
+		rows[i] = row
+	}
+	return header, rows, nil
+}
+
+// flattenFlags are the flags of flatten
+type flattenFlags struct {
+	file       *string
+	size       *string
+	iterations *int
+	csvFile    *string
+	printPairs *bool
+}
+
+func (opts *flattenFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("flatten")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to flatten")
+	opts.size = fs.String("size", "", "replicate the records of -file into a document of this size, e.g. 64MB")
+	opts.iterations = fs.Int("n", 20, "number of iterations")
+	opts.csvFile = fs.String("csv", "", "write the records as CSV to this file (- for stdout) and exit")
+	opts.printPairs = fs.Bool("print", false, "print the flattened pairs and exit")
+	return fs
+}
+
+func runFlatten(args []string) error {
+	var opts flattenFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	raw, err := datasets.Records(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if *opts.size != "" {
+		n, err := datasets.ParseSize(*opts.size)
+		if err != nil {
+			return fmt.Errorf("-size: %w", err)
+		}
+		data = datasets.ScaledDocument(raw, n)
+		if raw, err = datasets.Records(data); err != nil {
+			return err
+		}
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	pairs := flatten(nil, doc)
+
+	if *opts.printPairs {
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+		for _, p := range pairs {
+			b, err := json.Marshal(p.value)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s=%s\n", p.key, b)
+		}
+		return nil
+	}
+	recs := make([]interface{}, len(raw))
+	for i, r := range raw {
+		if err := json.Unmarshal(r, &recs[i]); err != nil {
+			return err
+		}
+	}
+	if *opts.csvFile != "" {
+		header, rows, err := csvTable(recs)
+		if err != nil {
+			return err
+		}
+		out := os.Stdout
+		if *opts.csvFile != "-" {
+			if out, err = os.Create(*opts.csvFile); err != nil {
+				return err
+			}
+			defer out.Close()
+		}
+		w := csv.NewWriter(out)
+		w.Write(header)
+		w.WriteAll(rows)
+		if err := w.Error(); err != nil {
+			return err
+		}
+		if *opts.csvFile != "-" {
+			fmt.Printf("%s: %d rows of %d columns\n", *opts.csvFile, len(rows), len(header))
+		}
+		return nil
+	}
+
+	back, err := unflatten(pairs)
+	if err != nil {
+		return fmt.Errorf("unflatten: %w", err)
+	}
+	if !reflect.DeepEqual(back, doc) {
+		return withKind(errMismatch, errors.New("flatten: unflattening does not give the document back"))
+	}
+	header, _, err := csvTable(recs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d bytes, %d leaves, %d records flatten to %d CSV columns\n\n",
+		*opts.file, len(data), len(pairs), len(recs), len(header))
+
+	steps := []struct {
+		name string
+		fn   func([]byte) error
+	}{
+		{"decode (encoding/json)", func(b []byte) error {
+			var v interface{}
+			return json.Unmarshal(b, &v)
+		}},
+		{"flatten", func([]byte) error {
+			pairs = flatten(pairs[:0], doc)
+			return nil
+		}},
+		{"unflatten", func([]byte) error {
+			_, err := unflatten(pairs)
+			return err
+		}},
+		{"records to CSV rows", func([]byte) error {
+			_, _, err := csvTable(recs)
+			return err
+		}},
+	}
+	fmt.Println("| Step | MB/s | µs |")
+	fmt.Println("|---|---:|---:|")
+	for _, s := range steps {
+		speed, err := bench.Measure(data, *opts.iterations, s.fn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		fmt.Printf("| %s | %.2f | %.1f |\n", s.name, speed, float64(len(data))/speed)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+)
+
+// trickyFloats are number literals that naive parsers get wrong: values
+// near the subnormal boundary, halfway cases that need the full input to
+// round correctly, 17-digit round-trip values and huge exponents.
+var trickyFloats = []string{
+	"0.1",
+	"0.3",
+	"0.30000000000000004",
+	"1e23",
+	"8.98846567431158e307",
+	"1.7976931348623157e308",
+	"1.7976931348623158e308",
+	"2.2250738585072011e-308",
+	"2.2250738585072012e-308",
+	"2.2250738585072014e-308",
+	"4.9406564584124654e-324",
+	"5e-324",
+	"2.4703282292062327e-324",
+	"2.4703282292062328e-324",
+	"7.038531e-26",
+	"2.9802322387695312e-08",
+	"9007199254740993",
+	"9007199254740995",
+	"18446744073709551616",
+	"123456789012345678901234567890",
+	"1.00000000000000011102230246251565404236316680908203125",
+	"1.00000000000000011102230246251565404236316680908203126",
+	"0.500000000000000166533453693773481063544750213623046875",
+	"100000000000000000000000000000000000000000e-60",
+	"0.000000000000000000000000000000000000000000001e300",
+	"-0.0",
+	"-0e10",
+	"1e-400",
+	"0e1000000",
+	"1e-1000000",
+	"1e400",
+	"-1e400",
+}
+
+// checkFloat parses literal with b and compares the bits of the result
+// with the correctly rounded value. Literals out of the float64 range may
+// be rejected or decoded as an infinity.
+func checkFloat(b backends.Backend, literal string) error {
+	want, err := strconv.ParseFloat(literal, 64)
+	outOfRange := errors.Is(err, strconv.ErrRange)
+	v, err := b.Decode([]byte("[" + literal + "]"))
+	if err != nil {
+		if outOfRange {
+			return nil
+		}
+		return err
+	}
+	arr, ok := v.([]interface{})
+	if !ok || len(arr) != 1 {
+		return fmt.Errorf("unexpected result %v", v)
+	}
+	got, ok := arr[0].(float64)
+	if !ok {
+		return fmt.Errorf("decoded as %T", arr[0])
+	}
+	if math.Float64bits(got) != math.Float64bits(want) {
+		return fmt.Errorf("got %v (%#x), want %v (%#x)",
+			got, math.Float64bits(got), want, math.Float64bits(want))
+	}
+	return nil
+}
+
+// floatsFlags are the flags of floats, which has none
+type floatsFlags struct{}
+
+func (*floatsFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("floats")
+	return fs
+}
+
+// Check that every backend rounds tricky doubles exactly like
+// strconv.ParseFloat, which is correctly rounded
+func runFloats(args []string) error {
+	var opts floatsFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	failed := false
+	for _, b := range backends.All() {
+		bad := 0
+		for _, literal := range trickyFloats {
+			if err := checkFloat(b, literal); err != nil {
+				fmt.Printf("%-16s %s: %v\n", b.Name(), literal, err)
+				bad++
+			}
+		}
+		fmt.Printf("%-16s %d/%d correctly rounded\n", b.Name(), len(trickyFloats)-bad, len(trickyFloats))
+		if bad > 0 {
+			failed = true
+		}
+	}
+	if failed {
+		return withKind(errMismatch, errors.New("some backends do not round correctly"))
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// codec serializes the typed data model in one format. Marshal and
+// Unmarshal take a *TwitterData or a *Players, so binary formats can be
+// implemented the way code-generating libraries do, without reflection.
+type codec interface {
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// codecs starts with the JSON baseline; the binary formats register
+// themselves after it
+var codecs = []codec{jsonCodec{}}
+
+func registerCodec(c codec) { codecs = append(codecs, c) }
+
+// jsonCodec is the baseline: encoding/json with the struct tags
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                               { return "json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// formatDataset is a typed document and a constructor for values to
+// decode it into
+type formatDataset struct {
+	name  string
+	value interface{}
+	fresh func() interface{}
+}
+
+// formatsFlags are the flags of formats
+type formatsFlags struct {
+	file       *string
+	players    *int
+	seed       *int64
+	iterations *int
+	only       *string
+}
+
+func (opts *formatsFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("formats")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to convert")
+	opts.players = fs.Int("players", 1000, "number of Player records in the player document")
+	opts.seed = fs.Int64("seed", 1, "seed of the Player records")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	opts.only = fs.String("format", "", "comma-separated formats to run (default all)")
+	return fs
+}
+
+func runFormats(args []string) error {
+	var opts formatsFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var twitter TwitterData
+	if err := json.Unmarshal(data, &twitter); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	ps := samplePlayers(*opts.players, *opts.seed)
+	inputs := []formatDataset{
+		{"twitter", &twitter, func() interface{} { return new(TwitterData) }},
+		{"players", &ps, func() interface{} { return new(Players) }},
+	}
+
+	for _, d := range inputs {
+		fmt.Printf("%s:\n", d.name)
+		fmt.Printf("  %-12s %10s %8s %12s %12s %12s\n", "format", "bytes", "vs json", "encode MB/s", "decode MB/s", "decode µs")
+		baseline, err := json.Marshal(d.value)
+		if err != nil {
+			return err
+		}
+		for _, c := range codecs {
+			if *opts.only != "" && !containsFormat(*opts.only, c.Name()) {
+				continue
+			}
+			encoded, err := c.Marshal(d.value)
+			if err != nil {
+				return fmt.Errorf("%s: encoding %s: %w", c.Name(), d.name, err)
+			}
+			// Every format must give back the value it was given
+			back := d.fresh()
+			if err := c.Unmarshal(encoded, back); err != nil {
+				return fmt.Errorf("%s: decoding %s: %w", c.Name(), d.name, err)
+			}
+			if !reflect.DeepEqual(back, d.value) {
+				return fmt.Errorf("%s: %s does not round-trip", c.Name(), d.name)
+			}
+
+			encode, err := bench.Measure(encoded, *opts.iterations, func([]byte) error {
+				_, err := c.Marshal(d.value)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			decode, err := bench.Measure(encoded, *opts.iterations, func(b []byte) error {
+				return c.Unmarshal(b, d.fresh())
+			})
+			if err != nil {
+				return err
+			}
+			ratio := float64(len(encoded)) / float64(len(baseline))
+			fmt.Printf("  %-12s %10d %7.2fx %12.2f %12.2f %12.1f\n", c.Name(), len(encoded), ratio, encode, decode, float64(len(encoded))/decode)
+		}
+	}
+	return nil
+}
+
+// containsFormat reports whether name is in the comma-separated list
+func containsFormat(list, name string) bool {
+	for _, f := range strings.Split(list, ",") {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
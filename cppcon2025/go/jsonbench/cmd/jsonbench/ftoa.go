@@ -0,0 +1,423 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// Ryu (Ulf Adams, PLDI 2018) finds the shortest decimal that rounds back
+// to a double with a few 64×128-bit multiplications by precomputed
+// powers of 5, where the classic algorithms need bignums. This is the
+// d2d step of the reference implementation with its full tables, built
+// with math/big on first use.
+
+const (
+	ryuMantissaBits   = 52
+	ryuExponentBits   = 11
+	ryuBias           = 1023
+	ryuPow5InvBits    = 125
+	ryuPow5Bits       = 125
+	ryuPow5InvEntries = 342
+	ryuPow5Entries    = 326
+)
+
+var (
+	ryuTablesOnce sync.Once
+	// ryuPow5Inv[q] is 2^(pow5bits(q)-1+125)/5^q + 1 and ryuPow5[i] the
+	// top 125 bits of 5^i, as {low, high} 64-bit halves
+	ryuPow5Inv [ryuPow5InvEntries][2]uint64
+	ryuPow5    [ryuPow5Entries][2]uint64
+)
+
+func ryuSplit(x *big.Int) [2]uint64 {
+	var lo big.Int
+	lo.And(x, new(big.Int).SetUint64(math.MaxUint64))
+	return [2]uint64{lo.Uint64(), new(big.Int).Rsh(x, 64).Uint64()}
+}
+
+func ryuTables() {
+	five := big.NewInt(5)
+	pow := big.NewInt(1)
+	for i := 0; i < ryuPow5InvEntries; i++ {
+		if i > 0 {
+			pow.Mul(pow, five)
+		}
+		n := pow.BitLen()
+		if i < ryuPow5Entries {
+			s := new(big.Int)
+			if shift := n - ryuPow5Bits; shift > 0 {
+				s.Rsh(pow, uint(shift))
+			} else {
+				s.Lsh(pow, uint(-shift))
+			}
+			ryuPow5[i] = ryuSplit(s)
+		}
+		inv := new(big.Int).Lsh(big.NewInt(1), uint(n-1+ryuPow5InvBits))
+		inv.Div(inv, pow)
+		ryuPow5Inv[i] = ryuSplit(inv.Add(inv, big.NewInt(1)))
+	}
+}
+
+// pow5bits is the bit length of 5^e, for e in [0, 3528]
+func pow5bits(e int32) int32 { return int32((uint32(e)*1217359)>>19) + 1 }
+
+// log10Pow2 is floor(log10(2^e)) and log10Pow5 floor(log10(5^e))
+func log10Pow2(e int32) int32 { return int32((uint32(e) * 78913) >> 18) }
+func log10Pow5(e int32) int32 { return int32((uint32(e) * 732923) >> 20) }
+
+func multipleOfPowerOf5(v uint64, p int32) bool {
+	n := int32(0)
+	for v%5 == 0 {
+		v /= 5
+		n++
+	}
+	return n >= p
+}
+
+func multipleOfPowerOf2(v uint64, p int32) bool { return v&(1<<uint(p)-1) == 0 }
+
+// mulShift64 is (m × mul) >> j for the 128-bit mul, with 64 < j < 128
+func mulShift64(m uint64, mul [2]uint64, j int32) uint64 {
+	hi0, _ := bits.Mul64(m, mul[0])
+	hi2, lo2 := bits.Mul64(m, mul[1])
+	lo, carry := bits.Add64(lo2, hi0, 0)
+	hi := hi2 + carry
+	s := uint(j - 64)
+	return lo>>s | hi<<(64-s)
+}
+
+// ryuDecimal returns the shortest m and e with m × 10^e rounding to the
+// finite, non-zero f (its sign aside); ties go to the even digit
+func ryuDecimal(f float64) (uint64, int32) {
+	ryuTablesOnce.Do(ryuTables)
+	u := math.Float64bits(f)
+	ieeeMantissa := u & (1<<ryuMantissaBits - 1)
+	ieeeExponent := int32(u>>ryuMantissaBits) & (1<<ryuExponentBits - 1)
+
+	var e2 int32
+	var m2 uint64
+	if ieeeExponent == 0 {
+		e2 = 1 - ryuBias - ryuMantissaBits - 2
+		m2 = ieeeMantissa
+	} else {
+		e2 = ieeeExponent - ryuBias - ryuMantissaBits - 2
+		m2 = 1<<ryuMantissaBits | ieeeMantissa
+	}
+	acceptBounds := m2&1 == 0
+
+	// The value, and the halfway points to its neighbours, all times 4
+	mv := 4 * m2
+	mmShift := uint64(0)
+	if ieeeMantissa != 0 || ieeeExponent <= 1 {
+		mmShift = 1
+	}
+	var vr, vp, vm uint64
+	var e10 int32
+	vmIsTrailingZeros, vrIsTrailingZeros := false, false
+	if e2 >= 0 {
+		q := log10Pow2(e2)
+		if e2 > 3 {
+			q--
+		}
+		e10 = q
+		k := ryuPow5InvBits + pow5bits(q) - 1
+		i := -e2 + q + k
+		vr = mulShift64(4*m2, ryuPow5Inv[q], i)
+		vp = mulShift64(4*m2+2, ryuPow5Inv[q], i)
+		vm = mulShift64(4*m2-1-mmShift, ryuPow5Inv[q], i)
+		if q <= 21 {
+			switch {
+			case mv%5 == 0:
+				vrIsTrailingZeros = multipleOfPowerOf5(mv, q)
+			case acceptBounds:
+				vmIsTrailingZeros = multipleOfPowerOf5(mv-1-mmShift, q)
+			case multipleOfPowerOf5(mv+2, q):
+				vp--
+			}
+		}
+	} else {
+		q := log10Pow5(-e2)
+		if -e2 > 1 {
+			q--
+		}
+		e10 = q + e2
+		i := -e2 - q
+		k := pow5bits(i) - ryuPow5Bits
+		j := q - k
+		vr = mulShift64(4*m2, ryuPow5[i], j)
+		vp = mulShift64(4*m2+2, ryuPow5[i], j)
+		vm = mulShift64(4*m2-1-mmShift, ryuPow5[i], j)
+		if q <= 1 {
+			// mv has at least q trailing zero bits, so vr is exact
+			vrIsTrailingZeros = true
+			if acceptBounds {
+				vmIsTrailingZeros = mmShift == 1
+			} else {
+				vp--
+			}
+		} else if q < 63 {
+			vrIsTrailingZeros = multipleOfPowerOf2(mv, q)
+		}
+	}
+
+	// Drop digits while the interval still holds a shorter decimal
+	removed := int32(0)
+	lastRemovedDigit := uint64(0)
+	var output uint64
+	if vmIsTrailingZeros || vrIsTrailingZeros {
+		for vp/10 > vm/10 {
+			vmIsTrailingZeros = vmIsTrailingZeros && vm%10 == 0
+			vrIsTrailingZeros = vrIsTrailingZeros && lastRemovedDigit == 0
+			lastRemovedDigit = vr % 10
+			vr, vp, vm = vr/10, vp/10, vm/10
+			removed++
+		}
+		if vmIsTrailingZeros {
+			for vm%10 == 0 {
+				vrIsTrailingZeros = vrIsTrailingZeros && lastRemovedDigit == 0
+				lastRemovedDigit = vr % 10
+				vr, vp, vm = vr/10, vp/10, vm/10
+				removed++
+			}
+		}
+		if vrIsTrailingZeros && lastRemovedDigit == 5 && vr%2 == 0 {
+			// Exactly halfway: round to even
+			lastRemovedDigit = 4
+		}
+		output = vr
+		if vr == vm && (!acceptBounds || !vmIsTrailingZeros) || lastRemovedDigit >= 5 {
+			output++
+		}
+	} else {
+		// The common case, without the bookkeeping for exact values
+		roundUp := false
+		if vp/100 > vm/100 {
+			roundUp = vr%100 >= 50
+			vr, vp, vm = vr/100, vp/100, vm/100
+			removed += 2
+		}
+		for vp/10 > vm/10 {
+			roundUp = vr%10 >= 5
+			vr, vp, vm = vr/10, vp/10, vm/10
+			removed++
+		}
+		output = vr
+		if vr == vm || roundUp {
+			output++
+		}
+	}
+	return output, e10 + removed
+}
+
+// appendRyu formats f the way encoding/json does, from Ryu's digits:
+// plain notation from 1e-6 up to 1e21 and the shortest exponent outside
+func appendRyu(buf []byte, f float64) []byte {
+	if f == 0 {
+		if math.Signbit(f) {
+			return append(buf, "-0"...)
+		}
+		return append(buf, '0')
+	}
+	if f < 0 {
+		buf = append(buf, '-')
+		f = -f
+	}
+	m, e := ryuDecimal(f)
+	var digits [20]byte
+	i := len(digits)
+	for m >= 10 {
+		i--
+		digits[i] = byte('0' + m%10)
+		m /= 10
+	}
+	i--
+	digits[i] = byte('0' + m)
+	d := digits[i:]
+	// The value is 0.d × 10^n
+	n := int32(len(d)) + e
+	switch {
+	case f < 1e-6 || f >= 1e21:
+		buf = append(buf, d[0])
+		if len(d) > 1 {
+			buf = append(buf, '.')
+			buf = append(buf, d[1:]...)
+		}
+		x := n - 1
+		if x < 0 {
+			buf = append(buf, 'e', '-')
+			x = -x
+		} else {
+			buf = append(buf, 'e', '+')
+			if x < 10 {
+				buf = append(buf, '0')
+			}
+		}
+		return strconv.AppendInt(buf, int64(x), 10)
+	case e >= 0:
+		buf = append(buf, d...)
+		for ; e > 0; e-- {
+			buf = append(buf, '0')
+		}
+	case n > 0:
+		buf = append(buf, d[:n]...)
+		buf = append(buf, '.')
+		buf = append(buf, d[n:]...)
+	default:
+		buf = append(buf, '0', '.')
+		for ; n < 0; n++ {
+			buf = append(buf, '0')
+		}
+		buf = append(buf, d...)
+	}
+	return buf
+}
+
+// appendJSONFloat is encoding/json's float64 formatting with strconv
+func appendJSONFloat(buf []byte, f float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, 64)
+	if format == 'e' {
+		// e-09 becomes e-9
+		if n := len(buf); n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf
+}
+
+// collectNumbers appends every number of a decoded document to nums
+func collectNumbers(nums []float64, v interface{}) []float64 {
+	switch v := v.(type) {
+	case float64:
+		nums = append(nums, v)
+	case []interface{}:
+		for _, e := range v {
+			nums = collectNumbers(nums, e)
+		}
+	case map[string]interface{}:
+		for _, e := range v {
+			nums = collectNumbers(nums, e)
+		}
+	}
+	return nums
+}
+
+// canadaLikeNumbers stands in for canada.json when it is missing:
+// longitude and latitude pairs over Canada, half of them rounded to the
+// micro-degrees of GPS data and half with all 17 significant digits
+func canadaLikeNumbers(n int, seed int64) []float64 {
+	r := datasets.NewRand(seed)
+	nums := make([]float64, n)
+	for i := range nums {
+		v := datasets.Uniform(r, -141, -52)
+		if i%2 == 1 {
+			v = datasets.Uniform(r, 41.7, 83.1)
+		}
+		if i%4 < 2 {
+			v = math.Round(v*1e6) / 1e6
+		}
+		nums[i] = v
+	}
+	return nums
+}
+
+// ftoaFlags are the flags of ftoa
+type ftoaFlags struct {
+	file       *string
+	iterations *int
+	seed       *int64
+}
+
+func (opts *ftoaFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("ftoa")
+	opts.file = fs.String("file", "../canada.json", "document whose numbers are formatted (canada-like coordinates if it is missing)")
+	opts.iterations = fs.Int("n", 20, "number of iterations")
+	opts.seed = fs.Int64("seed", 1, "seed of the canada-like coordinates")
+	return fs
+}
+
+func runFtoa(args []string) error {
+	var opts ftoaFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	var nums []float64
+	source := *opts.file
+	data, err := datasets.Read(*opts.file)
+	switch {
+	case err == nil:
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("%s: %w", *opts.file, err)
+		}
+		nums = collectNumbers(nil, doc)
+	case errors.Is(err, os.ErrNotExist):
+		// canada.json holds 111,126 coordinates
+		nums = canadaLikeNumbers(111126, *opts.seed)
+		source = "canada-like coordinates"
+	default:
+		return err
+	}
+	if len(nums) == 0 {
+		return fmt.Errorf("%s: no numbers", *opts.file)
+	}
+
+	// Ryu must print exactly what encoding/json prints
+	var a, b []byte
+	for _, f := range nums {
+		a = appendRyu(a[:0], f)
+		b = appendJSONFloat(b[:0], f)
+		if string(a) != string(b) {
+			return fmt.Errorf("ryu formats %v as %s, strconv as %s", f, a, b)
+		}
+	}
+	want, err := json.Marshal(nums[0])
+	if err != nil {
+		return err
+	}
+	if string(appendRyu(nil, nums[0])) != string(want) {
+		return fmt.Errorf("ryu formats %v as %s, encoding/json as %s", nums[0], appendRyu(nil, nums[0]), want)
+	}
+
+	methods := []struct {
+		name   string
+		append func([]byte, float64) []byte
+	}{
+		{"strconv 'g'", func(b []byte, f float64) []byte { return strconv.AppendFloat(b, f, 'g', -1, 64) }},
+		{"strconv, JSON style", appendJSONFloat},
+		{"ryu, JSON style", appendRyu},
+	}
+	fmt.Printf("%s: %d numbers\n\n", source, len(nums))
+	fmt.Println("| Method | ns/number | MB/s out |")
+	fmt.Println("|---|---:|---:|")
+	buf := make([]byte, 0, 32*len(nums))
+	for _, m := range methods {
+		start := time.Now()
+		for i := 0; i < *opts.iterations; i++ {
+			buf = buf[:0]
+			for _, f := range nums {
+				buf = m.append(buf, f)
+				buf = append(buf, ',')
+			}
+		}
+		elapsed := time.Since(start).Seconds()
+		count := float64(len(nums) * *opts.iterations)
+		fmt.Printf("| %s | %.1f | %.2f |\n", m.name, elapsed*1e9/count, float64(len(buf)*(*opts.iterations))/1e6/elapsed)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// generators build the datasets of generate other than scaled copies,
+// from a number of records and a seed
+var generators = map[string]func(records int, seed int64) ([]byte, error){
+	"attachments": datasets.Attachments,
+	"escapes": func(records int, seed int64) ([]byte, error) {
+		return datasets.EscapeHeavy(records, seed), nil
+	},
+	"players": func(records int, seed int64) ([]byte, error) {
+		return json.Marshal(samplePlayers(records, seed))
+	},
+	"uuid": uuidEvents,
+}
+
+// generateFlags are the flags of generate
+type generateFlags struct {
+	kind    *string
+	file    *string
+	size    *string
+	gap     *int
+	records *int
+	seed    *int64
+	out     *string
+}
+
+func (opts *generateFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("generate")
+	opts.kind = fs.String("kind", "scaled", "dataset to write: scaled, giantstring, attachments, escapes, players or uuid")
+	opts.file = fs.String("file", "../twitter.json", "document whose records -kind scaled replicates")
+	opts.size = fs.String("size", "64MB", "size of -kind scaled and giantstring, e.g. 256MB")
+	opts.gap = fs.Int("gap", 4096, "average bytes between the escapes of -kind giantstring")
+	opts.records = fs.Int("records", 1000, "number of records of the other kinds")
+	opts.seed = fs.Int64("seed", 1, "seed of the other kinds, which are byte-identical for a seed on every platform")
+	opts.out = fs.String("o", "-", "file to write, - for standard output")
+	return fs
+}
+
+// Write one of the datasets the experiments generate, or a copy of a
+// document scaled to a size, so that other programs and the benchmarks of
+// other languages can read the same bytes
+func runGenerate(args []string) error {
+	var opts generateFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	var data []byte
+	switch *opts.kind {
+	case "giantstring":
+		n, err := datasets.ParseSize(*opts.size)
+		if err != nil {
+			return fmt.Errorf("-size: %w", err)
+		}
+		data = datasets.GiantString(n, *opts.gap, *opts.seed)
+	case "scaled":
+		n, err := datasets.ParseSize(*opts.size)
+		if err != nil {
+			return fmt.Errorf("-size: %w", err)
+		}
+		doc, err := datasets.Read(*opts.file)
+		if err != nil {
+			return err
+		}
+		recs, err := datasets.Records(doc)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *opts.file, err)
+		}
+		data = datasets.ScaledDocument(recs, n)
+	default:
+		generate, ok := generators[*opts.kind]
+		if !ok {
+			return fmt.Errorf("unknown dataset kind %q", *opts.kind)
+		}
+		var err error
+		if data, err = generate(*opts.records, *opts.seed); err != nil {
+			return err
+		}
+	}
+	if *opts.out == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*opts.out, data, 0o644); err != nil {
+		return err
+	}
+	// The checksum tells whether another machine generated the same bytes
+	fmt.Fprintf(os.Stderr, "%s: %s dataset, %d bytes, sha256 %s\n", *opts.out, *opts.kind, len(data), bench.NewDataset(*opts.out, data).SHA256)
+	return nil
+}
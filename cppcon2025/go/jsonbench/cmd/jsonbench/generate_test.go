@@ -0,0 +1,39 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// TestGenerateGolden pins the bytes of every generated dataset for a seed:
+// a change to a generator, or a platform that generates other bytes, shows
+// up as a different checksum.
+func TestGenerateGolden(t *testing.T) {
+	want := map[string]string{
+		"attachments": "ae186fd1652f1ec5712e81e1d66f6df0766ccfeaf7a5772c5ce7a3c7e07d4660",
+		"escapes":     "596c9a4e0728e270a4099f4525992caaec1b628812da4c6a55e28fe41c6808cb",
+		"players":     "97ae9098ada4967f76c36b39fd7f7fe7088f04e049b6efe09afb0c3292c4f3bf",
+		"uuid":        "6479c98e5b5b317b552ec6199b3d6769dff7f310c4e7f0ed8aca2599ba5d7e64",
+		"giantstring": "5f02f4c86598e579e5044b1525101548c2ae1cfefe03c3c5fe37458df3e36146",
+	}
+	got := map[string][]byte{"giantstring": datasets.GiantString(64<<10, 64, 42)}
+	for kind, generate := range generators {
+		data, err := generate(100, 42)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		got[kind] = data
+	}
+	for kind, data := range got {
+		sum := sha256.Sum256(data)
+		if h := hex.EncodeToString(sum[:]); h != want[kind] {
+			t.Errorf("%s: sha256 %s, want %s", kind, h, want[kind])
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("%d generators, %d checksums", len(got), len(want))
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/dom"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/ondemand"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+)
+
+// giantStringFlags are the flags of giantstring
+type giantStringFlags struct {
+	size       *string
+	gap        *int
+	seed       *int64
+	iterations *int
+}
+
+func (opts *giantStringFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("giantstring")
+	opts.size = fs.String("size", "256MB", "size of the string")
+	opts.gap = fs.Int("gap", 4096, "average bytes between escapes")
+	opts.seed = fs.Int64("seed", 1, "seed of the string")
+	opts.iterations = fs.Int("n", 3, "number of iterations")
+	return fs
+}
+
+// Decode a document that is a single string of hundreds of MB, where the
+// parse is nothing but copying and unescaping: with every backend, the
+// DOM and On-Demand, reporting the throughput and the memory allocated
+// per decode relative to the input
+func runGiantString(args []string) error {
+	var opts giantStringFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	n, err := datasets.ParseSize(*opts.size)
+	if err != nil || n < 2 || int64(n) >= 1<<32 {
+		return withKind(errUsage, fmt.Errorf("-size %q: want a size below 4GB", *opts.size))
+	}
+	if *opts.gap < 1 {
+		return withKind(errUsage, fmt.Errorf("-gap must be positive"))
+	}
+	data := datasets.GiantString(n, *opts.gap, *opts.seed)
+	var want string
+	if err := json.Unmarshal(data, &want); err != nil {
+		return withKind(errDataset, err)
+	}
+
+	var domParser dom.Parser
+	var odParser ondemand.Parser
+	type method struct {
+		name   string
+		decode func([]byte) (string, error)
+	}
+	var methods []method
+	for _, b := range backends.All() {
+		b := b
+		methods = append(methods, method{b.Name(), func(data []byte) (string, error) {
+			v, err := b.Decode(data)
+			s, _ := v.(string)
+			return s, err
+		}})
+	}
+	methods = append(methods,
+		method{"dom", func(data []byte) (string, error) {
+			root, err := domParser.Parse(data)
+			if err != nil {
+				return "", err
+			}
+			return root.GetString()
+		}},
+		method{"On-Demand", func(data []byte) (string, error) {
+			doc, err := odParser.Iterate(data)
+			if err != nil {
+				return "", err
+			}
+			return doc.GetString()
+		}},
+		method{"stage 1 alone, for scale", func(data []byte) (string, error) {
+			_, err := scanner.Index(data, nil)
+			return want, err
+		}})
+
+	fmt.Printf("one string of %d bytes, an escape every %d bytes on average, %d bytes unescaped\n\n", len(data), *opts.gap, len(want))
+	fmt.Println("| Method | MB/s | allocated per decode | × input |")
+	fmt.Println("|---|---:|---:|---:|")
+	for _, m := range methods {
+		got, err := m.decode(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		if got != want {
+			return withKind(errMismatch, fmt.Errorf("%s: the string differs from that of encoding/json", m.name))
+		}
+		got = ""
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for i := 0; i < *opts.iterations; i++ {
+			if _, err := m.decode(data); err != nil {
+				return fmt.Errorf("%s: %w", m.name, err)
+			}
+		}
+		elapsed := time.Since(start).Seconds()
+		runtime.ReadMemStats(&after)
+		allocated := float64(after.TotalAlloc-before.TotalAlloc) / float64(*opts.iterations)
+		fmt.Printf("| %s | %.1f | %.1f MB | %.2f |\n", m.name, float64(len(data))*float64(*opts.iterations)/1e6/elapsed,
+			allocated/1e6, allocated/float64(len(data)))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+)
+
+// trend is the history of one backend on one dataset on one machine
+type trend struct {
+	machine, dataset, backend string
+	speeds                    []float64
+	commits                   []string
+}
+
+// historyFlags are the flags of history
+type historyFlags struct {
+	file       *string
+	regression *float64
+}
+
+func (opts *historyFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("history")
+	opts.file = fs.String("file", "results.jsonl", "history file written by bench")
+	opts.regression = fs.Float64("regression", 0, "fail when the latest run of a series is this many percent slower than its best earlier run (0 to disable)")
+	return fs
+}
+
+// Summarize how throughput evolved across the runs in the history file
+func runHistory(args []string) error {
+	var opts historyFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	runs, err := bench.ReadHistory(*opts.file)
+	if err != nil {
+		return err
+	}
+	trends := map[[3]string]*trend{}
+	var keys [][3]string
+	for _, run := range runs {
+		var machine string
+		if run.Environment != nil {
+			machine = run.Environment.Fingerprint
+		}
+		for _, r := range run.Results {
+			key := [3]string{machine, r.Dataset, r.Backend}
+			t, ok := trends[key]
+			if !ok {
+				t = &trend{machine: machine, dataset: r.Dataset, backend: r.Backend}
+				trends[key] = t
+				keys = append(keys, key)
+			}
+			t.speeds = append(t.speeds, r.MBPerSec)
+			t.commits = append(t.commits, run.Commit)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		for k := 0; k < 3; k++ {
+			if keys[i][k] != keys[j][k] {
+				return keys[i][k] < keys[j][k]
+			}
+		}
+		return false
+	})
+
+	fmt.Printf("%d runs in %s (MB/s)\n", len(runs), *opts.file)
+	fmt.Printf("%-14s %-16s %-20s %5s %9s %9s %9s %9s %8s\n",
+		"machine", "dataset", "backend", "runs", "first", "latest", "min", "max", "change")
+	var regressed []string
+	for _, key := range keys {
+		t := trends[key]
+		first, latest := t.speeds[0], t.speeds[len(t.speeds)-1]
+		lo, hi := first, first
+		for _, s := range t.speeds {
+			if s < lo {
+				lo = s
+			}
+			if s > hi {
+				hi = s
+			}
+		}
+		fmt.Printf("%-14s %-16s %-20s %5d %9.2f %9.2f %9.2f %9.2f %+7.1f%%\n",
+			t.machine, t.dataset, t.backend, len(t.speeds), first, latest, lo, hi, 100*(latest/first-1))
+		if *opts.regression > 0 && len(t.speeds) > 1 {
+			best := t.speeds[0]
+			for _, s := range t.speeds[:len(t.speeds)-1] {
+				best = max(best, s)
+			}
+			if latest < best*(1-*opts.regression/100) {
+				regressed = append(regressed, fmt.Sprintf("%s on %s (%.1f%%)", t.backend, t.dataset, 100*(latest/best-1)))
+			}
+		}
+	}
+	if len(regressed) > 0 {
+		return withKind(errRegression, fmt.Errorf("history: latest run more than %g%% slower than the best earlier one: %s",
+			*opts.regression, strings.Join(regressed, ", ")))
+	}
+	return nil
+}
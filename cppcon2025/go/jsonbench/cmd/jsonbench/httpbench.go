@@ -0,0 +1,262 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// bodyDecoders are the ways a handler can read a JSON request body into
+// the structs
+var bodyDecoders = []struct {
+	name   string
+	decode func(r io.Reader, v interface{}) error
+}{
+	{"ReadAll+Unmarshal", func(r io.Reader, v interface{}) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, v)
+	}},
+	{"NewDecoder", func(r io.Reader, v interface{}) error {
+		return json.NewDecoder(r).Decode(v)
+	}},
+}
+
+// decodeHandler decodes POSTed twitter.json bodies with decode and
+// answers with the number of statuses
+func decodeHandler(decode func(io.Reader, interface{}) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST a JSON body", http.StatusMethodNotAllowed)
+			return
+		}
+		var t TwitterData
+		if err := decode(r.Body, &t); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"statuses":%d}`, len(t.Statuses))
+	})
+}
+
+// loadConfig describes the requests a load generator sends
+type loadConfig struct {
+	url         string
+	method      string // POST when empty
+	body        []byte
+	contentType string
+	concurrency int
+	duration    time.Duration
+	rate        float64 // requests per second in total, 0 for closed loop
+	header      http.Header
+	response    func(body []byte) error // checks each response body when set
+}
+
+// loadResult is what a load generator measured
+type loadResult struct {
+	requests  int
+	errors    int
+	elapsed   time.Duration
+	latencies []time.Duration // sorted, successful requests only
+}
+
+func (r loadResult) perSecond() float64 { return float64(r.requests) / r.elapsed.Seconds() }
+
+// percentile returns the latency below which p of the requests completed
+func (r loadResult) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	i := int(p * float64(len(r.latencies)))
+	if i >= len(r.latencies) {
+		i = len(r.latencies) - 1
+	}
+	return r.latencies[i]
+}
+
+// generateLoad sends cfg.body from cfg.concurrency connections for
+// cfg.duration. In a closed loop each connection sends its next request
+// when the previous one is answered. With cfg.rate the requests are
+// scheduled at fixed intervals instead, and latencies are measured from
+// the scheduled time, so that a stalled server is not hidden by the
+// requests it kept from being sent.
+func generateLoad(cfg loadConfig) (loadResult, error) {
+	client := &http.Client{Transport: &http.Transport{
+		MaxIdleConnsPerHost: cfg.concurrency,
+		DisableCompression:  true,
+	}}
+	defer client.CloseIdleConnections()
+
+	var mu sync.Mutex
+	var result loadResult
+	var firstErr error
+	var scheduled int64
+	start := time.Now()
+	deadline := start.Add(cfg.duration)
+	// next returns when the next request is due, or false when the run is over
+	next := func() (time.Time, bool) {
+		if cfg.rate <= 0 {
+			now := time.Now()
+			return now, now.Before(deadline)
+		}
+		i := atomic.AddInt64(&scheduled, 1) - 1
+		due := start.Add(time.Duration(float64(i) / cfg.rate * float64(time.Second)))
+		if !due.Before(deadline) {
+			return due, false
+		}
+		time.Sleep(time.Until(due))
+		return due, true
+	}
+	var wg sync.WaitGroup
+	for c := 0; c < cfg.concurrency; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var latencies []time.Duration
+			requests, failures := 0, 0
+			var failure error
+			for {
+				t, ok := next()
+				if !ok {
+					break
+				}
+				if err := send(client, cfg); err != nil {
+					if failure == nil {
+						failure = err
+					}
+					failures++
+				} else {
+					latencies = append(latencies, time.Since(t))
+				}
+				requests++
+			}
+			mu.Lock()
+			result.requests += requests
+			result.errors += failures
+			result.latencies = append(result.latencies, latencies...)
+			if firstErr == nil {
+				firstErr = failure
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	result.elapsed = time.Since(start)
+	sort.Slice(result.latencies, func(i, j int) bool { return result.latencies[i] < result.latencies[j] })
+	if result.requests > 0 && result.errors == result.requests {
+		return result, fmt.Errorf("every request failed: %w", firstErr)
+	}
+	return result, nil
+}
+
+func send(client *http.Client, cfg loadConfig) error {
+	method := cfg.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, cfg.url, bytes.NewReader(cfg.body))
+	if err != nil {
+		return err
+	}
+	for k, v := range cfg.header {
+		req.Header[k] = v
+	}
+	if cfg.body != nil {
+		req.Header.Set("Content-Type", cfg.contentType)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if cfg.response == nil {
+		// Drain the body so that the connection is reused
+		_, err = io.Copy(io.Discard, resp.Body)
+	} else {
+		var body []byte
+		if body, err = io.ReadAll(resp.Body); err == nil && resp.StatusCode == http.StatusOK {
+			err = cfg.response(body)
+		}
+	}
+	if err == nil && resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("%s: %s", cfg.url, resp.Status)
+	}
+	return err
+}
+
+// httpBenchFlags are the flags of httpbench
+type httpBenchFlags struct {
+	file        *string
+	concurrency *int
+	duration    *time.Duration
+}
+
+func (opts *httpBenchFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("httpbench")
+	opts.file = fs.String("file", "../twitter.json", "JSON body to POST")
+	opts.concurrency = fs.Int("c", 8, "number of concurrent connections")
+	opts.duration = fs.Duration("d", 5*time.Second, "how long to load each handler")
+	return fs
+}
+
+// runHTTPBench serves twitter.json decoding on a local port and loads it
+// with the built-in generator, once per way of reading the body
+func runHTTPBench(args []string) error {
+	var opts httpBenchFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	body, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, new(TwitterData)); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	for _, d := range bodyDecoders {
+		mux.Handle("/"+d.name, decodeHandler(d.decode))
+	}
+	server := &http.Server{Handler: mux}
+	go server.Serve(ln)
+	defer server.Close()
+
+	fmt.Printf("%s: %d byte bodies, %d connections, %v per handler\n\n", *opts.file, len(body), *opts.concurrency, *opts.duration)
+	fmt.Println("| Body decoding | req/s | MB/s | p50 ms | p99 ms | errors |")
+	fmt.Println("|---|---:|---:|---:|---:|---:|")
+	for _, d := range bodyDecoders {
+		r, err := generateLoad(loadConfig{
+			url:         "http://" + ln.Addr().String() + "/" + d.name,
+			body:        body,
+			contentType: "application/json",
+			concurrency: *opts.concurrency,
+			duration:    *opts.duration,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", d.name, err)
+		}
+		fmt.Printf("| %s | %.0f | %.2f | %.3f | %.3f | %d |\n", d.name, r.perSecond(),
+			r.perSecond()*float64(len(body))/1e6, bench.Milliseconds(r.percentile(0.5)), bench.Milliseconds(r.percentile(0.99)), r.errors)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// docStats describes what a parser spends its time on in one document
+type docStats struct {
+	depth                                  int
+	objects, arrays, members               int
+	widestObject, longestArray             int
+	strings, stringBytes, escaped, longest int
+	integers, floats, trues, falses, nulls int
+	keys                                   map[string]int
+}
+
+// walk visits the value at the current position of d, at the given
+// nesting depth
+func (s *docStats) walk(d *backends.Decoder, depth int) error {
+	switch d.Peek() {
+	case '{':
+		s.objects++
+		s.depth = max(s.depth, depth+1)
+		n := 0
+		err := d.Members(func(key []byte) error {
+			n++
+			s.keys[string(key)]++
+			return s.walk(d, depth+1)
+		})
+		s.members += n
+		s.widestObject = max(s.widestObject, n)
+		return err
+	case '[':
+		s.arrays++
+		s.depth = max(s.depth, depth+1)
+		n := 0
+		err := d.Elements(func() error {
+			n++
+			return s.walk(d, depth+1)
+		})
+		s.longestArray = max(s.longestArray, n)
+		return err
+	}
+	raw, err := d.SkipRaw()
+	if err != nil {
+		return err
+	}
+	switch raw[0] {
+	case '"':
+		s.strings++
+		s.stringBytes += len(raw) - 2
+		s.longest = max(s.longest, len(raw)-2)
+		if bytes.IndexByte(raw, '\\') >= 0 {
+			s.escaped++
+		}
+	case 't':
+		s.trues++
+	case 'f':
+		s.falses++
+	case 'n':
+		s.nulls++
+	default:
+		if bytes.ContainsAny(raw, ".eE") {
+			s.floats++
+		} else {
+			s.integers++
+		}
+	}
+	return nil
+}
+
+// inspectFlags are the flags of inspect
+type inspectFlags struct {
+	files *string
+	top   *int
+}
+
+func (opts *inspectFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("inspect")
+	opts.files = fs.String("file", "../twitter.json", "comma-separated list of JSON documents, - for standard input")
+	opts.top = fs.Int("keys", 5, "number of most frequent keys to list")
+	return fs
+}
+
+// Describe the structure of each document: how deep it nests, how many
+// values of each kind it holds, and how much of it is strings, escapes
+// and whitespace
+func runInspect(args []string) error {
+	var opts inspectFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	for i, file := range strings.Split(*opts.files, ",") {
+		data, err := datasets.Read(file)
+		if err != nil {
+			return err
+		}
+		s := &docStats{keys: map[string]int{}}
+		d := backends.NewDecoder(data, backends.DecodeOptions{})
+		if err := s.walk(d, 0); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := d.End(); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, data); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		percent := func(n, of int) float64 { return 100 * float64(n) / float64(max(of, 1)) }
+		whitespace := len(data) - compact.Len()
+		fmt.Printf("%s: %d bytes, %.1f%% whitespace, nesting depth %d\n\n", datasets.Name(file), len(data), percent(whitespace, len(data)), s.depth)
+		fmt.Println("| Values | Count | Detail |")
+		fmt.Println("|---|---:|---|")
+		fmt.Printf("| objects | %d | %d members, %d distinct keys, widest %d |\n", s.objects, s.members, len(s.keys), s.widestObject)
+		fmt.Printf("| arrays | %d | longest %d |\n", s.arrays, s.longestArray)
+		fmt.Printf("| strings | %d | %d bytes (%.1f%% of the document), %d with escapes, longest %d |\n",
+			s.strings, s.stringBytes, percent(s.stringBytes, len(data)), s.escaped, s.longest)
+		fmt.Printf("| numbers | %d | %d integers, %d with a fraction or exponent |\n", s.integers+s.floats, s.integers, s.floats)
+		fmt.Printf("| true, false | %d | %d true |\n", s.trues+s.falses, s.trues)
+		fmt.Printf("| null | %d | |\n", s.nulls)
+
+		keys := make([]string, 0, len(s.keys))
+		for k := range s.keys {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool {
+			if s.keys[keys[a]] != s.keys[keys[b]] {
+				return s.keys[keys[a]] > s.keys[keys[b]]
+			}
+			return keys[a] < keys[b]
+		})
+		if len(keys) > *opts.top {
+			keys = keys[:*opts.top]
+		}
+		for j, k := range keys {
+			keys[j] = fmt.Sprintf("%q (%d)", k, s.keys[k])
+		}
+		if len(keys) > 0 {
+			fmt.Printf("\nmost frequent keys: %s\n", strings.Join(keys, ", "))
+		}
+	}
+	return nil
+}
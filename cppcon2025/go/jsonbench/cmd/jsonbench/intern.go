@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"runtime"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// countKeys adds the keys of every object under v to keys, by name
+func countKeys(v interface{}, keys map[string]int) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			keys[k]++
+			countKeys(e, keys)
+		}
+	case []interface{}:
+		for _, e := range v {
+			countKeys(e, keys)
+		}
+	}
+}
+
+// internFlags are the flags of intern
+type internFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *internFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("intern")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to decode")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	return fs
+}
+
+// Decode into map[string]interface{} with and without interning the
+// object keys, which repeat in every status and user of twitter.json,
+// and compare allocations and throughput
+func runIntern(args []string) error {
+	var opts internFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var want interface{}
+	if err := json.Unmarshal(data, &want); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	keys := map[string]int{}
+	countKeys(want, keys)
+	total, top, topCount := 0, "", 0
+	for k, n := range keys {
+		total += n
+		if n > topCount {
+			top, topCount = k, n
+		}
+	}
+	fmt.Printf("%s: %d bytes, %d keys, %d distinct; %q alone appears %d times\n\n",
+		datasets.Name(*opts.file), len(data), total, len(keys), top, topCount)
+
+	decoders := []backends.Backend{
+		backends.Stdlib{},
+		backends.Handrolled{},
+		backends.Handrolled{Options: backends.DecodeOptions{InternKeys: true}},
+	}
+	fmt.Println("| Decoder | MB/s | allocs/decode | KB/decode |")
+	fmt.Println("|---|---:|---:|---:|")
+	for _, b := range decoders {
+		got, err := b.Decode(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			return withKind(errMismatch, fmt.Errorf("%s: the values differ from those of encoding/json", b.Name()))
+		}
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for i := 0; i < *opts.iterations; i++ {
+			if _, err := b.Decode(data); err != nil {
+				return fmt.Errorf("%s: %w", b.Name(), err)
+			}
+		}
+		elapsed := time.Since(start).Seconds()
+		runtime.ReadMemStats(&after)
+		fmt.Printf("| %s | %.1f | %.0f | %.1f |\n", b.Name(),
+			float64(len(data))*float64(*opts.iterations)/1e6/elapsed,
+			float64(after.Mallocs-before.Mallocs)/float64(*opts.iterations),
+			float64(after.TotalAlloc-before.TotalAlloc)/1024/float64(*opts.iterations))
+	}
+	return nil
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// JSON Patch (RFC 6902) over the generic representation, with JSON
+// Pointers (RFC 6901) as paths
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("pointer %q does not start with /", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		if strings.IndexByte(t, '~') < 0 {
+			continue
+		}
+		for j := 0; j < len(t); j++ {
+			if t[j] == '~' && (j+1 == len(t) || t[j+1] != '0' && t[j+1] != '1') {
+				return nil, fmt.Errorf("pointer %q has a bad escape", p)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// formatPointer is the inverse of parsePointer
+func formatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// arrayIndex parses a reference token as an index into an array of n
+// elements; "-" and n itself are only allowed when adding
+func arrayIndex(token string, n int, adding bool) (int, error) {
+	if token == "-" && adding {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || token != strconv.Itoa(i) {
+		return 0, fmt.Errorf("bad array index %q", token)
+	}
+	if i > n || i == n && !adding {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// pointerGet returns the value the tokens refer to
+func pointerGet(v interface{}, tokens []string) (interface{}, error) {
+	for _, t := range tokens {
+		switch c := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = c[t]; !ok {
+				return nil, fmt.Errorf("member %q not found", t)
+			}
+		case []interface{}:
+			i, err := arrayIndex(t, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			v = c[i]
+		default:
+			return nil, fmt.Errorf("cannot look up %q in a %s", t, queryTypeName(v))
+		}
+	}
+	return v, nil
+}
+
+// patchAt calls f with the container holding the last token and stores
+// the container f returns, so that arrays can change length. It returns
+// the new root.
+func patchAt(v interface{}, tokens []string, f func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return f(v, tokens[0])
+	}
+	child, err := pointerGet(v, tokens[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = patchAt(child, tokens[1:], f); err != nil {
+		return nil, err
+	}
+	switch c := v.(type) {
+	case map[string]interface{}:
+		c[tokens[0]] = child
+	case []interface{}:
+		i, _ := arrayIndex(tokens[0], len(c), false)
+		c[i] = child
+	}
+	return v, nil
+}
+
+func patchAdd(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return patchAt(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		}
+		return nil, fmt.Errorf("cannot add %q to a %s", token, queryTypeName(container))
+	})
+}
+
+func patchRemove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	return patchAt(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[token]; !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			delete(c, token)
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:i], c[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove %q from a %s", token, queryTypeName(container))
+	})
+}
+
+func patchReplace(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return patchAt(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[token]; !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			c[i] = value
+			return c, nil
+		}
+		return nil, fmt.Errorf("cannot replace %q in a %s", token, queryTypeName(container))
+	})
+}
+
+// deepCopy copies the containers of a generic value
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			c[k] = deepCopy(e)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = deepCopy(e)
+		}
+		return c
+	}
+	return v
+}
+
+// patchOp is one operation of a JSON Patch, with its pointers parsed
+type patchOp struct {
+	op       string
+	path     string
+	from     string
+	value    interface{}
+	tokens   []string
+	fromToks []string
+}
+
+// parsePatch reads a patch document: an array of operation objects
+func parsePatch(v interface{}) ([]patchOp, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.New("patch: a patch is an array of operations")
+	}
+	ops := make([]patchOp, 0, len(arr))
+	for i, e := range arr {
+		obj, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("patch: operation %d is not an object", i)
+		}
+		var op patchOp
+		op.op, _ = obj["op"].(string)
+		path, ok := obj["path"].(string)
+		if !ok {
+			return nil, fmt.Errorf("patch: operation %d has no path", i)
+		}
+		op.path = path
+		var err error
+		if op.tokens, err = parsePointer(path); err != nil {
+			return nil, fmt.Errorf("patch: operation %d: %w", i, err)
+		}
+		switch op.op {
+		case "add", "replace", "test":
+			if op.value, ok = obj["value"]; !ok {
+				return nil, fmt.Errorf("patch: operation %d (%s) has no value", i, op.op)
+			}
+		case "move", "copy":
+			if op.from, ok = obj["from"].(string); !ok {
+				return nil, fmt.Errorf("patch: operation %d (%s) has no from", i, op.op)
+			}
+			if op.fromToks, err = parsePointer(op.from); err != nil {
+				return nil, fmt.Errorf("patch: operation %d: %w", i, err)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("patch: operation %d has unknown op %q", i, op.op)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// generic returns the operation as a patch document member
+func (op patchOp) generic() interface{} {
+	m := map[string]interface{}{"op": op.op, "path": op.path}
+	switch op.op {
+	case "add", "replace", "test":
+		m["value"] = op.value
+	case "move", "copy":
+		m["from"] = op.from
+	}
+	return m
+}
+
+// applyPatch applies ops to doc in order and returns the patched document.
+// doc is modified in place and is left partly patched when an operation
+// fails; apply to deepCopy(doc) where the RFC's all-or-nothing semantics
+// matter.
+func applyPatch(doc interface{}, ops []patchOp) (interface{}, error) {
+	var err error
+	for i, op := range ops {
+		switch op.op {
+		case "add":
+			doc, err = patchAdd(doc, op.tokens, deepCopy(op.value))
+		case "remove":
+			doc, err = patchRemove(doc, op.tokens)
+		case "replace":
+			doc, err = patchReplace(doc, op.tokens, deepCopy(op.value))
+		case "move":
+			if len(op.fromToks) < len(op.tokens) && reflect.DeepEqual(op.fromToks, op.tokens[:len(op.fromToks)]) {
+				err = errors.New("cannot move a value into itself")
+				break
+			}
+			var v interface{}
+			if v, err = pointerGet(doc, op.fromToks); err == nil {
+				if doc, err = patchRemove(doc, op.fromToks); err == nil {
+					doc, err = patchAdd(doc, op.tokens, v)
+				}
+			}
+		case "copy":
+			var v interface{}
+			if v, err = pointerGet(doc, op.fromToks); err == nil {
+				doc, err = patchAdd(doc, op.tokens, deepCopy(v))
+			}
+		case "test":
+			var v interface{}
+			if v, err = pointerGet(doc, op.tokens); err == nil && !reflect.DeepEqual(v, op.value) {
+				err = errors.New("test failed")
+			}
+		}
+		if err != nil {
+			return doc, fmt.Errorf("patch: operation %d (%s %s): %w", i, op.op, op.path, err)
+		}
+	}
+	return doc, nil
+}
+
+// diffPatch generates a patch that turns a into b: members are added,
+// removed or diffed recursively, arrays are diffed element by element
+// and then shortened or extended at the end, and anything else is
+// replaced
+func diffPatch(a, b interface{}) []patchOp {
+	return appendDiff(nil, nil, a, b)
+}
+
+func appendDiff(ops []patchOp, path []string, a, b interface{}) []patchOp {
+	at := func(op string, value interface{}, last ...string) patchOp {
+		tokens := append(append([]string{}, path...), last...)
+		return patchOp{op: op, path: formatPointer(tokens), value: value, tokens: tokens}
+	}
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(a)+len(b))
+			for k := range a {
+				keys = append(keys, k)
+			}
+			for k := range b {
+				if _, ok := a[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				av, inA := a[k]
+				bv, inB := b[k]
+				switch {
+				case !inB:
+					ops = append(ops, at("remove", nil, k))
+				case !inA:
+					ops = append(ops, at("add", bv, k))
+				default:
+					ops = appendDiff(ops, append(path, k), av, bv)
+				}
+			}
+			return ops
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			n := len(a)
+			if len(b) < n {
+				n = len(b)
+			}
+			for i := 0; i < n; i++ {
+				ops = appendDiff(ops, append(path, strconv.Itoa(i)), a[i], b[i])
+			}
+			for i := len(a) - 1; i >= len(b); i-- {
+				ops = append(ops, at("remove", nil, strconv.Itoa(i)))
+			}
+			for i := len(a); i < len(b); i++ {
+				ops = append(ops, at("add", b[i], "-"))
+			}
+			return ops
+		}
+	}
+	// Two maps or two arrays were handled above, so == cannot panic here
+	if a == b {
+		return ops
+	}
+	return append(ops, at("replace", b))
+}
+
+// benchmarkPatches are n small patches of the kind an API applies to
+// documents: each tests a status id, bumps its retweet count, appends a
+// hashtag and removes the first one
+func benchmarkPatches(doc interface{}, n int) ([][]byte, error) {
+	statuses, err := pointerGet(doc, []string{"statuses"})
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := statuses.([]interface{})
+	if !ok || len(arr) == 0 {
+		return nil, errors.New("no statuses to patch")
+	}
+	patches := make([][]byte, n)
+	for i := range patches {
+		k := i % len(arr)
+		id, _ := pointerGet(arr[k], []string{"id"})
+		p := []interface{}{
+			map[string]interface{}{"op": "test", "path": fmt.Sprintf("/statuses/%d/id", k), "value": id},
+			map[string]interface{}{"op": "replace", "path": fmt.Sprintf("/statuses/%d/retweet_count", k), "value": float64(i)},
+			map[string]interface{}{"op": "add", "path": fmt.Sprintf("/statuses/%d/entities/hashtags/-", k),
+				"value": map[string]interface{}{"text": fmt.Sprintf("tag%d", i), "indices": []interface{}{0.0, 4.0}}},
+			map[string]interface{}{"op": "remove", "path": fmt.Sprintf("/statuses/%d/entities/hashtags/0", k)},
+		}
+		if patches[i], err = json.Marshal(p); err != nil {
+			return nil, err
+		}
+	}
+	return patches, nil
+}
+
+// patchFlags are the flags of patch
+type patchFlags struct {
+	file       *string
+	n          *int
+	printPatch *bool
+}
+
+func (opts *patchFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("patch")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to patch")
+	opts.n = fs.Int("n", 10000, "number of small patches to apply")
+	opts.printPatch = fs.Bool("print", false, "print the generated patch and exit")
+	return fs
+}
+
+// runPatch generates a JSON Patch between twitter.json and an edited copy
+// and applies it, then applies thousands of small patches in a row
+func runPatch(args []string) error {
+	var opts patchFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+
+	// An edited copy: every status gets a new retweet count, loses its
+	// source and gains a member, and the last one is dropped
+	edited := deepCopy(doc)
+	if arr, ok := edited.(map[string]interface{})["statuses"].([]interface{}); ok && len(arr) > 0 {
+		for i, s := range arr {
+			if s, ok := s.(map[string]interface{}); ok {
+				s["retweet_count"] = float64(i)
+				delete(s, "source")
+				s["edited"] = true
+			}
+		}
+		edited.(map[string]interface{})["statuses"] = arr[:len(arr)-1]
+	}
+
+	start := time.Now()
+	patch := diffPatch(doc, edited)
+	generate := time.Since(start)
+	generic := make([]interface{}, len(patch))
+	for i, op := range patch {
+		generic[i] = op.generic()
+	}
+	encoded, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	if *opts.printPatch {
+		fmt.Println(string(encoded))
+		return nil
+	}
+	target := deepCopy(doc)
+	start = time.Now()
+	patched, err := applyPatch(target, patch)
+	apply := time.Since(start)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(patched, edited) {
+		return withKind(errMismatch, errors.New("patch: applying the generated patch does not give the edited document"))
+	}
+	fmt.Printf("%s: generated %d operations (%d bytes) in %.2f ms, applied in %.2f ms\n",
+		*opts.file, len(patch), len(encoded), bench.Milliseconds(generate), bench.Milliseconds(apply))
+
+	patches, err := benchmarkPatches(doc, *opts.n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	var parseTime, applyTime time.Duration
+	ops := 0
+	target = deepCopy(doc)
+	for _, p := range patches {
+		t := time.Now()
+		var v interface{}
+		if err := json.Unmarshal(p, &v); err != nil {
+			return err
+		}
+		parsed, err := parsePatch(v)
+		if err != nil {
+			return err
+		}
+		t2 := time.Now()
+		if target, err = applyPatch(target, parsed); err != nil {
+			return err
+		}
+		parseTime += t2.Sub(t)
+		applyTime += time.Since(t2)
+		ops += len(parsed)
+	}
+	total := (parseTime + applyTime).Seconds()
+	fmt.Printf("%d patches of %d operations: %.0f patches/s, %.0f operations/s (parse %.2f µs, apply %.2f µs per patch)\n",
+		len(patches), ops/len(patches), float64(len(patches))/total, float64(ops)/total,
+		parseTime.Seconds()*1e6/float64(len(patches)), applyTime.Seconds()*1e6/float64(len(patches)))
+	return nil
+}
@@ -0,0 +1,419 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// rpcError is an error object of a JSON-RPC response
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return fmt.Sprintf("%s (%d)", e.Message, e.Code) }
+
+// rpcMethod handles the params of a call, in the generic representation.
+// It returns an *rpcError for bad params; other errors become internal
+// errors.
+type rpcMethod func(params interface{}) (interface{}, error)
+
+// rpcServer dispatches JSON-RPC requests decoded with a backend
+type rpcServer struct {
+	backend backends.Backend
+	methods map[string]rpcMethod
+}
+
+// rpcTimes accumulates the time spent in each stage of serving requests
+type rpcTimes struct {
+	requests, decode, dispatch, encode int64
+}
+
+// serve answers one JSON-RPC message, a request or a batch. It returns
+// nil when no response is due because every request was a notification.
+func (s *rpcServer) serve(body []byte, times *rpcTimes) ([]byte, error) {
+	start := time.Now()
+	v, err := s.backend.Decode(body)
+	decoded := time.Now()
+	var response interface{}
+	if err != nil {
+		response = rpcResponse(nil, nil, &rpcError{rpcParseError, "parse error"})
+	} else if batch, ok := v.([]interface{}); ok {
+		if len(batch) == 0 {
+			response = rpcResponse(nil, nil, &rpcError{rpcInvalidRequest, "empty batch"})
+		} else {
+			var responses []interface{}
+			for _, r := range batch {
+				if resp := s.call(r); resp != nil {
+					responses = append(responses, resp)
+				}
+			}
+			if responses != nil {
+				response = responses
+			}
+		}
+	} else if resp := s.call(v); resp != nil {
+		response = resp
+	}
+	dispatched := time.Now()
+	var out []byte
+	if response != nil {
+		if out, err = backends.EncodeGeneric(s.backend, response); err != nil {
+			return nil, err
+		}
+	}
+	if times != nil {
+		atomic.AddInt64(&times.requests, 1)
+		atomic.AddInt64(&times.decode, int64(decoded.Sub(start)))
+		atomic.AddInt64(&times.dispatch, int64(dispatched.Sub(decoded)))
+		atomic.AddInt64(&times.encode, int64(time.Since(dispatched)))
+	}
+	return out, nil
+}
+
+// call runs one request object, returning nil for a notification
+func (s *rpcServer) call(v interface{}) interface{} {
+	req, ok := v.(map[string]interface{})
+	if !ok {
+		return rpcResponse(nil, nil, &rpcError{rpcInvalidRequest, "request is not an object"})
+	}
+	id, hasID := req["id"]
+	switch id.(type) {
+	case nil, string, float64:
+	default:
+		return rpcResponse(nil, nil, &rpcError{rpcInvalidRequest, "id must be a string, a number or null"})
+	}
+	name, ok := req["method"].(string)
+	if req["jsonrpc"] != "2.0" || !ok {
+		return rpcResponse(id, nil, &rpcError{rpcInvalidRequest, "invalid request"})
+	}
+	params := req["params"]
+	switch params.(type) {
+	case nil, []interface{}, map[string]interface{}:
+	default:
+		return rpcResponse(id, nil, &rpcError{rpcInvalidRequest, "params must be an array or an object"})
+	}
+	m, ok := s.methods[name]
+	if !ok {
+		if !hasID {
+			return nil
+		}
+		return rpcResponse(id, nil, &rpcError{rpcMethodNotFound, "method not found: " + name})
+	}
+	result, err := m(params)
+	if !hasID {
+		return nil
+	}
+	if err != nil {
+		var rerr *rpcError
+		if !errors.As(err, &rerr) {
+			rerr = &rpcError{rpcInternalError, err.Error()}
+		}
+		return rpcResponse(id, nil, rerr)
+	}
+	return rpcResponse(id, result, nil)
+}
+
+// rpcResponse builds a response object in the generic representation, so
+// that backends with an Encoder can serialize it
+func rpcResponse(id, result interface{}, err *rpcError) interface{} {
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if err != nil {
+		resp["error"] = map[string]interface{}{"code": float64(err.Code), "message": err.Message}
+	} else {
+		resp["result"] = result
+	}
+	return resp
+}
+
+// rpcParam returns the params member named name, or the element at
+// position when params is an array
+func rpcParam(params interface{}, name string, position int) (interface{}, bool) {
+	switch p := params.(type) {
+	case map[string]interface{}:
+		v, ok := p[name]
+		return v, ok
+	case []interface{}:
+		if position < len(p) {
+			return p[position], true
+		}
+	}
+	return nil, false
+}
+
+// demoMethods answers questions about twitter.json
+func demoMethods(statuses []interface{}, followers map[string]float64) map[string]rpcMethod {
+	return map[string]rpcMethod{
+		"echo": func(params interface{}) (interface{}, error) { return params, nil },
+		"sum": func(params interface{}) (interface{}, error) {
+			numbers, ok := params.([]interface{})
+			if !ok {
+				return nil, &rpcError{rpcInvalidParams, "sum takes an array of numbers"}
+			}
+			total := 0.0
+			for _, n := range numbers {
+				f, ok := n.(float64)
+				if !ok {
+					return nil, &rpcError{rpcInvalidParams, "sum takes an array of numbers"}
+				}
+				total += f
+			}
+			return total, nil
+		},
+		"status.get": func(params interface{}) (interface{}, error) {
+			i, _ := rpcParam(params, "index", 0)
+			f, ok := i.(float64)
+			if !ok || f < 0 || f >= float64(len(statuses)) || f != float64(int(f)) {
+				return nil, &rpcError{rpcInvalidParams, fmt.Sprintf("index must be an integer below %d", len(statuses))}
+			}
+			return statuses[int(f)], nil
+		},
+		"user.followers": func(params interface{}) (interface{}, error) {
+			name, _ := rpcParam(params, "screen_name", 0)
+			s, ok := name.(string)
+			if !ok {
+				return nil, &rpcError{rpcInvalidParams, "screen_name must be a string"}
+			}
+			n, ok := followers[s]
+			if !ok {
+				return nil, &rpcError{rpcInvalidParams, "unknown user: " + s}
+			}
+			return n, nil
+		},
+	}
+}
+
+// rpcHandler serves JSON-RPC over HTTP POST
+func rpcHandler(s *rpcServer, times *rpcTimes) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST a JSON-RPC request", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		out, err := s.serve(body, times)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if out == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(out)
+	})
+}
+
+// rpcClient calls methods on a JSON-RPC server over HTTP
+type rpcClient struct {
+	url    string
+	client *http.Client
+	nextID int64
+}
+
+// rpcReply is a response object as the client decodes it
+type rpcReply struct {
+	ID     interface{}     `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// call sends one request and decodes its result into result
+func (c *rpcClient) call(method string, params, result interface{}) error {
+	id := atomic.AddInt64(&c.nextID, 1)
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var reply rpcReply
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if reply.Error != nil {
+		return fmt.Errorf("%s: %w", method, reply.Error)
+	}
+	if reply.ID != float64(id) {
+		return fmt.Errorf("%s: response id %v for request %d", method, reply.ID, id)
+	}
+	return json.Unmarshal(reply.Result, result)
+}
+
+// rpcWorkload is the mix of messages the benchmark sends
+func rpcWorkload(statuses []json.RawMessage, screenName string) [][]byte {
+	var numbers []string
+	for i := 0; i < 32; i++ {
+		numbers = append(numbers, fmt.Sprintf("%d.5", i))
+	}
+	sum := `{"jsonrpc":"2.0","id":2,"method":"sum","params":[` + strings.Join(numbers, ",") + `]}`
+	followers := fmt.Sprintf(`{"jsonrpc":"2.0","id":"1","method":"user.followers","params":{"screen_name":%q}}`, screenName)
+	get := `{"jsonrpc":"2.0","id":3,"method":"status.get","params":[0]}`
+	echo := `{"jsonrpc":"2.0","id":4,"method":"echo","params":[` + string(statuses[0]) + `]}`
+	notify := `{"jsonrpc":"2.0","method":"echo","params":[]}`
+	batch := "[" + strings.Join([]string{followers, sum, get, notify}, ",") + "]"
+	var messages [][]byte
+	for _, m := range []string{followers, sum, get, echo, batch} {
+		messages = append(messages, []byte(m))
+	}
+	return messages
+}
+
+// jsonrpcFlags are the flags of jsonrpc
+type jsonrpcFlags struct {
+	file       *string
+	iterations *int
+	only       *string
+	listen     *string
+}
+
+func (opts *jsonrpcFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("jsonrpc")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document the service answers about")
+	opts.iterations = fs.Int("n", 20000, "messages per backend")
+	opts.only = fs.String("backend", "", "comma-separated backends to run (default all)")
+	opts.listen = fs.String("listen", "", "serve the service on this address instead of benchmarking it")
+	return fs
+}
+
+// runJSONRPC checks a JSON-RPC service over HTTP, then measures decoding,
+// dispatching and encoding requests with each backend
+func runJSONRPC(args []string) error {
+	var opts jsonrpcFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var twitter TwitterData
+	if err := json.Unmarshal(data, &twitter); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	recs, err := datasets.Records(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	statuses := make([]interface{}, len(recs))
+	for i, r := range recs {
+		if err := json.Unmarshal(r, &statuses[i]); err != nil {
+			return err
+		}
+	}
+	if len(twitter.Statuses) == 0 {
+		return fmt.Errorf("%s: no statuses", *opts.file)
+	}
+	followers := map[string]float64{}
+	for _, s := range twitter.Statuses {
+		followers[s.User.ScreenName] = float64(s.User.FollowersCount)
+	}
+	methods := demoMethods(statuses, followers)
+
+	if *opts.listen != "" {
+		var b backends.Backend = backends.Stdlib{}
+		if *opts.only != "" {
+			if b, err = backends.Lookup(*opts.only); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(os.Stderr, "serving JSON-RPC on %s with %s\n", *opts.listen, b.Name())
+		return http.ListenAndServe(*opts.listen, rpcHandler(&rpcServer{backend: b, methods: methods}, nil))
+	}
+
+	// The client and server must agree before anything is timed
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: rpcHandler(&rpcServer{backend: backends.Stdlib{}, methods: methods}, nil)}
+	go server.Serve(ln)
+	defer server.Close()
+	client := &rpcClient{url: "http://" + ln.Addr().String() + "/", client: &http.Client{}}
+	screenName := twitter.Statuses[0].User.ScreenName
+	var count, total float64
+	if err := client.call("user.followers", map[string]string{"screen_name": screenName}, &count); err != nil {
+		return err
+	}
+	if err := client.call("sum", []float64{1, 2, 3.5}, &total); err != nil {
+		return err
+	}
+	if count != followers[screenName] || total != 6.5 {
+		return fmt.Errorf("jsonrpc: got %v followers and a sum of %v", count, total)
+	}
+	if err := client.call("status.get", []int{len(statuses)}, new(interface{})); err == nil {
+		return errors.New("jsonrpc: status.get out of range did not fail")
+	}
+
+	messages := rpcWorkload(recs, screenName)
+	for _, m := range messages {
+		var replies []rpcReply
+		out, err := (&rpcServer{backend: backends.Stdlib{}, methods: methods}).serve(m, nil)
+		if err == nil && out[0] != '[' {
+			out = append(append([]byte("["), out...), ']')
+		}
+		if err == nil {
+			err = json.Unmarshal(out, &replies)
+		}
+		for _, r := range replies {
+			if err == nil && r.Error != nil {
+				err = r.Error
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("jsonrpc: %s: %w", m, err)
+		}
+	}
+	fmt.Printf("%d messages per backend, a mix of %d requests and batches\n\n", *opts.iterations, len(messages))
+	fmt.Println("| Backend | messages/s | decode µs | dispatch µs | encode µs |")
+	fmt.Println("|---|---:|---:|---:|---:|")
+	for _, b := range backends.All() {
+		if *opts.only != "" && !containsFormat(*opts.only, b.Name()) {
+			continue
+		}
+		s := &rpcServer{backend: b, methods: methods}
+		var times rpcTimes
+		start := time.Now()
+		for i := 0; i < *opts.iterations; i++ {
+			if _, err := s.serve(messages[i%len(messages)], &times); err != nil {
+				return fmt.Errorf("%s: %w", b.Name(), err)
+			}
+		}
+		perSecond := float64(*opts.iterations) / time.Since(start).Seconds()
+		micros := func(n int64) float64 { return float64(n) / float64(times.requests) / 1e3 }
+		fmt.Printf("| %s | %.0f | %.2f | %.2f | %.2f |\n", b.Name(), perSecond, micros(times.decode), micros(times.dispatch), micros(times.encode))
+	}
+	return nil
+}
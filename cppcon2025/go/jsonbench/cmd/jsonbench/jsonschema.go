@@ -0,0 +1,693 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// jsonSchema is the subset of JSON Schema (draft 2020-12) that API
+// gateways mostly rely on: type, properties, required,
+// additionalProperties, items, enum, minimum, maximum, minLength,
+// maxLength, minItems and maxItems. Other keywords are ignored, as the
+// specification allows for unknown ones. A nil *jsonSchema accepts
+// anything.
+type jsonSchema struct {
+	types                []string
+	properties           map[string]*jsonSchema
+	required             []string
+	additionalProperties *jsonSchema
+	noAdditional         bool // additionalProperties: false
+	items                *jsonSchema
+	enum                 []interface{}
+	minimum, maximum     *float64
+	minLength, maxLength int // -1 when absent
+	minItems, maxItems   int
+	never                bool // the false schema
+}
+
+// compileSchema builds a schema from its generic representation
+func compileSchema(v interface{}) (*jsonSchema, error) {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return nil, nil
+		}
+		return &jsonSchema{never: true}, nil
+	case map[string]interface{}:
+		s := &jsonSchema{minLength: -1, maxLength: -1, minItems: -1, maxItems: -1}
+		var err error
+		switch t := v["type"].(type) {
+		case nil:
+		case string:
+			s.types = []string{t}
+		case []interface{}:
+			for _, e := range t {
+				name, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("type must be a string or an array of strings")
+				}
+				s.types = append(s.types, name)
+			}
+		default:
+			return nil, fmt.Errorf("type must be a string or an array of strings")
+		}
+		if props, ok := v["properties"].(map[string]interface{}); ok {
+			s.properties = make(map[string]*jsonSchema, len(props))
+			for k, p := range props {
+				if s.properties[k], err = compileSchema(p); err != nil {
+					return nil, fmt.Errorf("properties.%s: %w", k, err)
+				}
+			}
+		}
+		if req, ok := v["required"].([]interface{}); ok {
+			for _, r := range req {
+				name, ok := r.(string)
+				if !ok {
+					return nil, fmt.Errorf("required must be an array of strings")
+				}
+				s.required = append(s.required, name)
+			}
+		}
+		if a, ok := v["additionalProperties"]; ok {
+			if a == false {
+				s.noAdditional = true
+			} else if s.additionalProperties, err = compileSchema(a); err != nil {
+				return nil, fmt.Errorf("additionalProperties: %w", err)
+			}
+		}
+		if items, ok := v["items"]; ok {
+			if s.items, err = compileSchema(items); err != nil {
+				return nil, fmt.Errorf("items: %w", err)
+			}
+		}
+		if enum, ok := v["enum"].([]interface{}); ok {
+			s.enum = enum
+		}
+		number := func(key string) *float64 {
+			if f, ok := v[key].(float64); ok {
+				return &f
+			}
+			return nil
+		}
+		count := func(key string) int {
+			if f, ok := v[key].(float64); ok && f >= 0 {
+				return int(f)
+			}
+			return -1
+		}
+		s.minimum, s.maximum = number("minimum"), number("maximum")
+		s.minLength, s.maxLength = count("minLength"), count("maxLength")
+		s.minItems, s.maxItems = count("minItems"), count("maxItems")
+		return s, nil
+	}
+	return nil, fmt.Errorf("a schema must be an object or a boolean, not %T", v)
+}
+
+// generic returns the schema in the generic representation, for printing
+func (s *jsonSchema) generic() interface{} {
+	if s == nil {
+		return true
+	}
+	if s.never {
+		return false
+	}
+	m := map[string]interface{}{}
+	switch len(s.types) {
+	case 0:
+	case 1:
+		m["type"] = s.types[0]
+	default:
+		types := make([]interface{}, len(s.types))
+		for i, t := range s.types {
+			types[i] = t
+		}
+		m["type"] = types
+	}
+	if s.properties != nil {
+		props := map[string]interface{}{}
+		for k, p := range s.properties {
+			props[k] = p.generic()
+		}
+		m["properties"] = props
+	}
+	if len(s.required) > 0 {
+		req := make([]interface{}, len(s.required))
+		for i, r := range s.required {
+			req[i] = r
+		}
+		m["required"] = req
+	}
+	if s.items != nil {
+		m["items"] = s.items.generic()
+	}
+	return m
+}
+
+// inferSchema generates the narrowest schema of this subset that v
+// satisfies: the types seen, every member seen as a property and the
+// members present in every object as required
+func inferSchema(v interface{}) *jsonSchema {
+	s := &jsonSchema{minLength: -1, maxLength: -1, minItems: -1, maxItems: -1}
+	switch v := v.(type) {
+	case nil:
+		s.types = []string{"null"}
+	case bool:
+		s.types = []string{"boolean"}
+	case float64:
+		if v == math.Trunc(v) {
+			s.types = []string{"integer"}
+		} else {
+			s.types = []string{"number"}
+		}
+	case string:
+		s.types = []string{"string"}
+	case []interface{}:
+		s.types = []string{"array"}
+		for i, e := range v {
+			if i == 0 {
+				s.items = inferSchema(e)
+			} else {
+				s.items = mergeSchemas(s.items, inferSchema(e))
+			}
+		}
+	case map[string]interface{}:
+		s.types = []string{"object"}
+		s.properties = make(map[string]*jsonSchema, len(v))
+		for k, e := range v {
+			s.properties[k] = inferSchema(e)
+			s.required = append(s.required, k)
+		}
+		sort.Strings(s.required)
+	}
+	return s
+}
+
+// mergeSchemas returns a schema that accepts what either of a and b accepts
+func mergeSchemas(a, b *jsonSchema) *jsonSchema {
+	m := &jsonSchema{minLength: -1, maxLength: -1, minItems: -1, maxItems: -1}
+	for _, t := range append(append([]string{}, a.types...), b.types...) {
+		if !containsType(m.types, t) {
+			m.types = append(m.types, t)
+		}
+	}
+	// integer is a subset of number
+	if containsType(m.types, "number") && containsType(m.types, "integer") {
+		kept := m.types[:0]
+		for _, t := range m.types {
+			if t != "integer" {
+				kept = append(kept, t)
+			}
+		}
+		m.types = kept
+	}
+	sort.Strings(m.types)
+	switch {
+	case a.items == nil:
+		m.items = b.items
+	case b.items == nil:
+		m.items = a.items
+	default:
+		m.items = mergeSchemas(a.items, b.items)
+	}
+	if a.properties != nil || b.properties != nil {
+		m.properties = map[string]*jsonSchema{}
+		for k, p := range a.properties {
+			m.properties[k] = p
+		}
+		for k, p := range b.properties {
+			if q, ok := m.properties[k]; ok {
+				m.properties[k] = mergeSchemas(q, p)
+			} else {
+				m.properties[k] = p
+			}
+		}
+		// Members are only required when both sides are objects that have them
+		aObject, bObject := containsType(a.types, "object"), containsType(b.types, "object")
+		for _, r := range a.required {
+			if !bObject || containsType(b.required, r) {
+				m.required = append(m.required, r)
+			}
+		}
+		for _, r := range b.required {
+			if !aObject && !containsType(m.required, r) {
+				m.required = append(m.required, r)
+			}
+		}
+	}
+	return m
+}
+
+func containsType(types []string, t string) bool {
+	for _, u := range types {
+		if u == t {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaError is the first violation found and where
+type schemaError struct {
+	path string
+	msg  string
+}
+
+func (e *schemaError) Error() string { return e.path + ": " + e.msg }
+
+// schemaValidator validates either a decoded value or, with d set, the
+// document d is parsing. path holds the keys and indexes leading to the
+// current value, formatted only when reporting an error.
+type schemaValidator struct {
+	d    *backends.Decoder
+	path []schemaPathElem
+}
+
+// schemaPathElem is an object key, as a string or as the decoder's bytes,
+// or an array index
+type schemaPathElem struct {
+	key      string
+	keyBytes []byte
+	index    int // -1 for a key
+}
+
+func (sv *schemaValidator) errorf(format string, args ...interface{}) error {
+	var path strings.Builder
+	path.WriteByte('$')
+	for _, e := range sv.path {
+		switch {
+		case e.index >= 0:
+			fmt.Fprintf(&path, "[%d]", e.index)
+		case e.keyBytes != nil:
+			path.WriteByte('.')
+			path.Write(e.keyBytes)
+		default:
+			path.WriteByte('.')
+			path.WriteString(e.key)
+		}
+	}
+	return &schemaError{path: path.String(), msg: fmt.Sprintf(format, args...)}
+}
+
+func (sv *schemaValidator) push(key string) {
+	sv.path = append(sv.path, schemaPathElem{key: key, index: -1})
+}
+
+// pushBytes records a key held in the decoder's buffer, which stays
+// unchanged while the member's value is parsed
+func (sv *schemaValidator) pushBytes(key []byte) {
+	sv.path = append(sv.path, schemaPathElem{keyBytes: key, index: -1})
+}
+
+func (sv *schemaValidator) pushIndex(i int) {
+	sv.path = append(sv.path, schemaPathElem{index: i})
+}
+
+func (sv *schemaValidator) pop() { sv.path = sv.path[:len(sv.path)-1] }
+
+// checkType reports whether a value of JSON type t (integer for whole
+// numbers) is allowed
+func (sv *schemaValidator) checkType(s *jsonSchema, t string) error {
+	if len(s.types) == 0 || containsType(s.types, t) || t == "integer" && containsType(s.types, "number") {
+		return nil
+	}
+	if t == "integer" {
+		t = "number"
+	}
+	return sv.errorf("%s is not of type %s", t, strings.Join(s.types, " or "))
+}
+
+func (sv *schemaValidator) checkNumber(s *jsonSchema, f float64) error {
+	t := "number"
+	if f == math.Trunc(f) {
+		t = "integer"
+	}
+	if err := sv.checkType(s, t); err != nil {
+		return err
+	}
+	if s.minimum != nil && f < *s.minimum {
+		return sv.errorf("%g is less than the minimum %g", f, *s.minimum)
+	}
+	if s.maximum != nil && f > *s.maximum {
+		return sv.errorf("%g is greater than the maximum %g", f, *s.maximum)
+	}
+	return nil
+}
+
+func (sv *schemaValidator) checkString(s *jsonSchema, str []byte) error {
+	if err := sv.checkType(s, "string"); err != nil {
+		return err
+	}
+	if s.minLength >= 0 || s.maxLength >= 0 {
+		n := utf8.RuneCount(str)
+		if s.minLength >= 0 && n < s.minLength {
+			return sv.errorf("string is shorter than %d characters", s.minLength)
+		}
+		if s.maxLength >= 0 && n > s.maxLength {
+			return sv.errorf("string is longer than %d characters", s.maxLength)
+		}
+	}
+	return nil
+}
+
+func (sv *schemaValidator) checkItems(s *jsonSchema, n int) error {
+	if s.minItems >= 0 && n < s.minItems {
+		return sv.errorf("array has fewer than %d items", s.minItems)
+	}
+	if s.maxItems >= 0 && n > s.maxItems {
+		return sv.errorf("array has more than %d items", s.maxItems)
+	}
+	return nil
+}
+
+// member returns the schema of an object member, or an error when
+// additional properties are not allowed
+func (sv *schemaValidator) member(s *jsonSchema, key string) (*jsonSchema, error) {
+	if p, ok := s.properties[key]; ok {
+		return p, nil
+	}
+	if s.noAdditional {
+		return nil, sv.errorf("additional property %q is not allowed", key)
+	}
+	return s.additionalProperties, nil
+}
+
+// value validates a decoded value
+func (sv *schemaValidator) value(s *jsonSchema, v interface{}) error {
+	if s == nil {
+		return nil
+	}
+	if s.never {
+		return sv.errorf("no value is allowed")
+	}
+	if s.enum != nil && !containsValue(s.enum, v) {
+		return sv.errorf("value is not one of the enum")
+	}
+	switch v := v.(type) {
+	case nil:
+		return sv.checkType(s, "null")
+	case bool:
+		return sv.checkType(s, "boolean")
+	case float64:
+		return sv.checkNumber(s, v)
+	case string:
+		return sv.checkString(s, []byte(v))
+	case []interface{}:
+		if err := sv.checkType(s, "array"); err != nil {
+			return err
+		}
+		if err := sv.checkItems(s, len(v)); err != nil {
+			return err
+		}
+		for i, e := range v {
+			sv.pushIndex(i)
+			if err := sv.value(s.items, e); err != nil {
+				return err
+			}
+			sv.pop()
+		}
+		return nil
+	case map[string]interface{}:
+		if err := sv.checkType(s, "object"); err != nil {
+			return err
+		}
+		for _, r := range s.required {
+			if _, ok := v[r]; !ok {
+				return sv.errorf("required property %q is missing", r)
+			}
+		}
+		for k, e := range v {
+			p, err := sv.member(s, k)
+			if err != nil {
+				return err
+			}
+			sv.push(k)
+			if err := sv.value(p, e); err != nil {
+				return err
+			}
+			sv.pop()
+		}
+		return nil
+	}
+	return sv.errorf("unexpected %T", v)
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, e := range values {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// parse validates the value at the decoder's position while parsing it,
+// without building it, and stops at the first violation. Every
+// occurrence of a duplicated key is checked, where decoding would only
+// keep the last one.
+func (sv *schemaValidator) parse(s *jsonSchema) error {
+	d := sv.d
+	if s == nil {
+		return d.Skip()
+	}
+	if s.never {
+		return sv.errorf("no value is allowed")
+	}
+	if s.enum != nil {
+		v, err := d.Value()
+		if err != nil {
+			return err
+		}
+		return sv.value(s, v)
+	}
+	c := d.Peek()
+	if c == 0 {
+		return d.Errorf("unexpected end of input")
+	}
+	switch {
+	case c == '{':
+		if err := sv.checkType(s, "object"); err != nil {
+			return err
+		}
+		var seen []bool
+		if len(s.required) > 0 {
+			seen = make([]bool, len(s.required))
+		}
+		if err := d.Members(func(key []byte) error {
+			for i, r := range s.required {
+				if r == string(key) {
+					seen[i] = true
+				}
+			}
+			p, ok := s.properties[string(key)]
+			if !ok {
+				if s.noAdditional {
+					return sv.errorf("additional property %q is not allowed", key)
+				}
+				p = s.additionalProperties
+			}
+			if p == nil {
+				return d.Skip()
+			}
+			sv.pushBytes(key)
+			if err := sv.parse(p); err != nil {
+				return err
+			}
+			sv.pop()
+			return nil
+		}); err != nil {
+			return err
+		}
+		for i, ok := range seen {
+			if !ok {
+				return sv.errorf("required property %q is missing", s.required[i])
+			}
+		}
+		return nil
+	case c == '[':
+		if err := sv.checkType(s, "array"); err != nil {
+			return err
+		}
+		n := 0
+		if err := d.Elements(func() error {
+			if s.maxItems >= 0 && n >= s.maxItems {
+				return sv.errorf("array has more than %d items", s.maxItems)
+			}
+			sv.pushIndex(n)
+			n++
+			if err := sv.parse(s.items); err != nil {
+				return err
+			}
+			sv.pop()
+			return nil
+		}); err != nil {
+			return err
+		}
+		return sv.checkItems(s, n)
+	case c == '"':
+		str, err := d.StringBytes(nil)
+		if err != nil {
+			return err
+		}
+		return sv.checkString(s, str)
+	case c == 't' || c == 'f':
+		if err := sv.checkType(s, "boolean"); err != nil {
+			return err
+		}
+		return d.Skip()
+	case c == 'n':
+		if err := sv.checkType(s, "null"); err != nil {
+			return err
+		}
+		return d.Literal("null")
+	default:
+		v, err := d.Number()
+		if err != nil {
+			return err
+		}
+		return sv.checkNumber(s, v.(float64))
+	}
+}
+
+// validateWhileParsing checks that data is JSON that s accepts in one
+// pass, the way a gateway can reject a request before decoding it
+func validateWhileParsing(s *jsonSchema, data []byte) error {
+	d := backends.NewDecoder(data, backends.DecodeOptions{})
+	sv := &schemaValidator{d: d}
+	if err := sv.parse(s); err != nil {
+		return err
+	}
+	return d.End()
+}
+
+// validateDecoded checks a value decoded by any backend
+func validateDecoded(s *jsonSchema, v interface{}) error {
+	return (&schemaValidator{}).value(s, v)
+}
+
+var lastStringMember = regexp.MustCompile(`":\s*"`)
+
+// jsonSchemaFlags are the flags of jsonschema
+type jsonSchemaFlags struct {
+	file        *string
+	schemaFile  *string
+	iterations  *int
+	printSchema *bool
+}
+
+func (opts *jsonSchemaFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("jsonschema")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to validate")
+	opts.schemaFile = fs.String("schema", "", "JSON Schema to validate against (default one generated from -file)")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	opts.printSchema = fs.Bool("print", false, "print the schema and exit")
+	return fs
+}
+
+// runJSONSchema compares validating twitter.json while parsing it with
+// decoding it first and validating the tree, on the document and on a
+// copy that breaks the schema near its end
+func runJSONSchema(args []string) error {
+	var opts jsonSchemaFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	var schema *jsonSchema
+	if *opts.schemaFile == "" {
+		schema = inferSchema(doc)
+	} else {
+		raw, err := datasets.Read(*opts.schemaFile)
+		if err != nil {
+			return err
+		}
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%s: %w", *opts.schemaFile, err)
+		}
+		if schema, err = compileSchema(v); err != nil {
+			return fmt.Errorf("%s: %w", *opts.schemaFile, err)
+		}
+	}
+	if *opts.printSchema {
+		out, err := json.MarshalIndent(schema.generic(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	// The invalid copy turns the last member string in the document into
+	// a number
+	invalid := data
+	if m := lastStringMember.FindAllIndex(data, -1); m != nil {
+		start := m[len(m)-1][1] - 1
+		d := backends.NewDecoder(data[start:], backends.DecodeOptions{})
+		if _, err := d.String(); err == nil {
+			invalid = append(append(append([]byte{}, data[:start]...), '0'), data[start+d.Offset():]...)
+		}
+	}
+
+	methods := []struct {
+		name     string
+		validate func([]byte) error
+	}{
+		{"validate while parsing", func(b []byte) error { return validateWhileParsing(schema, b) }},
+		{"handrolled, then validate", func(b []byte) error {
+			v, err := backends.Handrolled{}.Decode(b)
+			if err != nil {
+				return err
+			}
+			return validateDecoded(schema, v)
+		}},
+		{"encoding/json, then validate", func(b []byte) error {
+			var v interface{}
+			if err := json.Unmarshal(b, &v); err != nil {
+				return err
+			}
+			return validateDecoded(schema, v)
+		}},
+	}
+	for _, doc := range []struct {
+		name  string
+		data  []byte
+		valid bool
+	}{{*opts.file, data, true}, {"invalid copy", invalid, false}} {
+		fmt.Printf("%s:\n", doc.name)
+		fmt.Printf("  %-30s %12s  %s\n", "method", "MB/s", "result")
+		for _, m := range methods {
+			err := m.validate(doc.data)
+			if (err == nil) != doc.valid {
+				return fmt.Errorf("%s: %s: expected valid=%v, got %v", m.name, doc.name, doc.valid, err)
+			}
+			speed, _ := bench.Measure(doc.data, *opts.iterations, func(b []byte) error {
+				m.validate(b)
+				return nil
+			})
+			result := "valid"
+			if err != nil {
+				result = err.Error()
+			}
+			fmt.Printf("  %-30s %12.2f  %s\n", m.name, speed, result)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// forEachImplementation makes every supported implementation of package
+// simd active in turn, best first, and calls fn with it; the one active
+// before is restored
+func forEachImplementation(fn func(impl simd.Implementation) error) error {
+	defer simd.Select(simd.Active().Name)
+	for _, impl := range simd.Implementations() {
+		if !impl.Supported {
+			continue
+		}
+		if err := simd.Select(impl.Name); err != nil {
+			return err
+		}
+		if err := fn(impl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kernel is one of the jobs of the kernels matrix, run over a whole
+// document with the active implementation; it returns a value that must
+// not depend on the implementation
+type kernel struct {
+	name string
+	run  func(data []byte) (int, error)
+}
+
+// matrixKernels returns the kernels of the matrix for a document, whose
+// whitespace gaps and string contents are found once, up front
+func matrixKernels(data []byte) ([]kernel, error) {
+	gaps, _, err := tokenGaps(data)
+	if err != nil {
+		return nil, err
+	}
+	lits, err := stringLiterals(data)
+	if err != nil {
+		return nil, err
+	}
+	idx := make([]uint32, 0, len(data)/4)
+	dst := make([]byte, len(data))
+	return []kernel{
+		{"structural index", func(data []byte) (int, error) {
+			got, err := scanner.Index(data, idx[:0])
+			return len(got), err
+		}},
+		{"UTF-8 validation", func(data []byte) (int, error) {
+			if !scanner.ValidUTF8(data) {
+				return 0, errors.New("invalid UTF-8")
+			}
+			return 0, nil
+		}},
+		{"string copy", func([]byte) (int, error) {
+			copyPlain := simd.CopyPlain
+			if copyPlain == nil {
+				copyPlain = copyPlainScalar
+			}
+			copied := 0
+			for _, lit := range lits {
+				// Up to each escape, which ends a run, then past it
+				for s := lit[1 : len(lit)-1]; len(s) > 0; {
+					n := copyPlain(dst, s)
+					copied += n
+					s = s[min(n+2, len(s)):]
+				}
+			}
+			return copied, nil
+		}},
+		{"whitespace skip", func(data []byte) (int, error) {
+			skip := simd.SkipWhitespace
+			if skip == nil {
+				skip = skipWhitespaceLoop
+			}
+			skipped := 0
+			for _, gap := range gaps {
+				skipped += skip(data[gap:])
+			}
+			return skipped, nil
+		}},
+	}, nil
+}
+
+// kernelsFlags are the flags of kernels
+type kernelsFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *kernelsFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("kernels")
+	opts.file = fs.String("file", "../twitter.json", "JSON document the kernels run over")
+	opts.iterations = fs.Int("n", 200, "number of iterations")
+	return fs
+}
+
+// Run every kernel with every supported implementation, from the vector
+// kernels through SWAR to the byte loops of the generic one, as a matrix
+// in GB/s of the document; the implementations must agree
+func runKernels(args []string) error {
+	var opts kernelsFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	kernels, err := matrixKernels(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	fmt.Printf("%s: %d bytes, active implementation %s\n\n", datasets.Name(*opts.file), len(data), simd.Active().Name)
+
+	var names []string
+	speeds := make([][]float64, len(kernels))
+	want := make([]int, len(kernels))
+	err = forEachImplementation(func(impl simd.Implementation) error {
+		names = append(names, impl.Name)
+		for k, kern := range kernels {
+			got, err := kern.run(data)
+			if err != nil {
+				return fmt.Errorf("%s, %s: %w", kern.name, impl.Name, withKind(errDataset, err))
+			}
+			if len(speeds[k]) > 0 && got != want[k] {
+				return withKind(errMismatch, fmt.Errorf("%s: %s gets %d, %s %d", kern.name, impl.Name, got, names[0], want[k]))
+			}
+			want[k] = got
+			speed, err := bench.Measure(data, *opts.iterations, func(data []byte) error {
+				_, err := kern.run(data)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("%s, %s: %w", kern.name, impl.Name, err)
+			}
+			speeds[k] = append(speeds[k], speed)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("| Kernel, GB/s | %s |\n", strings.Join(names, " | "))
+	fmt.Printf("|---|%s\n", strings.Repeat("---:|", len(names)))
+	for k, kern := range kernels {
+		fmt.Printf("| %s", kern.name)
+		for _, speed := range speeds[k] {
+			fmt.Printf(" | %.2f", speed/1000)
+		}
+		fmt.Println(" |")
+	}
+	// SWAR against generic is the speedup without assembly
+	if i, j := slices.Index(names, "SWAR"), slices.Index(names, "generic"); i >= 0 && j >= 0 {
+		var gains []string
+		for k, kern := range kernels {
+			gains = append(gains, fmt.Sprintf("%s %.1f×", kern.name, speeds[k][i]/speeds[k][j]))
+		}
+		fmt.Printf("\nSWAR over the byte loops: %s\n", strings.Join(gains, ", "))
+	}
+	return nil
+}
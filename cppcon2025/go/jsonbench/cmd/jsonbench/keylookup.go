@@ -0,0 +1,326 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// A decoder into a struct maps every key of an object to a field, or to
+// nothing, before it can parse the value. These are the strategies a
+// decoder generator can emit for that dispatch, on the TwitterUser field
+// set, timed on the keys of the user objects of twitter.json: of their
+// 40-odd members, TwitterUser reads 9.
+
+// userFields are the JSON names of TwitterUser, in field order
+var userFields = []string{"id", "name", "screen_name", "location", "description",
+	"followers_count", "friends_count", "verified", "statuses_count"}
+
+// lookupLinear compares the key with every name in turn
+func lookupLinear(key []byte) int {
+	for i, f := range userFields {
+		if string(key) == f {
+			return i
+		}
+	}
+	return -1
+}
+
+var userFieldMap = func() map[string]int {
+	m := make(map[string]int, len(userFields))
+	for i, f := range userFields {
+		m[f] = i
+	}
+	return m
+}()
+
+// lookupMap is what encoding/json does; converting the key for the lookup
+// does not allocate
+func lookupMap(key []byte) int {
+	if i, ok := userFieldMap[string(key)]; ok {
+		return i
+	}
+	return -1
+}
+
+// userFieldsSorted holds the indexes of userFields in name order
+var userFieldsSorted = func() []int {
+	idx := make([]int, len(userFields))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return userFields[idx[a]] < userFields[idx[b]] })
+	return idx
+}()
+
+func lookupBinary(key []byte) int {
+	lo, hi := 0, len(userFieldsSorted)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		switch f := userFields[userFieldsSorted[mid]]; {
+		case string(key) == f:
+			return userFieldsSorted[mid]
+		case string(key) < f:
+			hi = mid
+		default:
+			lo = mid + 1
+		}
+	}
+	return -1
+}
+
+// lookupSwitch is a switch on the string, which the compiler turns into
+// a binary search on length and contents
+func lookupSwitch(key []byte) int {
+	switch string(key) {
+	case "id":
+		return 0
+	case "name":
+		return 1
+	case "screen_name":
+		return 2
+	case "location":
+		return 3
+	case "description":
+		return 4
+	case "followers_count":
+		return 5
+	case "friends_count":
+		return 6
+	case "verified":
+		return 7
+	case "statuses_count":
+		return 8
+	}
+	return -1
+}
+
+// lookupLengthByte narrows the candidates by length, then by first byte,
+// which together tell the 9 names apart, and compares with one constant
+func lookupLengthByte(key []byte) int {
+	switch len(key) {
+	case 2:
+		if string(key) == "id" {
+			return 0
+		}
+	case 4:
+		if string(key) == "name" {
+			return 1
+		}
+	case 8:
+		switch key[0] {
+		case 'l':
+			if string(key) == "location" {
+				return 3
+			}
+		case 'v':
+			if string(key) == "verified" {
+				return 7
+			}
+		}
+	case 11:
+		switch key[0] {
+		case 's':
+			if string(key) == "screen_name" {
+				return 2
+			}
+		case 'd':
+			if string(key) == "description" {
+				return 4
+			}
+		}
+	case 13:
+		if string(key) == "friends_count" {
+			return 6
+		}
+	case 14:
+		if string(key) == "statuses_count" {
+			return 8
+		}
+	case 15:
+		if string(key) == "followers_count" {
+			return 5
+		}
+	}
+	return -1
+}
+
+// perfectHash maps each of its names to its own slot from the length and
+// the first and last bytes, with a multiplier found by trial; a lookup
+// hashes, loads the candidate and compares once, with a name that is not
+// a constant
+type perfectHash struct {
+	names      []string
+	multiplier uint32
+	shift      uint
+	slots      []int8
+}
+
+func (p *perfectHash) hash(key []byte) uint32 {
+	return (uint32(key[0])<<16 | uint32(key[len(key)-1])<<8 | uint32(len(key))) * p.multiplier >> p.shift
+}
+
+// newPerfectHash searches for a multiplier that spreads names over twice
+// as many slots without collisions, as a generator would do at build time
+func newPerfectHash(names []string) (*perfectHash, error) {
+	bits := uint(1)
+	for 1<<bits < 2*len(names) {
+		bits++
+	}
+	p := &perfectHash{names: names, shift: 32 - bits, slots: make([]int8, 1<<bits)}
+	for m := uint32(1); m < 1<<20; m += 2 {
+		p.multiplier = m
+		for i := range p.slots {
+			p.slots[i] = -1
+		}
+		ok := true
+		for i, name := range names {
+			if name == "" {
+				return nil, errors.New("perfect hash: empty name")
+			}
+			h := p.hash([]byte(name))
+			if p.slots[h] >= 0 {
+				ok = false
+				break
+			}
+			p.slots[h] = int8(i)
+		}
+		if ok {
+			return p, nil
+		}
+	}
+	return nil, errors.New("perfect hash: no multiplier found")
+}
+
+func (p *perfectHash) lookup(key []byte) int {
+	if len(key) == 0 {
+		return -1
+	}
+	i := p.slots[p.hash(key)]
+	if i < 0 || string(key) != p.names[i] {
+		return -1
+	}
+	return int(i)
+}
+
+// collectUserKeys returns the keys of every "user" object of a document,
+// in order
+func collectUserKeys(data []byte) ([][]byte, error) {
+	d := backends.NewDecoder(data, backends.DecodeOptions{})
+	var keys [][]byte
+	var walk func(user bool) error
+	walk = func(user bool) error {
+		switch d.Peek() {
+		case '{':
+			return d.Members(func(key []byte) error {
+				if user {
+					keys = append(keys, append([]byte(nil), key...))
+				}
+				return walk(string(key) == "user")
+			})
+		case '[':
+			return d.Elements(func() error { return walk(false) })
+		}
+		return d.Skip()
+	}
+	if err := walk(false); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// keyLookupFlags are the flags of keylookup
+type keyLookupFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *keyLookupFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("keylookup")
+	opts.file = fs.String("file", "../twitter.json", "document whose user objects provide the keys")
+	opts.iterations = fs.Int("n", 2000, "number of iterations")
+	return fs
+}
+
+func runKeyLookup(args []string) error {
+	var opts keyLookupFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	keys, err := collectUserKeys(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("%s: no user objects", *opts.file)
+	}
+	ph, err := newPerfectHash(userFields)
+	if err != nil {
+		return err
+	}
+	strategies := []struct {
+		name   string
+		lookup func([]byte) int
+	}{
+		{"linear scan", lookupLinear},
+		{"map", lookupMap},
+		{"sorted binary search", lookupBinary},
+		{"switch on the string", lookupSwitch},
+		{"switch on length, first byte", lookupLengthByte},
+		{"perfect hash", ph.lookup},
+	}
+
+	var hits [][]byte
+	distinct := map[string]bool{}
+	for _, k := range keys {
+		distinct[string(k)] = true
+		if lookupMap(k) >= 0 {
+			hits = append(hits, k)
+		}
+	}
+	for k := range distinct {
+		want := lookupMap([]byte(k))
+		for _, s := range strategies {
+			if got := s.lookup([]byte(k)); got != want {
+				return fmt.Errorf("%s finds %q at %d, want %d", s.name, k, got, want)
+			}
+		}
+	}
+
+	// timeKeys returns the time per key and the number of fields found,
+	// which keeps the lookups from being optimized away
+	timeKeys := func(lookup func([]byte) int, keys [][]byte) (float64, int) {
+		found := 0
+		start := time.Now()
+		for it := 0; it < *opts.iterations; it++ {
+			for _, k := range keys {
+				if lookup(k) >= 0 {
+					found++
+				}
+			}
+		}
+		return time.Since(start).Seconds() * 1e9 / float64(len(keys)**opts.iterations), found
+	}
+	fmt.Printf("%s: %d keys in user objects (%d distinct), %d of them TwitterUser fields; perfect hash multiplier %#x over %d slots\n\n",
+		*opts.file, len(keys), len(distinct), len(hits), ph.multiplier, len(ph.slots))
+	fmt.Println("| Strategy | ns/key, every key | ns/key, fields only |")
+	fmt.Println("|---|---:|---:|")
+	for _, s := range strategies {
+		every, found := timeKeys(s.lookup, keys)
+		fields, foundFields := timeKeys(s.lookup, hits)
+		if found != foundFields {
+			return fmt.Errorf("%s: %d fields among every key, %d among the fields", s.name, found, foundFields)
+		}
+		fmt.Printf("| %s | %.2f | %.2f |\n", s.name, every, fields)
+	}
+	return nil
+}
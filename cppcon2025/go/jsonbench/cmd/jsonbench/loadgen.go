@@ -0,0 +1,123 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// printLatencyHistogram draws the latencies of r in the buckets of the
+// serve command's decode histogram
+func printLatencyHistogram(w io.Writer, r loadResult) {
+	counts := make([]int, len(latencyBuckets)+1)
+	for _, l := range r.latencies {
+		counts[sort.SearchFloat64s(latencyBuckets, l.Seconds())]++
+	}
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+	if max == 0 {
+		return
+	}
+	for i, n := range counts {
+		label := "> 1000 ms"
+		if i < len(latencyBuckets) {
+			label = fmt.Sprintf("<= %g ms", latencyBuckets[i]*1000)
+		}
+		bar := strings.Repeat("#", (n*report.BarChartWidth+max-1)/max)
+		fmt.Fprintf(w, "  %-11s |%-*s| %d\n", label, report.BarChartWidth, bar, n)
+	}
+}
+
+// loadgenFlags are the flags of loadgen
+type loadgenFlags struct {
+	url         *string
+	method      *string
+	bodyFile    *string
+	contentType *string
+	concurrency *int
+	rate        *float64
+	duration    *time.Duration
+}
+
+func (opts *loadgenFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("loadgen")
+	opts.url = fs.String("url", "", "endpoint to load (default a local decoding server, as in httpbench)")
+	opts.method = fs.String("method", "", "HTTP method (default POST with a body, GET without)")
+	opts.bodyFile = fs.String("body", "../twitter.json", "file sent as the request body (empty for none)")
+	opts.contentType = fs.String("content-type", "application/json", "Content-Type of the body")
+	opts.concurrency = fs.Int("c", 8, "number of concurrent connections")
+	opts.rate = fs.Float64("rate", 0, "requests per second in total (0 sends each request when the previous one is answered)")
+	opts.duration = fs.Duration("d", 10*time.Second, "how long to send requests")
+	return fs
+}
+
+// runLoadgen loads an HTTP endpoint, by default a local twitter.json
+// decoding server, and reports throughput, latency percentiles and a
+// latency histogram
+func runLoadgen(args []string) error {
+	var opts loadgenFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	var body []byte
+	if *opts.bodyFile != "" {
+		var err error
+		if body, err = datasets.Read(*opts.bodyFile); err != nil {
+			return err
+		}
+	}
+	if *opts.method == "" && body == nil {
+		*opts.method = http.MethodGet
+	}
+	if *opts.url == "" {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		server := &http.Server{Handler: decodeHandler(bodyDecoders[0].decode)}
+		go server.Serve(ln)
+		defer server.Close()
+		*opts.url = "http://" + ln.Addr().String() + "/"
+	}
+
+	r, err := generateLoad(loadConfig{
+		url:         *opts.url,
+		method:      *opts.method,
+		body:        body,
+		contentType: *opts.contentType,
+		concurrency: *opts.concurrency,
+		duration:    *opts.duration,
+		rate:        *opts.rate,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.url, err)
+	}
+	mode := "closed loop"
+	if *opts.rate > 0 {
+		mode = fmt.Sprintf("%g req/s scheduled", *opts.rate)
+	}
+	fmt.Printf("%s: %d connections, %s, %v\n\n", *opts.url, *opts.concurrency, mode, *opts.duration)
+	fmt.Println("| requests | errors | req/s | MB/s sent | p50 ms | p90 ms | p99 ms | p99.9 ms | max ms |")
+	fmt.Println("|---:|---:|---:|---:|---:|---:|---:|---:|---:|")
+	fmt.Printf("| %d | %d | %.0f | %.2f | %.3f | %.3f | %.3f | %.3f | %.3f |\n\n", r.requests, r.errors, r.perSecond(),
+		r.perSecond()*float64(len(body))/1e6, bench.Milliseconds(r.percentile(0.5)), bench.Milliseconds(r.percentile(0.9)),
+		bench.Milliseconds(r.percentile(0.99)), bench.Milliseconds(r.percentile(0.999)), bench.Milliseconds(r.percentile(1)))
+	printLatencyHistogram(os.Stdout, r)
+	return nil
+}
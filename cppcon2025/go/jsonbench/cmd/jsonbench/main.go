@@ -0,0 +1,228 @@
+// jsonbench gathers the Go JSON experiments used in the talk behind a
+// single binary with one subcommand per task and per experiment.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// command is one jsonbench subcommand. Its flags return a new flag set
+// of the command, which the command parses its arguments with and the
+// completion scripts and the man page list.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+	flags   func() *flag.FlagSet
+}
+
+// The main commands run, check and report the benchmark; the tools work
+// on results and builds; each experiment backs a section of the talk. The
+// tables are filled in init, since the flag sets of the commands look up
+// their summaries in them.
+var commands, tools, experiments []command
+
+func init() {
+	commands = []command{
+		{"bench", "benchmark every backend on one or more datasets", runBench, new(benchFlags).flags},
+		{"generate", "write a generated or scaled dataset", runGenerate, new(generateFlags).flags},
+		{"fetch", "download the benchmark datasets of the simdjson repository", runFetch, new(fetchFlags).flags},
+		{"verify", "check that every backend decodes and round trips the datasets like encoding/json", runVerify, new(verifyFlags).flags},
+		{"report", "render bench result files as an HTML page", runReport, new(reportFlags).flags},
+		{"inspect", "describe the structure of a document: depth, value counts, strings and numbers", runInspect, new(inspectFlags).flags},
+	}
+	tools = []command{
+		{"serve", "benchmark continuously and export Prometheus metrics", runServe, new(serveFlags).flags},
+		{"history", "summarize the trends in results.jsonl", runHistory, new(historyFlags).flags},
+		{"sweep", "benchmark copies of a dataset from 1 KB to 1 GB", runSweep, new(sweepFlags).flags},
+		{"aggregate", "combine Go, C++, Rust and Python results in one table", runAggregate, new(aggregateFlags).flags},
+		{"arch", "compare result files from different machines", runArch, new(archFlags).flags},
+		{"schema", "print the JSON Schema of the result files", runSchema, new(schemaFlags).flags},
+		{"run-all", "build and run every benchmark of the talks repo", runRunAll, new(runAllFlags).flags},
+		{"reproduce", "run bench in a pinned container for exact slide numbers", runReproduce, new(reproduceFlags).flags},
+		{"pgo", "report the speedup of a profile-guided build per backend", runPGO, new(pgoFlags).flags},
+		{"toolchains", "compare throughput across installed Go toolchains", runToolchains, new(toolchainsFlags).flags},
+		{"bce", "list the bounds checks left in the decoders and benchmark a build without them", runBCE, new(bceFlags).flags},
+		{"chart", "render results as an SVG chart for slides", runChart, new(chartFlags).flags},
+		{"completion", "print a bash, zsh or fish completion script", runCompletion, new(completionFlags).flags},
+		{"man", "print the jsonbench(1) man page", runMan, new(manFlags).flags},
+		{"snippets", "extract the // snippet: regions of the sources as highlighted HTML and SVG", runSnippets, new(snippetsFlags).flags},
+		{"notes", "turn a result file into speaker notes quoting its numbers", runNotes, new(notesFlags).flags},
+	}
+	experiments = []command{
+		{"cgo", "measure cgo call overhead and its amortization point", runCgo, new(cgoFlags).flags},
+		{"conformance", "run every backend over the JSONTestSuite corpus", runConformance, new(conformanceFlags).flags},
+		{"roundtrip", "check that decode/encode/decode is lossless", runRoundTrip, new(roundTripFlags).flags},
+		{"floats", "check that tricky doubles are correctly rounded", runFloats, new(floatsFlags).flags},
+		{"dupkeys", "report how each backend handles duplicate keys", runDupKeys, new(dupKeysFlags).flags},
+		{"depth", "report the nesting depth each backend accepts", runDepth, new(depthFlags).flags},
+		{"utf8", "benchmark the invalid UTF-8 handling modes", runUTF8, new(utf8Flags).flags},
+		{"canonical", "print or benchmark the RFC 8785 canonical form", runCanonical, new(canonicalFlags).flags},
+		{"formats", "compare JSON with binary formats on the typed data model", runFormats, new(formatsFlags).flags},
+		{"access", "read fields from a FlatBuffer in place versus decoding JSON", runAccess, new(accessFlags).flags},
+		{"parquet", "time converting twitter.json to a Parquet file, stage by stage", runParquet, new(parquetFlags).flags},
+		{"config", "compare loading a Player config from JSON, YAML and TOML", runConfig, new(configFlags).flags},
+		{"arrow", "decode twitter.json straight into Arrow columns versus via structs", runArrow, new(arrowFlags).flags},
+		{"tape", "index twitter.json once as a simdjson tape and traverse it", runTape, new(tapeFlags).flags},
+		{"httpbench", "load a local net/http server decoding POSTed JSON bodies", runHTTPBench, new(httpBenchFlags).flags},
+		{"download", "decode a large HTTP response while downloading versus after", runDownload, new(downloadFlags).flags},
+		{"proxy", "benchmark a proxy that transcodes JSON bodies per backend", runProxy, new(proxyFlags).flags},
+		{"websocket", "stream tweet-sized WebSocket messages to each backend", runWebSocket, new(webSocketFlags).flags},
+		{"loadgen", "load an HTTP endpoint and report latency percentiles and a histogram", runLoadgen, new(loadgenFlags).flags},
+		{"negotiate", "benchmark serving JSON, MessagePack or protobuf by Accept header", runNegotiate, new(negotiateFlags).flags},
+		{"marshal", "benchmark json.Marshal of responses under concurrency, with and without pooled buffers", runMarshal, new(marshalFlags).flags},
+		{"jsonrpc", "benchmark a JSON-RPC 2.0 service built on each backend", runJSONRPC, new(jsonrpcFlags).flags},
+		{"query", "run a jq-like expression over a document and time it", runQuery, new(queryFlags).flags},
+		{"jsonschema", "compare validating JSON Schema while parsing with parse-then-validate", runJSONSchema, new(jsonSchemaFlags).flags},
+		{"patch", "generate and apply RFC 6902 JSON Patches and time them", runPatch, new(patchFlags).flags},
+		{"merge", "apply and generate RFC 7386 JSON Merge Patches over a layered config", runMerge, new(mergeFlags).flags},
+		{"project", "redact a document to a whitelist of paths while streaming it", runProject, new(projectFlags).flags},
+		{"flatten", "flatten documents to dotted keys and back, and export records as CSV", runFlatten, new(flattenFlags).flags},
+		{"analyze", "aggregate twitter.json with full decoding and lazy extraction", runAnalyze, new(analyzeFlags).flags},
+		{"sql", "run a toy SQL query over JSON or NDJSON rows", runSQL, new(sqlFlags).flags},
+		{"ftoa", "benchmark formatting doubles with strconv and Ryu", runFtoa, new(ftoaFlags).flags},
+		{"atof", "benchmark parsing doubles with strconv and Eisel-Lemire", runAtof, new(atofFlags).flags},
+		{"atoi", "benchmark parsing the integer ids of twitter.json with strconv and SWAR", runAtoi, new(atoiFlags).flags},
+		{"timestamps", "benchmark parsing tweet created_at strings into time.Time", runTimestamps, new(timestampsFlags).flags},
+		{"base64", "compare []byte fields with string fields plus manual base64 decoding", runBase64, new(base64Flags).flags},
+		{"uuid", "compare UUID hydration into [16]byte inside and after decoding", runUUID, new(uuidFlags).flags},
+		{"unescape", "compare scalar and SIMD copying in JSON string unescaping", runUnescape, new(unescapeFlags).flags},
+		{"keylookup", "compare key-to-field dispatch strategies on the TwitterUser fields", runKeyLookup, new(keyLookupFlags).flags},
+		{"intern", "compare allocations decoding into maps with and without interned keys", runIntern, new(internFlags).flags},
+		{"whitespace", "benchmark skipping whitespace in minified and pretty-printed JSON", runWhitespace, new(whitespaceFlags).flags},
+		{"demo", "walk through read, validate, parse and extract stage by stage, for presenting on stage", runDemo, new(demoFlags).flags},
+		{"sidebyside", "run parse_twitter.go and its C++ simdjson counterpart split-screen", runSideBySide, new(sideBySideFlags).flags},
+		{"repl", "load a document once and evaluate paths on it interactively, with their latency", runREPL, new(replFlags).flags},
+		{"race", "race every backend at once on live-updating throughput bars", runRace, new(raceFlags).flags},
+		{"stages", "break the time of a parse down by stage, as a stacked bar", runStages, new(stagesFlags).flags},
+		{"scan", "benchmark the SIMD structural scanner of stage 1 in GB/s", runScan, new(scanFlags).flags},
+		{"kernels", "run every kernel with every implementation, SWAR included, as a matrix", runKernels, new(kernelsFlags).flags},
+		{"ondemand", "parse twitter.json with the On-Demand API, as parse_twitter.cpp does", runOnDemand, new(onDemandFlags).flags},
+		{"dom", "time building and traversing a tape DOM versus decoding into interface{}", runDOM, new(domFlags).flags},
+		{"batch", "parse thousands of tweet-sized documents with new and reused parsers", runBatch, new(batchFlags).flags},
+		{"prefetch", "check whether software prefetches ahead of the scanner help on a huge input", runPrefetch, new(prefetchFlags).flags},
+		{"unsafe", "time the hot loops with bounds-checked slices and with unsafe.Pointer", runUnsafe, new(unsafeFlags).flags},
+		{"validate", "check well-formedness only, in GB/s, as simdjson's validate benchmark does", runValidate, new(validateFlags).flags},
+		{"count", "count statuses and string bytes without decoding, by tokens and lazily", runCount, new(countFlags).flags},
+		{"skip", "time reading a few fields past the large subtrees each approach must skip", runSkip, new(skipFlags).flags},
+		{"giantstring", "decode one string of hundreds of MB with scattered escapes", runGiantString, new(giantStringFlags).flags},
+		{"ndjson", "stream NDJSON larger than memory through a worker pool, mapped a window at a time", runNDJSON, new(ndjsonFlags).flags},
+	}
+}
+
+// section is a group of commands in the usage and the man page
+type section struct {
+	title string
+	list  []command
+}
+
+func sections() []section {
+	return []section{{"commands", commands}, {"tools", tools}, {"experiments", experiments}}
+}
+
+// allCommands returns every command, in the order of the usage
+func allCommands() []command {
+	var all []command
+	for _, s := range sections() {
+		all = append(all, s.list...)
+	}
+	return all
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, c := range allCommands() {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// newFlagSet returns the flag set of a command; -h prints its usage, its
+// summary and its flags in the same layout for every command
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() { printUsage(fs) }
+	return fs
+}
+
+// operands are what the commands taking any expect after their flags
+var operands = map[string]string{
+	"aggregate":  "[language=]file...",
+	"arch":       "[machine=]file...",
+	"chart":      "file...",
+	"completion": "bash|zsh|fish",
+	"notes":      "results.json",
+	"query":      "'<expression>'",
+	"report":     "results.json...",
+	"snippets":   "[dir...]",
+	"sql":        "['<query>']",
+}
+
+// printUsage prints the help of the command of fs
+func printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "usage: jsonbench %s [flags]", fs.Name())
+	if o := operands[fs.Name()]; o != "" {
+		fmt.Fprint(w, " ", o)
+	}
+	fmt.Fprintln(w)
+	if c, ok := lookupCommand(fs.Name()); ok {
+		fmt.Fprintf(w, "\n%s\n", c.summary)
+	}
+	fmt.Fprintln(w, "\nflags:")
+	fs.PrintDefaults()
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: jsonbench <command> [flags]")
+	for _, section := range sections() {
+		fmt.Fprintf(os.Stderr, "\n%s:\n", section.title)
+		for _, c := range section.list {
+			fmt.Fprintf(os.Stderr, "  %-14s %s\n", c.name, c.summary)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "\nRun 'jsonbench help <command>' for the flags of a command.")
+	fmt.Fprintln(os.Stderr, "\nexit codes:")
+	for _, e := range exitCodes {
+		fmt.Fprintf(os.Stderr, "  %d  %s\n", e.code, e.meaning)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name, args := os.Args[1], os.Args[2:]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) == 0 {
+			usage()
+			return
+		}
+		// Every flag set prints its help and exits on -h
+		name, args = args[0], []string{"-h"}
+	}
+	c, ok := lookupCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		usage()
+		os.Exit(2)
+	}
+	// Like SIMDJSON_FORCE_IMPLEMENTATION, for comparing the kernels
+	if impl := os.Getenv("JSONBENCH_FORCE_IMPLEMENTATION"); impl != "" {
+		if err := simd.Select(impl); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(exitCode(withKind(errUnavailable, err)))
+		}
+	}
+	if err := c.run(args); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitCode(err))
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// roff escapes text for a man page line: backslashes, minus signs, and
+// a leading dot or quote that would read as a request
+func roff(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// isZeroDefault reports whether the default of f is the zero value,
+// which PrintDefaults leaves out too
+func isZeroDefault(f *flag.Flag) bool {
+	switch f.DefValue {
+	case "", "0", "false", "0s":
+		return true
+	}
+	return false
+}
+
+func writeManPage(w io.Writer) {
+	fmt.Fprintln(w, `.TH JSONBENCH 1 "" "jsonbench" "simdjson talks"`)
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintln(w, `jsonbench \- the Go JSON experiments of the simdjson talks`)
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, ".B jsonbench")
+	fmt.Fprintln(w, ".I command")
+	fmt.Fprintln(w, `.RI [ flags ] " " [ operands ]`)
+	fmt.Fprintln(w, ".br")
+	fmt.Fprintln(w, ".B jsonbench help")
+	fmt.Fprintln(w, ".RI [ command ]")
+	fmt.Fprintln(w, ".SH DESCRIPTION")
+	fmt.Fprintln(w, "jsonbench gathers the Go JSON experiments of the talk behind one binary:")
+	fmt.Fprintln(w, "the benchmark of every backend, the tools working on its results and one")
+	fmt.Fprintln(w, "command per experiment. Commands run from the jsonbench directory, so that")
+	fmt.Fprintln(w, "the default")
+	fmt.Fprintln(w, ".I ../twitter.json")
+	fmt.Fprintln(w, "paths resolve;")
+	fmt.Fprintln(w, ".B \\-file \\-")
+	fmt.Fprintln(w, "reads a document from standard input, and gzipped documents are decompressed.")
+	for _, section := range sections() {
+		fmt.Fprintf(w, ".SH %s\n", strings.ToUpper(section.title))
+		for _, c := range section.list {
+			fmt.Fprintln(w, ".SS", roff(strings.TrimSpace(c.name+" "+operands[c.name])))
+			fmt.Fprintln(w, roff(c.summary)+".")
+			for _, f := range flagList(c.flags()) {
+				name, usage := flag.UnquoteUsage(f)
+				fmt.Fprintln(w, ".TP")
+				if name != "" {
+					fmt.Fprintf(w, ".BI \\-%s \" %s\"\n", roff(f.Name), roff(name))
+				} else {
+					fmt.Fprintf(w, ".B \\-%s\n", roff(f.Name))
+				}
+				switch {
+				case isZeroDefault(f):
+				case name == "string":
+					usage += fmt.Sprintf(" (default %q)", f.DefValue)
+				default:
+					usage += fmt.Sprintf(" (default %s)", f.DefValue)
+				}
+				fmt.Fprintln(w, roff(usage))
+			}
+		}
+	}
+	fmt.Fprintln(w, ".SH EXIT STATUS")
+	for _, e := range exitCodes {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, ".B %d\n", e.code)
+		fmt.Fprintln(w, roff(e.meaning))
+	}
+	fmt.Fprintln(w, ".SH SEE ALSO")
+	fmt.Fprintln(w, "The README.md of the jsonbench directory, which describes each command in")
+	fmt.Fprintln(w, "detail, and https://github.com/simdjson/simdjson_talks.")
+}
+
+// manFlags are the flags of man
+type manFlags struct {
+	out *string
+}
+
+func (opts *manFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("man")
+	opts.out = fs.String("o", "", "write the page to this file instead of stdout, e.g. jsonbench.1")
+	return fs
+}
+
+// Print the man page, built like the completions from the command tables
+// and the flag set of every command
+func runMan(args []string) error {
+	var opts manFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	if *opts.out == "" {
+		writeManPage(os.Stdout)
+		return nil
+	}
+	f, err := os.Create(*opts.out)
+	if err != nil {
+		return err
+	}
+	writeManPage(f)
+	return f.Close()
+}
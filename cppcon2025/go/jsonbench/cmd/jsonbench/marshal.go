@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// marshalMethod encodes v and hands the bytes to w, as a handler writing
+// a response would
+type marshalMethod struct {
+	name   string
+	encode func(w io.Writer, v interface{}) error
+}
+
+var marshalBuffers = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+var marshalMethods = []marshalMethod{
+	{"json.Marshal", func(w io.Writer, v interface{}) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}},
+	{"pooled buffer", func(w io.Writer, v interface{}) error {
+		buf := marshalBuffers.Get().(*bytes.Buffer)
+		buf.Reset()
+		err := json.NewEncoder(buf).Encode(v)
+		if err == nil {
+			_, err = w.Write(buf.Bytes())
+		}
+		marshalBuffers.Put(buf)
+		return err
+	}},
+}
+
+// marshalResult is one method at one level of concurrency
+type marshalResult struct {
+	perSecond  float64
+	mbPerSec   float64
+	allocBytes float64 // per response
+	allocs     float64 // per response
+	gcs        uint32
+}
+
+// marshalConcurrently encodes total responses from goroutines goroutines,
+// taking the payloads round-robin
+func marshalConcurrently(m marshalMethod, payloads []interface{}, goroutines, total int) (marshalResult, error) {
+	var before, after runtime.MemStats
+	var mu sync.Mutex
+	var firstErr error
+	var written int64
+	var wg sync.WaitGroup
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for g := 0; g < goroutines; g++ {
+		n := total / goroutines
+		if g < total%goroutines {
+			n++
+		}
+		wg.Add(1)
+		go func(g, n int) {
+			defer wg.Done()
+			w := &countingWriter{}
+			var err error
+			for i := 0; i < n && err == nil; i++ {
+				err = m.encode(w, payloads[(g+i*goroutines)%len(payloads)])
+			}
+			mu.Lock()
+			written += w.n
+			if firstErr == nil {
+				firstErr = err
+			}
+			mu.Unlock()
+		}(g, n)
+	}
+	wg.Wait()
+	seconds := time.Since(start).Seconds()
+	runtime.ReadMemStats(&after)
+	if firstErr != nil {
+		return marshalResult{}, firstErr
+	}
+	return marshalResult{
+		perSecond:  float64(total) / seconds,
+		mbPerSec:   float64(written) / 1e6 / seconds,
+		allocBytes: float64(after.TotalAlloc-before.TotalAlloc) / float64(total),
+		allocs:     float64(after.Mallocs-before.Mallocs) / float64(total),
+		gcs:        after.NumGC - before.NumGC,
+	}, nil
+}
+
+// countingWriter stands in for a connection: it counts and drops the bytes
+type countingWriter struct{ n int64 }
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// marshalFlags are the flags of marshal
+type marshalFlags struct {
+	file   *string
+	levels *string
+	total  *int
+	whole  *bool
+}
+
+func (opts *marshalFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("marshal")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document whose statuses are the responses")
+	opts.levels = fs.String("g", "1,4,16,64,256,1024", "comma-separated numbers of goroutines")
+	opts.total = fs.Int("n", 100000, "responses to encode at each level")
+	opts.whole = fs.Bool("whole", false, "respond with the whole document instead of one status")
+	return fs
+}
+
+// runMarshal measures json.Marshal of response payloads from many
+// goroutines at once, against encoding into pooled buffers
+func runMarshal(args []string) error {
+	var opts marshalFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var twitter TwitterData
+	if err := json.Unmarshal(data, &twitter); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	var payloads []interface{}
+	if *opts.whole {
+		payloads = append(payloads, &twitter)
+	} else {
+		for i := range twitter.Statuses {
+			payloads = append(payloads, &twitter.Statuses[i])
+		}
+	}
+	if len(payloads) == 0 {
+		return fmt.Errorf("%s: no statuses", *opts.file)
+	}
+
+	fmt.Printf("%s: %d responses per level, GOMAXPROCS %d\n\n", *opts.file, *opts.total, runtime.GOMAXPROCS(0))
+	fmt.Println("| goroutines | method | responses/s | MB/s | B/response | allocs/response | GCs |")
+	fmt.Println("|---:|---|---:|---:|---:|---:|---:|")
+	for _, level := range strings.Split(*opts.levels, ",") {
+		goroutines, err := strconv.Atoi(level)
+		if err != nil || goroutines < 1 {
+			return fmt.Errorf("-g: bad number of goroutines %q", level)
+		}
+		for _, m := range marshalMethods {
+			r, err := marshalConcurrently(m, payloads, goroutines, *opts.total)
+			if err != nil {
+				return fmt.Errorf("%s: %w", m.name, err)
+			}
+			fmt.Printf("| %d | %s | %.0f | %.2f | %.0f | %.1f | %d |\n", goroutines, m.name, r.perSecond, r.mbPerSec, r.allocBytes, r.allocs, r.gcs)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+)
+
+// JSON Merge Patch (RFC 7386) over the generic representation: a patch
+// is a partial document, null deletes a member and anything that is not
+// an object replaces the target wholesale
+
+// applyMergePatch merges patch into target and returns the result.
+// Objects of target are updated in place; the values taken from patch
+// are copied, so the patch can be reused
+func applyMergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return deepCopy(patch)
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{}, len(p))
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = applyMergePatch(t[k], v)
+	}
+	return t
+}
+
+// diffMergePatch generates a merge patch that turns a into b: removed
+// members become null, objects are diffed recursively and anything else
+// that changed is replaced. A merge patch cannot set a member to null,
+// so b must not hold null members
+func diffMergePatch(a, b interface{}) (interface{}, error) {
+	bm, ok := b.(map[string]interface{})
+	if !ok {
+		return b, nil
+	}
+	am, ok := a.(map[string]interface{})
+	if !ok {
+		// The empty object, so that the whole of b is added
+		am = map[string]interface{}{}
+	}
+	patch := map[string]interface{}{}
+	for k := range am {
+		if _, ok := bm[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	keys := make([]string, 0, len(bm))
+	for k := range bm {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		bv := bm[k]
+		if bv == nil {
+			return nil, fmt.Errorf("member %q is null, which a merge patch cannot express", k)
+		}
+		av, inA := am[k]
+		if _, isMap := bv.(map[string]interface{}); isMap {
+			d, err := diffMergePatch(av, bv)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			if d, ok := d.(map[string]interface{}); ok && len(d) == 0 && inA {
+				continue
+			}
+			patch[k] = d
+			continue
+		}
+		if !inA || !reflect.DeepEqual(av, bv) {
+			patch[k] = bv
+		}
+	}
+	return patch, nil
+}
+
+// baseConfig is the configuration the merge command layers overlays on:
+// server settings, feature flags and n players keyed by username
+func baseConfig(n int, seed int64) interface{} {
+	players := map[string]interface{}{}
+	for _, p := range samplePlayers(n, seed) {
+		inventory := make([]interface{}, len(p.Inventory))
+		for i, item := range p.Inventory {
+			inventory[i] = item
+		}
+		players[p.Username] = map[string]interface{}{
+			"level":     float64(p.Level),
+			"health":    p.Health,
+			"inventory": inventory,
+		}
+	}
+	return map[string]interface{}{
+		"server": map[string]interface{}{
+			"listen":          ":8080",
+			"max_connections": 1024.0,
+			"timeouts":        map[string]interface{}{"read": "5s", "write": "5s", "idle": "60s"},
+		},
+		"features": map[string]interface{}{"pvp": false, "trading": true, "chat": true},
+		"players":  players,
+	}
+}
+
+// productionOverlay is the environment layer of the round trip: it
+// tunes the server, drops a feature, levels up every tenth player,
+// removes every fiftieth and adds a few
+func productionOverlay(n int) interface{} {
+	players := map[string]interface{}{}
+	for i := 0; i < n; i += 10 {
+		players[fmt.Sprintf("hero%d", i)] = map[string]interface{}{"level": float64(i%100 + 1)}
+	}
+	for i := 0; i < n; i += 50 {
+		players[fmt.Sprintf("hero%d", i)] = nil
+	}
+	for i := n; i < n+n/100+1; i++ {
+		players[fmt.Sprintf("hero%d", i)] = map[string]interface{}{
+			"level": 1.0, "health": 100.0, "inventory": []interface{}{"map"},
+		}
+	}
+	return map[string]interface{}{
+		"server": map[string]interface{}{
+			"listen":          ":443",
+			"max_connections": 65536.0,
+			"timeouts":        map[string]interface{}{"idle": "300s"},
+			"tls":             map[string]interface{}{"cert": "/etc/tls/cert.pem", "key": "/etc/tls/key.pem"},
+		},
+		"features": map[string]interface{}{"chat": nil, "pvp": true},
+		"players":  players,
+	}
+}
+
+// benchmarkMergePatches are n small overlays of the kind a config
+// service pushes: each changes one player's level and inventory, flips a
+// feature flag and adjusts the server
+func benchmarkMergePatches(players, n int) ([][]byte, error) {
+	patches := make([][]byte, n)
+	for i := range patches {
+		p := map[string]interface{}{
+			"players": map[string]interface{}{
+				fmt.Sprintf("hero%d", i%players): map[string]interface{}{
+					"level":     float64(i % 100),
+					"inventory": []interface{}{"sword", fmt.Sprintf("item%d", i)},
+				},
+			},
+			"features": map[string]interface{}{"pvp": i%2 == 0},
+			"server":   map[string]interface{}{"max_connections": float64(1024 + i%1024)},
+		}
+		var err error
+		if patches[i], err = json.Marshal(p); err != nil {
+			return nil, err
+		}
+	}
+	return patches, nil
+}
+
+// mergeFlags are the flags of merge
+type mergeFlags struct {
+	players    *int
+	seed       *int64
+	n          *int
+	printPatch *bool
+}
+
+func (opts *mergeFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("merge")
+	opts.players = fs.Int("players", 1000, "number of players in the base configuration")
+	opts.seed = fs.Int64("seed", 1, "seed of the players")
+	opts.n = fs.Int("n", 100000, "number of small merge patches to apply")
+	opts.printPatch = fs.Bool("print", false, "print the generated merge patch and exit")
+	return fs
+}
+
+func runMerge(args []string) error {
+	var opts mergeFlags
+	fs := opts.flags()
+	fs.Parse(args)
+	if *opts.players < 1 {
+		return errors.New("merge: -players must be at least 1")
+	}
+
+	base := baseConfig(*opts.players, *opts.seed)
+	start := time.Now()
+	merged := applyMergePatch(deepCopy(base), productionOverlay(*opts.players))
+	apply := time.Since(start)
+	start = time.Now()
+	patch, err := diffMergePatch(base, merged)
+	generate := time.Since(start)
+	if err != nil {
+		return fmt.Errorf("merge: %w", err)
+	}
+	encoded, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	if *opts.printPatch {
+		fmt.Println(string(encoded))
+		return nil
+	}
+	if !reflect.DeepEqual(applyMergePatch(deepCopy(base), patch), merged) {
+		return withKind(errMismatch, errors.New("merge: applying the generated merge patch does not give the merged configuration"))
+	}
+	fmt.Printf("%d players: production overlay applied in %.2f ms, merge patch back (%d bytes) generated in %.2f ms\n",
+		*opts.players, bench.Milliseconds(apply), len(encoded), bench.Milliseconds(generate))
+
+	patches, err := benchmarkMergePatches(*opts.players, *opts.n)
+	if err != nil {
+		return err
+	}
+	var parseTime, applyTime time.Duration
+	target := deepCopy(base)
+	for _, p := range patches {
+		t := time.Now()
+		var v interface{}
+		if err := json.Unmarshal(p, &v); err != nil {
+			return err
+		}
+		t2 := time.Now()
+		target = applyMergePatch(target, v)
+		parseTime += t2.Sub(t)
+		applyTime += time.Since(t2)
+	}
+	total := (parseTime + applyTime).Seconds()
+	fmt.Printf("%d merge patches: %.0f patches/s (parse %.2f µs, apply %.2f µs per patch)\n",
+		len(patches), float64(len(patches))/total,
+		parseTime.Seconds()*1e6/float64(len(patches)), applyTime.Seconds()*1e6/float64(len(patches)))
+	return nil
+}
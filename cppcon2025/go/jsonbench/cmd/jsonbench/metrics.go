@@ -0,0 +1,170 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the decode latency
+// histogram
+var latencyBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// backendMetrics accumulates what the exporter knows about one backend
+type backendMetrics struct {
+	mbPerSec        float64
+	bytesPerDecode  float64
+	allocsPerDecode float64
+	decodes         uint64
+	errors          uint64
+	bucketCounts    []uint64 // cumulative counts are computed when writing
+	latencySum      float64
+}
+
+// exporter holds the metrics served on /metrics
+type exporter struct {
+	mu       sync.Mutex
+	dataset  string
+	backends map[string]*backendMetrics
+}
+
+// observe runs one round of iterations with b and records the results
+func (e *exporter) observe(b backends.Backend, data []byte, iterations int) {
+	var before, after runtime.MemStats
+	latencies := make([]float64, 0, iterations)
+	errors := 0
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		t := time.Now()
+		if _, err := b.Decode(data); err != nil {
+			errors++
+		}
+		latencies = append(latencies, time.Since(t).Seconds())
+	}
+	elapsed := time.Since(start).Seconds()
+	runtime.ReadMemStats(&after)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	m, ok := e.backends[b.Name()]
+	if !ok {
+		m = &backendMetrics{bucketCounts: make([]uint64, len(latencyBuckets)+1)}
+		e.backends[b.Name()] = m
+	}
+	m.mbPerSec = float64(len(data)) * float64(iterations) / 1e6 / elapsed
+	m.bytesPerDecode = float64(after.TotalAlloc-before.TotalAlloc) / float64(iterations)
+	m.allocsPerDecode = float64(after.Mallocs-before.Mallocs) / float64(iterations)
+	m.decodes += uint64(iterations)
+	m.errors += uint64(errors)
+	for _, l := range latencies {
+		i := sort.SearchFloat64s(latencyBuckets, l)
+		m.bucketCounts[i]++
+		m.latencySum += l
+	}
+}
+
+// writeMetrics writes the Prometheus text exposition format
+func (e *exporter) writeMetrics(w io.Writer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	names := make([]string, 0, len(e.backends))
+	for name := range e.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	gauge := func(name, help string, value func(*backendMetrics) float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, n := range names {
+			fmt.Fprintf(w, "%s{backend=%q,dataset=%q} %g\n", name, n, e.dataset, value(e.backends[n]))
+		}
+	}
+	counter := func(name, help string, value func(*backendMetrics) uint64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, n := range names {
+			fmt.Fprintf(w, "%s{backend=%q,dataset=%q} %d\n", name, n, e.dataset, value(e.backends[n]))
+		}
+	}
+	gauge("jsonbench_throughput_megabytes_per_second", "Decoding throughput of the last round.",
+		func(m *backendMetrics) float64 { return m.mbPerSec })
+	gauge("jsonbench_allocated_bytes_per_decode", "Bytes allocated per decode in the last round.",
+		func(m *backendMetrics) float64 { return m.bytesPerDecode })
+	gauge("jsonbench_allocations_per_decode", "Heap allocations per decode in the last round.",
+		func(m *backendMetrics) float64 { return m.allocsPerDecode })
+	counter("jsonbench_decodes_total", "Documents decoded.",
+		func(m *backendMetrics) uint64 { return m.decodes })
+	counter("jsonbench_decode_errors_total", "Decodes that returned an error.",
+		func(m *backendMetrics) uint64 { return m.errors })
+
+	const hist = "jsonbench_decode_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Time to decode one document.\n# TYPE %s histogram\n", hist, hist)
+	for _, n := range names {
+		m := e.backends[n]
+		var cumulative uint64
+		for i, le := range latencyBuckets {
+			cumulative += m.bucketCounts[i]
+			fmt.Fprintf(w, "%s_bucket{backend=%q,dataset=%q,le=\"%g\"} %d\n", hist, n, e.dataset, le, cumulative)
+		}
+		cumulative += m.bucketCounts[len(latencyBuckets)]
+		fmt.Fprintf(w, "%s_bucket{backend=%q,dataset=%q,le=\"+Inf\"} %d\n", hist, n, e.dataset, cumulative)
+		fmt.Fprintf(w, "%s_sum{backend=%q,dataset=%q} %g\n", hist, n, e.dataset, m.latencySum)
+		fmt.Fprintf(w, "%s_count{backend=%q,dataset=%q} %d\n", hist, n, e.dataset, cumulative)
+	}
+}
+
+// serveFlags are the flags of serve
+type serveFlags struct {
+	file       *string
+	addr       *string
+	iterations *int
+	interval   *time.Duration
+}
+
+func (opts *serveFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("serve")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to decode")
+	opts.addr = fs.String("addr", ":9090", "address to serve /metrics on")
+	opts.iterations = fs.Int("n", 20, "decodes per backend per round")
+	opts.interval = fs.Duration("interval", time.Second, "pause between rounds")
+	return fs
+}
+
+// Benchmark every backend in a loop and expose the results on /metrics for
+// Prometheus, so a Grafana dashboard can follow the run live
+func runServe(args []string) error {
+	var opts serveFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	e := &exporter{dataset: datasets.Name(*opts.file), backends: map[string]*backendMetrics{}}
+	go func() {
+		for {
+			for _, b := range backends.All() {
+				e.observe(b, data, *opts.iterations)
+			}
+			time.Sleep(*opts.interval)
+		}
+	}()
+
+	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		e.writeMetrics(w)
+	})
+	log.Printf("serving metrics on http://%s/metrics", *opts.addr)
+	return http.ListenAndServe(*opts.addr, nil)
+}
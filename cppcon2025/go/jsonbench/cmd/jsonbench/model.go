@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// The typed data model of the talk: the tweet fields parse_twitter.go
+// extracts from twitter.json, and json.go's Player. The binary format
+// benchmarks encode and decode these instead of generic values. The bson,
+// yaml and toml tags give the other libraries the same field names as JSON.
+
+type TwitterUser struct {
+	ID             uint64 `json:"id" bson:"id"`
+	Name           string `json:"name" bson:"name"`
+	ScreenName     string `json:"screen_name" bson:"screen_name"`
+	Location       string `json:"location" bson:"location"`
+	Description    string `json:"description" bson:"description"`
+	FollowersCount uint64 `json:"followers_count" bson:"followers_count"`
+	FriendsCount   uint64 `json:"friends_count" bson:"friends_count"`
+	Verified       bool   `json:"verified" bson:"verified"`
+	StatusesCount  uint64 `json:"statuses_count" bson:"statuses_count"`
+}
+
+type Status struct {
+	User TwitterUser `json:"user" bson:"user"`
+}
+
+type TwitterData struct {
+	Statuses []Status `json:"statuses" bson:"statuses"`
+}
+
+// Player represents a player with their attributes
+type Player struct {
+	Username  string   `json:"username" bson:"username" yaml:"username" toml:"username"`
+	Level     int      `json:"level" bson:"level" yaml:"level" toml:"level"`
+	Health    float64  `json:"health" bson:"health" yaml:"health" toml:"health"`
+	Inventory []string `json:"inventory" bson:"inventory" yaml:"inventory" toml:"inventory"`
+}
+
+// Players is the document of the Player benchmark, a top-level array
+type Players []Player
+
+// samplePlayers returns n players generated from seed, the same ones on
+// every run and platform
+func samplePlayers(n int, seed int64) Players {
+	r := datasets.NewRand(seed)
+	items := []string{"sword", "shield", "potion", "bow", "arrows", "map", "lantern"}
+	players := make(Players, n)
+	for i := range players {
+		players[i] = Player{
+			Username:  fmt.Sprintf("hero%d", i),
+			Level:     1 + r.Intn(99),
+			Health:    float64(r.Intn(1000))/10 + 0.5,
+			Inventory: items[:1+r.Intn(len(items))],
+		}
+	}
+	return players
+}
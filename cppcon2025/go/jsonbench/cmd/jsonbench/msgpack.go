@@ -0,0 +1,646 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MessagePack (https://msgpack.org) encoding of the data model, written
+// by hand the way tinylib/msgp generates it: structs are maps keyed by
+// the JSON field names, and every type appends itself to a buffer.
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string { return "msgpack" }
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case *TwitterData:
+		return v.appendMsgpack(nil), nil
+	case *Players:
+		return v.appendMsgpack(nil), nil
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type %T", v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	r := &msgpackReader{data: data}
+	var err error
+	switch v := v.(type) {
+	case *TwitterData:
+		err = v.decodeMsgpack(r)
+	case *Players:
+		err = v.decodeMsgpack(r)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+	if err == nil && r.pos != len(data) {
+		err = fmt.Errorf("msgpack: %d bytes of trailing data", len(data)-r.pos)
+	}
+	return err
+}
+
+func init() {
+	registerCodec(msgpackCodec{})
+}
+
+func appendMsgpackMap(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+}
+
+// appendMsgpackArray writes an array header, or nil for a nil slice so
+// that decoding gives back nil
+func appendMsgpackArray(b []byte, n int, isNil bool) []byte {
+	switch {
+	case isNil:
+		return append(b, 0xc0)
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+// appendMsgpackUint uses the smallest encoding that holds u
+func appendMsgpackUint(b []byte, u uint64) []byte {
+	switch {
+	case u < 128:
+		return append(b, byte(u))
+	case u <= math.MaxUint8:
+		return append(b, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(u))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), u)
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return appendMsgpackUint(b, uint64(i))
+	case i >= -32:
+		return append(b, byte(i))
+	case i >= math.MinInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(i))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(i))
+}
+
+func appendMsgpackFloat(b []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(f))
+}
+
+func appendMsgpackBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+// appendMsgpackValue writes a generic value as decoded from JSON. Numbers
+// that are integers are written as such, the smaller encoding JSON to
+// MessagePack transcoders use; map keys are sorted, like encoding/json
+// writes them.
+func appendMsgpackValue(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		return appendMsgpackBool(b, v), nil
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 && !(v == 0 && math.Signbit(v)) {
+			return appendMsgpackInt(b, int64(v)), nil
+		}
+		return appendMsgpackFloat(b, v), nil
+	case string:
+		return appendMsgpackString(b, v), nil
+	case []interface{}:
+		b = appendMsgpackArray(b, len(v), false)
+		var err error
+		for _, e := range v {
+			if b, err = appendMsgpackValue(b, e); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = appendMsgpackMap(b, len(v))
+		var err error
+		for _, k := range keys {
+			b = appendMsgpackString(b, k)
+			if b, err = appendMsgpackValue(b, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type %T", v)
+}
+
+var errMsgpackShort = errors.New("msgpack: unexpected end of data")
+
+// msgpackReader decodes values from data in order
+type msgpackReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *msgpackReader) typeError(want string) error {
+	return fmt.Errorf("msgpack: expected %s, found 0x%02x at offset %d", want, r.data[r.pos], r.pos)
+}
+
+// take returns the next n bytes
+func (r *msgpackReader) take(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.pos < n {
+		return nil, errMsgpackShort
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *msgpackReader) byte() (byte, error) {
+	b, err := r.take(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// length reads a big-endian length of size 1, 2 or 4 bytes
+func (r *msgpackReader) length(size int) (int, error) {
+	b, err := r.take(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return int(b[0]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(b)), nil
+	}
+	return int(binary.BigEndian.Uint32(b)), nil
+}
+
+func (r *msgpackReader) mapHeader() (int, error) {
+	if r.pos >= len(r.data) {
+		return 0, errMsgpackShort
+	}
+	switch c := r.data[r.pos]; {
+	case c&0xf0 == 0x80:
+		r.pos++
+		return int(c & 0x0f), nil
+	case c == 0xde:
+		r.pos++
+		return r.length(2)
+	case c == 0xdf:
+		r.pos++
+		return r.length(4)
+	}
+	return 0, r.typeError("map")
+}
+
+// arrayHeader returns the array length, or -1 for nil. Every element takes
+// at least one byte, so longer arrays are rejected before allocating them.
+func (r *msgpackReader) arrayHeader() (int, error) {
+	if r.pos >= len(r.data) {
+		return 0, errMsgpackShort
+	}
+	var n int
+	var err error
+	switch c := r.data[r.pos]; {
+	case c == 0xc0:
+		r.pos++
+		return -1, nil
+	case c&0xf0 == 0x90:
+		r.pos++
+		n = int(c & 0x0f)
+	case c == 0xdc:
+		r.pos++
+		n, err = r.length(2)
+	case c == 0xdd:
+		r.pos++
+		n, err = r.length(4)
+	default:
+		return 0, r.typeError("array")
+	}
+	if err == nil && n > len(r.data)-r.pos {
+		err = errMsgpackShort
+	}
+	return n, err
+}
+
+// stringBytes returns the bytes of a string without copying them
+func (r *msgpackReader) stringBytes() ([]byte, error) {
+	if r.pos >= len(r.data) {
+		return nil, errMsgpackShort
+	}
+	var n int
+	var err error
+	switch c := r.data[r.pos]; {
+	case c&0xe0 == 0xa0:
+		r.pos++
+		n = int(c & 0x1f)
+	case c == 0xd9:
+		r.pos++
+		n, err = r.length(1)
+	case c == 0xda:
+		r.pos++
+		n, err = r.length(2)
+	case c == 0xdb:
+		r.pos++
+		n, err = r.length(4)
+	default:
+		return nil, r.typeError("string")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.take(n)
+}
+
+func (r *msgpackReader) string() (string, error) {
+	b, err := r.stringBytes()
+	return string(b), err
+}
+
+// int reads any integer encoding
+func (r *msgpackReader) int() (int64, error) {
+	c, err := r.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case c < 0x80:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	}
+	var size int
+	switch c {
+	case 0xcc, 0xd0:
+		size = 1
+	case 0xcd, 0xd1:
+		size = 2
+	case 0xce, 0xd2:
+		size = 4
+	case 0xcf, 0xd3:
+		size = 8
+	default:
+		r.pos--
+		return 0, r.typeError("integer")
+	}
+	b, err := r.take(size)
+	if err != nil {
+		return 0, err
+	}
+	switch c {
+	case 0xcc:
+		return int64(b[0]), nil
+	case 0xcd:
+		return int64(binary.BigEndian.Uint16(b)), nil
+	case 0xce:
+		return int64(binary.BigEndian.Uint32(b)), nil
+	case 0xcf:
+		return int64(binary.BigEndian.Uint64(b)), nil
+	case 0xd0:
+		return int64(int8(b[0])), nil
+	case 0xd1:
+		return int64(int16(binary.BigEndian.Uint16(b))), nil
+	case 0xd2:
+		return int64(int32(binary.BigEndian.Uint32(b))), nil
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+func (r *msgpackReader) uint() (uint64, error) {
+	start := r.pos
+	if r.pos < len(r.data) && r.data[r.pos] == 0xcf {
+		b, err := r.take(9)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(b[1:]), nil
+	}
+	i, err := r.int()
+	if err == nil && i < 0 {
+		r.pos = start
+		return 0, r.typeError("unsigned integer")
+	}
+	return uint64(i), err
+}
+
+func (r *msgpackReader) float() (float64, error) {
+	if r.pos >= len(r.data) {
+		return 0, errMsgpackShort
+	}
+	switch r.data[r.pos] {
+	case 0xcb:
+		b, err := r.take(9)
+		if err != nil {
+			return 0, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b[1:])), nil
+	case 0xca:
+		b, err := r.take(5)
+		if err != nil {
+			return 0, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b[1:]))), nil
+	}
+	i, err := r.int()
+	return float64(i), err
+}
+
+func (r *msgpackReader) bool() (bool, error) {
+	c, err := r.byte()
+	if err != nil {
+		return false, err
+	}
+	switch c {
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	}
+	r.pos--
+	return false, r.typeError("bool")
+}
+
+// skip steps over one value of any type, for unknown map keys
+func (r *msgpackReader) skip() error {
+	c, err := r.byte()
+	if err != nil {
+		return err
+	}
+	var n, elems int
+	switch {
+	case c < 0x80 || c >= 0xe0 || c == 0xc0 || c == 0xc2 || c == 0xc3:
+		return nil
+	case c&0xf0 == 0x80:
+		elems = 2 * int(c&0x0f)
+	case c&0xf0 == 0x90:
+		elems = int(c & 0x0f)
+	case c&0xe0 == 0xa0:
+		n = int(c & 0x1f)
+	case c == 0xcc || c == 0xd0:
+		n = 1
+	case c == 0xcd || c == 0xd1:
+		n = 2
+	case c == 0xce || c == 0xd2 || c == 0xca:
+		n = 4
+	case c == 0xcf || c == 0xd3 || c == 0xcb:
+		n = 8
+	case c >= 0xd4 && c <= 0xd8:
+		// fixext: a type byte and 1 to 16 bytes
+		n = 1 + 1<<(c-0xd4)
+	case c == 0xc4 || c == 0xd9:
+		n, err = r.length(1)
+	case c == 0xc5 || c == 0xda:
+		n, err = r.length(2)
+	case c == 0xc6 || c == 0xdb:
+		n, err = r.length(4)
+	case c == 0xc7:
+		n, err = r.length(1)
+		n++
+	case c == 0xc8:
+		n, err = r.length(2)
+		n++
+	case c == 0xc9:
+		n, err = r.length(4)
+		n++
+	case c == 0xdc:
+		elems, err = r.length(2)
+	case c == 0xdd:
+		elems, err = r.length(4)
+	case c == 0xde:
+		elems, err = r.length(2)
+		elems *= 2
+	case c == 0xdf:
+		elems, err = r.length(4)
+		elems *= 2
+	default:
+		r.pos--
+		return r.typeError("value")
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := r.take(n); err != nil {
+		return err
+	}
+	for i := 0; i < elems; i++ {
+		if err := r.skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *TwitterUser) appendMsgpack(b []byte) []byte {
+	b = appendMsgpackMap(b, 9)
+	b = appendMsgpackUint(appendMsgpackString(b, "id"), u.ID)
+	b = appendMsgpackString(appendMsgpackString(b, "name"), u.Name)
+	b = appendMsgpackString(appendMsgpackString(b, "screen_name"), u.ScreenName)
+	b = appendMsgpackString(appendMsgpackString(b, "location"), u.Location)
+	b = appendMsgpackString(appendMsgpackString(b, "description"), u.Description)
+	b = appendMsgpackUint(appendMsgpackString(b, "followers_count"), u.FollowersCount)
+	b = appendMsgpackUint(appendMsgpackString(b, "friends_count"), u.FriendsCount)
+	b = appendMsgpackBool(appendMsgpackString(b, "verified"), u.Verified)
+	return appendMsgpackUint(appendMsgpackString(b, "statuses_count"), u.StatusesCount)
+}
+
+func (u *TwitterUser) decodeMsgpack(r *msgpackReader) error {
+	n, err := r.mapHeader()
+	for i := 0; i < n && err == nil; i++ {
+		var key []byte
+		if key, err = r.stringBytes(); err != nil {
+			break
+		}
+		switch string(key) {
+		case "id":
+			u.ID, err = r.uint()
+		case "name":
+			u.Name, err = r.string()
+		case "screen_name":
+			u.ScreenName, err = r.string()
+		case "location":
+			u.Location, err = r.string()
+		case "description":
+			u.Description, err = r.string()
+		case "followers_count":
+			u.FollowersCount, err = r.uint()
+		case "friends_count":
+			u.FriendsCount, err = r.uint()
+		case "verified":
+			u.Verified, err = r.bool()
+		case "statuses_count":
+			u.StatusesCount, err = r.uint()
+		default:
+			err = r.skip()
+		}
+	}
+	return err
+}
+
+func (s *Status) appendMsgpack(b []byte) []byte {
+	b = appendMsgpackString(appendMsgpackMap(b, 1), "user")
+	return s.User.appendMsgpack(b)
+}
+
+func (s *Status) decodeMsgpack(r *msgpackReader) error {
+	n, err := r.mapHeader()
+	for i := 0; i < n && err == nil; i++ {
+		var key []byte
+		if key, err = r.stringBytes(); err != nil {
+			break
+		}
+		if string(key) == "user" {
+			err = s.User.decodeMsgpack(r)
+		} else {
+			err = r.skip()
+		}
+	}
+	return err
+}
+
+func (t *TwitterData) appendMsgpack(b []byte) []byte {
+	b = appendMsgpackString(appendMsgpackMap(b, 1), "statuses")
+	b = appendMsgpackArray(b, len(t.Statuses), t.Statuses == nil)
+	for i := range t.Statuses {
+		b = t.Statuses[i].appendMsgpack(b)
+	}
+	return b
+}
+
+func (t *TwitterData) decodeMsgpack(r *msgpackReader) error {
+	n, err := r.mapHeader()
+	for i := 0; i < n && err == nil; i++ {
+		var key []byte
+		if key, err = r.stringBytes(); err != nil {
+			break
+		}
+		if string(key) != "statuses" {
+			err = r.skip()
+			continue
+		}
+		var count int
+		if count, err = r.arrayHeader(); err != nil || count < 0 {
+			t.Statuses = nil
+			continue
+		}
+		t.Statuses = make([]Status, count)
+		for j := range t.Statuses {
+			if err = t.Statuses[j].decodeMsgpack(r); err != nil {
+				break
+			}
+		}
+	}
+	return err
+}
+
+func (p *Player) appendMsgpack(b []byte) []byte {
+	b = appendMsgpackMap(b, 4)
+	b = appendMsgpackString(appendMsgpackString(b, "username"), p.Username)
+	b = appendMsgpackInt(appendMsgpackString(b, "level"), int64(p.Level))
+	b = appendMsgpackFloat(appendMsgpackString(b, "health"), p.Health)
+	b = appendMsgpackString(b, "inventory")
+	b = appendMsgpackArray(b, len(p.Inventory), p.Inventory == nil)
+	for _, item := range p.Inventory {
+		b = appendMsgpackString(b, item)
+	}
+	return b
+}
+
+func (p *Player) decodeMsgpack(r *msgpackReader) error {
+	n, err := r.mapHeader()
+	for i := 0; i < n && err == nil; i++ {
+		var key []byte
+		if key, err = r.stringBytes(); err != nil {
+			break
+		}
+		switch string(key) {
+		case "username":
+			p.Username, err = r.string()
+		case "level":
+			var level int64
+			level, err = r.int()
+			p.Level = int(level)
+		case "health":
+			p.Health, err = r.float()
+		case "inventory":
+			var count int
+			if count, err = r.arrayHeader(); err != nil || count < 0 {
+				p.Inventory = nil
+				continue
+			}
+			p.Inventory = make([]string, count)
+			for j := range p.Inventory {
+				if p.Inventory[j], err = r.string(); err != nil {
+					break
+				}
+			}
+		default:
+			err = r.skip()
+		}
+	}
+	return err
+}
+
+func (ps *Players) appendMsgpack(b []byte) []byte {
+	b = appendMsgpackArray(b, len(*ps), *ps == nil)
+	for i := range *ps {
+		b = (*ps)[i].appendMsgpack(b)
+	}
+	return b
+}
+
+func (ps *Players) decodeMsgpack(r *msgpackReader) error {
+	count, err := r.arrayHeader()
+	if err != nil || count < 0 {
+		*ps = nil
+		return err
+	}
+	*ps = make(Players, count)
+	for i := range *ps {
+		if err := (*ps)[i].decodeMsgpack(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/dom"
+)
+
+// ndjsonParsers make the line parser of one worker, which can keep its
+// state from line to line
+var ndjsonParsers = map[string]func() func(line []byte) error{
+	"dom": func() func([]byte) error {
+		var p dom.Parser
+		return func(line []byte) error {
+			_, err := p.Parse(line)
+			return err
+		}
+	},
+	"handrolled": func() func([]byte) error {
+		return func(line []byte) error {
+			_, err := backends.Decode(line, backends.DecodeOptions{})
+			return err
+		}
+	},
+	"encoding/json": func() func([]byte) error {
+		return func(line []byte) error {
+			var v interface{}
+			return json.Unmarshal(line, &v)
+		}
+	},
+}
+
+// ndjsonPool parses NDJSON with a fixed set of workers, each with its own
+// parser; a window is cut at newlines into chunks that the workers take
+// from a channel, and process returns once all of them are parsed
+type ndjsonPool struct {
+	parsers []func([]byte) error
+	chunk   int
+	records int
+}
+
+func newNDJSONPool(workers, chunk int, parser func() func([]byte) error) *ndjsonPool {
+	p := &ndjsonPool{chunk: chunk}
+	for i := 0; i < workers; i++ {
+		p.parsers = append(p.parsers, parser())
+	}
+	return p
+}
+
+// process parses every line of window and returns the first error
+func (p *ndjsonPool) process(window []byte) error {
+	type chunk struct {
+		off  int
+		data []byte
+	}
+	chunks := make(chan chunk, len(p.parsers))
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for _, parse := range p.parsers {
+		wg.Add(1)
+		go func(parse func([]byte) error) {
+			defer wg.Done()
+			records := 0
+			for c := range chunks {
+				for off, data := c.off, c.data; len(data) > 0; {
+					line, rest, _ := bytes.Cut(data, []byte{'\n'})
+					if len(bytes.TrimSpace(line)) > 0 {
+						if err := parse(line); err != nil {
+							mu.Lock()
+							if firstErr == nil {
+								firstErr = fmt.Errorf("line at offset %d of the window: %w", off, err)
+							}
+							mu.Unlock()
+						}
+						records++
+					}
+					off, data = off+len(data)-len(rest), rest
+				}
+			}
+			mu.Lock()
+			p.records += records
+			mu.Unlock()
+		}(parse)
+	}
+	for off := 0; off < len(window); {
+		end := min(off+p.chunk, len(window))
+		if i := bytes.IndexByte(window[end:], '\n'); i >= 0 {
+			end += i + 1
+		} else {
+			end = len(window)
+		}
+		chunks <- chunk{off, window[off:end]}
+		off = end
+	}
+	close(chunks)
+	wg.Wait()
+	return firstErr
+}
+
+// writeNDJSON writes the records of the document at records, compacted
+// one per line and repeated, to a new temporary file of at least size
+// bytes
+func writeNDJSON(records string, size int) (string, error) {
+	data, err := datasets.Read(records)
+	if err != nil {
+		return "", err
+	}
+	lines, err := ndjsonStatuses(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", records, withKind(errDataset, err))
+	}
+	f, err := os.CreateTemp("", "jsonbench-*.ndjson")
+	if err != nil {
+		return "", err
+	}
+	w := bufio.NewWriterSize(f, 1<<20)
+	for written := 0; written < size; written += len(lines) {
+		w.Write(lines)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// ndjsonFlags are the flags of ndjson
+type ndjsonFlags struct {
+	file    *string
+	records *string
+	size    *string
+	window  *string
+	workers *int
+	parsers *string
+	modes   *string
+}
+
+func (opts *ndjsonFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("ndjson")
+	opts.file = fs.String("file", "", "NDJSON file to stream (default: one of -size generated from -records)")
+	opts.records = fs.String("records", "../twitter.json", "document whose records fill the generated file")
+	opts.size = fs.String("size", "1GB", "size of the generated file")
+	opts.window = fs.String("window", "64MB", "bytes mapped or read at a time")
+	opts.workers = fs.Int("workers", runtime.NumCPU(), "number of parsing workers")
+	opts.parsers = fs.String("parser", "dom", "comma-separated line parsers: dom, handrolled, encoding/json")
+	opts.modes = fs.String("read", "mmap,read", "comma-separated input modes: mmap, read")
+	return fs
+}
+
+// Stream an NDJSON file that can be larger than memory through a worker
+// pool, a window at a time, mapped or read, and report the sustained
+// throughput: over the whole file and in its slowest window
+func runNDJSON(args []string) error {
+	var opts ndjsonFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	w, err := datasets.ParseSize(*opts.window)
+	if err != nil || w <= 0 {
+		return withKind(errUsage, fmt.Errorf("-window %q: want a positive size", *opts.window))
+	}
+	if *opts.workers < 1 {
+		return withKind(errUsage, fmt.Errorf("-workers must be positive"))
+	}
+	for _, name := range strings.Split(*opts.parsers, ",") {
+		if ndjsonParsers[name] == nil {
+			return withKind(errUsage, fmt.Errorf("-parser: unknown parser %q", name))
+		}
+	}
+	for _, mode := range strings.Split(*opts.modes, ",") {
+		if mode != "mmap" && mode != "read" {
+			return withKind(errUsage, fmt.Errorf("-read: unknown mode %q", mode))
+		}
+	}
+	path := *opts.file
+	if path == "" {
+		n, err := datasets.ParseSize(*opts.size)
+		if err != nil || n <= 0 {
+			return withKind(errUsage, fmt.Errorf("-size %q: want a positive size", *opts.size))
+		}
+		if path, err = writeNDJSON(*opts.records, n); err != nil {
+			return err
+		}
+		defer os.Remove(path)
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d bytes, windows of %d bytes, %d workers\n\n", datasets.Name(path), st.Size(), w, *opts.workers)
+	fmt.Println("| Input | Parser | records | GB/s | slowest window GB/s | Go heap MB |")
+	fmt.Println("|---|---|---:|---:|---:|---:|")
+	for _, mode := range strings.Split(*opts.modes, ",") {
+		if mode == "mmap" && !datasets.MmapSupported {
+			fmt.Printf("| mmap | - | unsupported on %s |  |  |  |\n", runtime.GOOS)
+			continue
+		}
+		for _, name := range strings.Split(*opts.parsers, ",") {
+			// Chunks of a few hundred KB keep every worker busy inside a
+			// window
+			pool := newNDJSONPool(*opts.workers, max(w / *opts.workers / 16, 1<<16), ndjsonParsers[name])
+			// A window's time runs from the end of the previous one, so
+			// that it includes mapping or reading it
+			slowest := 0.0
+			start := time.Now()
+			last := start
+			err := datasets.Windows(path, w, mode == "mmap", func(window []byte) error {
+				if err := pool.process(window); err != nil {
+					return err
+				}
+				now := time.Now()
+				if speed := float64(len(window)) / now.Sub(last).Seconds() / 1e9; slowest == 0 || speed < slowest {
+					slowest = speed
+				}
+				last = now
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("%s, %s: %w", mode, name, err)
+			}
+			elapsed := time.Since(start).Seconds()
+			var ms runtime.MemStats
+			runtime.ReadMemStats(&ms)
+			fmt.Printf("| %s | %s | %d | %.2f | %.2f | %.0f |\n", mode, name, pool.records,
+				float64(st.Size())/elapsed/1e9, slowest, float64(ms.HeapSys)/1e6)
+		}
+	}
+	return nil
+}
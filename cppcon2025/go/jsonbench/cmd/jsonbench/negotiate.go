@@ -0,0 +1,229 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// offer is a format the negotiating middleware can answer in
+type offer struct {
+	codec      codec
+	mediaTypes []string // the first one is sent as the Content-Type
+
+	encodes int64
+	nanos   int64 // total encoding time
+}
+
+// negotiatedMediaTypes are the formats offered, in order of preference
+var negotiatedMediaTypes = []struct {
+	codec      string
+	mediaTypes []string
+}{
+	{"json", []string{"application/json"}},
+	{"msgpack", []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}},
+	{"protobuf", []string{"application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf"}},
+}
+
+func newOffers() ([]*offer, error) {
+	var offers []*offer
+	for _, m := range negotiatedMediaTypes {
+		var c codec
+		for _, registered := range codecs {
+			if registered.Name() == m.codec {
+				c = registered
+			}
+		}
+		if c == nil {
+			return nil, fmt.Errorf("no %s codec", m.codec)
+		}
+		offers = append(offers, &offer{codec: c, mediaTypes: m.mediaTypes})
+	}
+	return offers, nil
+}
+
+// negotiate picks the offer an Accept header prefers: the highest quality,
+// taken from the most specific media range that matches each offer, then
+// the range listed first. An empty header accepts anything.
+func negotiate(offers []*offer, accept string) *offer {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	var best *offer
+	bestQ, bestPos := 0.0, 0
+	for _, o := range offers {
+		q, pos, specificity := 0.0, 0, -1
+		for i, r := range strings.Split(accept, ",") {
+			params := strings.Split(r, ";")
+			mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+			s := rangeSpecificity(mediaRange, o.mediaTypes)
+			if s <= specificity {
+				continue
+			}
+			rangeQ := 1.0
+			for _, p := range params[1:] {
+				if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+					if f, err := strconv.ParseFloat(v, 64); err == nil {
+						rangeQ = f
+					}
+				}
+			}
+			q, pos, specificity = rangeQ, i, s
+		}
+		if q > bestQ || (q == bestQ && q > 0 && pos < bestPos) {
+			best, bestQ, bestPos = o, q, pos
+		}
+	}
+	return best
+}
+
+// rangeSpecificity is 2 when the media range names one of mediaTypes, 1
+// when it is type/* for one of them, 0 for */* and -1 when it matches none
+func rangeSpecificity(mediaRange string, mediaTypes []string) int {
+	if mediaRange == "*/*" {
+		return 0
+	}
+	s := -1
+	for _, t := range mediaTypes {
+		if mediaRange == t {
+			return 2
+		}
+		if mainType, ok := strings.CutSuffix(mediaRange, "/*"); ok && strings.HasPrefix(t, mainType+"/") {
+			s = 1
+		}
+	}
+	return s
+}
+
+// negotiated is middleware that encodes the value returned by handler in
+// the offer the request's Accept header prefers, answering 406 when it
+// accepts none of them
+func negotiated(offers []*offer, handler func(r *http.Request) (interface{}, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "Accept")
+		o := negotiate(offers, r.Header.Get("Accept"))
+		if o == nil {
+			http.Error(w, "acceptable formats: "+strings.Join(acceptable(offers), ", "), http.StatusNotAcceptable)
+			return
+		}
+		v, err := handler(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		start := time.Now()
+		body, err := o.codec.Marshal(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		atomic.AddInt64(&o.nanos, int64(time.Since(start)))
+		atomic.AddInt64(&o.encodes, 1)
+		w.Header().Set("Content-Type", o.mediaTypes[0])
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write(body)
+	})
+}
+
+func acceptable(offers []*offer) []string {
+	var types []string
+	for _, o := range offers {
+		types = append(types, o.mediaTypes[0])
+	}
+	return types
+}
+
+// negotiateFlags are the flags of negotiate
+type negotiateFlags struct {
+	file        *string
+	concurrency *int
+	duration    *time.Duration
+	listen      *string
+}
+
+func (opts *negotiateFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("negotiate")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to serve")
+	opts.concurrency = fs.Int("c", 8, "number of concurrent connections")
+	opts.duration = fs.Duration("d", 3*time.Second, "how long to load each format")
+	opts.listen = fs.String("listen", "", "serve the document on this address instead of benchmarking it")
+	return fs
+}
+
+// runNegotiate serves twitter.json behind the negotiating middleware and
+// loads it once per format, with clients decoding every response
+func runNegotiate(args []string) error {
+	var opts negotiateFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var twitter TwitterData
+	if err := json.Unmarshal(data, &twitter); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	offers, err := newOffers()
+	if err != nil {
+		return err
+	}
+	handler := negotiated(offers, func(*http.Request) (interface{}, error) { return &twitter, nil })
+	if *opts.listen != "" {
+		fmt.Fprintf(os.Stderr, "serving %s as %s on %s\n", *opts.file, strings.Join(acceptable(offers), ", "), *opts.listen)
+		return http.ListenAndServe(*opts.listen, handler)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: handler}
+	go server.Serve(ln)
+	defer server.Close()
+
+	fmt.Printf("%s: %d connections, %v per format\n\n", *opts.file, *opts.concurrency, *opts.duration)
+	fmt.Println("| Accept | bytes | req/s | p50 ms | p99 ms | server encode µs | client decode µs |")
+	fmt.Println("|---|---:|---:|---:|---:|---:|---:|")
+	for _, o := range offers {
+		var size, decodes, decodeNanos int64
+		c := o.codec
+		r, err := generateLoad(loadConfig{
+			url:         "http://" + ln.Addr().String() + "/",
+			method:      http.MethodGet,
+			concurrency: *opts.concurrency,
+			duration:    *opts.duration,
+			header:      http.Header{"Accept": {o.mediaTypes[0]}},
+			response: func(body []byte) error {
+				start := time.Now()
+				if err := c.Unmarshal(body, new(TwitterData)); err != nil {
+					return err
+				}
+				atomic.AddInt64(&decodeNanos, int64(time.Since(start)))
+				atomic.AddInt64(&decodes, 1)
+				atomic.StoreInt64(&size, int64(len(body)))
+				return nil
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.Name(), err)
+		}
+		fmt.Printf("| %s | %d | %.0f | %.3f | %.3f | %.1f | %.1f |\n", o.mediaTypes[0], size, r.perSecond(),
+			bench.Milliseconds(r.percentile(0.5)), bench.Milliseconds(r.percentile(0.99)),
+			float64(o.nanos)/float64(o.encodes)/1e3, float64(decodeNanos)/float64(decodes)/1e3)
+	}
+	return nil
+}
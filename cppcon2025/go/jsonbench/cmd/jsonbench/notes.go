@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// notesFlags are the flags of notes
+type notesFlags struct {
+	baseline *string
+	out      *string
+}
+
+func (opts *notesFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("notes")
+	opts.baseline = fs.String("baseline", report.BaselineBackend, "backend the others are compared with")
+	opts.out = fs.String("o", "", "write the notes to this file instead of stdout, e.g. notes.md")
+	return fs
+}
+
+// Turn a bench result file into speaker notes, one bullet per dataset,
+// so that the script of the talk says the numbers of the latest run
+func runNotes(args []string) error {
+	var opts notesFlags
+	fs := opts.flags()
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return withKind(errUsage, errors.New("notes: expected one result file"))
+	}
+
+	rf, err := bench.ReadResults(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	var w io.Writer = os.Stdout
+	if *opts.out != "" {
+		f, err := os.Create(*opts.out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	for _, note := range report.SpeakerNotes(rf, *opts.baseline) {
+		fmt.Fprintf(w, "- %s\n", note)
+	}
+	return nil
+}
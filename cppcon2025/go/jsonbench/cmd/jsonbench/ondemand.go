@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/ondemand"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+)
+
+// parseOnDemand is parse in software/parse_twitter.cpp, line for line,
+// with the ondemand package
+func parseOnDemand(parser *ondemand.Parser, data []byte) (TwitterData, error) {
+	var td TwitterData
+	// snippet:ondemand-parse
+	doc, err := parser.Iterate(data)
+	if err != nil {
+		return td, err
+	}
+	statuses := doc.Field("statuses").GetArray()
+	for statuses.Next() {
+		user := statuses.Value().GetObject().Field("user").GetObject()
+		var u TwitterUser
+		var errs [9]error
+		u.ID, errs[0] = user.Field("id").GetUint64()
+		u.Name, errs[1] = user.Field("name").GetString()
+		u.ScreenName, errs[2] = user.Field("screen_name").GetString()
+		u.Location, errs[3] = user.Field("location").GetString()
+		u.Description, errs[4] = user.Field("description").GetString()
+		u.FollowersCount, errs[5] = user.Field("followers_count").GetUint64()
+		u.FriendsCount, errs[6] = user.Field("friends_count").GetUint64()
+		u.Verified, errs[7] = user.Field("verified").GetBool()
+		u.StatusesCount, errs[8] = user.Field("statuses_count").GetUint64()
+		if err := errors.Join(errs[:]...); err != nil {
+			return td, err
+		}
+		td.Statuses = append(td.Statuses, Status{User: u})
+	}
+	// snippet:ondemand-parse
+	return td, statuses.Err()
+}
+
+// onDemandFlags are the flags of ondemand
+type onDemandFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *onDemandFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("ondemand")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to parse")
+	opts.iterations = fs.Int("n", 200, "number of iterations")
+	return fs
+}
+
+// Time the On-Demand API against encoding/json on the structs of
+// parse_twitter.go, with stage 1 alone for scale; both must fill the
+// same structs
+func runOnDemand(args []string) error {
+	var opts onDemandFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var want TwitterData
+	if err := json.Unmarshal(data, &want); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	var parser ondemand.Parser
+	got, err := parseOnDemand(&parser, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	if !reflect.DeepEqual(got, want) {
+		return withKind(errMismatch, errors.New("ondemand: the structs differ from those of encoding/json"))
+	}
+	fmt.Printf("%s: %d bytes, %d statuses\n\n", datasets.Name(*opts.file), len(data), len(got.Statuses))
+
+	idx := make([]uint32, 0, len(data)/4)
+	methods := []struct {
+		name  string
+		parse func([]byte) error
+	}{
+		{"encoding/json into the structs", func(data []byte) error {
+			var td TwitterData
+			return json.Unmarshal(data, &td)
+		}},
+		{"On-Demand into the structs", func(data []byte) error {
+			_, err := parseOnDemand(&parser, data)
+			return err
+		}},
+		{"stage 1 alone, for scale", func(data []byte) error {
+			_, err := scanner.Index(data, idx[:0])
+			return err
+		}},
+	}
+	fmt.Println("| Method | MB/s | speedup |")
+	fmt.Println("|---|---:|---:|")
+	base := 0.0
+	for _, m := range methods {
+		speed, err := bench.Measure(data, *opts.iterations, m.parse)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		if base == 0 {
+			base = speed
+		}
+		fmt.Printf("| %s | %.1f | %.1f× |\n", m.name, speed, speed/base)
+	}
+	return nil
+}
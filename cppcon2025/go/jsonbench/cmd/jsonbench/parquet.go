@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// The JSON to Parquet pipeline of analytics users: decode the statuses,
+// flatten them into one column per user field, and write the columns as a
+// Parquet file. The writer is the smallest one readers accept: one row
+// group, one uncompressed PLAIN data page per column, every column
+// required. The footer is Thrift's compact protocol, written by hand like
+// the other formats.
+
+// statusColumns is twitter.json flattened to one row per status, stored
+// column by column
+type statusColumns struct {
+	rows           int
+	id             []uint64
+	name           []string
+	screenName     []string
+	location       []string
+	description    []string
+	followersCount []uint64
+	friendsCount   []uint64
+	verified       []bool
+	statusesCount  []uint64
+}
+
+// flattenStatuses copies the fields of every status into the columns,
+// reusing their storage
+func flattenStatuses(t *TwitterData, c *statusColumns) {
+	n := len(t.Statuses)
+	*c = statusColumns{
+		rows:           n,
+		id:             c.id[:0],
+		name:           c.name[:0],
+		screenName:     c.screenName[:0],
+		location:       c.location[:0],
+		description:    c.description[:0],
+		followersCount: c.followersCount[:0],
+		friendsCount:   c.friendsCount[:0],
+		verified:       c.verified[:0],
+		statusesCount:  c.statusesCount[:0],
+	}
+	for i := range t.Statuses {
+		u := &t.Statuses[i].User
+		c.id = append(c.id, u.ID)
+		c.name = append(c.name, u.Name)
+		c.screenName = append(c.screenName, u.ScreenName)
+		c.location = append(c.location, u.Location)
+		c.description = append(c.description, u.Description)
+		c.followersCount = append(c.followersCount, u.FollowersCount)
+		c.friendsCount = append(c.friendsCount, u.FriendsCount)
+		c.verified = append(c.verified, u.Verified)
+		c.statusesCount = append(c.statusesCount, u.StatusesCount)
+	}
+}
+
+// Parquet physical and converted types
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetUTF8   = 0
+	parquetUint64 = 14
+)
+
+// parquetColumn is a flattened column, PLAIN-encoded
+type parquetColumn struct {
+	name      string
+	kind      int32
+	converted int32 // -1 for none
+	values    []byte
+}
+
+func plainUint64s(b []byte, v []uint64) []byte {
+	for _, x := range v {
+		b = binary.LittleEndian.AppendUint64(b, x)
+	}
+	return b
+}
+
+func plainStrings(b []byte, v []string) []byte {
+	for _, s := range v {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(s)))
+		b = append(b, s...)
+	}
+	return b
+}
+
+// plainBools packs the values into bits, least significant first
+func plainBools(b []byte, v []bool) []byte {
+	for i := 0; i < len(v); i += 8 {
+		var c byte
+		for j := 0; j < 8 && i+j < len(v); j++ {
+			if v[i+j] {
+				c |= 1 << j
+			}
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// columns encodes c in the order of the Parquet schema
+func (c *statusColumns) columns() []parquetColumn {
+	return []parquetColumn{
+		{"id", parquetInt64, parquetUint64, plainUint64s(nil, c.id)},
+		{"name", parquetByteArray, parquetUTF8, plainStrings(nil, c.name)},
+		{"screen_name", parquetByteArray, parquetUTF8, plainStrings(nil, c.screenName)},
+		{"location", parquetByteArray, parquetUTF8, plainStrings(nil, c.location)},
+		{"description", parquetByteArray, parquetUTF8, plainStrings(nil, c.description)},
+		{"followers_count", parquetInt64, parquetUint64, plainUint64s(nil, c.followersCount)},
+		{"friends_count", parquetInt64, parquetUint64, plainUint64s(nil, c.friendsCount)},
+		{"verified", parquetBoolean, -1, plainBools(nil, c.verified)},
+		{"statuses_count", parquetInt64, parquetUint64, plainUint64s(nil, c.statusesCount)},
+	}
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter writes Thrift compact protocol structs, which encode each
+// field id as the delta from the previous one in the same struct
+type thriftWriter struct {
+	b    []byte
+	last []int16
+}
+
+func (w *thriftWriter) field(id int16, kind byte) {
+	prev := w.last[len(w.last)-1]
+	if delta := id - prev; delta > 0 && delta <= 15 {
+		w.b = append(w.b, byte(delta)<<4|kind)
+	} else {
+		w.b = append(w.b, kind)
+		w.b = binary.AppendVarint(w.b, int64(id))
+	}
+	w.last[len(w.last)-1] = id
+}
+
+func (w *thriftWriter) begin() { w.last = append(w.last, 0) }
+
+func (w *thriftWriter) end() {
+	w.b = append(w.b, 0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.b = binary.AppendVarint(w.b, int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.b = binary.AppendVarint(w.b, v)
+}
+
+func (w *thriftWriter) string(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.b = binary.AppendUvarint(w.b, uint64(len(s)))
+	w.b = append(w.b, s...)
+}
+
+func (w *thriftWriter) list(id int16, kind byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.b = append(w.b, byte(n)<<4|kind)
+	} else {
+		w.b = append(w.b, 0xf0|kind)
+		w.b = binary.AppendUvarint(w.b, uint64(n))
+	}
+}
+
+// struct_ starts a struct field; list elements start with begin
+func (w *thriftWriter) struct_(id int16) {
+	w.field(id, thriftStruct)
+	w.begin()
+}
+
+// writeParquet appends the columns as a Parquet file of rows rows
+func writeParquet(b []byte, rows int, columns []parquetColumn) []byte {
+	type chunk struct{ offset, size int }
+	chunks := make([]chunk, len(columns))
+	b = append(b, "PAR1"...)
+	var w thriftWriter
+	for i, c := range columns {
+		// PageHeader with a DataPageHeader; required columns have no
+		// repetition or definition levels, so the page is the values
+		w.b = w.b[:0]
+		w.begin()
+		w.i32(1, 0) // DATA_PAGE
+		w.i32(2, int32(len(c.values)))
+		w.i32(3, int32(len(c.values)))
+		w.struct_(5)
+		w.i32(1, int32(rows))
+		w.i32(2, 0) // PLAIN
+		w.i32(3, 3) // RLE
+		w.i32(4, 3)
+		w.end()
+		w.end()
+		chunks[i] = chunk{len(b), len(w.b) + len(c.values)}
+		b = append(b, w.b...)
+		b = append(b, c.values...)
+	}
+
+	// FileMetaData
+	w.b = w.b[:0]
+	w.begin()
+	w.i32(1, 1)
+	w.list(2, thriftStruct, len(columns)+1)
+	w.begin()
+	w.string(4, "schema")
+	w.i32(5, int32(len(columns)))
+	w.end()
+	for _, c := range columns {
+		w.begin()
+		w.i32(1, c.kind)
+		w.i32(3, 0) // REQUIRED
+		w.string(4, c.name)
+		if c.converted >= 0 {
+			w.i32(6, c.converted)
+		}
+		w.end()
+	}
+	w.i64(3, int64(rows))
+	w.list(4, thriftStruct, 1)
+	w.begin()
+	w.list(1, thriftStruct, len(columns))
+	var total int
+	for i, c := range columns {
+		w.begin()
+		w.i64(2, int64(chunks[i].offset))
+		w.struct_(3)
+		w.i32(1, c.kind)
+		w.list(2, thriftI32, 1)
+		w.b = binary.AppendVarint(w.b, 0) // PLAIN
+		w.list(3, thriftBinary, 1)
+		w.b = binary.AppendUvarint(w.b, uint64(len(c.name)))
+		w.b = append(w.b, c.name...)
+		w.i32(4, 0) // UNCOMPRESSED
+		w.i64(5, int64(rows))
+		w.i64(6, int64(chunks[i].size))
+		w.i64(7, int64(chunks[i].size))
+		w.i64(9, int64(chunks[i].offset))
+		w.end()
+		w.end()
+		total += chunks[i].size
+	}
+	w.i64(2, int64(total))
+	w.i64(3, int64(rows))
+	w.end()
+	w.string(6, "simdjson_talks jsonbench")
+	w.end()
+
+	b = append(b, w.b...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(w.b)))
+	return append(b, "PAR1"...)
+}
+
+// parquetFlags are the flags of parquet
+type parquetFlags struct {
+	file       *string
+	out        *string
+	iterations *int
+}
+
+func (opts *parquetFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("parquet")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to convert")
+	opts.out = fs.String("o", "", "also write the Parquet file here")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	return fs
+}
+
+// runParquet times each stage of converting twitter.json to Parquet, all
+// as throughput of the JSON input
+func runParquet(args []string) error {
+	var opts parquetFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var twitter TwitterData
+	if err := json.Unmarshal(data, &twitter); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	var columns statusColumns
+	flattenStatuses(&twitter, &columns)
+	parquet := writeParquet(nil, columns.rows, columns.columns())
+	if *opts.out != "" {
+		if err := os.WriteFile(*opts.out, parquet, 0o644); err != nil {
+			return err
+		}
+	}
+
+	var buf []byte
+	stages := []struct {
+		name string
+		fn   func([]byte) error
+	}{
+		{"decode", func(b []byte) error {
+			var t TwitterData
+			return json.Unmarshal(b, &t)
+		}},
+		{"flatten", func([]byte) error {
+			flattenStatuses(&twitter, &columns)
+			return nil
+		}},
+		{"write", func([]byte) error {
+			buf = writeParquet(buf[:0], columns.rows, columns.columns())
+			return nil
+		}},
+		{"pipeline", func(b []byte) error {
+			var t TwitterData
+			if err := json.Unmarshal(b, &t); err != nil {
+				return err
+			}
+			flattenStatuses(&t, &columns)
+			buf = writeParquet(buf[:0], columns.rows, columns.columns())
+			return nil
+		}},
+	}
+
+	fmt.Printf("%s: %d bytes of JSON, %d rows, %d bytes of Parquet\n\n", *opts.file, len(data), columns.rows, len(parquet))
+	fmt.Println("| Stage | MB/s of JSON | µs |")
+	fmt.Println("|---|---:|---:|")
+	for _, s := range stages {
+		speed, err := bench.Measure(data, *opts.iterations, s.fn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		fmt.Printf("| %s | %.2f | %.1f |\n", s.name, speed, float64(len(data))/speed)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+)
+
+// buildAndBench builds the jsonbench command of the module in src with
+// goCmd and the extra build flags, runs bench with it and returns the results. The binary and its
+// result file are named after name in dir.
+func buildAndBench(dir, src, goCmd string, env, buildFlags, benchArgs []string, name string) (bench.ResultFile, error) {
+	bin := filepath.Join(dir, name)
+	build := append(append([]string{goCmd, "build"}, buildFlags...), "-o", bin, "./cmd/jsonbench")
+	if _, err := execIn(src, env, build); err != nil {
+		return bench.ResultFile{}, fmt.Errorf("building %s: %w", name, err)
+	}
+	out := filepath.Join(dir, name+".json")
+	argv := append([]string{bin, "bench", "-history", "", "-results", "", "-o", out}, benchArgs...)
+	if _, err := execIn(src, env, argv); err != nil {
+		return bench.ResultFile{}, fmt.Errorf("running %s: %w", name, err)
+	}
+	return bench.ReadResults(out)
+}
+
+// pgoFlags are the flags of pgo
+type pgoFlags struct {
+	files      *string
+	iterations *int
+	count      *int
+	src        *string
+	keep       *string
+}
+
+func (opts *pgoFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("pgo")
+	opts.files = fs.String("file", "../twitter.json", "comma-separated list of JSON documents")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	opts.count = fs.Int("count", 5, "repeat each measurement and report the median")
+	opts.src = fs.String("src", ".", "directory of the jsonbench module")
+	opts.keep = fs.String("keep", "", "keep the binaries, profile and results in this directory")
+	return fs
+}
+
+func runPGO(args []string) error {
+	var opts pgoFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	dir := *opts.keep
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "jsonbench-pgo")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// The children run in src
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	// Relative dataset paths are resolved against src by the child runs
+	benchArgs := []string{"-file", *opts.files, "-n", strconv.Itoa(*opts.iterations), "-count", strconv.Itoa(*opts.count)}
+
+	// The profile comes from the same benchmark, so every backend's decode
+	// path is in it in proportion to the time it takes
+	profile := filepath.Join(dir, "default.pgo")
+	fmt.Println("building without PGO and collecting", profile)
+	base, err := buildAndBench(dir, *opts.src, "go", nil, []string{"-pgo=off"}, append(benchArgs, "-cpuprofile", profile), "jsonbench-nopgo")
+	if err != nil {
+		return err
+	}
+	fmt.Println("rebuilding with -pgo and benchmarking again")
+	withPGO, err := buildAndBench(dir, *opts.src, "go", nil, []string{"-pgo=" + profile}, benchArgs, "jsonbench-pgo")
+	if err != nil {
+		return err
+	}
+
+	before := map[string]float64{}
+	for _, r := range base.Results {
+		before[r.Dataset+"\x00"+r.Backend] = r.MBPerSec
+	}
+	fmt.Println()
+	fmt.Println("| Dataset | Library | Without PGO | With PGO | Speedup |")
+	fmt.Println("|---------|---------|-------------|----------|---------|")
+	for _, r := range withPGO.Results {
+		b := before[r.Dataset+"\x00"+r.Backend]
+		speedup := "-"
+		if b > 0 {
+			speedup = fmt.Sprintf("%.2fx", r.MBPerSec/b)
+		}
+		fmt.Printf("| %s | %s | %s | %s | %s |\n", r.Dataset, r.Backend, formatThroughput(b), formatThroughput(r.MBPerSec), speedup)
+	}
+	return nil
+}
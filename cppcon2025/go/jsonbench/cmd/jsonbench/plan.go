@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// benchPlan is what a bench run would do: the datasets, the backends,
+// the decodes per measurement and where the results would go
+type benchPlan struct {
+	files             []string
+	iterations, count int
+	history, results  string
+	out               string
+}
+
+// latestSpeeds returns the throughput of the latest run of each dataset
+// and backend measured on this machine, keyed by dataset then backend
+func latestSpeeds(history string) (map[[2]string]float64, error) {
+	speeds := map[[2]string]float64{}
+	if history == "" {
+		return speeds, nil
+	}
+	runs, err := bench.ReadHistory(history)
+	if errors.Is(err, fs.ErrNotExist) {
+		return speeds, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	machine := bench.CurrentEnvironment().Fingerprint
+	for _, run := range runs {
+		if run.Environment == nil || run.Environment.Fingerprint != machine {
+			continue
+		}
+		for _, r := range run.Results {
+			speeds[[2]string{r.Dataset, r.Backend}] = r.MBPerSec
+		}
+	}
+	return speeds, nil
+}
+
+// print describes the plan and estimates how long each measurement
+// takes from the history of this machine. It reads the datasets, to
+// know their decoded size, and runs nothing.
+func (p benchPlan) print() error {
+	speeds, err := latestSpeeds(p.history)
+	if err != nil {
+		return err
+	}
+	all := backends.All()
+	fmt.Printf("dry run: %d datasets x %d backends, %d iterations, count %d; nothing is measured\n\n",
+		len(p.files), len(all), p.iterations, p.count)
+	fmt.Println("| Dataset | Bytes | Backend | Decodes | Estimate |")
+	fmt.Println("|---|---:|---|---:|---:|")
+	var total time.Duration
+	unknown := 0
+	for _, file := range p.files {
+		data, err := datasets.Read(file)
+		if err != nil {
+			return err
+		}
+		name := datasets.Name(file)
+		// One warm-up decode precedes the iterations of each measurement
+		decodes := p.count * (p.iterations + 1)
+		for _, b := range all {
+			estimate := "no history"
+			if speed, ok := speeds[[2]string{name, b.Name()}]; ok && speed > 0 {
+				d := time.Duration(float64(len(data)) * float64(decodes) / (speed * 1e6) * float64(time.Second))
+				total += d
+				estimate = d.Round(time.Millisecond).String()
+			} else {
+				unknown++
+			}
+			fmt.Printf("| %s | %d | %s | %d | %s |\n", name, len(data), b.Name(), decodes, estimate)
+		}
+	}
+	fmt.Printf("\nestimated time: %s", total.Round(time.Millisecond))
+	if unknown > 0 {
+		fmt.Printf(", plus %d measurements without history on this machine", unknown)
+	}
+	fmt.Println()
+	for _, w := range []struct{ what, path string }{
+		{"results file", p.out},
+		{"history", p.history},
+		{"run directory under", p.results},
+	} {
+		if w.path != "" {
+			fmt.Printf("would write %s %s\n", w.what, w.path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// prefetchFlags are the flags of prefetch
+type prefetchFlags struct {
+	file      *string
+	size      *string
+	distances *string
+	rounds    *int
+}
+
+func (opts *prefetchFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("prefetch")
+	opts.file = fs.String("file", "../twitter.json", "document whose records fill the large input")
+	opts.size = fs.String("size", "256MB", "size of the large input")
+	opts.distances = fs.String("distances", "0,256B,1KB,4KB,16KB,64KB,1MB", "comma-separated prefetch distances, 0 for none")
+	opts.rounds = fs.Int("n", 5, "number of rounds")
+	return fs
+}
+
+// Index a document far larger than the caches with software prefetches
+// at several distances ahead of the scanner, and report whether any of
+// them beats the hardware prefetchers alone. The distances run in turn,
+// round after round, so that a drift of the machine affects them all
+// alike; the best round of each is kept.
+func runPrefetch(args []string) error {
+	var opts prefetchFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	n, err := datasets.ParseSize(*opts.size)
+	if err != nil || n <= 0 || int64(n) >= 1<<32 {
+		return withKind(errUsage, fmt.Errorf("-size %q: want a size below 4GB", *opts.size))
+	}
+	var ds []int
+	for _, s := range strings.Split(*opts.distances, ",") {
+		d, err := datasets.ParseSize(s)
+		if err != nil || d < 0 {
+			return withKind(errUsage, fmt.Errorf("-distances: bad distance %q", s))
+		}
+		ds = append(ds, d)
+	}
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	recs, err := datasets.Records(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	doc := datasets.ScaledDocument(recs, n)
+
+	want, err := scanner.Index(doc, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	idx := make([]uint32, 0, len(want))
+	for _, d := range ds {
+		got, err := scanner.IndexPrefetch(doc, idx[:0], d)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(got, want) {
+			return withKind(errMismatch, fmt.Errorf("distance %d: the structural index differs from Index's", d))
+		}
+	}
+
+	best := make([]time.Duration, len(ds))
+	for r := 0; r < *opts.rounds; r++ {
+		for i, d := range ds {
+			start := time.Now()
+			if _, err := scanner.IndexPrefetch(doc, idx[:0], d); err != nil {
+				return err
+			}
+			if t := time.Since(start); r == 0 || t < best[i] {
+				best[i] = t
+			}
+		}
+	}
+
+	prefetch := "no prefetch instruction, Prefetch does nothing here"
+	if simd.PrefetchSupported {
+		prefetch = "PREFETCHT0 or PRFM per cache line"
+	}
+	fmt.Printf("%s scaled to %d bytes, scanner %s, %s; best of %d rounds\n\n",
+		datasets.Name(*opts.file), len(doc), scanner.Name(), prefetch, *opts.rounds)
+	fmt.Println("| Prefetch distance | GB/s | speedup |")
+	fmt.Println("|---|---:|---:|")
+	var none time.Duration
+	winner := -1
+	for i, d := range ds {
+		if d == 0 {
+			none = best[i]
+		}
+	}
+	for i, d := range ds {
+		name := "none"
+		if d > 0 {
+			name = fmt.Sprintf("%d bytes", d)
+		}
+		speedup := ""
+		if none > 0 {
+			speedup = fmt.Sprintf("%.2f×", none.Seconds()/best[i].Seconds())
+			if d > 0 && (winner < 0 || best[i] < best[winner]) {
+				winner = i
+			}
+		}
+		fmt.Printf("| %s | %.2f | %s |\n", name, float64(len(doc))/best[i].Seconds()/1e9, speedup)
+	}
+	// Within 2%, the difference is noise
+	switch {
+	case winner < 0:
+		fmt.Println("\nAdd a distance of 0 to compare with no prefetching.")
+	case best[winner].Seconds() < none.Seconds()*0.98:
+		fmt.Printf("\nPrefetching %d bytes ahead helps on this machine: %.1f%% faster.\n",
+			ds[winner], 100*(none.Seconds()/best[winner].Seconds()-1))
+	default:
+		fmt.Println("\nPrefetching does not help on this machine: the hardware prefetchers keep up with the scan.")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+)
+
+// startFlamegraph starts a CPU profile that stopFlamegraph renders to svg.
+// The raw profile is kept next to it (svg + ".pprof") so it can also be
+// opened with "go tool pprof -http=: file.pprof", whose flame graph view
+// is the one to show on stage.
+func startFlamegraph(svg string) (*os.File, error) {
+	f, err := os.Create(strings.TrimSuffix(svg, ".svg") + ".pprof")
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// stopFlamegraph stops the profile and renders it with pprof's SVG output,
+// which needs Graphviz's dot on the PATH
+func stopFlamegraph(f *os.File, svg string) error {
+	pprof.StopCPUProfile()
+	if err := f.Close(); err != nil {
+		return err
+	}
+	cmd := exec.Command("go", "tool", "pprof", "-svg", "-output", svg, f.Name())
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("rendering %s: %w (the profile is in %s)", svg, err, f.Name())
+	}
+	return nil
+}
+
+// profileFlags are the profiling flags shared by every benchmark command,
+// named after their go test counterparts
+type profileFlags struct {
+	cpuProfile   string
+	trace        string
+	blockProfile string
+}
+
+func addProfileFlags(fs *flag.FlagSet) *profileFlags {
+	p := &profileFlags{}
+	fs.StringVar(&p.cpuProfile, "cpuprofile", "", "write a CPU profile to this file")
+	fs.StringVar(&p.trace, "trace", "", "write an execution trace to this file")
+	fs.StringVar(&p.blockProfile, "blockprofile", "", "write a goroutine blocking profile to this file")
+	return p
+}
+
+// start begins the requested profiles; the returned function stops them
+// and writes the files
+func (p *profileFlags) start() (stop func(), err error) {
+	var stops []func()
+	stop = func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+	if p.cpuProfile != "" {
+		f, err := os.Create(p.cpuProfile)
+		if err != nil {
+			return stop, err
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return stop, err
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			f.Close()
+		})
+	}
+	if p.trace != "" {
+		f, err := os.Create(p.trace)
+		if err != nil {
+			return stop, err
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return stop, err
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			f.Close()
+		})
+	}
+	if p.blockProfile != "" {
+		runtime.SetBlockProfileRate(1)
+		stops = append(stops, func() {
+			f, err := os.Create(p.blockProfile)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				return
+			}
+			defer f.Close()
+			if err := pprof.Lookup("block").WriteTo(f, 0); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
+		})
+	}
+	return stop, nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// projection is a whitelist of dotted paths as a tree. A node with keep
+// set keeps its whole value; any other node keeps only the members it
+// lists. Arrays are transparent: a path applies to each element.
+type projection struct {
+	keep     bool
+	children map[string]*projection
+}
+
+// compileProjection builds the tree of paths such as "statuses.user"
+func compileProjection(paths []string) (*projection, error) {
+	root := &projection{}
+	for _, path := range paths {
+		if path == "" {
+			return nil, errors.New("empty path")
+		}
+		p := root
+		for _, key := range strings.Split(path, ".") {
+			if key == "" {
+				return nil, fmt.Errorf("path %q has an empty key", path)
+			}
+			if p.children == nil {
+				p.children = map[string]*projection{}
+			}
+			c := p.children[key]
+			if c == nil {
+				c = &projection{}
+				p.children[key] = c
+			}
+			p = c
+		}
+		p.keep = true
+	}
+	if len(root.children) == 0 {
+		return nil, errors.New("no paths to keep")
+	}
+	return root, nil
+}
+
+// keeps reports whether the value starting with c survives p: a scalar
+// below a path that goes deeper has nothing to keep, so it is dropped
+func (p *projection) keeps(c byte) bool {
+	return p.keep || c == '{' || c == '['
+}
+
+// project copies data to out keeping only the paths of p, in a single
+// pass with the hand-rolled decoder. Kept values are copied verbatim;
+// the rest is checked and skipped.
+func (p *projection) project(data, out []byte) ([]byte, error) {
+	d := backends.NewDecoder(data, backends.DecodeOptions{})
+	out, err := p.transform(d, out)
+	if err != nil {
+		return out, err
+	}
+	return out, d.End()
+}
+
+func (p *projection) transform(d *backends.Decoder, out []byte) ([]byte, error) {
+	if p.keep {
+		raw, err := d.SkipRaw()
+		return append(out, raw...), err
+	}
+	first := true
+	if d.Peek() == '[' {
+		out = append(out, '[')
+		err := d.Elements(func() error {
+			if !p.keeps(d.Peek()) {
+				return d.Skip()
+			}
+			if !first {
+				out = append(out, ',')
+			}
+			first = false
+			var err error
+			out, err = p.transform(d, out)
+			return err
+		})
+		return append(out, ']'), err
+	}
+	out = append(out, '{')
+	err := d.Members(func(key []byte) error {
+		c := p.children[string(key)]
+		if c == nil || !c.keeps(d.Peek()) {
+			return d.Skip()
+		}
+		if !first {
+			out = append(out, ',')
+		}
+		first = false
+		out = append(appendCanonicalString(out, string(key)), ':')
+		var err error
+		out, err = c.transform(d, out)
+		return err
+	})
+	return append(out, '}'), err
+}
+
+// prune applies p to a decoded value, with the same rules as project
+func (p *projection) prune(v interface{}) interface{} {
+	if p.keep {
+		return v
+	}
+	switch v := v.(type) {
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			if p.keepsValue(e) {
+				out = append(out, p.prune(e))
+			}
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(p.children))
+		for k, c := range p.children {
+			if e, ok := v[k]; ok && c.keepsValue(e) {
+				out[k] = c.prune(e)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func (p *projection) keepsValue(v interface{}) bool {
+	switch v.(type) {
+	case []interface{}, map[string]interface{}:
+		return true
+	}
+	return p.keep
+}
+
+// projectFlags are the flags of project
+type projectFlags struct {
+	file        *string
+	size        *string
+	keep        *string
+	iterations  *int
+	printOutput *bool
+}
+
+func (opts *projectFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("project")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to redact")
+	opts.size = fs.String("size", "", "replicate the records of -file into a top-level array of this size, e.g. 256MB (paths then start below the records: -keep id_str,user)")
+	opts.keep = fs.String("keep", "statuses.id_str,statuses.user", "comma-separated dotted paths to keep")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	opts.printOutput = fs.Bool("print", false, "print the redacted document and exit")
+	return fs
+}
+
+// runProject compares redacting a document in one streaming pass with
+// decoding it, pruning the value and encoding the result, the way a
+// "redact and forward" service would do it without a streaming parser
+func runProject(args []string) error {
+	var opts projectFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	p, err := compileProjection(strings.Split(*opts.keep, ","))
+	if err != nil {
+		return fmt.Errorf("-keep: %w", err)
+	}
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	if *opts.size != "" {
+		recs, err := datasets.Records(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *opts.file, err)
+		}
+		n, err := datasets.ParseSize(*opts.size)
+		if err != nil {
+			return fmt.Errorf("-size: %w", err)
+		}
+		data = datasets.ScaledDocument(recs, n)
+	}
+
+	projected, err := p.project(data, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if *opts.printOutput {
+		os.Stdout.Write(projected)
+		fmt.Println()
+		return nil
+	}
+	// The streamed output must be the pruned document
+	var doc, got interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if err := json.Unmarshal(projected, &got); err != nil {
+		return fmt.Errorf("project: invalid output: %w", err)
+	}
+	if !reflect.DeepEqual(got, p.prune(doc)) {
+		return withKind(errMismatch, errors.New("project: streaming and pruning the decoded value disagree"))
+	}
+
+	fmt.Printf("%s: %d bytes redacted to %d (%.1f%%), keeping %s\n\n",
+		*opts.file, len(data), len(projected), 100*float64(len(projected))/float64(len(data)), *opts.keep)
+	fmt.Println("| Method | MB/s | µs |")
+	fmt.Println("|---|---:|---:|")
+	out := make([]byte, 0, len(projected))
+	speed, err := bench.Measure(data, *opts.iterations, func(b []byte) error {
+		var err error
+		out, err = p.project(b, out[:0])
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("streaming: %w", err)
+	}
+	fmt.Printf("| streaming | %.2f | %.1f |\n", speed, float64(len(data))/speed)
+	for _, b := range backends.All() {
+		speed, err := bench.Measure(data, *opts.iterations, func(data []byte) error {
+			v, err := b.Decode(data)
+			if err != nil {
+				return err
+			}
+			_, err = backends.EncodeGeneric(b, p.prune(v))
+			return err
+		})
+		if err != nil {
+			// Not every backend decodes every document
+			continue
+		}
+		fmt.Printf("| %s + prune + encode | %.2f | %.1f |\n", b.Name(), speed, float64(len(data))/speed)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/cmd/jsonbench/twitterpb"
+)
+
+// Protocol Buffers encoding of the data model with google.golang.org/protobuf
+// and the messages protoc-gen-go generates from twitter.proto. The structs
+// are copied into the messages before proto.Marshal and out of them after
+// proto.Unmarshal, as a program keeping its own model types does. As in
+// proto3, zero values are not written, so empty and nil slices both
+// decode as nil.
+
+//go:generate protoc --go_out=. --go_opt=module=github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/cmd/jsonbench twitter.proto
+
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string { return "protobuf" }
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case *TwitterData:
+		return proto.Marshal(v.toProto())
+	case *Players:
+		return proto.Marshal(v.toProto())
+	}
+	return nil, fmt.Errorf("protobuf: unsupported type %T", v)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *TwitterData:
+		var m twitterpb.TwitterData
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		v.fromProto(&m)
+		return nil
+	case *Players:
+		var m twitterpb.Players
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		v.fromProto(&m)
+		return nil
+	}
+	return fmt.Errorf("protobuf: unsupported type %T", v)
+}
+
+func init() {
+	registerCodec(protobufCodec{})
+}
+
+func (t *TwitterData) toProto() *twitterpb.TwitterData {
+	m := &twitterpb.TwitterData{Statuses: make([]*twitterpb.Status, len(t.Statuses))}
+	for i := range t.Statuses {
+		u := &t.Statuses[i].User
+		m.Statuses[i] = &twitterpb.Status{User: &twitterpb.TwitterUser{
+			Id:             u.ID,
+			Name:           u.Name,
+			ScreenName:     u.ScreenName,
+			Location:       u.Location,
+			Description:    u.Description,
+			FollowersCount: u.FollowersCount,
+			FriendsCount:   u.FriendsCount,
+			Verified:       u.Verified,
+			StatusesCount:  u.StatusesCount,
+		}}
+	}
+	return m
+}
+
+func (t *TwitterData) fromProto(m *twitterpb.TwitterData) {
+	t.Statuses = nil
+	for _, s := range m.GetStatuses() {
+		u := s.GetUser()
+		t.Statuses = append(t.Statuses, Status{User: TwitterUser{
+			ID:             u.GetId(),
+			Name:           u.GetName(),
+			ScreenName:     u.GetScreenName(),
+			Location:       u.GetLocation(),
+			Description:    u.GetDescription(),
+			FollowersCount: u.GetFollowersCount(),
+			FriendsCount:   u.GetFriendsCount(),
+			Verified:       u.GetVerified(),
+			StatusesCount:  u.GetStatusesCount(),
+		}})
+	}
+}
+
+func (ps *Players) toProto() *twitterpb.Players {
+	m := &twitterpb.Players{Players: make([]*twitterpb.Player, len(*ps))}
+	for i, p := range *ps {
+		m.Players[i] = &twitterpb.Player{
+			Username:  p.Username,
+			Level:     int64(p.Level),
+			Health:    p.Health,
+			Inventory: p.Inventory,
+		}
+	}
+	return m
+}
+
+func (ps *Players) fromProto(m *twitterpb.Players) {
+	*ps = nil
+	for _, p := range m.GetPlayers() {
+		*ps = append(*ps, Player{
+			Username:  p.GetUsername(),
+			Level:     int(p.GetLevel()),
+			Health:    p.GetHealth(),
+			Inventory: p.GetInventory(),
+		})
+	}
+}
@@ -0,0 +1,267 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// The stages of a proxied request
+const (
+	stageRead = iota
+	stageDecode
+	stageTransform
+	stageEncode
+	stageForward
+	stageCount
+)
+
+var stageNames = [stageCount]string{"read", "decode", "transform", "encode", "forward"}
+
+// stageTimes accumulates the time spent in each stage
+type stageTimes struct {
+	requests int64
+	nanos    [stageCount]int64
+}
+
+func (s *stageTimes) micros(stage int) float64 {
+	n := atomic.LoadInt64(&s.requests)
+	if n == 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&s.nanos[stage])) / float64(n) / 1e3
+}
+
+// filterKeys keeps only the object members named in keep, at any depth
+func filterKeys(v interface{}, keep map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(keep))
+		for k, e := range v {
+			if keep[k] {
+				out[k] = filterKeys(e, keep)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = filterKeys(e, keep)
+		}
+		return out
+	}
+	return v
+}
+
+// transcoder turns a JSON body into what the proxy forwards
+type transcoder struct {
+	mode string
+	keep map[string]bool
+}
+
+func (t transcoder) contentType() string {
+	if t.mode == "msgpack" {
+		return "application/msgpack"
+	}
+	return "application/json"
+}
+
+func (t transcoder) transform(v interface{}) interface{} {
+	if t.mode == "filter" {
+		return filterKeys(v, t.keep)
+	}
+	return v
+}
+
+// encode writes JSON with the backend's encoder when it has one
+func (t transcoder) encode(b backends.Backend, v interface{}) ([]byte, error) {
+	if t.mode == "msgpack" {
+		return appendMsgpackValue(nil, v)
+	}
+	return backends.EncodeGeneric(b, v)
+}
+
+// proxyHandler decodes each request body with b, transcodes it and
+// forwards it to upstream, answering with upstream's response
+func proxyHandler(b backends.Backend, t transcoder, client *http.Client, upstream string, times *stageTimes) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var elapsed [stageCount]time.Duration
+		start := time.Now()
+		lap := func(stage int) {
+			now := time.Now()
+			elapsed[stage] = now.Sub(start)
+			start = now
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lap(stageRead)
+		v, err := b.Decode(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lap(stageDecode)
+		v = t.transform(v)
+		lap(stageTransform)
+		out, err := t.encode(b, v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		lap(stageEncode)
+		resp, err := client.Post(upstream, t.contentType(), bytes.NewReader(out))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		reply, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		lap(stageForward)
+
+		atomic.AddInt64(&times.requests, 1)
+		for stage, d := range elapsed {
+			atomic.AddInt64(&times.nanos[stage], int64(d))
+		}
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		w.Write(reply)
+	})
+}
+
+// sinkHandler stands in for the service behind the proxy: it reads the
+// body and reports its size
+func sinkHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"bytes":%d}`, n)
+	})
+}
+
+// proxyFlags are the flags of proxy
+type proxyFlags struct {
+	file        *string
+	mode        *string
+	keep        *string
+	only        *string
+	concurrency *int
+	duration    *time.Duration
+	listen      *string
+	upstream    *string
+}
+
+func (opts *proxyFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("proxy")
+	opts.file = fs.String("file", "../twitter.json", "JSON body to send through the proxy")
+	opts.mode = fs.String("mode", "minify", "minify, filter or msgpack")
+	opts.keep = fs.String("keep", "statuses,id,text,user,screen_name,followers_count", "object keys that filter keeps")
+	opts.only = fs.String("backend", "", "comma-separated backends to run (default all)")
+	opts.concurrency = fs.Int("c", 8, "number of concurrent connections")
+	opts.duration = fs.Duration("d", 3*time.Second, "how long to load each backend")
+	opts.listen = fs.String("listen", "", "serve the proxy on this address instead of benchmarking it")
+	opts.upstream = fs.String("upstream", "", "forward to this URL instead of a built-in sink")
+	return fs
+}
+
+// runProxy benchmarks the proxy with each backend, or serves it on -listen
+func runProxy(args []string) error {
+	var opts proxyFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	t := transcoder{mode: *opts.mode, keep: map[string]bool{}}
+	switch *opts.mode {
+	case "minify", "msgpack":
+	case "filter":
+		for _, k := range strings.Split(*opts.keep, ",") {
+			t.keep[k] = true
+		}
+	default:
+		return fmt.Errorf("unknown -mode %q", *opts.mode)
+	}
+	if *opts.upstream == "" {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		sink := &http.Server{Handler: sinkHandler()}
+		go sink.Serve(ln)
+		defer sink.Close()
+		*opts.upstream = "http://" + ln.Addr().String() + "/"
+	}
+	client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: *opts.concurrency}}
+
+	if *opts.listen != "" {
+		var b backends.Backend = backends.Stdlib{}
+		if *opts.only != "" {
+			var err error
+			if b, err = backends.Lookup(*opts.only); err != nil {
+				return err
+			}
+		}
+		var times stageTimes
+		fmt.Fprintf(os.Stderr, "proxying %s with %s (%s) to %s\n", *opts.listen, b.Name(), *opts.mode, *opts.upstream)
+		return http.ListenAndServe(*opts.listen, proxyHandler(b, t, client, *opts.upstream, &times))
+	}
+
+	body, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	server := &http.Server{Handler: mux}
+	go server.Serve(ln)
+	defer server.Close()
+
+	fmt.Printf("%s through the proxy (%s), %d connections, %v per backend\n\n", *opts.file, *opts.mode, *opts.concurrency, *opts.duration)
+	fmt.Printf("| Backend | req/s | p99 ms | %s µs |\n", strings.Join(stageNames[:], " µs | "))
+	fmt.Println("|---|---:|---:|" + strings.Repeat("---:|", stageCount))
+	for i, b := range backends.All() {
+		if *opts.only != "" && !containsFormat(*opts.only, b.Name()) {
+			continue
+		}
+		var times stageTimes
+		path := fmt.Sprintf("/%d", i)
+		mux.Handle(path, proxyHandler(b, t, client, *opts.upstream, &times))
+		r, err := generateLoad(loadConfig{
+			url:         "http://" + ln.Addr().String() + path,
+			body:        body,
+			contentType: "application/json",
+			concurrency: *opts.concurrency,
+			duration:    *opts.duration,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		fmt.Printf("| %s | %.0f | %.3f |", b.Name(), r.perSecond(), bench.Milliseconds(r.percentile(0.99)))
+		for stage := 0; stage < stageCount; stage++ {
+			fmt.Printf(" %.1f |", times.micros(stage))
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,646 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// A subset of the jq language over the generic representation:
+//
+//	.  .foo  .["foo"]  .[0]  .[]  a | b  [a]  (a)  select(a)  length  not
+//	a == b  a != b  a < b  a <= b  a > b  a >= b  a and b  a or b
+//
+// and number, string, true, false and null literals. A filter calls emit
+// once for each of its outputs, so that .[] streams instead of building
+// arrays.
+type queryFunc func(v interface{}, emit func(interface{}) error) error
+
+// queryToken is a lexeme: 'f' a .field, 'i' an identifier, 'n' a number,
+// 's' a string and 'p' punctuation or an operator
+type queryToken struct {
+	kind byte
+	text string
+}
+
+func isIdentByte(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
+
+func lexQuery(src string) ([]queryToken, error) {
+	var toks []queryToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '.' && i+1 < len(src) && isIdentByte(src[i+1], true):
+			j := i + 1
+			for j < len(src) && isIdentByte(src[j], false) {
+				j++
+			}
+			toks = append(toks, queryToken{'f', src[i+1 : j]})
+			i = j
+		case isIdentByte(c, true):
+			j := i
+			for j < len(src) && isIdentByte(src[j], false) {
+				j++
+			}
+			toks = append(toks, queryToken{'i', src[i:j]})
+			i = j
+		case c == '-' || c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(src) && strings.IndexByte("0123456789.eE+-", src[j]) >= 0 {
+				j++
+			}
+			toks = append(toks, queryToken{'n', src[i:j]})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, errors.New("unterminated string")
+			}
+			toks = append(toks, queryToken{'s', src[i : j+1]})
+			i = j + 1
+		default:
+			op := src[i : i+1]
+			if i+1 < len(src) && src[i+1] == '=' && strings.IndexByte("=!<>", c) >= 0 {
+				op = src[i : i+2]
+			} else if strings.IndexByte(".[]()|<>", c) < 0 {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			toks = append(toks, queryToken{'p', op})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+type queryParser struct {
+	toks []queryToken
+	pos  int
+}
+
+// compileQuery parses a jq expression into a filter
+func compileQuery(src string) (queryFunc, error) {
+	toks, err := lexQuery(src)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	p := &queryParser{toks: toks}
+	q, err := p.pipe()
+	if err == nil && p.pos < len(p.toks) {
+		err = fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	return q, nil
+}
+
+func (p *queryParser) peek(text string) bool {
+	if p.pos >= len(p.toks) {
+		return false
+	}
+	t := p.toks[p.pos]
+	return (t.kind == 'p' || t.kind == 'i') && t.text == text
+}
+
+func (p *queryParser) accept(text string) bool {
+	if p.peek(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expect(text string) error {
+	if !p.accept(text) {
+		if p.pos < len(p.toks) {
+			return fmt.Errorf("expected %q, found %q", text, p.toks[p.pos].text)
+		}
+		return fmt.Errorf("expected %q at the end", text)
+	}
+	return nil
+}
+
+func (p *queryParser) pipe() (queryFunc, error) {
+	left, err := p.or()
+	for err == nil && p.accept("|") {
+		var right queryFunc
+		if right, err = p.or(); err == nil {
+			left = queryPipe(left, right)
+		}
+	}
+	return left, err
+}
+
+func queryPipe(left, right queryFunc) queryFunc {
+	return func(v interface{}, emit func(interface{}) error) error {
+		return left(v, func(x interface{}) error { return right(x, emit) })
+	}
+}
+
+func (p *queryParser) or() (queryFunc, error) {
+	left, err := p.and()
+	for err == nil && p.accept("or") {
+		var right queryFunc
+		if right, err = p.and(); err == nil {
+			left = queryBinary(left, right, func(a, b interface{}) (interface{}, error) { return queryTruthy(a) || queryTruthy(b), nil })
+		}
+	}
+	return left, err
+}
+
+func (p *queryParser) and() (queryFunc, error) {
+	left, err := p.comparison()
+	for err == nil && p.accept("and") {
+		var right queryFunc
+		if right, err = p.comparison(); err == nil {
+			left = queryBinary(left, right, func(a, b interface{}) (interface{}, error) { return queryTruthy(a) && queryTruthy(b), nil })
+		}
+	}
+	return left, err
+}
+
+func (p *queryParser) comparison() (queryFunc, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if !p.accept(op) {
+			continue
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		return queryBinary(left, right, func(a, b interface{}) (interface{}, error) {
+			c := queryCompare(a, b)
+			switch op {
+			case "==":
+				return c == 0, nil
+			case "!=":
+				return c != 0, nil
+			case "<=":
+				return c <= 0, nil
+			case ">=":
+				return c >= 0, nil
+			case "<":
+				return c < 0, nil
+			}
+			return c > 0, nil
+		}), nil
+	}
+	return left, nil
+}
+
+// queryBinary emits op of every pair of outputs of left and right
+func queryBinary(left, right queryFunc, op func(a, b interface{}) (interface{}, error)) queryFunc {
+	return func(v interface{}, emit func(interface{}) error) error {
+		return right(v, func(b interface{}) error {
+			return left(v, func(a interface{}) error {
+				r, err := op(a, b)
+				if err != nil {
+					return err
+				}
+				return emit(r)
+			})
+		})
+	}
+}
+
+func (p *queryParser) term() (queryFunc, error) {
+	q, err := p.primary()
+	for err == nil {
+		switch {
+		case p.pos < len(p.toks) && p.toks[p.pos].kind == 'f':
+			q = queryPipe(q, queryField(p.toks[p.pos].text))
+			p.pos++
+		case p.peek("[") || p.peek(".") && p.pos+1 < len(p.toks) && p.toks[p.pos+1].text == "[":
+			p.accept(".")
+			var index queryFunc
+			if index, err = p.index(); err == nil {
+				q = queryPipe(q, index)
+			}
+		default:
+			return q, nil
+		}
+	}
+	return nil, err
+}
+
+// index parses [], [n] or ["key"]
+func (p *queryParser) index() (queryFunc, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	if p.accept("]") {
+		return queryIterate, nil
+	}
+	if p.pos >= len(p.toks) {
+		return nil, errors.New("unterminated index")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	var key interface{}
+	switch t.kind {
+	case 'n':
+		n, err := strconv.Atoi(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("index %s is not an integer", t.text)
+		}
+		key = n
+	case 's':
+		var s string
+		if err := json.Unmarshal([]byte(t.text), &s); err != nil {
+			return nil, fmt.Errorf("bad string %s", t.text)
+		}
+		key = s
+	default:
+		return nil, fmt.Errorf("unsupported index %q", t.text)
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return func(v interface{}, emit func(interface{}) error) error {
+		x, err := queryIndex(v, key)
+		if err != nil {
+			return err
+		}
+		return emit(x)
+	}, nil
+}
+
+func (p *queryParser) primary() (queryFunc, error) {
+	if p.pos >= len(p.toks) {
+		return nil, errors.New("unexpected end of query")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	switch t.kind {
+	case 'f':
+		return queryField(t.text), nil
+	case 'n':
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %s", t.text)
+		}
+		return queryLiteral(f), nil
+	case 's':
+		var s string
+		if err := json.Unmarshal([]byte(t.text), &s); err != nil {
+			return nil, fmt.Errorf("bad string %s", t.text)
+		}
+		return queryLiteral(s), nil
+	case 'i':
+		switch t.text {
+		case "true", "false":
+			return queryLiteral(t.text == "true"), nil
+		case "null":
+			return queryLiteral(nil), nil
+		case "length":
+			return queryLength, nil
+		case "not":
+			return func(v interface{}, emit func(interface{}) error) error { return emit(!queryTruthy(v)) }, nil
+		case "select":
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			cond, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return func(v interface{}, emit func(interface{}) error) error {
+				return cond(v, func(c interface{}) error {
+					if queryTruthy(c) {
+						return emit(v)
+					}
+					return nil
+				})
+			}, nil
+		}
+		return nil, fmt.Errorf("unknown function %s", t.text)
+	}
+	switch t.text {
+	case ".":
+		// .[...] is an index of the identity, parsed as a suffix
+		return queryIdentity, nil
+	case "(":
+		q, err := p.pipe()
+		if err == nil {
+			err = p.expect(")")
+		}
+		return q, err
+	case "[":
+		if p.accept("]") {
+			return queryLiteral([]interface{}{}), nil
+		}
+		q, err := p.pipe()
+		if err == nil {
+			err = p.expect("]")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, emit func(interface{}) error) error {
+			out := []interface{}{}
+			if err := q(v, func(x interface{}) error {
+				out = append(out, x)
+				return nil
+			}); err != nil {
+				return err
+			}
+			return emit(out)
+		}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+func queryIdentity(v interface{}, emit func(interface{}) error) error { return emit(v) }
+
+func queryLiteral(x interface{}) queryFunc {
+	return func(_ interface{}, emit func(interface{}) error) error { return emit(x) }
+}
+
+func queryField(name string) queryFunc {
+	return func(v interface{}, emit func(interface{}) error) error {
+		x, err := queryIndex(v, name)
+		if err != nil {
+			return err
+		}
+		return emit(x)
+	}
+}
+
+// queryIndex looks up an object member or an array element; null and
+// missing members give null, as in jq
+func queryIndex(v, key interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		if k, ok := key.(string); ok {
+			return v[k], nil
+		}
+	case []interface{}:
+		if i, ok := key.(int); ok {
+			if i < 0 {
+				i += len(v)
+			}
+			if i < 0 || i >= len(v) {
+				return nil, nil
+			}
+			return v[i], nil
+		}
+	}
+	return nil, fmt.Errorf("cannot index %s with %v", queryTypeName(v), key)
+}
+
+// queryIterate emits the elements of an array or the values of an object,
+// the latter in key order
+func queryIterate(v interface{}, emit func(interface{}) error) error {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, x := range v {
+			if err := emit(x); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := emit(v[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot iterate over %s", queryTypeName(v))
+}
+
+func queryLength(v interface{}, emit func(interface{}) error) error {
+	switch v := v.(type) {
+	case nil:
+		return emit(0.0)
+	case float64:
+		return emit(math.Abs(v))
+	case string:
+		return emit(float64(utf8.RuneCountInString(v)))
+	case []interface{}:
+		return emit(float64(len(v)))
+	case map[string]interface{}:
+		return emit(float64(len(v)))
+	}
+	return fmt.Errorf("%s has no length", queryTypeName(v))
+}
+
+func queryTruthy(v interface{}) bool {
+	b, ok := v.(bool)
+	return v != nil && (!ok || b)
+}
+
+func queryTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// queryCompare orders values as jq does: null < false < true < numbers <
+// strings < arrays < objects, arrays element by element. Objects of the
+// same rank are only told equal or apart.
+func queryCompare(a, b interface{}) int {
+	rank := func(v interface{}) int {
+		switch v := v.(type) {
+		case nil:
+			return 0
+		case bool:
+			if v {
+				return 2
+			}
+			return 1
+		case float64:
+			return 3
+		case string:
+			return 4
+		case []interface{}:
+			return 5
+		}
+		return 6
+	}
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch a := a.(type) {
+	case float64:
+		switch b := b.(float64); {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case []interface{}:
+		b := b.([]interface{})
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if c := queryCompare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return len(a) - len(b)
+	case map[string]interface{}:
+		if !reflect.DeepEqual(a, b) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// queryFlags are the flags of query
+type queryFlags struct {
+	file  *string
+	size  *string
+	only  *string
+	count *bool
+}
+
+func (opts *queryFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("query")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to query")
+	opts.size = fs.String("size", "", "replicate the records of -file into a document of this size, e.g. 256MB")
+	opts.only = fs.String("backend", "", "backend to decode with (default the fastest on this document)")
+	opts.count = fs.Bool("count", false, "print only the number of results")
+	return fs
+}
+
+// runQuery runs a jq expression over a document decoded with the fastest
+// backend and reports the time spent decoding and querying
+func runQuery(args []string) error {
+	var opts queryFlags
+	fs := opts.flags()
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return withKind(errUsage, errors.New("query: expected one expression"))
+	}
+	q, err := compileQuery(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	if *opts.size != "" {
+		recs, err := datasets.Records(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *opts.file, err)
+		}
+		n, err := datasets.ParseSize(*opts.size)
+		if err != nil {
+			return fmt.Errorf("-size: %w", err)
+		}
+		data = datasets.ScaledDocument(recs, n)
+	}
+
+	candidates := backends.All()
+	if *opts.only != "" {
+		b, err := backends.Lookup(*opts.only)
+		if err != nil {
+			return err
+		}
+		candidates = []backends.Backend{b}
+	}
+	// Every candidate decodes the document once; the fastest one's value
+	// is queried
+	var chosen backends.Backend
+	var doc interface{}
+	var best time.Duration
+	for _, b := range candidates {
+		start := time.Now()
+		v, err := b.Decode(data)
+		elapsed := time.Since(start)
+		if err != nil {
+			if *opts.only != "" {
+				return fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			continue
+		}
+		if chosen == nil || elapsed < best {
+			chosen, doc, best = b, v, elapsed
+		}
+	}
+	if chosen == nil {
+		return fmt.Errorf("%s: no backend decodes it", *opts.file)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	results := 0
+	start := time.Now()
+	err = q(doc, func(v interface{}) error {
+		results++
+		if *opts.count {
+			return nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		out.Write(b)
+		return out.WriteByte('\n')
+	})
+	elapsed := time.Since(start)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+	if *opts.count {
+		fmt.Fprintln(out, results)
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d bytes decoded by %s in %.1f ms (%.2f MB/s), queried in %.1f ms, %d results\n",
+		*opts.file, len(data), chosen.Name(), bench.Milliseconds(best), float64(len(data))/1e6/best.Seconds(), bench.Milliseconds(elapsed), results)
+	return nil
+}
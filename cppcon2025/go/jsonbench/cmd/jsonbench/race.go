@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// racer is one backend of the race, updated by its goroutine and read by
+// the dashboard
+type racer struct {
+	backend backends.Backend
+	bytes   atomic.Int64
+	err     error // set before done is closed
+	done    chan struct{}
+}
+
+// isTerminal reports whether f is a character device, where the
+// dashboard can redraw in place
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// raceFlags are the flags of race
+type raceFlags struct {
+	file     *string
+	duration *time.Duration
+	interval *time.Duration
+	only     *string
+	width    *int
+}
+
+func (opts *raceFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("race")
+	opts.file = fs.String("file", "../twitter.json", "JSON document every backend decodes")
+	opts.duration = fs.Duration("d", 10*time.Second, "length of the race")
+	opts.interval = fs.Duration("interval", 100*time.Millisecond, "time between redraws")
+	opts.only = fs.String("backend", "", "comma-separated list of backends (default all)")
+	opts.width = fs.Int("width", 40, "width of the bars")
+	return fs
+}
+
+// Race every backend on the same document at once, each decoding it in a
+// loop on its own goroutine, and redraw their throughput as live bars:
+// the benchmark as a show. The backends share the machine, so the bench
+// command remains the measurement.
+func runRace(args []string) error {
+	var opts raceFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	list := backends.All()
+	if *opts.only != "" {
+		list = nil
+		for _, name := range strings.Split(*opts.only, ",") {
+			b, err := backends.Lookup(name)
+			if err != nil {
+				return err
+			}
+			list = append(list, b)
+		}
+	}
+
+	// Ctrl-C ends the race early with the standings so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *opts.duration)
+	defer cancel()
+
+	racers := make([]*racer, len(list))
+	names := make([]string, len(list))
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, b := range list {
+		r := &racer{backend: b, done: make(chan struct{})}
+		racers[i], names[i] = r, b.Name()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(r.done)
+			for ctx.Err() == nil {
+				if _, err := r.backend.Decode(data); err != nil {
+					r.err = err
+					return
+				}
+				r.bytes.Add(int64(len(data)))
+			}
+		}()
+	}
+
+	board := report.NewDashboard(os.Stdout, names, *opts.width)
+	update := func(elapsed float64) {
+		for i, r := range racers {
+			select {
+			case <-r.done:
+				if r.err != nil {
+					board.Fail(i, r.err)
+					continue
+				}
+			default:
+			}
+			board.Set(i, float64(r.bytes.Load())/1e6/elapsed)
+		}
+	}
+	live := isTerminal(os.Stdout)
+	if live {
+		// Hide the cursor while redrawing
+		fmt.Print("\033[?25l")
+		defer fmt.Print("\033[?25h")
+	}
+	ticker := time.NewTicker(*opts.interval)
+	defer ticker.Stop()
+	status := fmt.Sprintf("%d backends racing on %s (%d bytes)", len(list), datasets.Name(*opts.file), len(data))
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+			if live {
+				update(time.Since(start).Seconds())
+				board.Draw(fmt.Sprintf("%s: %.1fs of %s", status, time.Since(start).Seconds(), *opts.duration))
+			}
+		}
+	}
+	wg.Wait()
+	elapsed := time.Since(start).Seconds()
+	if live {
+		update(elapsed)
+		board.Draw(fmt.Sprintf("%s: finished after %.1fs", status, elapsed))
+	} else {
+		// Piped output gets the bars once, without escapes
+		fmt.Printf("%s: finished after %.1fs\n", status, elapsed)
+		var results []bench.Result
+		for i, r := range racers {
+			if r.err != nil {
+				fmt.Printf("  %s failed: %v\n", names[i], r.err)
+				continue
+			}
+			results = append(results, bench.Result{Dataset: datasets.Name(*opts.file), Backend: names[i], MBPerSec: float64(r.bytes.Load()) / 1e6 / elapsed})
+		}
+		report.PrintBarChart(os.Stdout, results)
+	}
+
+	// The standings, as the sound bite
+	var order []int
+	for i, r := range racers {
+		if r.err == nil {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return racers[order[a]].bytes.Load() > racers[order[b]].bytes.Load() })
+	var places []string
+	for place, i := range order {
+		places = append(places, fmt.Sprintf("%d. %s", place+1, names[i]))
+	}
+	fmt.Println(strings.Join(places, ", "))
+	return nil
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// pathStep is one step of a path such as statuses[0].user.name: a key,
+// an index, or [] for every element or member value
+type pathStep struct {
+	key     string
+	index   int // -1 for a key
+	iterate bool
+}
+
+// parsePath parses dotted keys and [n] or [] suffixes, with an optional
+// leading dot as in jq
+func parsePath(src string) ([]pathStep, error) {
+	var steps []pathStep
+	src = strings.TrimPrefix(src, ".")
+	for i := 0; i < len(src); {
+		switch c := src[i]; {
+		case c == '[':
+			j := strings.IndexByte(src[i:], ']')
+			if j < 0 {
+				return nil, errors.New("unterminated index")
+			}
+			inner := src[i+1 : i+j]
+			if inner == "" {
+				steps = append(steps, pathStep{index: -1, iterate: true})
+			} else {
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("index %q is not a non-negative integer", inner)
+				}
+				steps = append(steps, pathStep{index: n})
+			}
+			i += j + 1
+		case c == '.' && i > 0:
+			i++
+		case isIdentByte(c, true):
+			j := i
+			for j < len(src) && isIdentByte(src[j], false) {
+				j++
+			}
+			steps = append(steps, pathStep{key: src[i:j], index: -1})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q in path", c)
+		}
+	}
+	return steps, nil
+}
+
+// errPathDone unwinds the decoder once a path without [] has its value,
+// so that the rest of the document is never read
+var errPathDone = errors.New("path done")
+
+// lazyPath walks the document at d along steps, skipping everything off
+// the path, and calls emit with the raw text of each value it reaches
+func lazyPath(d *backends.Decoder, steps []pathStep, emit func(raw []byte) error) error {
+	if len(steps) == 0 {
+		raw, err := d.SkipRaw()
+		if err != nil {
+			return err
+		}
+		return emit(raw)
+	}
+	s, rest := steps[0], steps[1:]
+	switch c := d.Peek(); {
+	case s.iterate && c == '[':
+		return d.Elements(func() error { return lazyPath(d, rest, emit) })
+	case s.iterate && c == '{':
+		return d.Members(func([]byte) error { return lazyPath(d, rest, emit) })
+	case s.index >= 0 && c == '[':
+		i := 0
+		return d.Elements(func() error {
+			i++
+			if i-1 == s.index {
+				return lazyPath(d, rest, emit)
+			}
+			return d.Skip()
+		})
+	case !s.iterate && s.index < 0 && c == '{':
+		return d.Members(func(key []byte) error {
+			if string(key) == s.key {
+				return lazyPath(d, rest, emit)
+			}
+			return d.Skip()
+		})
+	case c == 'n':
+		// null and missing values give nothing
+		return d.Skip()
+	}
+	return d.Errorf("cannot apply %s to %s", s, rawKind(d.Peek()))
+}
+
+// rawKind names the kind of value starting with c
+func rawKind(c byte) string {
+	switch c {
+	case '{':
+		return "an object"
+	case '[':
+		return "an array"
+	case '"':
+		return "a string"
+	case 't', 'f':
+		return "a boolean"
+	case 0:
+		return "the end of the input"
+	}
+	return "a number"
+}
+
+func (s pathStep) String() string {
+	switch {
+	case s.iterate:
+		return "[]"
+	case s.index >= 0:
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return "." + s.key
+}
+
+// evalLazy runs a path over the raw document and returns the values it
+// reached and how far into the document the decoder read
+func evalLazy(data []byte, steps []pathStep) ([][]byte, int, error) {
+	iterates := false
+	for _, s := range steps {
+		iterates = iterates || s.iterate
+	}
+	var values [][]byte
+	d := backends.NewDecoder(data, backends.DecodeOptions{})
+	err := lazyPath(d, steps, func(raw []byte) error {
+		values = append(values, raw)
+		if !iterates {
+			return errPathDone
+		}
+		return nil
+	})
+	if err == errPathDone {
+		err = nil
+	}
+	return values, d.Offset(), err
+}
+
+// timeQuery runs fn until at least a millisecond has passed and returns
+// the mean time per run
+func timeQuery(fn func() error) (time.Duration, error) {
+	start := time.Now()
+	runs := 0
+	for time.Since(start) < time.Millisecond {
+		if err := fn(); err != nil {
+			return 0, err
+		}
+		runs++
+	}
+	return time.Since(start) / time.Duration(runs), nil
+}
+
+// formatMicros prints a latency in microseconds, with a decimal below 10
+func formatMicros(d time.Duration) string {
+	us := float64(d) / float64(time.Microsecond)
+	if us < 10 {
+		return fmt.Sprintf("%.2f µs", us)
+	}
+	return fmt.Sprintf("%.0f µs", us)
+}
+
+// truncate cuts a printed value to max bytes
+func truncate(s string, max int) string {
+	if max > 0 && len(s) > max {
+		return s[:max] + fmt.Sprintf("… (%d bytes)", len(s))
+	}
+	return s
+}
+
+const replHelp = `Type a path, such as statuses[0].user.name or statuses[].user.screen_name,
+to read it lazily from the raw bytes: only the document up to the value is
+scanned, and nothing off the path is built. The same path is then looked
+up in the tree decoded at startup, for comparison. Anything else, such as
+.statuses[] | select(.retweet_count > 5) | .id, is a query over the tree
+(see the query command).
+
+  :help   this text
+  :quit   leave (or end of input)`
+
+// replFlags are the flags of repl
+type replFlags struct {
+	file    *string
+	backend *string
+	max     *int
+}
+
+func (opts *replFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("repl")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to explore")
+	opts.backend = fs.String("backend", "encoding/json", "backend decoding the tree at startup")
+	opts.max = fs.Int("max", 200, "print at most this many bytes of each value (0 for all)")
+	return fs
+}
+
+// Load a document once and evaluate paths on it interactively, lazily
+// over the raw bytes and over the decoded tree, with the latency of each
+func runREPL(args []string) error {
+	var opts replFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	b, err := backends.Lookup(*opts.backend)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	tree, err := b.Decode(data)
+	decode := time.Since(start)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	fmt.Printf("%s: %d bytes, decoded by %s in %.1f ms; :help for help\n",
+		datasets.Name(*opts.file), len(data), b.Name(), bench.Milliseconds(decode))
+
+	in := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("> ")
+		if !in.Scan() {
+			fmt.Println()
+			return in.Err()
+		}
+		line := strings.TrimSpace(in.Text())
+		switch line {
+		case "":
+			continue
+		case ":quit", ":q":
+			return nil
+		case ":help", ":h":
+			fmt.Println(replHelp)
+			continue
+		}
+		if err := evalREPL(line, data, tree, *opts.max); err != nil {
+			fmt.Println("error:", err)
+		}
+	}
+}
+
+// evalREPL evaluates one input line and prints its values and timings
+func evalREPL(line string, data []byte, tree interface{}, max int) error {
+	expr := line
+	if !strings.HasPrefix(expr, ".") {
+		expr = "." + expr
+	}
+	steps, pathErr := parsePath(line)
+	if pathErr == nil {
+		values, scanned, err := evalLazy(data, steps)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			fmt.Println(truncate(string(v), max))
+		}
+		if len(values) == 0 {
+			fmt.Println("null")
+		}
+		lazy, _ := timeQuery(func() error {
+			_, _, err := evalLazy(data, steps)
+			return err
+		})
+		fmt.Printf("lazy: %s, %d values, scanned %d of %d bytes (%.1f%%)\n",
+			formatMicros(lazy), len(values), scanned, len(data), 100*float64(scanned)/float64(len(data)))
+	}
+
+	q, err := compileQuery(expr)
+	if err != nil {
+		if pathErr != nil {
+			return fmt.Errorf("neither a path (%v) nor a %w", pathErr, err)
+		}
+		// Paths with keys jq cannot spell have no tree lookup
+		return nil
+	}
+	results := 0
+	run := func(print bool) error {
+		return q(tree, func(v interface{}) error {
+			if !print {
+				return nil
+			}
+			results++
+			out, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(truncate(string(out), max))
+			return nil
+		})
+	}
+	if err := run(pathErr != nil); err != nil {
+		return err
+	}
+	elapsed, _ := timeQuery(func() error { return run(false) })
+	if pathErr != nil {
+		fmt.Printf("tree: %s, %d results\n", formatMicros(elapsed), results)
+	} else {
+		fmt.Printf("tree: %s, after decoding the whole document once\n", formatMicros(elapsed))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// reportFlags are the flags of report
+type reportFlags struct {
+	out   *string
+	title *string
+}
+
+func (opts *reportFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("report")
+	opts.out = fs.String("o", "report.html", "HTML file to write")
+	opts.title = fs.String("title", "Go JSON throughput", "page title")
+	return fs
+}
+
+// Turn one or more bench result files into a single self-contained HTML
+// page with one bar chart per dataset
+func runReport(args []string) error {
+	var opts reportFlags
+	fs := opts.flags()
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return withKind(errUsage, errors.New("report: expected at least one result file"))
+	}
+
+	var files []bench.ResultFile
+	var names []string
+	for _, path := range fs.Args() {
+		rf, err := bench.ReadResults(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		files = append(files, rf)
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+
+	f, err := os.Create(*opts.out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return report.HTML(f, *opts.title, files, names)
+}
@@ -0,0 +1,197 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+)
+
+// reproduceGoVersion is the toolchain the slide numbers were measured with
+const reproduceGoVersion = "1.25.0"
+
+// writeDockerfile writes the image recipe: the pinned toolchain, the
+// jsonbench command built from the module, and the datasets under /data.
+// The benchmark itself runs when the container starts.
+func writeDockerfile(w io.Writer, goVersion, commit string, datasets []string, iterations, count int) error {
+	cmd := []string{"jsonbench", "bench",
+		"-file", strings.Join(datasets, ","),
+		"-n", strconv.Itoa(iterations),
+		"-count", strconv.Itoa(count),
+		"-o", "/results/results.json",
+		"-history", "/results/results.jsonl",
+		"-results", "/results/runs"}
+	quoted := make([]string, len(cmd))
+	for i, c := range cmd {
+		quoted[i] = strconv.Quote(c)
+	}
+	_, err := fmt.Fprintf(w, `FROM golang:%s
+LABEL org.opencontainers.image.revision=%q
+ENV GOEXPERIMENT=jsonv2 GOTOOLCHAIN=local
+COPY data /data
+COPY jsonbench /src/jsonbench
+WORKDIR /src/jsonbench
+RUN go build -o /usr/local/bin/jsonbench ./cmd/jsonbench
+VOLUME /results
+CMD [%s]
+`, goVersion, commit, strings.Join(quoted, ", "))
+	return err
+}
+
+// copyFile copies src to dst, creating the parent directories
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// copySources copies the module (go.mod, the Go and assembly files of
+// every package and runall.json) from src to dst, leaving out tests and
+// the browser demo
+func copySources(dst, src string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == "testdata" || rel == filepath.Join("cmd", "jsonbench-wasm") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if (ext != ".go" && ext != ".s" && ext != ".json" && rel != "go.mod") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		return copyFile(filepath.Join(dst, rel), path)
+	})
+}
+
+// reproduceFlags are the flags of reproduce
+type reproduceFlags struct {
+	files      *string
+	iterations *int
+	count      *int
+	goVersion  *string
+	cpus       *int
+	out        *string
+	contextDir *string
+	engine     *string
+	src        *string
+}
+
+func (opts *reproduceFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("reproduce")
+	opts.files = fs.String("file", "../twitter.json", "comma-separated list of JSON documents to bake into the image")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	opts.count = fs.Int("count", 5, "repeat each measurement and report the median")
+	opts.goVersion = fs.String("go", reproduceGoVersion, "Go version of the golang base image")
+	opts.cpus = fs.Int("cpus", 1, "pin the container to this many CPUs, starting at CPU 0")
+	opts.out = fs.String("o", "reproduce", "directory mounted as /results")
+	opts.contextDir = fs.String("context", "", "write the build context to this directory (default: a temporary one)")
+	opts.engine = fs.String("engine", "docker", "container engine (docker or podman)")
+	opts.src = fs.String("src", ".", "directory of the jsonbench module")
+	return fs
+}
+
+func runReproduce(args []string) error {
+	var opts reproduceFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	dir := *opts.contextDir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "jsonbench-reproduce")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	}
+	if err := copySources(filepath.Join(dir, "jsonbench"), *opts.src); err != nil {
+		return err
+	}
+	var datasets []string
+	for _, file := range strings.Split(*opts.files, ",") {
+		name := filepath.Base(file)
+		if err := copyFile(filepath.Join(dir, "data", name), file); err != nil {
+			return err
+		}
+		datasets = append(datasets, "/data/"+name)
+	}
+	commit := bench.GitCommit()
+	f, err := os.Create(filepath.Join(dir, "Dockerfile"))
+	if err != nil {
+		return err
+	}
+	if err := writeDockerfile(f, *opts.goVersion, commit, datasets, *opts.iterations, *opts.count); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if _, err := exec.LookPath(*opts.engine); err != nil {
+		if *opts.contextDir == "" {
+			return fmt.Errorf("%s not found; use -context to keep the build context", *opts.engine)
+		}
+		return fmt.Errorf("%s not found; the build context is in %s", *opts.engine, dir)
+	}
+	results, err := filepath.Abs(*opts.out)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(results, 0o755); err != nil {
+		return err
+	}
+	image := "jsonbench-reproduce:go" + *opts.goVersion
+	if err := run(*opts.engine, "build", "-t", image, dir); err != nil {
+		return err
+	}
+	// --cpuset-cpus keeps the benchmark on the same cores between runs,
+	// --cpus stops it from borrowing time from the others
+	if err := run(*opts.engine, "run", "--rm",
+		"--cpuset-cpus", fmt.Sprintf("0-%d", *opts.cpus-1),
+		"--cpus", strconv.Itoa(*opts.cpus),
+		"-v", results+":/results",
+		image); err != nil {
+		return err
+	}
+	fmt.Printf("results written to %s (commit %s)\n", filepath.Join(results, "results.json"), commit)
+	return nil
+}
+
+// run runs a command with its output going to ours
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// roundTrip decodes data with b, re-encodes the result with b, decodes it
+// again and describes the first difference between the two decoded values
+func roundTrip(b backends.Backend, data []byte, opt backends.CompareOptions) (string, error) {
+	enc, ok := b.(backends.Encoder)
+	if !ok {
+		return "", fmt.Errorf("%s cannot encode", b.Name())
+	}
+	first, err := b.Decode(data)
+	if err != nil {
+		return "", fmt.Errorf("decode: %w", err)
+	}
+	out, err := enc.Encode(first)
+	if err != nil {
+		return "", fmt.Errorf("encode: %w", err)
+	}
+	// The input and the output compare under the same options as the
+	// decoded values, so -tolerance and -exact apply to both
+	eq, err := backends.EqualWith(data, out, opt)
+	if err != nil {
+		return "", fmt.Errorf("compare: %w", err)
+	}
+	if !eq {
+		return "re-encoded document is not equal to the input", nil
+	}
+	second, err := b.Decode(out)
+	if err != nil {
+		return "", fmt.Errorf("decode of re-encoded output: %w", err)
+	}
+	return backends.Diff("$", first, second, opt), nil
+}
+
+// roundTripFlags are the flags of roundtrip
+type roundTripFlags struct {
+	file      *string
+	tolerance *float64
+	exact     *bool
+}
+
+func (opts *roundTripFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("roundtrip")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to round trip")
+	opts.tolerance = fs.Float64("tolerance", 0, "relative difference allowed between numbers")
+	opts.exact = fs.Bool("exact", false, "compare the numbers of the input and the re-encoded output as exact decimals, not as float64")
+	return fs
+}
+
+// Check that decode -> encode -> decode is lossless for every backend
+func runRoundTrip(args []string) error {
+	var opts roundTripFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	opt := backends.CompareOptions{Tolerance: *opts.tolerance, ExactNumbers: *opts.exact}
+	lossy := false
+	for _, b := range backends.All() {
+		if _, ok := b.(backends.Encoder); !ok {
+			fmt.Printf("%-16s skipped (no encoder)\n", b.Name())
+			continue
+		}
+		diff, err := roundTrip(b, data, opt)
+		switch {
+		case err != nil:
+			fmt.Printf("%-16s error: %v\n", b.Name(), err)
+			lossy = true
+		case diff != "":
+			fmt.Printf("%-16s lossy: %s\n", b.Name(), diff)
+			lossy = true
+		default:
+			fmt.Printf("%-16s ok\n", b.Name())
+		}
+	}
+	if lossy {
+		return withKind(errMismatch, errors.New("round trip is not lossless for every backend"))
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// runAllEntry describes one benchmark program of the talks repo
+type runAllEntry struct {
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	// Dir is the working directory, relative to the manifest; environment
+	// variables are expanded
+	Dir string `json:"dir"`
+	// Env is added to the environment of both commands
+	Env   []string `json:"env,omitempty"`
+	Build []string `json:"build,omitempty"`
+	// Run may use {file} (absolute dataset path), {n} (iterations) and
+	// {out} (a JSON result file the program writes in the shared schema).
+	// Without {out}, results are parsed from stdout.
+	Run []string `json:"run"`
+	// Backend names the result when the program only prints a number
+	Backend string `json:"backend,omitempty"`
+	// Optional entries are skipped when their directory or program is
+	// missing
+	Optional bool `json:"optional,omitempty"`
+}
+
+// throughputOnly matches outputs like parse_twitter.go's "(278.97 MB/s)"
+var throughputOnly = regexp.MustCompile(`([0-9.]+)\s*(GB|MB)/s`)
+
+// expandArgs substitutes the placeholders of a manifest command
+func expandArgs(args []string, file string, n int, out string) []string {
+	r := strings.NewReplacer("{file}", file, "{n}", strconv.Itoa(n), "{out}", out)
+	expanded := make([]string, len(args))
+	for i, a := range args {
+		expanded[i] = os.ExpandEnv(r.Replace(a))
+	}
+	return expanded
+}
+
+// execIn runs argv in dir and returns its standard output
+func execIn(dir string, env []string, argv []string) ([]byte, error) {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+// runEntry builds and runs one benchmark and normalizes its results
+func runEntry(e runAllEntry, base, file, dataset string, n int) ([]aggregated, error) {
+	d := os.ExpandEnv(e.Dir)
+	if d == "" && e.Dir != "" {
+		return nil, fmt.Errorf("%s is not set", e.Dir)
+	}
+	dir := filepath.Join(base, d)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	if len(e.Build) > 0 {
+		if _, err := execIn(dir, e.Env, expandArgs(e.Build, file, n, "")); err != nil {
+			return nil, fmt.Errorf("build: %w", err)
+		}
+	}
+	out, err := os.CreateTemp("", "runall-*.json")
+	if err != nil {
+		return nil, err
+	}
+	out.Close()
+	defer os.Remove(out.Name())
+	// bench writes the manifest of its results next to them
+	defer os.Remove(bench.ManifestPath(out.Name()))
+	stdout, err := execIn(dir, e.Env, expandArgs(e.Run, file, n, out.Name()))
+	if err != nil {
+		return nil, fmt.Errorf("run: %w", err)
+	}
+
+	if strings.Contains(strings.Join(e.Run, " "), "{out}") {
+		rf, err := bench.ReadResults(out.Name())
+		if err != nil {
+			return nil, err
+		}
+		var rows []aggregated
+		for _, r := range rf.Results {
+			rows = append(rows, aggregated{Language: e.Language, Dataset: r.Dataset, Backend: r.Backend, MBPerSec: r.MBPerSec})
+		}
+		return rows, nil
+	}
+	tmp, err := os.CreateTemp("", "runall-*.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Write(stdout)
+	tmp.Close()
+	rows, err := readTextResults(tmp.Name(), e.Language, dataset)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 && e.Backend != "" {
+		if m := throughputOnly.FindSubmatch(bytes.TrimSpace(stdout)); m != nil {
+			speed, _ := strconv.ParseFloat(string(m[1]), 64)
+			if string(m[2]) == "GB" {
+				speed *= 1000
+			}
+			rows = append(rows, aggregated{Language: e.Language, Dataset: dataset, Backend: e.Backend, MBPerSec: speed})
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no results in output")
+	}
+	return rows, nil
+}
+
+// runAllFlags are the flags of run-all
+type runAllFlags struct {
+	manifest   *string
+	file       *string
+	iterations *int
+	baseline   *string
+}
+
+func (opts *runAllFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("run-all")
+	opts.manifest = fs.String("manifest", "runall.json", "list of benchmark programs")
+	opts.file = fs.String("file", "../twitter.json", "dataset passed to every benchmark")
+	opts.iterations = fs.Int("n", 100, "iterations passed to every benchmark")
+	opts.baseline = fs.String("baseline", report.BaselineBackend, "backend the speedup column is relative to")
+	return fs
+}
+
+// Build and run every benchmark listed in the manifest on the same dataset
+// with the same iteration count, then print one combined table
+func runRunAll(args []string) error {
+	var opts runAllFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := os.ReadFile(*opts.manifest)
+	if err != nil {
+		return err
+	}
+	var entries []runAllEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("%s: %w", *opts.manifest, err)
+	}
+	abs, err := filepath.Abs(*opts.file)
+	if err != nil {
+		return err
+	}
+	base := filepath.Dir(*opts.manifest)
+	dataset := filepath.Base(abs)
+
+	var rows []aggregated
+	for _, e := range entries {
+		fmt.Fprintf(os.Stderr, "== %s (%s)\n", e.Name, e.Language)
+		list, err := runEntry(e, base, abs, dataset, *opts.iterations)
+		if err != nil {
+			if e.Optional {
+				fmt.Fprintf(os.Stderr, "skipping %s: %v\n", e.Name, err)
+				continue
+			}
+			return fmt.Errorf("%s: %w", e.Name, err)
+		}
+		rows = append(rows, list...)
+	}
+	printAggregated(rows, *opts.baseline)
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// scanFlags are the flags of scan
+type scanFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *scanFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("scan")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to index")
+	opts.iterations = fs.Int("n", 500, "number of iterations")
+	return fs
+}
+
+// Time stage 1 alone: finding the structural characters of a document,
+// byte by byte as backends.StructuralIndex does, and 64 bytes at a time
+// with the scanner package, once per supported implementation. Every
+// scanner must find the same offsets.
+func runScan(args []string) error {
+	var opts scanFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	want, err := backends.StructuralIndex(data, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	fmt.Printf("%s: %d bytes, %d structural offsets\n", datasets.Name(*opts.file), len(data), len(want))
+	for _, impl := range simd.Implementations() {
+		state := "unsupported"
+		switch {
+		case impl.Name == simd.Active().Name:
+			state = "active"
+		case impl.Supported:
+			state = "supported"
+		}
+		fmt.Printf("  %-8s %-11s %s\n", impl.Name, state, impl.Description)
+	}
+
+	fmt.Println("\n| Scanner | GB/s | speedup |")
+	fmt.Println("|---|---:|---:|")
+	idx := make([]uint32, 0, len(want))
+	base := 0.0
+	measure := func(name string, index func([]byte, []uint32) ([]uint32, error)) error {
+		got, err := index(data, idx[:0])
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if !slices.Equal(got, want) {
+			i := 0
+			for i < len(got) && i < len(want) && got[i] == want[i] {
+				i++
+			}
+			return withKind(errMismatch, fmt.Errorf("%s: structural offset %d differs from the byte loop's", name, i))
+		}
+		speed, err := bench.Measure(data, *opts.iterations, func(data []byte) error {
+			_, err := index(data, idx[:0])
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if base == 0 {
+			base = speed
+		}
+		fmt.Printf("| %s | %.2f | %.1f× |\n", name, speed/1000, speed/base)
+		return nil
+	}
+	if err := measure("byte loop", backends.StructuralIndex); err != nil {
+		return err
+	}
+	return forEachImplementation(func(impl simd.Implementation) error {
+		return measure("scanner, "+scanner.Name(), scanner.Index)
+	})
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/resultschema"
+)
+
+// schemaFlags are the flags of schema, which has none
+type schemaFlags struct{}
+
+func (*schemaFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("schema")
+	return fs
+}
+
+// Print the JSON Schema of the shared result format
+func runSchema(args []string) error {
+	var opts schemaFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	schema, err := resultschema.JSONSchema()
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(schema))
+	return nil
+}
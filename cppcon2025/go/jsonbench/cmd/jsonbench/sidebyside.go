@@ -0,0 +1,205 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// sideProgram is one half of the comparison: its source, the binary
+// built from it, and what running it printed
+type sideProgram struct {
+	title    string
+	source   string
+	binary   string
+	lines    int // of code, without blank and comment-only lines
+	size     int64
+	output   []string
+	mbPerSec float64
+}
+
+// codeLines counts the lines of src that are neither blank nor only a
+// // comment, the comment syntax Go and C++ share
+func codeLines(src []byte) int {
+	n := 0
+	s := bufio.NewScanner(bytes.NewReader(src))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line != "" && !strings.HasPrefix(line, "//") {
+			n++
+		}
+	}
+	return n
+}
+
+// run measures the source and binary of p and runs it with args
+func (p *sideProgram) run(args []string) error {
+	src, err := os.ReadFile(p.source)
+	if err != nil {
+		return err
+	}
+	p.lines = codeLines(src)
+	fi, err := os.Stat(p.binary)
+	if err != nil {
+		return err
+	}
+	p.size = fi.Size()
+	stdout, err := execIn("", nil, append([]string{p.binary}, args...))
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.title, err)
+	}
+	p.output = strings.Split(strings.TrimRight(string(stdout), "\n"), "\n")
+	m := throughputOnly.FindSubmatch(stdout)
+	if m == nil {
+		return fmt.Errorf("%s: no throughput in output", p.title)
+	}
+	p.mbPerSec, _ = strconv.ParseFloat(string(m[1]), 64)
+	if string(m[2]) == "GB" {
+		p.mbPerSec *= 1000
+	}
+	return nil
+}
+
+// fitColumn cuts or pads s to width runes
+func fitColumn(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		r := []rune(s)
+		return string(r[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// binarySize prints the size of a binary in KB or MB
+func binarySize(n int64) string {
+	if n < 1e6 {
+		return fmt.Sprintf("%.0f KB binary", float64(n)/1e3)
+	}
+	return fmt.Sprintf("%.1f MB binary", float64(n)/1e6)
+}
+
+// printSideBySide renders the two programs in two columns, then the
+// throughput of both as bars over the full width
+func printSideBySide(left, right *sideProgram, width int) {
+	column := (width - 3) / 2
+	rule := strings.Repeat("─", column)
+	row := func(l, r string) {
+		fmt.Printf("%s │ %s\n", fitColumn(l, column), fitColumn(r, column))
+	}
+	row(left.title, right.title)
+	fmt.Printf("%s─┼─%s\n", rule, rule)
+	row(filepath.Base(left.source), filepath.Base(right.source))
+	row(fmt.Sprintf("%d lines of code", left.lines), fmt.Sprintf("%d lines of code", right.lines))
+	row(binarySize(left.size), binarySize(right.size))
+	fmt.Printf("%s─┼─%s\n", rule, rule)
+	for i := 0; i < len(left.output) || i < len(right.output); i++ {
+		var l, r string
+		if i < len(left.output) {
+			l = left.output[i]
+		}
+		if i < len(right.output) {
+			r = right.output[i]
+		}
+		row(l, r)
+	}
+	fmt.Printf("%s─┴─%s\n\n", rule, rule)
+
+	max := left.mbPerSec
+	if right.mbPerSec > max {
+		max = right.mbPerSec
+	}
+	nameWidth := utf8.RuneCountInString(left.title)
+	if n := utf8.RuneCountInString(right.title); n > nameWidth {
+		nameWidth = n
+	}
+	barWidth := width - nameWidth - 24
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	for _, p := range []*sideProgram{left, right} {
+		n := int(p.mbPerSec/max*float64(barWidth) + 0.5)
+		fmt.Printf("%s %s%s %8.0f MB/s %5.1fx\n", fitColumn(p.title, nameWidth),
+			strings.Repeat("█", n), strings.Repeat(" ", barWidth-n), p.mbPerSec, p.mbPerSec/left.mbPerSec)
+	}
+}
+
+// sideBySideFlags are the flags of sidebyside
+type sideBySideFlags struct {
+	goSource   *string
+	cppSource  *string
+	cppBinary  *string
+	file       *string
+	iterations *int
+	width      *int
+}
+
+func (opts *sideBySideFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("sidebyside")
+	opts.goSource = fs.String("go", "../parse_twitter.go", "Go program, built with go build")
+	opts.cppSource = fs.String("cpp", "../../software/parse_twitter.cpp", "C++ program, for its code size")
+	opts.cppBinary = fs.String("cpp-bin", "../../software/build/parse_twitter", "C++ program built with the software/ CMake project")
+	opts.file = fs.String("file", "../twitter.json", "JSON document both programs parse")
+	opts.iterations = fs.Int("n", 1000, "iterations passed to both programs")
+	opts.width = fs.Int("width", 0, "width of the screen (default $COLUMNS, or 160)")
+	return fs
+}
+
+// Run parse_twitter.go and its C++ simdjson counterpart on the same
+// document and show them split-screen: code size, output and throughput,
+// the comparison the talk is built around
+func runSideBySide(args []string) error {
+	var opts sideBySideFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	if *opts.width == 0 {
+		*opts.width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+		if *opts.width < 40 {
+			*opts.width = 160
+		}
+	}
+	if _, err := os.Stat(*opts.cppBinary); err != nil {
+		return withKind(errUnavailable, fmt.Errorf("sidebyside: %w; build it with cmake -B build && cmake --build build --target parse_twitter in software/", err))
+	}
+	abs, err := filepath.Abs(*opts.file)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return withKind(errDataset, err)
+	}
+	dir, err := os.MkdirTemp("", "sidebyside-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	goBinary := filepath.Join(dir, "parse_twitter")
+	source, err := filepath.Abs(*opts.goSource)
+	if err != nil {
+		return err
+	}
+	// The slide programs share a directory, so the source is built alone
+	if _, err := execIn(filepath.Dir(source), nil, []string{"go", "build", "-o", goBinary, filepath.Base(source)}); err != nil {
+		return fmt.Errorf("go build %s: %w", *opts.goSource, err)
+	}
+
+	flags := []string{"-file", abs, "-n", strconv.Itoa(*opts.iterations)}
+	left := &sideProgram{title: "Go (encoding/json)", source: *opts.goSource, binary: goBinary}
+	right := &sideProgram{title: "C++ (simdjson On-Demand)", source: *opts.cppSource, binary: *opts.cppBinary}
+	for _, p := range []*sideProgram{left, right} {
+		fmt.Fprintf(os.Stderr, "== %s\n", p.title)
+		if err := p.run(flags); err != nil {
+			return err
+		}
+	}
+	printSideBySide(left, right, *opts.width)
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/dom"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/ondemand"
+)
+
+// skipStatus is what the skip experiment reads of a status: two fields
+// before its largest subtrees, retweeted_status and entities, and one
+// after them, so that every method has to get past both
+type skipStatus struct {
+	ID            uint64 `json:"id"`
+	RetweetCount  uint64 `json:"retweet_count"`
+	FavoriteCount uint64 `json:"favorite_count"`
+	Lang          string `json:"lang"`
+}
+
+// skipLazy reads the fields with the hand-rolled decoder, which skips
+// every other value by scanning it byte by byte
+func skipLazy(data []byte) ([]skipStatus, error) {
+	d := backends.NewDecoder(data, backends.DecodeOptions{SyntaxOnly: true})
+	var out []skipStatus
+	err := d.Members(func(key []byte) error {
+		if string(key) != "statuses" {
+			return d.Skip()
+		}
+		return d.Elements(func() error {
+			var s skipStatus
+			err := d.Members(func(key []byte) (err error) {
+				switch string(key) {
+				case "id":
+					s.ID, err = d.Uint64()
+				case "retweet_count":
+					s.RetweetCount, err = d.Uint64()
+				case "favorite_count":
+					s.FavoriteCount, err = d.Uint64()
+				case "lang":
+					s.Lang, err = d.String()
+				default:
+					err = d.Skip()
+				}
+				return err
+			})
+			out = append(out, s)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, d.End()
+}
+
+// skipOnDemand reads the fields in document order from the structural
+// index; skipping a subtree moves past its structural characters only
+func skipOnDemand(parser *ondemand.Parser, data []byte) ([]skipStatus, error) {
+	doc, err := parser.Iterate(data)
+	if err != nil {
+		return nil, err
+	}
+	var out []skipStatus
+	statuses := doc.Field("statuses").GetArray()
+	for statuses.Next() {
+		status := statuses.Value().GetObject()
+		var s skipStatus
+		var errs [4]error
+		s.ID, errs[0] = status.Field("id").GetUint64()
+		s.RetweetCount, errs[1] = status.Field("retweet_count").GetUint64()
+		s.FavoriteCount, errs[2] = status.Field("favorite_count").GetUint64()
+		s.Lang, errs[3] = status.Field("lang").GetString()
+		if err := errors.Join(errs[:]...); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, statuses.Err()
+}
+
+// skipDOM builds the tape, then looks the fields up; every field before
+// one is jumped over, a container in one step
+func skipDOM(parser *dom.Parser, data []byte) ([]skipStatus, error) {
+	root, err := parser.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := root.Field("statuses").GetArray()
+	if err != nil {
+		return nil, err
+	}
+	var out []skipStatus
+	statuses.Each(func(e dom.Element) bool {
+		var s skipStatus
+		var errs [4]error
+		s.ID, errs[0] = e.Field("id").GetUint64()
+		s.RetweetCount, errs[1] = e.Field("retweet_count").GetUint64()
+		s.FavoriteCount, errs[2] = e.Field("favorite_count").GetUint64()
+		s.Lang, errs[3] = e.Field("lang").GetString()
+		if err = errors.Join(errs[:]...); err != nil {
+			return false
+		}
+		out = append(out, s)
+		return true
+	})
+	return out, err
+}
+
+// subtreeBytes returns the bytes of the values of keys in the statuses
+// of data, as written
+func subtreeBytes(data []byte, keys ...string) (int, error) {
+	d := backends.NewDecoder(data, backends.DecodeOptions{SyntaxOnly: true})
+	n := 0
+	err := d.Members(func(key []byte) error {
+		if string(key) != "statuses" {
+			return d.Skip()
+		}
+		return d.Elements(func() error {
+			return d.Members(func(key []byte) error {
+				raw, err := d.SkipRaw()
+				for _, k := range keys {
+					if string(key) == k {
+						n += len(raw)
+					}
+				}
+				return err
+			})
+		})
+	})
+	return n, err
+}
+
+// skipFlags are the flags of skip
+type skipFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *skipFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("skip")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to read")
+	opts.iterations = fs.Int("n", 200, "number of iterations")
+	return fs
+}
+
+// Time reading a few fields of every status past the subtrees that are
+// not needed: encoding/json and the hand-rolled decoder scan the skipped
+// bytes, On-Demand and the DOM jump over them
+func runSkip(args []string) error {
+	var opts skipFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var want struct{ Statuses []skipStatus }
+	if err := json.Unmarshal(data, &want); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	skipped, err := subtreeBytes(data, "retweeted_status", "entities")
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	fmt.Printf("%s: %d bytes, %d statuses; retweeted_status and entities are %d bytes (%.0f%%)\n\n",
+		datasets.Name(*opts.file), len(data), len(want.Statuses), skipped, 100*float64(skipped)/float64(len(data)))
+
+	var odParser ondemand.Parser
+	var domParser dom.Parser
+	methods := []struct {
+		name string
+		read func([]byte) ([]skipStatus, error)
+	}{
+		{"encoding/json, unknown fields skipped", func(data []byte) ([]skipStatus, error) {
+			var v struct{ Statuses []skipStatus }
+			err := json.Unmarshal(data, &v)
+			return v.Statuses, err
+		}},
+		{"hand-rolled, Skip", skipLazy},
+		{"On-Demand, structural index", func(data []byte) ([]skipStatus, error) {
+			return skipOnDemand(&odParser, data)
+		}},
+		{"dom, tape jumps", func(data []byte) ([]skipStatus, error) {
+			return skipDOM(&domParser, data)
+		}},
+	}
+	fmt.Println("| Method | MB/s | speedup |")
+	fmt.Println("|---|---:|---:|")
+	base := 0.0
+	for _, m := range methods {
+		got, err := m.read(data)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", m.name, *opts.file, withKind(errDataset, err))
+		}
+		if !reflect.DeepEqual(got, want.Statuses) {
+			return withKind(errMismatch, fmt.Errorf("%s: the fields differ from those of encoding/json", m.name))
+		}
+		speed, err := bench.Measure(data, *opts.iterations, func(data []byte) error {
+			_, err := m.read(data)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		if base == 0 {
+			base = speed
+		}
+		fmt.Printf("| %s | %.1f | %.1f× |\n", m.name, speed, speed/base)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// findSnippets extracts the snippets of the Go sources under each root,
+// skipping hidden directories and build output
+func findSnippets(roots []string) ([]report.Snippet, error) {
+	var list []report.Snippet
+	seen := map[string]string{}
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if name := d.Name(); path != root && (strings.HasPrefix(name, ".") || name == "bin") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			snippets, err := report.ExtractSnippets(path, src)
+			if err != nil {
+				return err
+			}
+			for _, s := range snippets {
+				if first, ok := seen[s.Name]; ok {
+					return fmt.Errorf("%s:%d: snippet %s is also in %s", path, s.Line-1, s.Name, first)
+				}
+				seen[s.Name] = fmt.Sprintf("%s:%d", path, s.Line-1)
+				list = append(list, s)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// snippetsFlags are the flags of snippets
+type snippetsFlags struct {
+	out     *string
+	formats *string
+	history *string
+	dataset *string
+	cssFile *string
+	list    *bool
+}
+
+func (opts *snippetsFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("snippets")
+	opts.out = fs.String("o", "snippets", "directory to write name.html and name.svg to")
+	opts.formats = fs.String("format", "html,svg", "comma-separated list of html and svg")
+	opts.history = fs.String("history", "results.jsonl", "bench history the throughput of this machine is taken from (empty for none)")
+	opts.dataset = fs.String("dataset", "twitter.json", "dataset whose throughput the captions quote")
+	opts.cssFile = fs.String("css", "", "stylesheet replacing the default highlighting")
+	opts.list = fs.Bool("list", false, "list the snippets and write nothing")
+	return fs
+}
+
+// Extract the regions of the Go sources marked with // snippet:name
+// comments into highlighted HTML and SVG fragments, captioned with the
+// latest throughput of their backend, so the code on the slides stays the
+// code that is measured
+func runSnippets(args []string) error {
+	var opts snippetsFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	roots := fs.Args()
+	if len(roots) == 0 {
+		// The jsonbench sources and the slide programs next to them
+		roots = []string{".."}
+	}
+	snippets, err := findSnippets(roots)
+	if err != nil {
+		return err
+	}
+	if *opts.list {
+		for _, s := range snippets {
+			lines := strings.Count(s.Source, "\n")
+			fmt.Printf("%s\t%s:%d-%d\t%s\n", s.Name, s.File, s.Line, s.Line+lines-1, s.Backend)
+		}
+		return nil
+	}
+	css := report.DefaultSnippetCSS
+	if *opts.cssFile != "" {
+		data, err := os.ReadFile(*opts.cssFile)
+		if err != nil {
+			return err
+		}
+		css = string(data)
+	}
+	renderers := map[string]func(report.Snippet, string, string) string{
+		"html": report.SnippetHTML,
+		"svg":  report.SnippetSVG,
+	}
+	var kinds []string
+	for _, kind := range strings.Split(*opts.formats, ",") {
+		if _, ok := renderers[kind]; !ok {
+			return withKind(errUsage, fmt.Errorf("snippets: unknown format %q", kind))
+		}
+		kinds = append(kinds, kind)
+	}
+	speeds, err := latestSpeeds(*opts.history)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*opts.out, 0o755); err != nil {
+		return err
+	}
+	for _, s := range snippets {
+		caption := ""
+		if s.Backend != "" {
+			if speed, ok := speeds[[2]string{*opts.dataset, s.Backend}]; ok {
+				caption = fmt.Sprintf("%s: %.0f MB/s on %s", s.Backend, speed, *opts.dataset)
+			} else {
+				fmt.Fprintf(os.Stderr, "snippets: no %s result for %s on this machine; %s has no caption\n", *opts.dataset, s.Backend, s.Name)
+			}
+		}
+		for _, kind := range kinds {
+			path := filepath.Join(*opts.out, s.Name+"."+kind)
+			if err := os.WriteFile(path, []byte(renderers[kind](s, css, caption)), 0o644); err != nil {
+				return err
+			}
+			fmt.Println(path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,847 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// A toy SQL over JSON rows:
+//
+//	SELECT * | expr [AS name], ...
+//	[FROM path] [WHERE expr] [ORDER BY expr|n [ASC|DESC], ...] [LIMIT n]
+//
+// where expressions are dotted paths into the row (a number indexes an
+// array), number, 'string', TRUE, FALSE and NULL literals, =, <> or !=,
+// <, <=, >, >=, IS [NOT] NULL, NOT, AND, OR and parentheses. A missing
+// member is NULL and the logic is SQL's three-valued one: WHERE keeps
+// the rows for which the condition is TRUE. FROM names the array of rows
+// in a JSON document; over NDJSON every line is a row and FROM is only a
+// name.
+const exampleSQL = "SELECT user.screen_name, user.followers_count FROM statuses WHERE user.verified ORDER BY 2 DESC LIMIT 10"
+
+// sqlExpr evaluates an expression over a row
+type sqlExpr func(row interface{}) interface{}
+
+type sqlColumn struct {
+	name string
+	expr sqlExpr
+}
+
+// sqlOrder is an ORDER BY item: a selected column when column is not
+// negative, an expression over the row otherwise
+type sqlOrder struct {
+	column int
+	expr   sqlExpr
+	desc   bool
+}
+
+type sqlQuery struct {
+	columns []sqlColumn
+	from    []string
+	where   sqlExpr
+	order   []sqlOrder
+	limit   int
+	// paths are the paths the query reads, nil for SELECT * or when it
+	// reads none
+	paths [][]string
+}
+
+// sqlToken is a lexeme, with the kinds of queryToken: 'i' an identifier
+// or dotted path, 'n' a number, 's' a string and 'p' punctuation
+type sqlToken struct {
+	kind byte
+	text string
+}
+
+func lexSQL(src string) ([]sqlToken, error) {
+	var toks []sqlToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentByte(c, true):
+			j := i
+			for j < len(src) && (isIdentByte(src[j], false) || src[j] == '.' && j+1 < len(src) && isIdentByte(src[j+1], false)) {
+				j++
+			}
+			toks = append(toks, sqlToken{'i', src[i:j]})
+			i = j
+		case c == '-' || c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(src) && strings.IndexByte("0123456789.eE", src[j]) >= 0 {
+				j++
+			}
+			toks = append(toks, sqlToken{'n', src[i:j]})
+			i = j
+		case c == '\'':
+			// '' is a quote inside a string
+			var s strings.Builder
+			j := i + 1
+			for ; j < len(src); j++ {
+				if src[j] == '\'' {
+					if j+1 < len(src) && src[j+1] == '\'' {
+						j++
+					} else {
+						break
+					}
+				}
+				s.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, errors.New("unterminated string")
+			}
+			toks = append(toks, sqlToken{'s', s.String()})
+			i = j + 1
+		default:
+			op := src[i : i+1]
+			if i+1 < len(src) && (src[i+1] == '=' && strings.IndexByte("!<>", c) >= 0 || c == '<' && src[i+1] == '>') {
+				op = src[i : i+2]
+			} else if strings.IndexByte(",()*=<>", c) < 0 {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			toks = append(toks, sqlToken{'p', op})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+type sqlParser struct {
+	toks  []sqlToken
+	pos   int
+	paths [][]string
+}
+
+// compileSQL parses a query
+func compileSQL(src string) (*sqlQuery, error) {
+	toks, err := lexSQL(src)
+	if err != nil {
+		return nil, fmt.Errorf("sql: %w", err)
+	}
+	p := &sqlParser{toks: toks}
+	q, err := p.query()
+	if err == nil && p.pos < len(p.toks) {
+		err = fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sql: %w", err)
+	}
+	return q, nil
+}
+
+// keyword accepts a keyword, in any case
+func (p *sqlParser) keyword(kw string) bool {
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == 'i' && strings.EqualFold(p.toks[p.pos].text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) accept(op string) bool {
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == 'p' && p.toks[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) expect(text string, ok bool) error {
+	if ok {
+		return nil
+	}
+	if p.pos < len(p.toks) {
+		return fmt.Errorf("expected %s, found %q", text, p.toks[p.pos].text)
+	}
+	return fmt.Errorf("expected %s at the end", text)
+}
+
+var sqlKeywords = map[string]bool{
+	"SELECT": true, "AS": true, "FROM": true, "WHERE": true, "ORDER": true, "BY": true, "ASC": true, "DESC": true,
+	"LIMIT": true, "AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true, "TRUE": true, "FALSE": true,
+}
+
+// name accepts an identifier that is not a keyword
+func (p *sqlParser) name() (string, bool) {
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == 'i' && !sqlKeywords[strings.ToUpper(p.toks[p.pos].text)] {
+		p.pos++
+		return p.toks[p.pos-1].text, true
+	}
+	return "", false
+}
+
+func (p *sqlParser) query() (*sqlQuery, error) {
+	q := &sqlQuery{limit: -1}
+	if err := p.expect("SELECT", p.keyword("SELECT")); err != nil {
+		return nil, err
+	}
+	star := false
+	if p.accept("*") {
+		star = true
+		q.columns = []sqlColumn{{"*", func(row interface{}) interface{} { return row }}}
+	} else {
+		for {
+			start := p.pos
+			e, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			col := sqlColumn{expr: e}
+			var texts []string
+			for _, t := range p.toks[start:p.pos] {
+				texts = append(texts, t.text)
+			}
+			col.name = strings.Join(texts, " ")
+			if p.keyword("AS") {
+				var ok bool
+				col.name, ok = p.name()
+				if err := p.expect("a column name", ok); err != nil {
+					return nil, err
+				}
+			}
+			q.columns = append(q.columns, col)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.keyword("FROM") {
+		from, ok := p.name()
+		if err := p.expect("a table path", ok); err != nil {
+			return nil, err
+		}
+		q.from = strings.Split(from, ".")
+	}
+	if p.keyword("WHERE") {
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		q.where = e
+	}
+	if p.keyword("ORDER") {
+		if err := p.expect("BY", p.keyword("BY")); err != nil {
+			return nil, err
+		}
+		for {
+			o, err := p.orderItem(q)
+			if err != nil {
+				return nil, err
+			}
+			q.order = append(q.order, o)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.keyword("LIMIT") {
+		ok := p.pos < len(p.toks) && p.toks[p.pos].kind == 'n'
+		if err := p.expect("a row count", ok); err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(p.toks[p.pos].text)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("bad LIMIT %q", p.toks[p.pos].text)
+		}
+		p.pos++
+		q.limit = n
+	}
+	if !star {
+		q.paths = p.paths
+	}
+	return q, nil
+}
+
+// orderItem parses an ORDER BY item: a column position, a column name
+// or an expression
+func (p *sqlParser) orderItem(q *sqlQuery) (sqlOrder, error) {
+	o := sqlOrder{column: -1}
+	if p.pos < len(p.toks) {
+		t := p.toks[p.pos]
+		if t.kind == 'n' {
+			n, err := strconv.Atoi(t.text)
+			if err != nil || n < 1 || n > len(q.columns) {
+				return o, fmt.Errorf("ORDER BY %s is not a column of the SELECT list", t.text)
+			}
+			o.column = n - 1
+			p.pos++
+		} else if t.kind == 'i' && (p.pos+1 == len(p.toks) || p.toks[p.pos+1].kind != 'p' || p.toks[p.pos+1].text == ",") {
+			for i, c := range q.columns {
+				if c.name == t.text {
+					o.column = i
+					p.pos++
+					break
+				}
+			}
+		}
+	}
+	if o.column < 0 {
+		e, err := p.or()
+		if err != nil {
+			return o, err
+		}
+		o.expr = e
+	}
+	if p.keyword("DESC") {
+		o.desc = true
+	} else {
+		p.keyword("ASC")
+	}
+	return o, nil
+}
+
+func (p *sqlParser) or() (sqlExpr, error) {
+	left, err := p.and()
+	for err == nil && p.keyword("OR") {
+		var right sqlExpr
+		if right, err = p.and(); err == nil {
+			l, r := left, right
+			left = func(row interface{}) interface{} {
+				a, b := sqlBool(l(row)), sqlBool(r(row))
+				if a == true || b == true {
+					return true
+				}
+				if a == nil || b == nil {
+					return nil
+				}
+				return false
+			}
+		}
+	}
+	return left, err
+}
+
+func (p *sqlParser) and() (sqlExpr, error) {
+	left, err := p.not()
+	for err == nil && p.keyword("AND") {
+		var right sqlExpr
+		if right, err = p.not(); err == nil {
+			l, r := left, right
+			left = func(row interface{}) interface{} {
+				a, b := sqlBool(l(row)), sqlBool(r(row))
+				if a == false || b == false {
+					return false
+				}
+				if a == nil || b == nil {
+					return nil
+				}
+				return true
+			}
+		}
+	}
+	return left, err
+}
+
+func (p *sqlParser) not() (sqlExpr, error) {
+	if !p.keyword("NOT") {
+		return p.comparison()
+	}
+	e, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	return func(row interface{}) interface{} {
+		if b, ok := sqlBool(e(row)).(bool); ok {
+			return !b
+		}
+		return nil
+	}, nil
+}
+
+func (p *sqlParser) comparison() (sqlExpr, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if p.keyword("IS") {
+		negate := p.keyword("NOT")
+		if err := p.expect("NULL", p.keyword("NULL")); err != nil {
+			return nil, err
+		}
+		return func(row interface{}) interface{} { return (left(row) == nil) != negate }, nil
+	}
+	for _, op := range []string{"=", "<>", "!=", "<=", ">=", "<", ">"} {
+		if !p.accept(op) {
+			continue
+		}
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		return func(row interface{}) interface{} { return sqlCompare(op, left(row), right(row)) }, nil
+	}
+	return left, nil
+}
+
+func (p *sqlParser) primary() (sqlExpr, error) {
+	if p.accept("(") {
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect("')'", p.accept(")"))
+	}
+	switch {
+	case p.keyword("NULL"):
+		return func(interface{}) interface{} { return nil }, nil
+	case p.keyword("TRUE"):
+		return func(interface{}) interface{} { return true }, nil
+	case p.keyword("FALSE"):
+		return func(interface{}) interface{} { return false }, nil
+	}
+	if err := p.expect("an expression", p.pos < len(p.toks)); err != nil {
+		return nil, err
+	}
+	t := p.toks[p.pos]
+	switch t.kind {
+	case 'n':
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q", t.text)
+		}
+		p.pos++
+		return func(interface{}) interface{} { return f }, nil
+	case 's':
+		p.pos++
+		return func(interface{}) interface{} { return t.text }, nil
+	}
+	name, ok := p.name()
+	if err := p.expect("an expression", ok); err != nil {
+		return nil, err
+	}
+	path := strings.Split(name, ".")
+	p.paths = append(p.paths, path)
+	return func(row interface{}) interface{} { return sqlPath(row, path) }, nil
+}
+
+// sqlPath follows path from v, giving nil (NULL) when a step is missing
+func sqlPath(v interface{}, path []string) interface{} {
+	for _, key := range path {
+		switch c := v.(type) {
+		case map[string]interface{}:
+			v = c[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil
+			}
+			v = c[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// sqlBool is v as a condition: a boolean, or nil for anything else
+func sqlBool(v interface{}) interface{} {
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return nil
+}
+
+// sqlCompare compares two values: NULL if either is NULL, unequal if
+// their types differ, and ordered for numbers and strings
+func sqlCompare(op string, a, b interface{}) interface{} {
+	if a == nil || b == nil {
+		return nil
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		switch op {
+		case "=":
+			return false
+		case "<>", "!=":
+			return true
+		}
+		return nil
+	}
+	c := queryCompare(a, b)
+	switch op {
+	case "=":
+		return c == 0
+	case "<>", "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// sqlResult collects the rows of a query
+type sqlResult struct {
+	q    *sqlQuery
+	rows [][]interface{}
+	keys [][]interface{}
+}
+
+// add evaluates the query over one document: the elements of the array
+// at the FROM path, or the document itself when there is no FROM path
+func (r *sqlResult) add(doc interface{}, from []string) {
+	v := sqlPath(doc, from)
+	if arr, ok := v.([]interface{}); ok {
+		for _, row := range arr {
+			r.addRow(row)
+		}
+	} else if v != nil {
+		r.addRow(v)
+	}
+}
+
+func (r *sqlResult) addRow(row interface{}) {
+	q := r.q
+	if q.where != nil && q.where(row) != true {
+		return
+	}
+	// Without ORDER BY the first rows are the result
+	if len(q.order) == 0 && q.limit >= 0 && len(r.rows) >= q.limit {
+		return
+	}
+	values := make([]interface{}, len(q.columns))
+	for i, c := range q.columns {
+		values[i] = c.expr(row)
+	}
+	r.rows = append(r.rows, values)
+	if len(q.order) > 0 {
+		keys := make([]interface{}, len(q.order))
+		for i, o := range q.order {
+			if o.column >= 0 {
+				keys[i] = values[o.column]
+			} else {
+				keys[i] = o.expr(row)
+			}
+		}
+		r.keys = append(r.keys, keys)
+	}
+}
+
+// finish sorts the rows and applies LIMIT. NULLs sort last in ascending
+// order and first in descending order, as in PostgreSQL.
+func (r *sqlResult) finish() [][]interface{} {
+	if len(r.q.order) > 0 {
+		idx := make([]int, len(r.rows))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.SliceStable(idx, func(i, j int) bool {
+			a, b := r.keys[idx[i]], r.keys[idx[j]]
+			for k, o := range r.q.order {
+				var c int
+				switch {
+				case a[k] == nil && b[k] == nil:
+				case a[k] == nil:
+					c = 1
+				case b[k] == nil:
+					c = -1
+				default:
+					c = queryCompare(a[k], b[k])
+				}
+				if o.desc {
+					c = -c
+				}
+				if c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+		rows := make([][]interface{}, len(idx))
+		for i, j := range idx {
+			rows[i] = r.rows[j]
+		}
+		r.rows = rows
+	}
+	if r.q.limit >= 0 && len(r.rows) > r.q.limit {
+		r.rows = r.rows[:r.q.limit]
+	}
+	return r.rows
+}
+
+// projection keeps the paths the query reads below prefix, up to their
+// first array index, since projections treat arrays as transparent
+func (q *sqlQuery) projection(prefix []string) (*projection, error) {
+	if q.paths == nil {
+		return nil, nil
+	}
+	var paths []string
+	for _, path := range q.paths {
+		full := append(append([]string{}, prefix...), path...)
+		for i, key := range full {
+			if _, err := strconv.Atoi(key); err == nil {
+				full = full[:i]
+				break
+			}
+		}
+		if len(full) == 0 {
+			return nil, nil
+		}
+		paths = append(paths, strings.Join(full, "."))
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	return compileProjection(paths)
+}
+
+// executeSQL runs q over a JSON document or, with ndjson set, over each
+// of its lines, decoding with b. With p set, every document is projected
+// first so that b only decodes what the query reads.
+func executeSQL(q *sqlQuery, b backends.Backend, p *projection, data []byte, ndjson bool) ([][]interface{}, error) {
+	r := &sqlResult{q: q}
+	from := q.from
+	if ndjson {
+		from = nil
+	}
+	var buf []byte
+	each := func(doc []byte) error {
+		if p != nil {
+			var err error
+			if buf, err = p.project(doc, buf[:0]); err != nil {
+				return err
+			}
+			doc = buf
+		}
+		v, err := b.Decode(doc)
+		if err != nil {
+			return err
+		}
+		r.add(v, from)
+		return nil
+	}
+	if !ndjson {
+		if err := each(data); err != nil {
+			return nil, err
+		}
+		return r.finish(), nil
+	}
+	for line := 1; len(data) > 0; line++ {
+		var doc []byte
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			doc, data = data[:i], data[i+1:]
+		} else {
+			doc, data = data, nil
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		if err := each(doc); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return r.finish(), nil
+}
+
+// printSQLTable prints rows as aligned columns: strings as they are,
+// NULL for nil and JSON for the other values
+func printSQLTable(q *sqlQuery, rows [][]interface{}) error {
+	cells := make([][]string, len(rows)+1)
+	cells[0] = make([]string, len(q.columns))
+	for i, c := range q.columns {
+		cells[0][i] = c.name
+	}
+	for i, row := range rows {
+		cells[i+1] = make([]string, len(row))
+		for j, v := range row {
+			switch v := v.(type) {
+			case nil:
+				cells[i+1][j] = "NULL"
+			case string:
+				cells[i+1][j] = v
+			default:
+				b, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				cells[i+1][j] = string(b)
+			}
+		}
+	}
+	widths := make([]int, len(q.columns))
+	for _, row := range cells {
+		for j, c := range row {
+			if n := utf8.RuneCountInString(c); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+	out := bufio.NewWriter(os.Stdout)
+	for i, row := range cells {
+		for j, c := range row {
+			if j == len(row)-1 {
+				out.WriteString(c)
+				break
+			}
+			out.WriteString(c)
+			out.WriteString(strings.Repeat(" ", widths[j]-utf8.RuneCountInString(c)+2))
+		}
+		out.WriteByte('\n')
+		if i == 0 {
+			for j, w := range widths {
+				if j > 0 {
+					out.WriteString("  ")
+				}
+				out.WriteString(strings.Repeat("-", w))
+			}
+			out.WriteByte('\n')
+		}
+	}
+	if len(rows) == 1 {
+		fmt.Fprintln(out, "(1 row)")
+	} else {
+		fmt.Fprintf(out, "(%d rows)\n", len(rows))
+	}
+	return out.Flush()
+}
+
+// ndjsonStatuses turns the records of a document into NDJSON, one
+// compacted record per line
+func ndjsonStatuses(data []byte) ([]byte, error) {
+	recs, err := datasets.Records(data)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, r := range recs {
+		if err := json.Compact(&buf, r); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// sqlFlags are the flags of sql
+type sqlFlags struct {
+	file       *string
+	ndjson     *bool
+	only       *string
+	iterations *int
+}
+
+func (opts *sqlFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("sql")
+	fs.Usage = func() {
+		printUsage(fs)
+		fmt.Fprintf(fs.Output(), "\nThe default query is\n\t%s\n", exampleSQL)
+	}
+	opts.file = fs.String("file", "../twitter.json", "JSON document, or NDJSON with -ndjson")
+	opts.ndjson = fs.Bool("ndjson", false, "-file holds one row per line")
+	opts.only = fs.String("backend", "handrolled", "backend to decode with")
+	opts.iterations = fs.Int("n", 20, "benchmark iterations over the JSON and NDJSON forms of -file (0 to skip)")
+	return fs
+}
+
+func runSQL(args []string) error {
+	var opts sqlFlags
+	fs := opts.flags()
+	fs.Parse(args)
+	src := exampleSQL
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		src = fs.Arg(0)
+	default:
+		fs.Usage()
+		return withKind(errUsage, errors.New("sql: expected one query"))
+	}
+	q, err := compileSQL(src)
+	if err != nil {
+		return err
+	}
+	b, err := backends.Lookup(*opts.only)
+	if err != nil {
+		return err
+	}
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+
+	rows, err := executeSQL(q, b, nil, data, *opts.ndjson)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if err := printSQLTable(q, rows); err != nil {
+		return err
+	}
+	if *opts.iterations <= 0 || *opts.ndjson {
+		return nil
+	}
+
+	// The records of -file as one document and as NDJSON lines, each
+	// decoded in full and projected to the paths of the query first
+	lines, err := ndjsonStatuses(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	inputs := []struct {
+		name   string
+		data   []byte
+		ndjson bool
+		prefix []string
+	}{
+		{"JSON", data, false, q.from},
+		{"NDJSON", lines, true, nil},
+	}
+	fmt.Printf("\n%s, decoding with %s\n\n", *opts.file, b.Name())
+	fmt.Println("| Input | Method | MB/s | ms |")
+	fmt.Println("|---|---|---:|---:|")
+	for _, in := range inputs {
+		p, err := q.projection(in.prefix)
+		if err != nil {
+			return err
+		}
+		methods := []struct {
+			name string
+			p    *projection
+		}{{"decode", nil}}
+		if p != nil {
+			methods = append(methods, struct {
+				name string
+				p    *projection
+			}{"project + decode", p})
+		}
+		var want [][]interface{}
+		for i, m := range methods {
+			got, err := executeSQL(q, b, m.p, in.data, in.ndjson)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", in.name, m.name, err)
+			}
+			if i == 0 {
+				want = got
+			} else if !reflect.DeepEqual(got, want) {
+				return fmt.Errorf("%s: %s and %s give different results", in.name, m.name, methods[0].name)
+			}
+			start := time.Now()
+			for i := 0; i < *opts.iterations; i++ {
+				if _, err := executeSQL(q, b, m.p, in.data, in.ndjson); err != nil {
+					return err
+				}
+			}
+			elapsed := time.Since(start) / time.Duration(*opts.iterations)
+			fmt.Printf("| %s | %s | %.2f | %.2f |\n", in.name, m.name,
+				float64(len(in.data))/1e6/elapsed.Seconds(), bench.Milliseconds(elapsed))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/report"
+)
+
+// stageFills draw the stages of the terminal bar, in order
+var stageFills = []string{"█", "▓", "▒", "░", "·"}
+
+// stagesFlags are the flags of stages
+type stagesFlags struct {
+	file       *string
+	iterations *int
+	width      *int
+	svg        *string
+	css        *string
+}
+
+func (opts *stagesFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("stages")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to break down")
+	opts.iterations = fs.Int("n", 200, "number of iterations of each stage")
+	opts.width = fs.Int("width", 60, "width of the stacked bar")
+	opts.svg = fs.String("svg", "", "also write the breakdown as an SVG chart to this file")
+	opts.css = fs.String("css", "", "stylesheet replacing the default chart style")
+	return fs
+}
+
+// Split the custom pipeline into the stages of a two-stage parser and
+// time each alone: reading the file, validating UTF-8, indexing the
+// structural characters, then converting the numbers and the strings at
+// the indexed offsets. The stacked bar shows where the time of a parse
+// goes, which is the architecture story of the talk in one picture; the
+// one-pass tape build is timed too, for scale.
+func runStages(args []string) error {
+	var opts stagesFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var idx []uint32
+	if idx, err = backends.StructuralIndex(data, idx); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	numbers, err := backends.ParseNumbers(data, idx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	strs, err := backends.ParseStrings(data, idx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	fmt.Printf("%s: %d bytes, %d structural offsets, %d numbers, %d strings\n",
+		datasets.Name(*opts.file), len(data), len(idx), numbers, strs)
+
+	type stage struct {
+		name string
+		run  func([]byte) error
+	}
+	var stages []stage
+	// Generated datasets have no file to read
+	if _, err := os.Stat(*opts.file); err == nil {
+		stages = append(stages, stage{"I/O", func([]byte) error {
+			_, err := os.ReadFile(*opts.file)
+			return err
+		}})
+	}
+	stages = append(stages,
+		stage{"UTF-8 validation", func(data []byte) error {
+			if !utf8.Valid(data) {
+				return withKind(errDataset, fmt.Errorf("invalid UTF-8"))
+			}
+			return nil
+		}},
+		stage{"structural index", func(data []byte) error {
+			var err error
+			idx, err = backends.StructuralIndex(data, idx[:0])
+			return err
+		}},
+		stage{"numbers", func(data []byte) error {
+			_, err := backends.ParseNumbers(data, idx)
+			return err
+		}},
+		stage{"strings", func(data []byte) error {
+			_, err := backends.ParseStrings(data, idx)
+			return err
+		}},
+	)
+
+	micros := make([]float64, len(stages))
+	total := 0.0
+	for i, s := range stages {
+		speed, err := bench.Measure(data, *opts.iterations, s.run)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		micros[i] = float64(len(data)) / speed
+		total += micros[i]
+	}
+	var tape backends.Tape
+	tapeSpeed, err := bench.Measure(data, *opts.iterations, func(data []byte) error {
+		return backends.BuildTape(data, &tape)
+	})
+	if err != nil {
+		return fmt.Errorf("tape: %w", err)
+	}
+
+	fmt.Println("\n| Stage | µs | share | MB/s of JSON |")
+	fmt.Println("|---|---:|---:|---:|")
+	for i, s := range stages {
+		fmt.Printf("| %s | %.1f | %.1f%% | %.1f |\n", s.name, micros[i], 100*micros[i]/total, float64(len(data))/micros[i])
+	}
+	fmt.Printf("| total | %.1f | 100%% | %.1f |\n", total, float64(len(data))/total)
+	fmt.Printf("| one-pass tape build, for scale | %.1f | | %.1f |\n", float64(len(data))/tapeSpeed, tapeSpeed)
+
+	// The stacked bar, each stage's cells rounded against the running sum
+	// so that they add up to the width
+	var bar strings.Builder
+	sum, drawn := 0.0, 0
+	for i := range stages {
+		sum += micros[i]
+		n := int(sum/total*float64(*opts.width)+0.5) - drawn
+		bar.WriteString(strings.Repeat(stageFills[i%len(stageFills)], n))
+		drawn += n
+	}
+	legend := make([]string, len(stages))
+	for i, s := range stages {
+		legend[i] = stageFills[i%len(stageFills)] + " " + s.name
+	}
+	fmt.Printf("\n%s\n%s\n", bar.String(), strings.Join(legend, "  "))
+
+	if *opts.svg != "" {
+		style := report.DefaultChartCSS
+		if *opts.css != "" {
+			b, err := os.ReadFile(*opts.css)
+			if err != nil {
+				return err
+			}
+			style = string(b)
+		}
+		list := make([]report.Series, len(stages))
+		for i, s := range stages {
+			list[i] = report.Series{Name: s.name, Values: []float64{micros[i]}}
+		}
+		chart := report.StackedBarSVG("Where a parse of "+datasets.Name(*opts.file)+" spends its time", style, "µs", []string{datasets.Name(*opts.file)}, list)
+		if err := os.WriteFile(*opts.svg, []byte(chart), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s\n", *opts.svg)
+	}
+	return nil
+}
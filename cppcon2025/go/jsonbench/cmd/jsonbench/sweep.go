@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// sweepFlags are the flags of sweep
+type sweepFlags struct {
+	file    *string
+	minSize *string
+	maxSize *string
+	factor  *int
+	volume  *string
+}
+
+func (opts *sweepFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("sweep")
+	opts.file = fs.String("file", "../twitter.json", "dataset whose records are replicated")
+	opts.minSize = fs.String("min", "1KB", "smallest document size")
+	opts.maxSize = fs.String("max", "1GB", "largest document size")
+	opts.factor = fs.Int("factor", 4, "size ratio between consecutive steps")
+	opts.volume = fs.String("volume", "256MB", "bytes to parse per measurement")
+	return fs
+}
+
+// Benchmark every backend on copies of the dataset scaled from a few KB to
+// a GB and print a tab-separated table (one column per backend) that
+// gnuplot or a spreadsheet can plot directly
+func runSweep(args []string) error {
+	var opts sweepFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	lo, err := datasets.ParseSize(*opts.minSize)
+	if err != nil {
+		return err
+	}
+	hi, err := datasets.ParseSize(*opts.maxSize)
+	if err != nil {
+		return err
+	}
+	vol, err := datasets.ParseSize(*opts.volume)
+	if err != nil {
+		return err
+	}
+	if *opts.factor < 2 || lo < 2 {
+		return fmt.Errorf("need -factor >= 2 and -min >= 2 bytes")
+	}
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	recs, err := datasets.Records(data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("# decoding throughput in MB/s by document size")
+	fmt.Print("# bytes")
+	for _, b := range backends.All() {
+		fmt.Print("\t", b.Name())
+	}
+	fmt.Println()
+	for size := lo; size <= hi; size *= *opts.factor {
+		doc := datasets.ScaledDocument(recs, size)
+		iterations := vol / len(doc)
+		if iterations < 1 {
+			iterations = 1
+		}
+		fmt.Print(len(doc))
+		for _, b := range backends.All() {
+			speed, err := bench.Measure(doc, iterations, func(data []byte) error {
+				_, err := b.Decode(data)
+				return err
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s at %d bytes: %v\n", b.Name(), len(doc), err)
+			}
+			fmt.Printf("\t%.2f", speed)
+		}
+		fmt.Println()
+	}
+	return nil
+}
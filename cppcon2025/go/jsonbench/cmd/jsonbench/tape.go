@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// tapeTask reads some fields of twitter.json from a tape
+type tapeTask struct {
+	name  string
+	tape  func(root backends.TapeRef) uint64
+	typed func(t *TwitterData) uint64
+}
+
+var tapeTasks = []tapeTask{
+	{
+		name: "last screen_name",
+		tape: func(root backends.TapeRef) uint64 {
+			statuses, _ := root.Get("statuses")
+			var last backends.TapeRef
+			statuses.Elements(func(s backends.TapeRef) { last = s })
+			user, _ := last.Get("user")
+			name, _ := user.Get("screen_name")
+			return uint64(len(name.Bytes()))
+		},
+		typed: accessTasks[0].typed,
+	},
+	{
+		name: "sum followers_count",
+		tape: func(root backends.TapeRef) uint64 {
+			statuses, _ := root.Get("statuses")
+			var sum uint64
+			statuses.Elements(func(s backends.TapeRef) {
+				user, _ := s.Get("user")
+				followers, _ := user.Get("followers_count")
+				sum += followers.Uint64()
+			})
+			return sum
+		},
+		typed: accessTasks[1].typed,
+	},
+}
+
+// tapeFlags are the flags of tape
+type tapeFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *tapeFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("tape")
+	opts.file = fs.String("file", "../twitter.json", "twitter.json document to index")
+	opts.iterations = fs.Int("n", 1000, "number of iterations")
+	return fs
+}
+
+// runTape indexes twitter.json once and times traversing the tape against
+// decoding the document again for every read
+func runTape(args []string) error {
+	var opts tapeFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var t backends.Tape
+	if err := backends.BuildTape(data, &t); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	// The tape must hold exactly the document
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if !reflect.DeepEqual(t.Root().Value(), generic) {
+		return fmt.Errorf("%s: the tape differs from the document", *opts.file)
+	}
+	var twitter TwitterData
+	if err := json.Unmarshal(data, &twitter); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if len(twitter.Statuses) == 0 {
+		return fmt.Errorf("%s: no statuses", *opts.file)
+	}
+
+	index, err := bench.Measure(data, *opts.iterations, func(b []byte) error {
+		return backends.BuildTape(b, &t)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d tape words, %d string bytes; indexing once takes %.1f µs (%.2f MB/s)\n\n",
+		*opts.file, len(t.Words), len(t.Strings), float64(len(data))/index, index)
+	fmt.Printf("%-22s %12s %16s %10s\n", "task", "tape µs", "re-decode µs", "speedup")
+	for _, task := range tapeTasks {
+		var got, want uint64
+		tapeSpeed, err := bench.Measure(data, *opts.iterations, func([]byte) error {
+			got = task.tape(t.Root())
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		decodeSpeed, err := bench.Measure(data, *opts.iterations, func(b []byte) error {
+			var t TwitterData
+			if err := json.Unmarshal(b, &t); err != nil {
+				return err
+			}
+			want = task.typed(&t)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("%s: tape read %d, encoding/json %d", task.name, got, want)
+		}
+		tapeMicros := float64(len(data)) / tapeSpeed
+		decodeMicros := float64(len(data)) / decodeSpeed
+		fmt.Printf("%-22s %12.2f %16.1f %9.0fx\n", task.name, tapeMicros, decodeMicros, decodeMicros/tapeMicros)
+	}
+	return nil
+}
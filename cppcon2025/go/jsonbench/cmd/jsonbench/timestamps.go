@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// Tweets carry their dates as strings in Ruby's layout,
+// "Sun Aug 31 00:29:15 +0000 2014", and a service that needs them as
+// time.Time parses each one after decoding the JSON.
+
+var errBadTimestamp = errors.New("invalid created_at timestamp")
+
+// twoDigits converts two ASCII digits, or returns -1
+func twoDigits(b []byte) int {
+	d0, d1 := int(b[0])-'0', int(b[1])-'0'
+	if uint(d0) > 9 || uint(d1) > 9 {
+		return -1
+	}
+	return d0*10 + d1
+}
+
+// lower3 lowers the ASCII letters of a three-letter name; setting bit
+// 5 turns no other byte into a lowercase letter
+func lower3(b []byte) [3]byte { return [3]byte{b[0] | 0x20, b[1] | 0x20, b[2] | 0x20} }
+
+// rubyMonth returns the month of a three-letter name, or 0. Names match
+// in any case, as in time.Parse.
+func rubyMonth(b []byte) int {
+	name := lower3(b)
+	switch string(name[:]) {
+	case "jan":
+		return 1
+	case "feb":
+		return 2
+	case "mar":
+		return 3
+	case "apr":
+		return 4
+	case "may":
+		return 5
+	case "jun":
+		return 6
+	case "jul":
+		return 7
+	case "aug":
+		return 8
+	case "sep":
+		return 9
+	case "oct":
+		return 10
+	case "nov":
+		return 11
+	case "dec":
+		return 12
+	}
+	return 0
+}
+
+func rubyWeekday(b []byte) bool {
+	name := lower3(b)
+	switch string(name[:]) {
+	case "mon", "tue", "wed", "thu", "fri", "sat", "sun":
+		return true
+	}
+	return false
+}
+
+func isLeap(year int) bool { return year%4 == 0 && (year%100 != 0 || year%400 == 0) }
+
+func daysIn(month, year int) int {
+	switch month {
+	case 2:
+		if isLeap(year) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
+
+// daysFromCivil is the number of days from 1970-01-01 to a date of the
+// proleptic Gregorian calendar (Howard Hinnant's algorithm)
+func daysFromCivil(year, month, day int) int64 {
+	if month <= 2 {
+		year--
+	}
+	era := year / 400
+	if year < 0 && year%400 != 0 {
+		era--
+	}
+	yoe := year - era*400
+	m := month + 9
+	if month > 2 {
+		m = month - 3
+	}
+	doy := (153*m+2)/5 + day - 1
+	doe := yoe*365 + yoe/4 - yoe/100 + doy
+	return int64(era)*146097 + int64(doe) - 719468
+}
+
+// rubyFields checks the fixed layout "Mon Jan 02 15:04:05 -0700 2006"
+// and returns its numbers; the offset is in seconds east of UTC. It is
+// stricter than time.Parse, which also takes runs of spaces and numbers
+// without their leading zero.
+func rubyFields(b []byte) (year, month, day, hour, min, sec, offset int, err error) {
+	if len(b) != 30 || b[3] != ' ' || b[7] != ' ' || b[10] != ' ' || b[13] != ':' || b[16] != ':' ||
+		b[19] != ' ' || b[20] != '+' && b[20] != '-' || b[25] != ' ' || !rubyWeekday(b[0:3]) {
+		return 0, 0, 0, 0, 0, 0, 0, errBadTimestamp
+	}
+	month = rubyMonth(b[4:7])
+	day, hour, min, sec = twoDigits(b[8:10]), twoDigits(b[11:13]), twoDigits(b[14:16]), twoDigits(b[17:19])
+	zh, zm := twoDigits(b[21:23]), twoDigits(b[23:25])
+	y0, y1 := twoDigits(b[26:28]), twoDigits(b[28:30])
+	if month == 0 || day < 1 || hour < 0 || hour > 23 || min < 0 || min > 59 || sec < 0 || sec > 59 ||
+		zh < 0 || zh > 24 || zm < 0 || zm > 60 || y0 < 0 || y1 < 0 {
+		return 0, 0, 0, 0, 0, 0, 0, errBadTimestamp
+	}
+	year = y0*100 + y1
+	if day > daysIn(month, year) {
+		return 0, 0, 0, 0, 0, 0, 0, errBadTimestamp
+	}
+	offset = zh*3600 + zm*60
+	if b[20] == '-' {
+		offset = -offset
+	}
+	return year, month, day, hour, min, sec, offset, nil
+}
+
+// rubyTime returns the instant at the offset of the string: in UTC for
+// +0000 and in a fixed zone otherwise
+func rubyTime(unix int64, offset int) time.Time {
+	if offset == 0 {
+		return time.Unix(unix, 0).UTC()
+	}
+	return time.Unix(unix, 0).In(time.FixedZone("", offset))
+}
+
+// parseRubyTime parses the fixed layout by position, with the calendar
+// arithmetic of daysFromCivil
+func parseRubyTime(b []byte) (time.Time, error) {
+	year, month, day, hour, min, sec, offset, err := rubyFields(b)
+	if err != nil {
+		return time.Time{}, err
+	}
+	unix := daysFromCivil(year, month, day)*86400 + int64(hour*3600+min*60+sec-offset)
+	return rubyTime(unix, offset), nil
+}
+
+// monthDays[y][m-1] is the day number of the first of each month of the
+// years 1970 to 2099, which replaces the calendar arithmetic with a load
+var monthDays = func() (t [130][12]int32) {
+	for y := range t {
+		for m := range t[y] {
+			t[y][m] = int32(daysFromCivil(1970+y, m+1, 1))
+		}
+	}
+	return t
+}()
+
+// parseRubyTimeTable is parseRubyTime with the monthDays table, and the
+// arithmetic only for years outside it
+func parseRubyTimeTable(b []byte) (time.Time, error) {
+	year, month, day, hour, min, sec, offset, err := rubyFields(b)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var days int64
+	if y := year - 1970; y >= 0 && y < len(monthDays) {
+		days = int64(monthDays[y][month-1]) + int64(day-1)
+	} else {
+		days = daysFromCivil(year, month, day)
+	}
+	unix := days*86400 + int64(hour*3600+min*60+sec-offset)
+	return rubyTime(unix, offset), nil
+}
+
+// collectTimestamps returns the created_at strings of a document
+func collectTimestamps(data []byte) ([][]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var out [][]byte
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]interface{}:
+			for k, e := range v {
+				if s, ok := e.(string); ok && k == "created_at" {
+					out = append(out, []byte(s))
+				}
+				walk(e)
+			}
+		}
+	}
+	walk(doc)
+	return out, nil
+}
+
+// timestampsFlags are the flags of timestamps
+type timestampsFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *timestampsFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("timestamps")
+	opts.file = fs.String("file", "../twitter.json", "document whose created_at strings are parsed")
+	opts.iterations = fs.Int("n", 1000, "number of iterations")
+	return fs
+}
+
+func runTimestamps(args []string) error {
+	var opts timestampsFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	stamps, err := collectTimestamps(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if len(stamps) == 0 {
+		return fmt.Errorf("%s: no created_at strings", *opts.file)
+	}
+	methods := []struct {
+		name  string
+		parse func([]byte) (time.Time, error)
+	}{
+		{"time.Parse(time.RubyDate)", func(b []byte) (time.Time, error) { return time.Parse(time.RubyDate, string(b)) }},
+		{"fixed layout", parseRubyTime},
+		{"fixed layout + month table", parseRubyTimeTable},
+	}
+	for _, s := range stamps {
+		want, err := methods[0].parse(s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s, err)
+		}
+		_, wantOffset := want.Zone()
+		for _, m := range methods[1:] {
+			got, err := m.parse(s)
+			if _, offset := got.Zone(); err != nil || !got.Equal(want) || offset != wantOffset {
+				return fmt.Errorf("%s parses %s as %v, time.Parse as %v", m.name, s, got, want)
+			}
+		}
+	}
+
+	// What the decoding of the document costs, for scale
+	decode, err := bench.Measure(data, *opts.iterations/10+1, func(b []byte) error {
+		var v interface{}
+		return json.Unmarshal(b, &v)
+	})
+	if err != nil {
+		return err
+	}
+	decodeMicros := float64(len(data)) / decode
+	fmt.Printf("%s: %d created_at strings such as %q; encoding/json decodes the document in %.1f µs\n\n",
+		*opts.file, len(stamps), stamps[0], decodeMicros)
+	fmt.Println("| Method | ns/timestamp | µs per document | % of the decoding |")
+	fmt.Println("|---|---:|---:|---:|")
+	for _, m := range methods {
+		start := time.Now()
+		for i := 0; i < *opts.iterations; i++ {
+			for _, s := range stamps {
+				if _, err := m.parse(s); err != nil {
+					return err
+				}
+			}
+		}
+		perDocument := time.Since(start).Seconds() * 1e6 / float64(*opts.iterations)
+		fmt.Printf("| %s | %.1f | %.1f | %.1f |\n", m.name, perDocument*1e3/float64(len(stamps)), perDocument, 100*perDocument/decodeMicros)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+//go:build tinygo
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// TinyGo builds keep bench, the decoders and the correctness checks, and
+// stub out what needs the gc runtime: profiling, os/exec, net/http,
+// html/template and the raw perf syscalls. The backends are encoding/json,
+// which only partly works with TinyGo's reflection, and the hand-rolled
+// decoder and the tape built with it, which need none.
+
+var errTinyGo = fmt.Errorf("%w in TinyGo builds", errUnavailable)
+
+func init() {
+	resultLanguage = "Go (TinyGo)"
+}
+
+func runServe(args []string) error      { return fmt.Errorf("serve: %w", errTinyGo) }
+func runReport(args []string) error     { return fmt.Errorf("report: %w", errTinyGo) }
+func runRunAll(args []string) error     { return fmt.Errorf("run-all: %w", errTinyGo) }
+func runReproduce(args []string) error  { return fmt.Errorf("reproduce: %w", errTinyGo) }
+func runCgo(args []string) error        { return fmt.Errorf("cgo: %w", errTinyGo) }
+func runPGO(args []string) error        { return fmt.Errorf("pgo: %w", errTinyGo) }
+func runToolchains(args []string) error { return fmt.Errorf("toolchains: %w", errTinyGo) }
+func runBCE(args []string) error        { return fmt.Errorf("bce: %w", errTinyGo) }
+func runHTTPBench(args []string) error  { return fmt.Errorf("httpbench: %w", errTinyGo) }
+func runDownload(args []string) error   { return fmt.Errorf("download: %w", errTinyGo) }
+func runFetch(args []string) error      { return fmt.Errorf("fetch: %w", errTinyGo) }
+func runProxy(args []string) error      { return fmt.Errorf("proxy: %w", errTinyGo) }
+func runWebSocket(args []string) error  { return fmt.Errorf("websocket: %w", errTinyGo) }
+func runLoadgen(args []string) error    { return fmt.Errorf("loadgen: %w", errTinyGo) }
+func runNegotiate(args []string) error  { return fmt.Errorf("negotiate: %w", errTinyGo) }
+func runJSONRPC(args []string) error    { return fmt.Errorf("jsonrpc: %w", errTinyGo) }
+func runSideBySide(args []string) error { return fmt.Errorf("sidebyside: %w", errTinyGo) }
+
+// The stubbed commands take no flags
+type (
+	serveFlags      struct{}
+	reportFlags     struct{}
+	runAllFlags     struct{}
+	reproduceFlags  struct{}
+	cgoFlags        struct{}
+	pgoFlags        struct{}
+	toolchainsFlags struct{}
+	bceFlags        struct{}
+	httpBenchFlags  struct{}
+	downloadFlags   struct{}
+	fetchFlags      struct{}
+	proxyFlags      struct{}
+	webSocketFlags  struct{}
+	loadgenFlags    struct{}
+	negotiateFlags  struct{}
+	jsonrpcFlags    struct{}
+	sideBySideFlags struct{}
+)
+
+func (*serveFlags) flags() *flag.FlagSet      { return newFlagSet("serve") }
+func (*reportFlags) flags() *flag.FlagSet     { return newFlagSet("report") }
+func (*runAllFlags) flags() *flag.FlagSet     { return newFlagSet("run-all") }
+func (*reproduceFlags) flags() *flag.FlagSet  { return newFlagSet("reproduce") }
+func (*cgoFlags) flags() *flag.FlagSet        { return newFlagSet("cgo") }
+func (*pgoFlags) flags() *flag.FlagSet        { return newFlagSet("pgo") }
+func (*toolchainsFlags) flags() *flag.FlagSet { return newFlagSet("toolchains") }
+func (*bceFlags) flags() *flag.FlagSet        { return newFlagSet("bce") }
+func (*httpBenchFlags) flags() *flag.FlagSet  { return newFlagSet("httpbench") }
+func (*downloadFlags) flags() *flag.FlagSet   { return newFlagSet("download") }
+func (*fetchFlags) flags() *flag.FlagSet      { return newFlagSet("fetch") }
+func (*proxyFlags) flags() *flag.FlagSet      { return newFlagSet("proxy") }
+func (*webSocketFlags) flags() *flag.FlagSet  { return newFlagSet("websocket") }
+func (*loadgenFlags) flags() *flag.FlagSet    { return newFlagSet("loadgen") }
+func (*negotiateFlags) flags() *flag.FlagSet  { return newFlagSet("negotiate") }
+func (*jsonrpcFlags) flags() *flag.FlagSet    { return newFlagSet("jsonrpc") }
+func (*sideBySideFlags) flags() *flag.FlagSet { return newFlagSet("sidebyside") }
+
+func startFlamegraph(svg string) (*os.File, error) {
+	return nil, fmt.Errorf("-flamegraph: %w", errTinyGo)
+}
+
+func stopFlamegraph(f *os.File, svg string) error { return nil }
+
+// profileFlags accepts the same flags as the gc build so that scripts keep
+// working, but refuses to start a profile
+type profileFlags struct {
+	cpuProfile   string
+	trace        string
+	blockProfile string
+}
+
+func addProfileFlags(fs *flag.FlagSet) *profileFlags {
+	p := &profileFlags{}
+	fs.StringVar(&p.cpuProfile, "cpuprofile", "", "write a CPU profile to this file")
+	fs.StringVar(&p.trace, "trace", "", "write an execution trace to this file")
+	fs.StringVar(&p.blockProfile, "blockprofile", "", "write a goroutine blocking profile to this file")
+	return p
+}
+
+func (p *profileFlags) start() (stop func(), err error) {
+	stop = func() {}
+	if p.cpuProfile != "" || p.trace != "" || p.blockProfile != "" {
+		return stop, fmt.Errorf("profiling: %w", errTinyGo)
+	}
+	return stop, nil
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A TOML 1.0 parser for the subset config files use: key = value pairs,
+// [table] and [[array of tables]] headers, strings, integers, floats,
+// booleans and arrays, into the same generic values as parseYAML. Dotted
+// keys, inline tables and dates are rejected. Like BurntSushi/toml it
+// builds a tree before binding, with much less work per value.
+
+type tomlParser struct {
+	data []byte
+	pos  int
+	line int
+}
+
+func parseTOML(data []byte) (interface{}, error) {
+	p := &tomlParser{data: data, line: 1}
+	root := map[string]interface{}{}
+	table := root
+	// Headers may not repeat, except for arrays of tables
+	defined := map[string]bool{}
+	for {
+		p.skipSpace(true)
+		if p.pos == len(p.data) {
+			return root, nil
+		}
+		if p.data[p.pos] == '[' {
+			array := p.pos+1 < len(p.data) && p.data[p.pos+1] == '['
+			if array {
+				p.pos += 2
+			} else {
+				p.pos++
+			}
+			p.skipSpace(false)
+			name, err := p.key()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace(false)
+			if !p.consume("]") || (array && !p.consume("]")) {
+				return nil, p.errorf("expected ] after table name %q", name)
+			}
+			table = map[string]interface{}{}
+			switch existing := root[name].(type) {
+			case nil:
+				if array {
+					root[name] = []interface{}{table}
+				} else {
+					root[name] = table
+				}
+			case []interface{}:
+				if !array || !defined[name] {
+					return nil, p.errorf("%q is already defined", name)
+				}
+				root[name] = append(existing, table)
+			default:
+				return nil, p.errorf("%q is already defined", name)
+			}
+			defined[name] = array
+		} else {
+			name, err := p.key()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace(false)
+			if !p.consume("=") {
+				return nil, p.errorf("expected = after key %q", name)
+			}
+			p.skipSpace(false)
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := table[name]; dup {
+				return nil, p.errorf("duplicate key %q", name)
+			}
+			table[name] = v
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) consume(s string) bool {
+	if len(p.data)-p.pos < len(s) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if p.data[p.pos+i] != s[i] {
+			return false
+		}
+	}
+	p.pos += len(s)
+	return true
+}
+
+// skipSpace skips blanks and, with newlines, also line breaks and comments
+func (p *tomlParser) skipSpace(newlines bool) {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t':
+		case '\r', '\n', '#':
+			if !newlines {
+				return
+			}
+			if p.data[p.pos] == '#' {
+				for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+					p.pos++
+				}
+				continue
+			}
+			if p.data[p.pos] == '\n' {
+				p.line++
+			}
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+// endOfLine consumes an optional comment and the line break after a value
+// or header
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace(false)
+	if p.pos < len(p.data) && p.data[p.pos] == '#' {
+		for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+	p.consume("\r")
+	if p.pos < len(p.data) && !p.consume("\n") {
+		return p.errorf("unexpected %q after value", p.data[p.pos])
+	}
+	p.line++
+	return nil
+}
+
+func isTOMLBareKey(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) key() (string, error) {
+	if p.pos < len(p.data) && (p.data[p.pos] == '"' || p.data[p.pos] == '\'') {
+		return p.string()
+	}
+	start := p.pos
+	for p.pos < len(p.data) && isTOMLBareKey(p.data[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a key")
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		return "", p.errorf("dotted keys are not supported")
+	}
+	return string(p.data[start:p.pos]), nil
+}
+
+func (p *tomlParser) value() (interface{}, error) {
+	if p.pos == len(p.data) {
+		return nil, p.errorf("expected a value")
+	}
+	switch c := p.data[p.pos]; {
+	case c == '"' || c == '\'':
+		return p.string()
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return nil, p.errorf("inline tables are not supported")
+	case p.consume("true"):
+		return true, nil
+	case p.consume("false"):
+		return false, nil
+	}
+	return p.number()
+}
+
+// array parses [v, ...], which may span lines and end with a comma
+func (p *tomlParser) array() (interface{}, error) {
+	p.pos++
+	items := []interface{}{}
+	for {
+		p.skipSpace(true)
+		if p.consume("]") {
+			return items, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		p.skipSpace(true)
+		if !p.consume(",") {
+			if p.consume("]") {
+				return items, nil
+			}
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+// string parses a single-line basic or literal string
+func (p *tomlParser) string() (string, error) {
+	quote := p.data[p.pos]
+	p.pos++
+	if p.pos+1 < len(p.data) && p.data[p.pos] == quote && p.data[p.pos+1] == quote {
+		return "", p.errorf("multi-line strings are not supported")
+	}
+	start := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == quote:
+			s := string(p.data[start:p.pos])
+			p.pos++
+			if quote == '"' && strings.IndexByte(s, '\\') >= 0 {
+				return p.unescape(s)
+			}
+			return s, nil
+		case c == '\\' && quote == '"':
+			p.pos++
+		case c == '\n' || c < ' ' && c != '\t' || c == 0x7f:
+			return "", p.errorf("control character in string")
+		}
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParser) unescape(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", p.errorf("unterminated escape")
+		}
+		switch c = s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(c)
+		case 'u', 'U':
+			n := 4
+			if c == 'U' {
+				n = 8
+			}
+			if i+n >= len(s) {
+				return "", p.errorf("short \\%c escape", c)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return "", p.errorf("invalid \\%c escape", c)
+			}
+			b.WriteRune(rune(r))
+			i += n
+		default:
+			return "", p.errorf("invalid escape \\%c", c)
+		}
+	}
+	return b.String(), nil
+}
+
+// number parses an integer or float; underscores must sit between digits
+func (p *tomlParser) number() (interface{}, error) {
+	start := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if !isTOMLBareKey(c) && c != '+' && c != '.' {
+			break
+		}
+		p.pos++
+	}
+	token := string(p.data[start:p.pos])
+	switch token {
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+	digits := token
+	if strings.Contains(token, "_") {
+		for i := 0; i < len(token); i++ {
+			if token[i] == '_' && (i == 0 || i == len(token)-1 || !isTOMLDigit(token[i-1]) || !isTOMLDigit(token[i+1])) {
+				return nil, p.errorf("invalid number %q", token)
+			}
+		}
+		digits = strings.ReplaceAll(token, "_", "")
+	}
+	if len(digits) > 2 && digits[0] == '0' && (digits[1] == 'x' || digits[1] == 'o' || digits[1] == 'b') {
+		base := 16
+		if digits[1] == 'o' {
+			base = 8
+		} else if digits[1] == 'b' {
+			base = 2
+		}
+		if i, err := strconv.ParseInt(digits[2:], base, 64); err == nil && digits[2] != '+' && digits[2] != '-' {
+			return i, nil
+		}
+		return nil, p.errorf("invalid number %q", token)
+	}
+	// Leading zeros are not allowed, in integers or before a float's point
+	unsigned := strings.TrimLeft(digits, "+-")
+	if len(unsigned) > 1 && unsigned[0] == '0' && isTOMLDigit(unsigned[1]) {
+		return nil, p.errorf("invalid number %q", token)
+	}
+	if !strings.ContainsAny(digits, ".eE") {
+		if i, err := strconv.ParseInt(digits, 10, 64); err == nil {
+			return i, nil
+		}
+	} else if !strings.ContainsAny(digits, "nNxXpP") && !strings.HasSuffix(digits, ".") && !strings.HasPrefix(unsigned, ".") && !strings.Contains(digits, ".e") && !strings.Contains(digits, ".E") {
+		if f, err := strconv.ParseFloat(digits, 64); err == nil {
+			return f, nil
+		}
+	}
+	if token == "" {
+		return nil, p.errorf("expected a value")
+	}
+	return nil, p.errorf("invalid value %q", token)
+}
+
+func isTOMLDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
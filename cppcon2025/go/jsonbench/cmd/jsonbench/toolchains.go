@@ -0,0 +1,87 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// toolchainsFlags are the flags of toolchains
+type toolchainsFlags struct {
+	toolchains *string
+	files      *string
+	iterations *int
+	count      *int
+	src        *string
+	keep       *string
+}
+
+func (opts *toolchainsFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("toolchains")
+	opts.toolchains = fs.String("go", "go", "comma-separated go commands to compare, e.g. go1.22.12,go1.24.6,gotip+jsonv2 (+jsonv2 sets GOEXPERIMENT=jsonv2)")
+	opts.files = fs.String("file", "../twitter.json", "comma-separated list of JSON documents")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	opts.count = fs.Int("count", 5, "repeat each measurement and report the median")
+	opts.src = fs.String("src", ".", "directory of the jsonbench module")
+	opts.keep = fs.String("keep", "", "keep the binaries and results in this directory")
+	return fs
+}
+
+func runToolchains(args []string) error {
+	var opts toolchainsFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	dir := *opts.keep
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "jsonbench-toolchains")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	benchArgs := []string{"-file", *opts.files, "-n", strconv.Itoa(*opts.iterations), "-count", strconv.Itoa(*opts.count)}
+
+	// One column per toolchain, labelled with the runtime version the
+	// binary reports
+	var table pivot
+	for i, spec := range strings.Split(*opts.toolchains, ",") {
+		goCmd, experiment, _ := strings.Cut(spec, "+")
+		// GOTOOLCHAIN=local stops the go command from switching versions
+		env := []string{"GOTOOLCHAIN=local"}
+		if experiment != "" {
+			env = append(env, "GOEXPERIMENT="+experiment)
+		}
+		fmt.Println("benchmarking with", spec)
+		rf, err := buildAndBench(dir, *opts.src, goCmd, env, nil, benchArgs, "jsonbench-"+strconv.Itoa(i))
+		if err != nil {
+			return err
+		}
+		column := ""
+		if rf.Environment != nil {
+			column = rf.Environment.Runtime
+		}
+		if column == "" {
+			column = spec
+		}
+		for _, r := range rf.Results {
+			table.add(column, r.Dataset+" | "+r.Backend, r.MBPerSec)
+		}
+	}
+
+	fmt.Println()
+	table.print("Dataset | Library")
+	return nil
+}
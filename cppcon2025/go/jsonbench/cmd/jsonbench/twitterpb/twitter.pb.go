@@ -0,0 +1,413 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: twitter.proto
+
+package twitterpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TwitterUser struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ScreenName     string                 `protobuf:"bytes,3,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	Location       string                 `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	Description    string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	FollowersCount uint64                 `protobuf:"varint,6,opt,name=followers_count,json=followersCount,proto3" json:"followers_count,omitempty"`
+	FriendsCount   uint64                 `protobuf:"varint,7,opt,name=friends_count,json=friendsCount,proto3" json:"friends_count,omitempty"`
+	Verified       bool                   `protobuf:"varint,8,opt,name=verified,proto3" json:"verified,omitempty"`
+	StatusesCount  uint64                 `protobuf:"varint,9,opt,name=statuses_count,json=statusesCount,proto3" json:"statuses_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TwitterUser) Reset() {
+	*x = TwitterUser{}
+	mi := &file_twitter_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TwitterUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TwitterUser) ProtoMessage() {}
+
+func (x *TwitterUser) ProtoReflect() protoreflect.Message {
+	mi := &file_twitter_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TwitterUser.ProtoReflect.Descriptor instead.
+func (*TwitterUser) Descriptor() ([]byte, []int) {
+	return file_twitter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TwitterUser) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TwitterUser) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TwitterUser) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *TwitterUser) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *TwitterUser) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *TwitterUser) GetFollowersCount() uint64 {
+	if x != nil {
+		return x.FollowersCount
+	}
+	return 0
+}
+
+func (x *TwitterUser) GetFriendsCount() uint64 {
+	if x != nil {
+		return x.FriendsCount
+	}
+	return 0
+}
+
+func (x *TwitterUser) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *TwitterUser) GetStatusesCount() uint64 {
+	if x != nil {
+		return x.StatusesCount
+	}
+	return 0
+}
+
+type Status struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *TwitterUser           `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_twitter_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_twitter_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_twitter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Status) GetUser() *TwitterUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type TwitterData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statuses      []*Status              `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TwitterData) Reset() {
+	*x = TwitterData{}
+	mi := &file_twitter_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TwitterData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TwitterData) ProtoMessage() {}
+
+func (x *TwitterData) ProtoReflect() protoreflect.Message {
+	mi := &file_twitter_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TwitterData.ProtoReflect.Descriptor instead.
+func (*TwitterData) Descriptor() ([]byte, []int) {
+	return file_twitter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TwitterData) GetStatuses() []*Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+type Player struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Level         int64                  `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+	Health        float64                `protobuf:"fixed64,3,opt,name=health,proto3" json:"health,omitempty"`
+	Inventory     []string               `protobuf:"bytes,4,rep,name=inventory,proto3" json:"inventory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Player) Reset() {
+	*x = Player{}
+	mi := &file_twitter_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Player) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Player) ProtoMessage() {}
+
+func (x *Player) ProtoReflect() protoreflect.Message {
+	mi := &file_twitter_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Player.ProtoReflect.Descriptor instead.
+func (*Player) Descriptor() ([]byte, []int) {
+	return file_twitter_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Player) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Player) GetLevel() int64 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *Player) GetHealth() float64 {
+	if x != nil {
+		return x.Health
+	}
+	return 0
+}
+
+func (x *Player) GetInventory() []string {
+	if x != nil {
+		return x.Inventory
+	}
+	return nil
+}
+
+type Players struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Players       []*Player              `protobuf:"bytes,1,rep,name=players,proto3" json:"players,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Players) Reset() {
+	*x = Players{}
+	mi := &file_twitter_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Players) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Players) ProtoMessage() {}
+
+func (x *Players) ProtoReflect() protoreflect.Message {
+	mi := &file_twitter_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Players.ProtoReflect.Descriptor instead.
+func (*Players) Descriptor() ([]byte, []int) {
+	return file_twitter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Players) GetPlayers() []*Player {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+var File_twitter_proto protoreflect.FileDescriptor
+
+const file_twitter_proto_rawDesc = "" +
+	"\n" +
+	"\rtwitter.proto\x12\tjsonbench\"\xa1\x02\n" +
+	"\vTwitterUser\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
+	"\vscreen_name\x18\x03 \x01(\tR\n" +
+	"screenName\x12\x1a\n" +
+	"\blocation\x18\x04 \x01(\tR\blocation\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12'\n" +
+	"\x0ffollowers_count\x18\x06 \x01(\x04R\x0efollowersCount\x12#\n" +
+	"\rfriends_count\x18\a \x01(\x04R\ffriendsCount\x12\x1a\n" +
+	"\bverified\x18\b \x01(\bR\bverified\x12%\n" +
+	"\x0estatuses_count\x18\t \x01(\x04R\rstatusesCount\"4\n" +
+	"\x06Status\x12*\n" +
+	"\x04user\x18\x01 \x01(\v2\x16.jsonbench.TwitterUserR\x04user\"<\n" +
+	"\vTwitterData\x12-\n" +
+	"\bstatuses\x18\x01 \x03(\v2\x11.jsonbench.StatusR\bstatuses\"p\n" +
+	"\x06Player\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\x03R\x05level\x12\x16\n" +
+	"\x06health\x18\x03 \x01(\x01R\x06health\x12\x1c\n" +
+	"\tinventory\x18\x04 \x03(\tR\tinventory\"6\n" +
+	"\aPlayers\x12+\n" +
+	"\aplayers\x18\x01 \x03(\v2\x11.jsonbench.PlayerR\aplayersBTZRgithub.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/cmd/jsonbench/twitterpbb\x06proto3"
+
+var (
+	file_twitter_proto_rawDescOnce sync.Once
+	file_twitter_proto_rawDescData []byte
+)
+
+func file_twitter_proto_rawDescGZIP() []byte {
+	file_twitter_proto_rawDescOnce.Do(func() {
+		file_twitter_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_twitter_proto_rawDesc), len(file_twitter_proto_rawDesc)))
+	})
+	return file_twitter_proto_rawDescData
+}
+
+var file_twitter_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_twitter_proto_goTypes = []any{
+	(*TwitterUser)(nil), // 0: jsonbench.TwitterUser
+	(*Status)(nil),      // 1: jsonbench.Status
+	(*TwitterData)(nil), // 2: jsonbench.TwitterData
+	(*Player)(nil),      // 3: jsonbench.Player
+	(*Players)(nil),     // 4: jsonbench.Players
+}
+var file_twitter_proto_depIdxs = []int32{
+	0, // 0: jsonbench.Status.user:type_name -> jsonbench.TwitterUser
+	1, // 1: jsonbench.TwitterData.statuses:type_name -> jsonbench.Status
+	3, // 2: jsonbench.Players.players:type_name -> jsonbench.Player
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_twitter_proto_init() }
+func file_twitter_proto_init() {
+	if File_twitter_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_twitter_proto_rawDesc), len(file_twitter_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_twitter_proto_goTypes,
+		DependencyIndexes: file_twitter_proto_depIdxs,
+		MessageInfos:      file_twitter_proto_msgTypes,
+	}.Build()
+	File_twitter_proto = out.File
+	file_twitter_proto_goTypes = nil
+	file_twitter_proto_depIdxs = nil
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// Unescaping a JSON string is mostly copying: between two escapes every
+// byte goes through unchanged. simdjson copies 32 bytes at a time and
+// looks for the next backslash in the same registers; simd.CopyPlain,
+// written in assembly for AVX-512, AVX2 and NEON and in SWAR Go for the
+// rest, does the same, and copyPlainScalar is the byte loop beneath.
+
+var errBadEscape = errors.New("invalid escape in string")
+
+// copyPlainScalar copies the leading bytes of src that need no
+// unescaping (everything but '\\', '"' and control characters) to dst,
+// which is at least as long, and returns their number
+func copyPlainScalar(dst, src []byte) int {
+	for i, c := range src {
+		if c == '\\' || c == '"' || c < 0x20 {
+			return i
+		}
+		dst[i] = c
+	}
+	return len(src)
+}
+
+// unhex4 converts the 4 hex digits of a \u escape
+func unhex4(b []byte) (rune, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range b[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// appendUnescaped appends the unescaped contents of a JSON string, the
+// bytes between its quotes, to dst, copying the runs between escapes
+// with copyPlain. Like the hand-rolled decoder in pass-through mode it
+// leaves invalid UTF-8 alone and turns lone surrogates into U+FFFD.
+func appendUnescaped(dst, src []byte, copyPlain func(dst, src []byte) int) ([]byte, error) {
+	// An escape is never shorter than what it stands for, so the output
+	// fits in len(src) more bytes
+	if cap(dst)-len(dst) < len(src) {
+		dst = append(make([]byte, 0, len(dst)+len(src)), dst...)
+	}
+	for {
+		n := copyPlain(dst[len(dst):len(dst)+len(src)], src)
+		dst, src = dst[:len(dst)+n], src[n:]
+		if len(src) == 0 {
+			return dst, nil
+		}
+		if src[0] != '\\' || len(src) < 2 {
+			return dst, fmt.Errorf("%w: unexpected %q", errBadEscape, src[0])
+		}
+		e := src[1]
+		src = src[2:]
+		switch e {
+		case '"', '\\', '/':
+			dst = append(dst, e)
+		case 'b':
+			dst = append(dst, '\b')
+		case 'f':
+			dst = append(dst, '\f')
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		case 't':
+			dst = append(dst, '\t')
+		case 'u':
+			r, ok := unhex4(src)
+			if !ok {
+				return dst, fmt.Errorf("%w: invalid unicode escape", errBadEscape)
+			}
+			src = src[4:]
+			if utf16.IsSurrogate(r) {
+				r2 := utf8.RuneError
+				if len(src) >= 6 && src[0] == '\\' && src[1] == 'u' {
+					// A second escape that does not complete the pair is
+					// decoded on its own
+					if low, ok := unhex4(src[2:]); ok {
+						if r2 = utf16.DecodeRune(r, low); r2 != utf8.RuneError {
+							src = src[6:]
+						}
+					}
+				}
+				r = r2
+			}
+			dst = utf8.AppendRune(dst, r)
+		default:
+			return dst, fmt.Errorf("%w: \\%c", errBadEscape, e)
+		}
+	}
+}
+
+// stringLiterals returns every string of a document, keys included, with
+// their quotes
+func stringLiterals(data []byte) ([][]byte, error) {
+	var out [][]byte
+	for i := 0; i < len(data); i++ {
+		if data[i] != '"' {
+			continue
+		}
+		j := i + 1
+		for j < len(data) && data[j] != '"' {
+			if data[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(data) {
+			return nil, errors.New("unterminated string")
+		}
+		out = append(out, data[i:j+1])
+		i = j
+	}
+	return out, nil
+}
+
+// unescapeFlags are the flags of unescape
+type unescapeFlags struct {
+	file       *string
+	n          *int
+	seed       *int64
+	iterations *int
+}
+
+func (opts *unescapeFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("unescape")
+	opts.file = fs.String("file", "../twitter.json", "real document whose strings are unescaped too")
+	opts.n = fs.Int("strings", 10000, "number of strings of the escape-heavy dataset")
+	opts.seed = fs.Int64("seed", 1, "seed of the escape-heavy dataset")
+	opts.iterations = fs.Int("n", 20, "number of iterations")
+	return fs
+}
+
+func runUnescape(args []string) error {
+	var opts unescapeFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	inputs := []struct {
+		name string
+		data []byte
+	}{{fmt.Sprintf("escape-heavy (%d strings)", *opts.n), datasets.EscapeHeavy(*opts.n, *opts.seed)}}
+	if data, err := datasets.Read(*opts.file); err == nil {
+		inputs = append(inputs, struct {
+			name string
+			data []byte
+		}{*opts.file, data})
+	} else {
+		fmt.Fprintf(os.Stderr, "skipping %s: %v\n", *opts.file, err)
+	}
+
+	var buf []byte
+	methods := []struct {
+		name string
+		fn   func(lit []byte) ([]byte, error)
+	}{
+		{"encoding/json", func(lit []byte) ([]byte, error) {
+			var s string
+			err := json.Unmarshal(lit, &s)
+			return append(buf[:0], s...), err
+		}},
+		{"hand-rolled decoder", func(lit []byte) ([]byte, error) {
+			s, err := backends.NewDecoder(lit, backends.DecodeOptions{UTF8: backends.PassInvalid}).String()
+			return append(buf[:0], s...), err
+		}},
+		{"portable copy", func(lit []byte) ([]byte, error) {
+			return appendUnescaped(buf[:0], lit[1:len(lit)-1], copyPlainScalar)
+		}},
+	}
+	forEachImplementation(func(impl simd.Implementation) error {
+		if copyPlain := simd.CopyPlain; copyPlain != nil {
+			methods = append(methods, struct {
+				name string
+				fn   func(lit []byte) ([]byte, error)
+			}{impl.Name + " copy", func(lit []byte) ([]byte, error) {
+				return appendUnescaped(buf[:0], lit[1:len(lit)-1], copyPlain)
+			}})
+		}
+		return nil
+	})
+
+	for _, in := range inputs {
+		lits, err := stringLiterals(in.data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", in.name, err)
+		}
+		size, escapes := 0, 0
+		for _, lit := range lits {
+			size += len(lit)
+			escapes += bytes.Count(lit, []byte{'\\'})
+			want, err := methods[0].fn(lit)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", in.name, lit, err)
+			}
+			want = append([]byte(nil), want...)
+			for _, m := range methods[1:] {
+				if got, err := m.fn(lit); err != nil || !bytes.Equal(got, want) {
+					return fmt.Errorf("%s: %s unescapes %s as %q (%v), encoding/json as %q", in.name, m.name, lit, got, err, want)
+				}
+			}
+		}
+		fmt.Printf("\n%s: %d strings, %d bytes, %d backslashes\n\n", in.name, len(lits), size, escapes)
+		fmt.Println("| Method | MB/s | ns/string |")
+		fmt.Println("|---|---:|---:|")
+		for _, m := range methods {
+			start := time.Now()
+			for it := 0; it < *opts.iterations; it++ {
+				for _, lit := range lits {
+					if buf, err = m.fn(lit); err != nil {
+						return err
+					}
+				}
+			}
+			elapsed := time.Since(start).Seconds()
+			fmt.Printf("| %s | %.2f | %.1f |\n", m.name, float64(size**opts.iterations)/elapsed/1e6,
+				elapsed*1e9/float64(len(lits)**opts.iterations))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"math/bits"
+	"unsafe"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bitutil"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+)
+
+// The hottest loops of a parser, each written twice: with slices, whose
+// bounds the compiler checks unless it can prove them, and with
+// unsafe.Pointer arithmetic, which it never checks. Each pair must
+// compute the same result.
+
+// countQuotesSafe counts the quotes and backslashes of data a byte at a
+// time; the range loop needs no bounds check
+func countQuotesSafe(data []byte) int {
+	n := 0
+	for _, c := range data {
+		if c == '"' || c == '\\' {
+			n++
+		}
+	}
+	return n
+}
+
+func countQuotesUnsafe(data []byte) int {
+	n := 0
+	p := unsafe.Pointer(unsafe.SliceData(data))
+	for i := 0; i < len(data); i++ {
+		if c := *(*byte)(unsafe.Add(p, i)); c == '"' || c == '\\' {
+			n++
+		}
+	}
+	return n
+}
+
+// countQuotesWordsSafe counts them 8 bytes at a time, loading each word
+// with binary.LittleEndian, which checks the bounds of data[i:] once
+func countQuotesWordsSafe(data []byte) int {
+	n, i := 0, 0
+	for ; i+8 <= len(data); i += 8 {
+		v := binary.LittleEndian.Uint64(data[i:])
+		n += bits.OnesCount64(bitutil.Equal(v, '"') | bitutil.Equal(v, '\\'))
+	}
+	return n + countQuotesSafe(data[i:])
+}
+
+// countQuotesWordsUnsafe loads the words through a *uint64, which reads
+// them in the byte order of the CPU: the count is the same either way
+func countQuotesWordsUnsafe(data []byte) int {
+	n, i := 0, 0
+	p := unsafe.Pointer(unsafe.SliceData(data))
+	for ; i+8 <= len(data); i += 8 {
+		v := *(*uint64)(unsafe.Add(p, i))
+		n += bits.OnesCount64(bitutil.Equal(v, '"') | bitutil.Equal(v, '\\'))
+	}
+	return n + countQuotesUnsafe(data[i:])
+}
+
+// copyPlainUnsafe is copyPlainScalar without the bounds checks of dst
+func copyPlainUnsafe(dst, src []byte) int {
+	d := unsafe.Pointer(unsafe.SliceData(dst))
+	s := unsafe.Pointer(unsafe.SliceData(src))
+	for i := 0; i < len(src); i++ {
+		c := *(*byte)(unsafe.Add(s, i))
+		if c == '\\' || c == '"' || c < 0x20 {
+			return i
+		}
+		*(*byte)(unsafe.Add(d, i)) = c
+	}
+	return len(src)
+}
+
+// copyRuns copies data to dst run by run, skipping the byte at the end
+// of each run, as unescaping does, and returns the bytes copied
+func copyRuns(dst, data []byte, copyPlain func(dst, src []byte) int) int {
+	total := 0
+	for i := 0; i < len(data); i++ {
+		n := copyPlain(dst[i:], data[i:])
+		total += n
+		i += n
+	}
+	return total
+}
+
+// The strings of a document, as the offsets of their first byte and of
+// their closing quote
+type stringSpans [][2]int
+
+// spans finds the strings of data from its structural index: a string
+// ends at the last quote before the next structural character
+func spans(data []byte) (stringSpans, error) {
+	idx, err := scanner.Index(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	var s stringSpans
+	for i, off := range idx {
+		if data[off] != '"' {
+			continue
+		}
+		end := len(data)
+		if i+1 < len(idx) {
+			end = int(idx[i+1])
+		}
+		s = append(s, [2]int{int(off) + 1, int(off) + 1 + bytes.LastIndexByte(data[off+1:end], '"')})
+	}
+	return s, nil
+}
+
+// buildStrings makes a Go string of every string of data and returns
+// their total length, or -1 if one differs from its bytes
+func buildStrings(data []byte, s stringSpans, str func([]byte) string) int {
+	total := 0
+	for _, sp := range s {
+		b := data[sp[0]:sp[1]]
+		v := str(b)
+		if len(v) != len(b) || (len(b) > 0 && v[0] != b[0]) {
+			return -1
+		}
+		total += len(v)
+	}
+	return total
+}
+
+// stringUnsafe returns b as a string without copying: the string aliases
+// b, which must not change as long as the string lives
+func stringUnsafe(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// unsafeFlags are the flags of unsafe
+type unsafeFlags struct {
+	file       *string
+	iterations *int
+}
+
+func (opts *unsafeFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("unsafe")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to run the loops over")
+	opts.iterations = fs.Int("n", 200, "number of iterations")
+	return fs
+}
+
+// Time each safe loop against its unsafe twin on -file
+func runUnsafe(args []string) error {
+	var opts unsafeFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	strs, err := spans(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, withKind(errDataset, err))
+	}
+	dst := make([]byte, len(data))
+	pairs := []struct {
+		name         string
+		safe, unsafe func() int
+	}{
+		{"scan for quotes, a byte at a time",
+			func() int { return countQuotesSafe(data) },
+			func() int { return countQuotesUnsafe(data) }},
+		{"scan for quotes, 8 bytes at a time",
+			func() int { return countQuotesWordsSafe(data) },
+			func() int { return countQuotesWordsUnsafe(data) }},
+		{"copy the runs between quotes and escapes",
+			func() int { return copyRuns(dst, data, copyPlainScalar) },
+			func() int { return copyRuns(dst, data, copyPlainUnsafe) }},
+		{"build a Go string per string",
+			func() int { return buildStrings(data, strs, func(b []byte) string { return string(b) }) },
+			func() int { return buildStrings(data, strs, stringUnsafe) }},
+	}
+
+	fmt.Printf("%s: %d bytes, %d strings\n\n", datasets.Name(*opts.file), len(data), len(strs))
+	fmt.Println("| Loop | safe GB/s | unsafe GB/s | unsafe speedup |")
+	fmt.Println("|---|---:|---:|---:|")
+	for _, p := range pairs {
+		want, got := p.safe(), p.unsafe()
+		if want < 0 || got != want {
+			return withKind(errMismatch, fmt.Errorf("%s: the unsafe loop gives %d, the safe one %d", p.name, got, want))
+		}
+		var speeds [2]float64
+		for i, fn := range []func() int{p.safe, p.unsafe} {
+			if speeds[i], err = bench.Measure(data, *opts.iterations, func([]byte) error {
+				fn()
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("| %s | %.2f | %.2f | %.2f× |\n", p.name, speeds[0]/1e3, speeds[1]/1e3, speeds[1]/speeds[0])
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// utf8Flags are the flags of utf8
+type utf8Flags struct {
+	file       *string
+	iterations *int
+	prof       *profileFlags
+}
+
+func (opts *utf8Flags) flags() *flag.FlagSet {
+	fs := newFlagSet("utf8")
+	opts.file = fs.String("file", "../twitter.json", "JSON document to decode")
+	opts.iterations = fs.Int("n", 100, "number of iterations")
+	opts.prof = addProfileFlags(fs)
+	return fs
+}
+
+// Compare the cost of the hand-rolled decoder's UTF-8 modes with
+// encoding/json, which always replaces invalid bytes with U+FFFD
+func runUTF8(args []string) error {
+	var opts utf8Flags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	stop, err := opts.prof.start()
+	defer stop()
+	if err != nil {
+		return err
+	}
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	speed, err := bench.Measure(data, *opts.iterations, func(b []byte) error {
+		var v interface{}
+		return json.Unmarshal(b, &v)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-32s %8.2f MB/s\n", "encoding/json", speed)
+	for _, mode := range []backends.UTF8Mode{backends.ReplaceInvalid, backends.RejectInvalid, backends.PassInvalid} {
+		b := backends.Handrolled{Options: backends.DecodeOptions{UTF8: mode}}
+		speed, err := bench.Measure(data, *opts.iterations, func(data []byte) error {
+			_, err := b.Decode(data)
+			return err
+		})
+		if err != nil {
+			fmt.Printf("%-32s %v\n", b.Name(), err)
+			continue
+		}
+		fmt.Printf("%-32s %8.2f MB/s\n", b.Name(), speed)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// Services commonly carry identifiers as UUID strings,
+// "6ba7b810-9dad-11d1-80b4-00c04fd430c8", and keep them as [16]byte once
+// decoded. The hydration either runs inside the decoder, through the
+// field type's UnmarshalJSON or UnmarshalText, or as a pass over string
+// fields once decoding is done.
+
+var errBadUUID = errors.New("invalid UUID")
+
+type uuid [16]byte
+
+// uuidHex maps an ASCII byte to its hex value, or 0xFF
+var uuidHex = func() (t [256]byte) {
+	for i := range t {
+		t[i] = 0xFF
+	}
+	for c := '0'; c <= '9'; c++ {
+		t[c] = byte(c - '0')
+	}
+	for c := 'a'; c <= 'f'; c++ {
+		t[c] = byte(c-'a') + 10
+		t[c-'a'+'A'] = byte(c-'a') + 10
+	}
+	return t
+}()
+
+// uuidGroups holds the offsets of the 16 hex pairs in the canonical form
+var uuidGroups = [16]int{0, 2, 4, 6, 9, 11, 14, 16, 19, 21, 24, 26, 28, 30, 32, 34}
+
+// parseUUID parses the canonical 8-4-4-4-12 form, in either case
+func parseUUID(b []byte) (uuid, error) {
+	var u uuid
+	if len(b) != 36 || b[8] != '-' || b[13] != '-' || b[18] != '-' || b[23] != '-' {
+		return u, errBadUUID
+	}
+	for i, off := range uuidGroups {
+		hi, lo := uuidHex[b[off]], uuidHex[b[off+1]]
+		if hi > 15 || lo > 15 {
+			return u, errBadUUID
+		}
+		u[i] = hi<<4 | lo
+	}
+	return u, nil
+}
+
+func appendUUID(dst []byte, u uuid) []byte {
+	const digits = "0123456789abcdef"
+	for i, c := range u {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			dst = append(dst, '-')
+		}
+		dst = append(dst, digits[c>>4], digits[c&15])
+	}
+	return dst
+}
+
+func (u uuid) MarshalText() ([]byte, error) { return appendUUID(nil, u), nil }
+
+// UnmarshalJSON reads the string in place: a UUID never needs escapes,
+// so the decoder's unescaping and copying are skipped. As in
+// encoding/json, null leaves the value alone.
+func (u *uuid) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return errBadUUID
+	}
+	v, err := parseUUID(b[1 : len(b)-1])
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// uuidText hydrates through UnmarshalText, after encoding/json has
+// decoded the string
+type uuidText [16]byte
+
+func (u *uuidText) UnmarshalText(b []byte) error {
+	v, err := parseUUID(b)
+	if err != nil {
+		return err
+	}
+	*u = uuidText(v)
+	return nil
+}
+
+type uuidEvent struct {
+	EventID   uuid   `json:"event_id"`
+	UserID    uuid   `json:"user_id"`
+	SessionID uuid   `json:"session_id"`
+	Type      string `json:"type"`
+	Timestamp int64  `json:"ts"`
+}
+
+type uuidEventText struct {
+	EventID   uuidText `json:"event_id"`
+	UserID    uuidText `json:"user_id"`
+	SessionID uuidText `json:"session_id"`
+	Type      string   `json:"type"`
+	Timestamp int64    `json:"ts"`
+}
+
+type uuidEventString struct {
+	EventID   string `json:"event_id"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+	Type      string `json:"type"`
+	Timestamp int64  `json:"ts"`
+}
+
+// hydrate converts the string fields of an event after decoding
+func (e *uuidEventString) hydrate() (uuidEvent, error) {
+	out := uuidEvent{Type: e.Type, Timestamp: e.Timestamp}
+	for _, f := range []struct {
+		dst *uuid
+		src string
+	}{{&out.EventID, e.EventID}, {&out.UserID, e.UserID}, {&out.SessionID, e.SessionID}} {
+		u, err := parseUUID([]byte(f.src))
+		if err != nil {
+			return out, err
+		}
+		*f.dst = u
+	}
+	return out, nil
+}
+
+// uuidEvents generates n events with random version 4 UUIDs, drawn from
+// pools of users and sessions so that identifiers repeat as in real logs
+func uuidEvents(n int, seed int64) ([]byte, error) {
+	r := datasets.NewRand(seed)
+	random := func() uuid {
+		var u uuid
+		r.Read(u[:])
+		u[6] = u[6]&0x0F | 0x40
+		u[8] = u[8]&0x3F | 0x80
+		return u
+	}
+	users := make([]uuid, n/100+1)
+	for i := range users {
+		users[i] = random()
+	}
+	sessions := make([]uuid, n/10+1)
+	for i := range sessions {
+		sessions[i] = random()
+	}
+	types := []string{"click", "view", "purchase", "scroll"}
+	events := make([]uuidEvent, n)
+	for i := range events {
+		events[i] = uuidEvent{
+			EventID:   random(),
+			UserID:    users[r.Intn(len(users))],
+			SessionID: sessions[r.Intn(len(sessions))],
+			Type:      types[r.Intn(len(types))],
+			Timestamp: 1700000000000 + int64(i)*37,
+		}
+	}
+	return json.Marshal(events)
+}
+
+// uuidFlags are the flags of uuid
+type uuidFlags struct {
+	n          *int
+	seed       *int64
+	iterations *int
+}
+
+func (opts *uuidFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("uuid")
+	opts.n = fs.Int("events", 10000, "number of events to generate")
+	opts.seed = fs.Int64("seed", 1, "seed of the generated identifiers")
+	opts.iterations = fs.Int("n", 20, "number of iterations")
+	return fs
+}
+
+func runUUID(args []string) error {
+	var opts uuidFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := uuidEvents(*opts.n, *opts.seed)
+	if err != nil {
+		return err
+	}
+	var want []uuidEvent
+	if err := json.Unmarshal(data, &want); err != nil {
+		return err
+	}
+	var got []uuidEvent
+	methods := []struct {
+		name string
+		fn   func([]byte) error
+	}{
+		{"string fields, no hydration", func(b []byte) error {
+			var events []uuidEventString
+			return json.Unmarshal(b, &events)
+		}},
+		{"string fields + post-processing", func(b []byte) error {
+			var events []uuidEventString
+			if err := json.Unmarshal(b, &events); err != nil {
+				return err
+			}
+			got = make([]uuidEvent, len(events))
+			for i := range events {
+				var err error
+				if got[i], err = events[i].hydrate(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"UnmarshalText", func(b []byte) error {
+			var events []uuidEventText
+			if err := json.Unmarshal(b, &events); err != nil {
+				return err
+			}
+			got = make([]uuidEvent, len(events))
+			for i, e := range events {
+				got[i] = uuidEvent{uuid(e.EventID), uuid(e.UserID), uuid(e.SessionID), e.Type, e.Timestamp}
+			}
+			return nil
+		}},
+		{"UnmarshalJSON", func(b []byte) error {
+			got = nil
+			return json.Unmarshal(b, &got)
+		}},
+	}
+
+	fmt.Printf("%d events, %d bytes, 3 UUID fields each\n\n", len(want), len(data))
+	fmt.Println("| Method | MB/s | µs | ns/UUID over strings |")
+	fmt.Println("|---|---:|---:|---:|")
+	var base float64
+	for i, m := range methods {
+		// The baseline leaves got alone, so it passes the check
+		got = want
+		if err := m.fn(data); err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		if len(got) != len(want) {
+			return fmt.Errorf("%s: %d events, want %d", m.name, len(got), len(want))
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				return fmt.Errorf("%s: event %d differs", m.name, j)
+			}
+		}
+		speed, err := bench.Measure(data, *opts.iterations, m.fn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		micros := float64(len(data)) / speed
+		if i == 0 {
+			base = micros
+			fmt.Printf("| %s | %.2f | %.1f | |\n", m.name, speed, micros)
+			continue
+		}
+		// The hydration cost is the time over decoding the strings alone
+		fmt.Printf("| %s | %.2f | %.1f | %.1f |\n", m.name, speed, micros, (micros-base)*1e3/float64(3*len(want)))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/dom"
+)
+
+// validator checks a document without using it
+type validator struct {
+	name  string
+	valid func([]byte) bool
+}
+
+// validators returns every backend's Valid, the hand-rolled decoder
+// skipping values without building them, and a DOM parse whose result is
+// dropped, which is what simdjson's validate benchmark times
+func validators() []validator {
+	var vs []validator
+	for _, b := range backends.All() {
+		vs = append(vs, validator{b.Name() + " Valid", b.Valid})
+	}
+	var parser dom.Parser
+	return append(vs, []validator{
+		{"hand-rolled Skip, no values", func(data []byte) bool {
+			d := backends.NewDecoder(data, backends.DecodeOptions{SyntaxOnly: true})
+			return d.Skip() == nil && d.End() == nil
+		}},
+		{"dom Parse, as simdjson validates", func(data []byte) bool {
+			_, err := parser.Parse(data)
+			return err == nil
+		}},
+	}...)
+}
+
+var errInvalid = errors.New("invalid JSON")
+
+// validateFlags are the flags of validate
+type validateFlags struct {
+	files      *string
+	iterations *int
+}
+
+func (opts *validateFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("validate")
+	opts.files = fs.String("file", "../twitter.json", "comma-separated list of JSON documents")
+	opts.iterations = fs.Int("n", 200, "number of iterations")
+	return fs
+}
+
+// Check the well-formedness of each document of -file with every
+// validator, in GB/s for comparison with simdjson's validate benchmark.
+// Every validator must accept the documents and reject them truncated.
+func runValidate(args []string) error {
+	var opts validateFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	fmt.Println("| Dataset | Validator | GB/s | speedup |")
+	fmt.Println("|---|---|---:|---:|")
+	for _, file := range strings.Split(*opts.files, ",") {
+		data, err := datasets.Read(file)
+		if err != nil {
+			return err
+		}
+		name := datasets.Name(file)
+		base := 0.0
+		for _, v := range validators() {
+			if !v.valid(data) {
+				return fmt.Errorf("%s: %s: %w", name, v.name, withKind(errDataset, errInvalid))
+			}
+			if len(data) > 1 && v.valid(data[:len(data)/2]) {
+				return withKind(errMismatch, fmt.Errorf("%s: %s accepts the first half of the document", name, v.name))
+			}
+			speed, err := bench.Measure(data, *opts.iterations, func(data []byte) error {
+				if !v.valid(data) {
+					return errInvalid
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", name, v.name, err)
+			}
+			if base == 0 {
+				base = speed
+			}
+			fmt.Printf("| %s | %s | %.2f | %.1f× |\n", name, v.name, speed/1e3, speed/base)
+		}
+	}
+	return nil
+}
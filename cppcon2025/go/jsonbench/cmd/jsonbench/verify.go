@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// verifyBackend checks b on one document against encoding/json, whose
+// decoded value is want, and describes each disagreement
+func verifyBackend(b backends.Backend, data []byte, want interface{}, opt backends.CompareOptions) []string {
+	var problems []string
+	if b.Valid(data) != json.Valid(data) {
+		problems = append(problems, fmt.Sprintf("Valid = %v, encoding/json says %v", b.Valid(data), json.Valid(data)))
+	}
+	got, err := b.Decode(data)
+	if err != nil {
+		return append(problems, "decode: "+err.Error())
+	}
+	if d := backends.Diff("$", want, got, opt); d != "" {
+		problems = append(problems, "decodes differently: "+d)
+	}
+	if _, ok := b.(backends.Encoder); ok {
+		diff, err := roundTrip(b, data, opt)
+		switch {
+		case err != nil:
+			problems = append(problems, "round trip: "+err.Error())
+		case diff != "":
+			problems = append(problems, "round trip is lossy: "+diff)
+		}
+	}
+	return problems
+}
+
+// verifyFlags are the flags of verify
+type verifyFlags struct {
+	files     *string
+	only      *string
+	tolerance *float64
+	exact     *bool
+}
+
+func (opts *verifyFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("verify")
+	opts.files = fs.String("file", "../twitter.json", "comma-separated list of JSON documents, - for standard input")
+	opts.only = fs.String("backend", "", "comma-separated backends to check (default all)")
+	opts.tolerance = fs.Float64("tolerance", 0, "relative difference allowed between numbers")
+	opts.exact = fs.Bool("exact", false, "compare the numbers of the input and the re-encoded output as exact decimals, not as float64")
+	return fs
+}
+
+// Check that every backend agrees with encoding/json on each dataset,
+// before its throughput is worth comparing
+func runVerify(args []string) error {
+	var opts verifyFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	opt := backends.CompareOptions{Tolerance: *opts.tolerance, ExactNumbers: *opts.exact}
+	failed := 0
+	fmt.Println("| Dataset | Backend | Result |")
+	fmt.Println("|---|---|---|")
+	for _, file := range strings.Split(*opts.files, ",") {
+		data, err := datasets.Read(file)
+		if err != nil {
+			return err
+		}
+		var want interface{}
+		if err := json.Unmarshal(data, &want); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		for _, b := range backends.All() {
+			if *opts.only != "" && !containsFormat(*opts.only, b.Name()) {
+				continue
+			}
+			result := "ok"
+			if problems := verifyBackend(b, data, want, opt); len(problems) > 0 {
+				result = strings.Join(problems, "; ")
+				failed++
+			}
+			fmt.Printf("| %s | %s | %s |\n", datasets.Name(file), b.Name(), result)
+		}
+	}
+	if failed > 0 {
+		return withKind(errMismatch, fmt.Errorf("verify: %d backend and dataset pairs disagree with encoding/json", failed))
+	}
+	return nil
+}
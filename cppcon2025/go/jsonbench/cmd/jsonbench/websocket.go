@@ -0,0 +1,174 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+)
+
+// WebSocket (RFC 6455) streams of JSON messages with gorilla/websocket,
+// the framing a Go service receiving them is most likely to use, so the
+// per-message cost measured is that of the library's framing, masking
+// and buffering on top of the parse. Extensions and subprotocols are not
+// negotiated.
+
+var wsUpgrader websocket.Upgrader
+
+// wsDecodeHandler decodes every message it receives with b and, when the
+// client closes, sends back how many it decoded
+func wsDecodeHandler(b backends.Backend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		decoded, failed := 0, 0
+		for {
+			_, msg, err := c.ReadMessage()
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				break
+			}
+			if err != nil {
+				return
+			}
+			if len(msg) > 0 && msg[0] == '#' {
+				// The client's end of stream marker
+				break
+			}
+			if _, err := b.Decode(msg); err != nil {
+				failed++
+			} else {
+				decoded++
+			}
+		}
+		c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"decoded":%d,"failed":%d}`, decoded, failed)))
+		c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	})
+}
+
+// streamMessages sends messages round-robin over conns connections to url
+// for duration, then waits for the server's counts
+func streamMessages(url string, messages [][]byte, conns int, duration time.Duration) (decoded, failed int, elapsed time.Duration, err error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+	deadline := start.Add(duration)
+	for i := 0; i < conns; i++ {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			c, _, cerr := websocket.DefaultDialer.Dial(url, nil)
+			if cerr == nil {
+				for j := offset; time.Now().Before(deadline); j++ {
+					if cerr = c.WriteMessage(websocket.TextMessage, messages[j%len(messages)]); cerr != nil {
+						break
+					}
+				}
+			}
+			var counts struct{ Decoded, Failed int }
+			if cerr == nil {
+				if cerr = c.WriteMessage(websocket.TextMessage, []byte("#end")); cerr == nil {
+					var reply []byte
+					if _, reply, cerr = c.ReadMessage(); cerr == nil {
+						cerr = json.Unmarshal(reply, &counts)
+					}
+				}
+				c.Close()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			decoded += counts.Decoded
+			failed += counts.Failed
+			if err == nil {
+				err = cerr
+			}
+		}(i)
+	}
+	wg.Wait()
+	return decoded, failed, time.Since(start), err
+}
+
+// webSocketFlags are the flags of websocket
+type webSocketFlags struct {
+	file     *string
+	conns    *int
+	duration *time.Duration
+	only     *string
+}
+
+func (opts *webSocketFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("websocket")
+	opts.file = fs.String("file", "../twitter.json", "document whose records are the messages")
+	opts.conns = fs.Int("c", 4, "number of concurrent connections")
+	opts.duration = fs.Duration("d", 3*time.Second, "how long to stream to each backend")
+	opts.only = fs.String("backend", "", "comma-separated backends to run (default all)")
+	return fs
+}
+
+// runWebSocket measures sustained messages per second per backend with
+// the statuses of twitter.json as the messages
+func runWebSocket(args []string) error {
+	var opts webSocketFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	recs, err := datasets.Records(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	// Compact records, as a client would send them
+	messages := make([][]byte, len(recs))
+	total := 0
+	for i, r := range recs {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		messages[i] = b
+		total += len(b)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	server := &http.Server{Handler: mux}
+	go server.Serve(ln)
+	defer server.Close()
+
+	fmt.Printf("%s: %d messages of %d bytes on average, %d connections, %v per backend\n\n", *opts.file, len(messages), total/len(messages), *opts.conns, *opts.duration)
+	fmt.Println("| Backend | messages/s | MB/s | failed |")
+	fmt.Println("|---|---:|---:|---:|")
+	for i, b := range backends.All() {
+		if *opts.only != "" && !containsFormat(*opts.only, b.Name()) {
+			continue
+		}
+		path := "/ws/" + strconv.Itoa(i)
+		mux.Handle(path, wsDecodeHandler(b))
+		decoded, failed, elapsed, err := streamMessages("ws://"+ln.Addr().String()+path, messages, *opts.conns, *opts.duration)
+		if err != nil {
+			return fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		perSecond := float64(decoded) / elapsed.Seconds()
+		fmt.Printf("| %s | %.0f | %.2f | %d |\n", b.Name(), perSecond, perSecond*float64(total)/float64(len(messages))/1e6, failed)
+	}
+	return nil
+}
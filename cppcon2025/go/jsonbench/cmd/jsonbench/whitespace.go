@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/datasets"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// Between two tokens a parser skips whitespace. In minified JSON there
+// is none to skip, and each check costs the same whatever its width; in
+// pretty-printed JSON every line break is followed by a run of
+// indentation that a wider scanner crosses in fewer steps. The skippers
+// return the number of leading whitespace bytes of their input.
+
+// skipWhitespaceLoop is the byte loop of the hand-rolled decoder
+func skipWhitespaceLoop(data []byte) int {
+	for i, c := range data {
+		switch c {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return i
+		}
+	}
+	return len(data)
+}
+
+// firstByteThen checks the first byte alone, which ends most gaps, and
+// only calls the wider skip on whitespace
+func firstByteThen(skip func([]byte) int) func([]byte) int {
+	return func(data []byte) int {
+		if len(data) == 0 {
+			return 0
+		}
+		switch data[0] {
+		case ' ', '\t', '\n', '\r':
+			return skip(data)
+		}
+		return 0
+	}
+}
+
+// tokenGaps returns the offset at which every run of whitespace between
+// two tokens may start (the start of the document and the end of every
+// token) and the number of whitespace bytes
+func tokenGaps(data []byte) ([]int, int, error) {
+	gaps := []int{0}
+	whitespace := 0
+	for pos := 0; ; {
+		n := skipWhitespaceLoop(data[pos:])
+		whitespace += n
+		pos += n
+		if pos == len(data) {
+			return gaps, whitespace, nil
+		}
+		switch c := data[pos]; {
+		case c == '"':
+			pos++
+			for pos < len(data) && data[pos] != '"' {
+				if data[pos] == '\\' {
+					pos++
+				}
+				pos++
+			}
+			if pos >= len(data) {
+				return nil, 0, fmt.Errorf("unterminated string at offset %d", pos)
+			}
+			pos++
+		case bytes.IndexByte([]byte("{}[]:,"), c) >= 0:
+			pos++
+		default:
+			for pos < len(data) && bytes.IndexByte([]byte(" \t\n\r{}[]:,\""), data[pos]) < 0 {
+				pos++
+			}
+		}
+		gaps = append(gaps, pos)
+	}
+}
+
+// whitespaceFlags are the flags of whitespace
+type whitespaceFlags struct {
+	file       *string
+	indent     *string
+	iterations *int
+}
+
+func (opts *whitespaceFlags) flags() *flag.FlagSet {
+	fs := newFlagSet("whitespace")
+	opts.file = fs.String("file", "../twitter.json", "document to minify and pretty-print")
+	opts.indent = fs.String("indent", "  ", "indentation of the pretty-printed copy")
+	opts.iterations = fs.Int("n", 200, "number of iterations")
+	return fs
+}
+
+func runWhitespace(args []string) error {
+	var opts whitespaceFlags
+	fs := opts.flags()
+	fs.Parse(args)
+
+	data, err := datasets.Read(*opts.file)
+	if err != nil {
+		return err
+	}
+	var minified, pretty bytes.Buffer
+	if err := json.Compact(&minified, data); err != nil {
+		return fmt.Errorf("%s: %w", *opts.file, err)
+	}
+	if err := json.Indent(&pretty, minified.Bytes(), "", *opts.indent); err != nil {
+		return err
+	}
+	inputs := []struct {
+		name       string
+		data       []byte
+		gaps       []int
+		whitespace int
+	}{{name: "minified", data: minified.Bytes()}, {name: "pretty-printed", data: pretty.Bytes()}}
+	for i := range inputs {
+		in := &inputs[i]
+		gaps, whitespace, err := tokenGaps(in.data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", in.name, err)
+		}
+		in.gaps, in.whitespace = gaps, whitespace
+		fmt.Printf("%s: %d bytes, %d of them whitespace (%.1f%%), %d gaps between tokens\n",
+			in.name, len(in.data), whitespace, 100*float64(whitespace)/float64(len(in.data)), len(gaps))
+	}
+
+	skippers := []struct {
+		name string
+		skip func([]byte) int
+	}{
+		{"byte loop", skipWhitespaceLoop},
+	}
+	forEachImplementation(func(impl simd.Implementation) error {
+		if simd.SkipWhitespace != nil {
+			skippers = append(skippers, struct {
+				name string
+				skip func([]byte) int
+			}{fmt.Sprintf("%s, %d bytes", impl.Name, impl.Width), simd.SkipWhitespace})
+		}
+		return nil
+	})
+	for _, s := range skippers[1:] {
+		skippers = append(skippers, struct {
+			name string
+			skip func([]byte) int
+		}{"first byte, then " + s.name, firstByteThen(s.skip)})
+	}
+
+	// Every skipper must stop where the byte loop does
+	for _, in := range inputs {
+		for _, gap := range in.gaps {
+			want := skipWhitespaceLoop(in.data[gap:])
+			for _, s := range skippers[1:] {
+				if got := s.skip(in.data[gap:]); got != want {
+					return fmt.Errorf("%s: %s skips %d bytes at offset %d, want %d", in.name, s.name, got, gap, want)
+				}
+			}
+		}
+	}
+
+	fmt.Println("\n| Scanner | minified µs | pretty-printed µs |")
+	fmt.Println("|---|---:|---:|")
+	for _, s := range skippers {
+		fmt.Printf("| %s", s.name)
+		for _, in := range inputs {
+			skipped := 0
+			start := time.Now()
+			for it := 0; it < *opts.iterations; it++ {
+				for _, gap := range in.gaps {
+					skipped += s.skip(in.data[gap:])
+				}
+			}
+			if skipped != in.whitespace**opts.iterations {
+				return fmt.Errorf("%s: skipped %d bytes of whitespace, want %d", s.name, skipped, in.whitespace**opts.iterations)
+			}
+			fmt.Printf(" | %.1f", time.Since(start).Seconds()*1e6/float64(*opts.iterations))
+		}
+		fmt.Println(" |")
+	}
+
+	// The whole decoding, for scale
+	for _, d := range []struct {
+		name   string
+		decode func([]byte) error
+	}{
+		{"encoding/json decode", func(b []byte) error {
+			var v interface{}
+			return json.Unmarshal(b, &v)
+		}},
+		{"handrolled decode", func(b []byte) error {
+			_, err := backends.Decode(b, backends.DecodeOptions{})
+			return err
+		}},
+	} {
+		fmt.Printf("| %s", d.name)
+		for _, in := range inputs {
+			speed, err := bench.Measure(in.data, *opts.iterations/10+1, d.decode)
+			if err != nil {
+				return fmt.Errorf("%s: %w", d.name, err)
+			}
+			fmt.Printf(" | %.1f", float64(len(in.data))/speed)
+		}
+		fmt.Println(" |")
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A YAML parser for the block-style subset config files use: mappings,
+// sequences, flow sequences of scalars, plain and quoted scalars, and
+// comments, resolved with the YAML 1.2 core schema into generic values
+// like encoding/json's, with int64 integers. Anchors, tags,
+// block scalars, flow mappings and multiple documents are rejected. It
+// parses into a tree and then binds it, like gopkg.in/yaml.v3 does, but
+// does far less, so its speed is an upper bound for a real library.
+
+// yamlLine is a line without its indentation and comment
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+func parseYAML(data []byte) (interface{}, error) {
+	p := &yamlParser{}
+	number := 0
+	for len(data) > 0 {
+		number++
+		var line []byte
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			line, data = data, nil
+		}
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		indent := 0
+		for indent < len(line) && line[indent] == ' ' {
+			indent++
+		}
+		if indent < len(line) && line[indent] == '\t' {
+			return nil, fmt.Errorf("yaml: line %d: tab in indentation", number)
+		}
+		text := strings.TrimRight(stripYAMLComment(string(line[indent:])), " \t")
+		if text == "" || (indent == 0 && text == "---") {
+			continue
+		}
+		p.lines = append(p.lines, yamlLine{number, indent, text})
+	}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+	v, err := p.node(p.lines[0].indent)
+	if err == nil && p.i < len(p.lines) {
+		err = fmt.Errorf("yaml: line %d: unexpected indentation", p.lines[p.i].number)
+	}
+	return v, err
+}
+
+// stripYAMLComment cuts a comment, a # at the start or after a space,
+// outside quotes
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '[' || s[i-1] == ',' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// node parses the sequence or mapping whose lines start at indent
+func (p *yamlParser) node(indent int) (interface{}, error) {
+	if isYAMLSequenceItem(p.lines[p.i].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.i < len(p.lines) {
+		line := &p.lines[p.i]
+		if line.indent != indent || !isYAMLSequenceItem(line.text) {
+			break
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		var item interface{}
+		var err error
+		switch {
+		case rest == "":
+			item, err = p.child(indent, false)
+		case isYAMLSequenceItem(rest) || yamlKey(rest) >= 0:
+			// The item is a nested block starting on this line: reparse the
+			// rest of the line at the column it starts in
+			line.indent += len(line.text) - len(rest)
+			line.text = rest
+			item, err = p.node(line.indent)
+		default:
+			p.i++
+			item, err = parseYAMLScalar(rest, line.number)
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.i < len(p.lines) {
+		line := p.lines[p.i]
+		if line.indent != indent || isYAMLSequenceItem(line.text) {
+			break
+		}
+		colon := yamlKey(line.text)
+		if colon < 0 {
+			return nil, fmt.Errorf("yaml: line %d: expected a key: value", line.number)
+		}
+		key, err := parseYAMLScalar(line.text[:colon], line.number)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			name = line.text[:colon]
+		}
+		if _, dup := m[name]; dup {
+			return nil, fmt.Errorf("yaml: line %d: duplicate key %q", line.number, name)
+		}
+		rest := strings.TrimLeft(line.text[colon+1:], " ")
+		var v interface{}
+		if rest == "" {
+			v, err = p.child(indent, true)
+		} else {
+			p.i++
+			v, err = parseYAMLScalar(rest, line.number)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[name] = v
+	}
+	return m, nil
+}
+
+// child parses the block value of a line with nothing after its "-" or
+// ":", which is null when the next line is not indented deeper. A
+// mapping's sequence value may also start at the key's own indentation.
+func (p *yamlParser) child(indent int, inMapping bool) (interface{}, error) {
+	p.i++
+	if p.i == len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.i]
+	if next.indent > indent || (inMapping && next.indent == indent && isYAMLSequenceItem(next.text)) {
+		return p.node(next.indent)
+	}
+	return nil, nil
+}
+
+// yamlKey returns the offset of the colon ending the key of a mapping
+// entry, or -1
+func yamlKey(text string) int {
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		end := yamlQuoteEnd(text)
+		if end < 0 || end+1 >= len(text) || text[end+1] != ':' {
+			return -1
+		}
+		if end+2 == len(text) || text[end+2] == ' ' {
+			return end + 1
+		}
+		return -1
+	}
+	if text != "" && text[0] == '[' {
+		return -1
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlQuoteEnd returns the offset of the quote closing text's first
+// character, or -1
+func yamlQuoteEnd(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote:
+			if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func parseYAMLScalar(s string, line int) (interface{}, error) {
+	if s == "" {
+		return nil, nil
+	}
+	switch s[0] {
+	case '"', '\'':
+		end := yamlQuoteEnd(s)
+		if end != len(s)-1 {
+			return nil, fmt.Errorf("yaml: line %d: invalid quoted scalar %s", line, s)
+		}
+		return unquoteYAML(s, line)
+	case '[':
+		return parseYAMLFlowSequence(s, line)
+	case '{', '&', '*', '!', '|', '>', '%', '@', '`':
+		return nil, fmt.Errorf("yaml: line %d: unsupported syntax %q", line, s[:1])
+	}
+	return resolveYAML(s), nil
+}
+
+func unquoteYAML(s string, line int) (string, error) {
+	body := s[1 : len(s)-1]
+	if s[0] == '\'' {
+		return strings.ReplaceAll(body, "''", "'"), nil
+	}
+	if strings.IndexByte(body, '\\') < 0 {
+		return body, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(body) {
+			return "", fmt.Errorf("yaml: line %d: unterminated escape", line)
+		}
+		switch c = body[i]; c {
+		case '0':
+			b.WriteByte(0)
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 't', '\t':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'v':
+			b.WriteByte('\v')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case 'e':
+			b.WriteByte(0x1b)
+		case ' ', '"', '/', '\\':
+			b.WriteByte(c)
+		case 'x', 'u', 'U':
+			n := 2
+			if c == 'u' {
+				n = 4
+			} else if c == 'U' {
+				n = 8
+			}
+			if i+n >= len(body) {
+				return "", fmt.Errorf("yaml: line %d: short \\%c escape", line, c)
+			}
+			r, err := strconv.ParseUint(body[i+1:i+1+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return "", fmt.Errorf("yaml: line %d: invalid \\%c escape", line, c)
+			}
+			b.WriteRune(rune(r))
+			i += n
+		default:
+			return "", fmt.Errorf("yaml: line %d: invalid escape \\%c", line, c)
+		}
+	}
+	return b.String(), nil
+}
+
+// parseYAMLFlowSequence parses a one-line [a, "b", 3] of scalars
+func parseYAMLFlowSequence(s string, line int) (interface{}, error) {
+	if s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("yaml: line %d: unterminated flow sequence", line)
+	}
+	items := []interface{}{}
+	body := strings.TrimSpace(s[1 : len(s)-1])
+	for body != "" {
+		var item string
+		if body[0] == '"' || body[0] == '\'' {
+			end := yamlQuoteEnd(body)
+			if end < 0 {
+				return nil, fmt.Errorf("yaml: line %d: unterminated quoted scalar", line)
+			}
+			item, body = body[:end+1], strings.TrimLeft(body[end+1:], " ")
+			if body != "" && body[0] != ',' {
+				return nil, fmt.Errorf("yaml: line %d: expected , in flow sequence", line)
+			}
+		} else if i := strings.IndexByte(body, ','); i >= 0 {
+			item, body = strings.TrimRight(body[:i], " "), body[i:]
+		} else {
+			item, body = body, ""
+		}
+		body = strings.TrimLeft(strings.TrimPrefix(body, ","), " ")
+		if item == "" {
+			return nil, fmt.Errorf("yaml: line %d: empty flow sequence entry", line)
+		}
+		v, err := parseYAMLScalar(item, line)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// resolveYAML gives a plain scalar its core schema type
+func resolveYAML(s string) interface{} {
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o") {
+		base := 16
+		if s[1] == 'o' {
+			base = 8
+		}
+		if i, err := strconv.ParseInt(s[2:], base, 64); err == nil && len(s) > 2 && s[2] != '+' && s[2] != '-' {
+			return i
+		}
+		return s
+	}
+	if c := s[0]; c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9') {
+		if strings.ContainsAny(s, "_xXpPnN") { // no Go-only forms, Inf or NaN
+			return s
+		}
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
@@ -0,0 +1,281 @@
+// tasks builds the demo binaries, fetches the datasets and runs the test
+// and benchmark suites, in Go so the same steps work on every platform:
+//
+//	go run ./cmd/tasks build data bench
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// task is one step of the runner; deps run first, once per invocation
+type task struct {
+	name    string
+	summary string
+	deps    []string
+	run     func() error
+}
+
+var tasks []task
+
+func init() {
+	tasks = []task{
+		{"build", "build jsonbench, the WASI and browser binaries and the slide programs", nil, build},
+		{"data", "download the benchmark datasets next to twitter.json", []string{"build"}, data},
+		{"check", "check formatting and vet every supported platform and build tag", nil, check},
+		{"test", "run the unit tests and verify every backend against encoding/json", []string{"build"}, test},
+		{"bench", "run every benchmark listed in runall.json", []string{"build", "data"}, bench},
+		{"serve", "serve cppcon2025/go for the browser demo", []string{"build"}, serve},
+		{"docs", "write the man page and the shell completions of jsonbench", []string{"build"}, docs},
+		{"clean", "remove the build outputs", nil, clean},
+		{"all", "check, build, fetch the datasets and test", []string{"check", "build", "data", "test"}, func() error { return nil }},
+	}
+}
+
+var (
+	addr  = flag.String("addr", ":8000", "address the serve task listens on")
+	suite = flag.String("suite", "", "extra flags passed to run-all, e.g. '-n 20'")
+)
+
+// binDir holds the binaries built for the host
+const binDir = "bin"
+
+// exe names a host binary in binDir
+func exe(name string) string {
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(binDir, name)
+}
+
+// run prints and runs a command with the given extra environment,
+// streaming its output
+func run(env []string, name string, args ...string) error {
+	fmt.Fprintln(os.Stderr, "+", strings.Join(append(append(env[:len(env):len(env)], name), args...), " "))
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// goEnv returns the value of one go env variable
+func goEnv(key string) (string, error) {
+	out, err := exec.Command("go", "env", key).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func build() error {
+	if err := run(nil, "go", "build", "-o", exe("jsonbench"), "./cmd/jsonbench"); err != nil {
+		return err
+	}
+	if err := run([]string{"GOOS=wasip1", "GOARCH=wasm"}, "go", "build", "-o", filepath.Join(binDir, "jsonbench.wasm"), "./cmd/jsonbench"); err != nil {
+		return err
+	}
+	if err := run([]string{"GOOS=js", "GOARCH=wasm"}, "go", "build", "-o", filepath.Join("cmd", "jsonbench-wasm", "main.wasm"), "./cmd/jsonbench-wasm"); err != nil {
+		return err
+	}
+	// The loader moved from misc/wasm to lib/wasm in Go 1.24
+	goroot, err := goEnv("GOROOT")
+	if err != nil {
+		return err
+	}
+	loader := filepath.Join(goroot, "lib", "wasm", "wasm_exec.js")
+	if _, err := os.Stat(loader); err != nil {
+		loader = filepath.Join(goroot, "misc", "wasm", "wasm_exec.js")
+	}
+	if err := copyFile(loader, filepath.Join("cmd", "jsonbench-wasm", "wasm_exec.js")); err != nil {
+		return err
+	}
+	// The slide programs are single files outside the module
+	for _, name := range []string{"parse_twitter", "json", "reflect"} {
+		out, err := filepath.Abs(exe(name))
+		if err != nil {
+			return err
+		}
+		if err := run(nil, "go", "-C", "..", "build", "-o", out, name+".go"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func docs() error {
+	for _, out := range []struct {
+		file string
+		args []string
+	}{
+		{"jsonbench.1", []string{"man"}},
+		{"jsonbench.bash", []string{"completion", "bash"}},
+		{"_jsonbench", []string{"completion", "zsh"}},
+		{"jsonbench.fish", []string{"completion", "fish"}},
+	} {
+		f, err := os.Create(filepath.Join(binDir, out.file))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "+", exe("jsonbench"), strings.Join(out.args, " "), ">", f.Name())
+		cmd := exec.Command(exe("jsonbench"), out.args...)
+		cmd.Stdout, cmd.Stderr = f, os.Stderr
+		err = cmd.Run()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", out.file, err)
+		}
+	}
+	return nil
+}
+
+func data() error {
+	return run(nil, exe("jsonbench"), "fetch")
+}
+
+func check() error {
+	goroot, err := goEnv("GOROOT")
+	if err != nil {
+		return err
+	}
+	out, err := exec.Command(filepath.Join(goroot, "bin", "gofmt"), "-l", ".").Output()
+	if err != nil {
+		return fmt.Errorf("gofmt: %w", err)
+	}
+	if len(out) > 0 {
+		return fmt.Errorf("gofmt: files need formatting:\n%s", out)
+	}
+	for _, env := range [][]string{
+		nil,
+		{"GOARCH=amd64"},
+		{"GOARCH=arm64"},
+		{"GOOS=darwin", "GOARCH=arm64"},
+		{"GOOS=windows", "GOARCH=amd64"},
+	} {
+		if err := run(env, "go", "vet", "./..."); err != nil {
+			return err
+		}
+	}
+	return run(nil, "go", "vet", "-tags", "tinygo", "./...")
+}
+
+func test() error {
+	if err := run(nil, "go", "test", "./..."); err != nil {
+		return err
+	}
+	return run(nil, exe("jsonbench"), "verify")
+}
+
+func bench() error {
+	return run(nil, exe("jsonbench"), append([]string{"run-all"}, strings.Fields(*suite)...)...)
+}
+
+func serve() error {
+	fmt.Printf("serving .. on %s, open http://localhost%s/jsonbench/cmd/jsonbench-wasm/\n", *addr, *addr)
+	return http.ListenAndServe(*addr, http.FileServer(http.Dir("..")))
+}
+
+func clean() error {
+	for _, path := range []string{
+		binDir,
+		filepath.Join("cmd", "jsonbench-wasm", "main.wasm"),
+		filepath.Join("cmd", "jsonbench-wasm", "wasm_exec.js"),
+	} {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func lookup(name string) *task {
+	for i := range tasks {
+		if tasks[i].name == name {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+// runTask runs the dependencies of t, then t, skipping tasks already done
+func runTask(t *task, done map[string]bool) error {
+	if done[t.name] {
+		return nil
+	}
+	done[t.name] = true
+	for _, dep := range t.deps {
+		if err := runTask(lookup(dep), done); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "== %s\n", t.name)
+	return t.run()
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: go run ./cmd/tasks [flags] <task>...")
+	fmt.Fprintln(os.Stderr, "\ntasks:")
+	for _, t := range tasks {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", t.name, t.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nflags:")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	// Paths are relative to the module, wherever the runner is started
+	gomod, err := goEnv("GOMOD")
+	if err != nil || gomod == "" || gomod == os.DevNull {
+		fmt.Fprintln(os.Stderr, "Error: run the tasks from the jsonbench module")
+		os.Exit(1)
+	}
+	if err := os.Chdir(filepath.Dir(gomod)); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	done := map[string]bool{}
+	for _, name := range flag.Args() {
+		t := lookup(name)
+		if t == nil {
+			fmt.Fprintf(os.Stderr, "unknown task %q\n\n", name)
+			usage()
+			os.Exit(2)
+		}
+		if err := runTask(t, done); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	}
+}
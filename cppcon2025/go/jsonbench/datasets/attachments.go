@@ -0,0 +1,35 @@
+package datasets
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The attachments dataset embeds binary payloads as base64 strings, the
+// usual way of carrying images, keys and thumbnails in JSON.
+
+// Attachment is a record of the dataset with the payload decoded by
+// encoding/json, which turns base64 strings into []byte fields
+type Attachment struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Mime string `json:"mime"`
+	Data []byte `json:"data"`
+}
+
+// Attachments generates n records with random payloads whose
+// sizes are spread evenly on a log scale from 16 bytes to 64 KB
+func Attachments(n int, seed int64) ([]byte, error) {
+	r := NewRand(seed)
+	mimes := []string{"image/png", "image/jpeg", "application/pdf", "application/octet-stream"}
+	records := make([]Attachment, n)
+	for i := range records {
+		// 12 doublings from 16 bytes, each split into 256 linear steps
+		step := r.Intn(12 * 256)
+		size := (16 << (step / 256)) * (256 + step%256) / 256
+		data := make([]byte, size)
+		r.Read(data)
+		records[i] = Attachment{ID: i, Name: fmt.Sprintf("file%d", i), Mime: mimes[i%len(mimes)], Data: data}
+	}
+	return json.Marshal(records)
+}
@@ -0,0 +1,18 @@
+package datasets
+
+// CorpusURL is the directory of simdjson's benchmark documents, next to
+// which the talk's measurements were taken
+const CorpusURL = "https://raw.githubusercontent.com/simdjson/simdjson/master/jsonexamples/"
+
+// Corpus names the documents of CorpusURL the benchmarks use: twitter.json,
+// which every command defaults to, and documents dominated by numbers, by
+// short strings and by deep nesting
+var Corpus = []string{
+	"twitter.json",
+	"canada.json",
+	"citm_catalog.json",
+	"github_events.json",
+	"gsoc-2018.json",
+	"mesh.json",
+	"numbers.json",
+}
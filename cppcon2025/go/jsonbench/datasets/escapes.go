@@ -0,0 +1,28 @@
+package datasets
+
+// EscapeHeavy generates an array of n strings in which short runs
+// of text alternate with escapes of every kind, from \n to surrogate
+// pairs, about one escape per 8 bytes
+func EscapeHeavy(n int, seed int64) []byte {
+	r := NewRand(seed)
+	escapes := []string{`\"`, `\\`, `\/`, `\n`, `\t`, `\r`, `\b`, `\f`, `\u00e9`, `\u4e2d`, `\u0001`, `\ud83d\ude00`}
+	const letters = "abcdefghijklmnopqrstuvwxyz ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789.,"
+	doc := []byte{'['}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			doc = append(doc, ',')
+		}
+		doc = append(doc, '"')
+		for pieces := 8 + r.Intn(120); pieces > 0; pieces-- {
+			for run := r.Intn(12); run > 0; run-- {
+				doc = append(doc, letters[r.Intn(len(letters))])
+			}
+			if r.Intn(8) == 0 {
+				doc = append(doc, "é中"[:2+r.Intn(2)*3]...)
+			}
+			doc = append(doc, escapes[r.Intn(len(escapes))]...)
+		}
+		doc = append(doc, '"')
+	}
+	return append(doc, ']')
+}
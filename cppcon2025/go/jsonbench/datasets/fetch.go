@@ -0,0 +1,39 @@
+//go:build !tinygo
+
+package datasets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Fetch downloads the document name from the directory at baseURL into
+// dir and returns its path. The file only appears once it is complete and
+// valid JSON, so an interrupted download never leaves a truncated dataset.
+func Fetch(baseURL, name, dir string) (string, error) {
+	resp, err := http.Get(strings.TrimSuffix(baseURL, "/") + "/" + name)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", name, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	if !json.Valid(data) {
+		return "", fmt.Errorf("%s: the download is not valid JSON", name)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,41 @@
+package datasets
+
+// GiantString generates a document that is one JSON string of about size
+// bytes: text, with some 2- and 3-byte UTF-8, broken by an escape every
+// gap bytes on average. Copying and unescaping are then all of the parse,
+// in a single value larger than any buffer a parser would preallocate.
+func GiantString(size, gap int, seed int64) []byte {
+	r := NewRand(seed)
+	escapes := []string{`\"`, `\\`, `\/`, `\n`, `\t`, `\u00e9`, `\u4e2d`, `\ud83d\ude00`}
+	const letters = "abcdefghijklmnopqrstuvwxyz ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789.,"
+	// The runs between escapes are cut from one block of text, so that
+	// hundreds of MB take seconds to generate
+	block := make([]byte, 0, 1<<16)
+	for len(block) < 1<<16-3 {
+		if r.Intn(64) == 0 {
+			block = append(block, "é中"[:2+r.Intn(2)*3]...)
+			continue
+		}
+		block = append(block, letters[r.Intn(len(letters))])
+	}
+	doc := make([]byte, 0, size+gap+16)
+	doc = append(doc, '"')
+	for len(doc) < size-1 {
+		run := min(r.Intn(2*gap+1), size-1-len(doc))
+		for run > 0 {
+			start := r.Intn(len(block) - 4)
+			// Start and end on whole runes
+			for block[start]&0xC0 == 0x80 {
+				start++
+			}
+			end := min(start+run, len(block))
+			for end < len(block) && block[end]&0xC0 == 0x80 {
+				end--
+			}
+			doc = append(doc, block[start:end]...)
+			run -= end - start
+		}
+		doc = append(doc, escapes[r.Intn(len(escapes))]...)
+	}
+	return append(doc, '"')
+}
@@ -0,0 +1,14 @@
+//go:build !unix || tinygo
+
+package datasets
+
+import (
+	"errors"
+	"os"
+)
+
+const mmapSupported = false
+
+func mapWindow(f *os.File, off int64, n int) ([]byte, func() error, error) {
+	return nil, nil, errors.New("mmap is not supported")
+}
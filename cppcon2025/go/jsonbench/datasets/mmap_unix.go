@@ -0,0 +1,24 @@
+//go:build unix && !tinygo
+
+package datasets
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const mmapSupported = true
+
+// mapWindow maps n bytes of f from off, which mmap wants aligned on a
+// page, so the mapping starts at the page of off
+func mapWindow(f *os.File, off int64, n int) ([]byte, func() error, error) {
+	aligned := off &^ int64(os.Getpagesize()-1)
+	m, err := unix.Mmap(int(f.Fd()), aligned, n+int(off-aligned), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Read-ahead, and pages behind the reader dropped first
+	unix.Madvise(m, unix.MADV_SEQUENTIAL)
+	return m[off-aligned:], func() error { return unix.Munmap(m) }, nil
+}
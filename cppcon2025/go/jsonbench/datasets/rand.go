@@ -0,0 +1,21 @@
+package datasets
+
+import "math/rand"
+
+// The generated datasets are byte-identical for a given seed across runs,
+// Go versions and platforms, so that a benchmark of one can be repeated
+// from its seed alone. The generators draw from NewRand, whose math/rand
+// source is specified never to change; they size and pick with integers,
+// round floating-point values explicitly (Go may fuse a multiply and an
+// add into one FMA instruction on arm64, ppc64 and s390x, which changes
+// the last bit), and write JSON with encoding/json, which sorts map keys.
+
+// NewRand returns the deterministic source of a generator
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// Uniform returns a value in [lo, hi), rounded the same on every platform
+func Uniform(r *rand.Rand, lo, hi float64) float64 {
+	return lo + float64((hi-lo)*r.Float64())
+}
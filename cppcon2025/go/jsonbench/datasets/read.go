@@ -0,0 +1,58 @@
+package datasets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Stdin is the path that names standard input
+const Stdin = "-"
+
+var (
+	stdinOnce sync.Once
+	stdinData []byte
+	stdinErr  error
+)
+
+// Read returns the document at path, or on standard input for "-", and
+// decompresses it if it is gzipped, so that every command taking a -file
+// composes with curl and generators in a pipe. Standard input is read
+// once; later reads return the same document.
+func Read(path string) ([]byte, error) {
+	var data []byte
+	var err error
+	if path == Stdin {
+		stdinOnce.Do(func() { stdinData, stdinErr = io.ReadAll(os.Stdin) })
+		data, err = stdinData, stdinErr
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", Name(path), err)
+	}
+	data, err = io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", Name(path), err)
+	}
+	return data, nil
+}
+
+// Name is the dataset name reported for the document at path
+func Name(path string) string {
+	if path == Stdin {
+		return "stdin"
+	}
+	return filepath.Base(path)
+}
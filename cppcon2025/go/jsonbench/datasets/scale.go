@@ -0,0 +1,76 @@
+// Package datasets reads and builds benchmark documents: documents from
+// files, standard input or the simdjson corpus, copies of a real document
+// scaled to a size, and generated documents that stress one part of a
+// parser.
+package datasets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Records returns the raw elements of the document's first top-level
+// array (the statuses of twitter.json), or the document itself when it
+// has none, keeping their original formatting
+func Records(data []byte) ([]json.RawMessage, error) {
+	var arr []json.RawMessage
+	if err := json.Unmarshal(data, &arr); err == nil && len(arr) > 0 {
+		return arr, nil
+	}
+	// The members are read in document order, so that the same array is
+	// picked on every run
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err == nil && t == json.Delim('{') {
+		for dec.More() {
+			if _, err := dec.Token(); err != nil {
+				break
+			}
+			var v json.RawMessage
+			if err := dec.Decode(&v); err != nil {
+				break
+			}
+			if err := json.Unmarshal(v, &arr); err == nil && len(arr) > 0 {
+				return arr, nil
+			}
+		}
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("invalid JSON")
+	}
+	return []json.RawMessage{data}, nil
+}
+
+// ScaledDocument builds a JSON array of records, cycling through them,
+// until it reaches at least size bytes
+func ScaledDocument(recs []json.RawMessage, size int) []byte {
+	var buf bytes.Buffer
+	buf.Grow(size + len(recs[0]) + 2)
+	buf.WriteByte('[')
+	for i := 0; buf.Len() < size-1; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(recs[i%len(recs)])
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+// ParseSize reads sizes such as 512, 64KB, 16MB or 1GB
+func ParseSize(s string) (int, error) {
+	units := []struct {
+		suffix string
+		scale  int
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(s, u.suffix))
+			return n * u.scale, err
+		}
+	}
+	return strconv.Atoi(s)
+}
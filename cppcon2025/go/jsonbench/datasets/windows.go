@@ -0,0 +1,67 @@
+package datasets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// MmapSupported reports whether Windows can map files into memory here
+const MmapSupported = mmapSupported
+
+// Windows calls fn with the file at path in consecutive windows of at
+// most size bytes, each ending after a newline (the last one at the end
+// of the file), so that NDJSON far larger than memory is processed a
+// window at a time and no line is cut. With mmap, each window is mapped,
+// advised as read sequentially and unmapped once fn returns, leaving the
+// kernel to fault the pages in and evict them; otherwise it is read into
+// one buffer that every window reuses. fn must not keep the window.
+func Windows(path string, size int, mmap bool, fn func(window []byte) error) error {
+	if mmap && !MmapSupported {
+		return fmt.Errorf("%s: mmap is not supported on this platform", Name(path))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	for off, total := int64(0), st.Size(); off < total; {
+		n := int(min(int64(size), total-off))
+		var w []byte
+		release := func() error { return nil }
+		if mmap {
+			if w, release, err = mapWindow(f, off, n); err != nil {
+				return fmt.Errorf("%s: mmap at %d: %w", Name(path), off, err)
+			}
+		} else {
+			if buf == nil {
+				buf = make([]byte, size)
+			}
+			w = buf[:n]
+			if _, err := f.ReadAt(w, off); err != nil {
+				return fmt.Errorf("%s: %w", Name(path), err)
+			}
+		}
+		end := len(w)
+		if off+int64(n) < total {
+			if end = bytes.LastIndexByte(w, '\n') + 1; end == 0 {
+				release()
+				return fmt.Errorf("%s: a line at offset %d is longer than the %d-byte window", Name(path), off, size)
+			}
+		}
+		err := fn(w[:end])
+		if rerr := release(); err == nil {
+			err = rerr
+		}
+		if err != nil {
+			return err
+		}
+		off += int64(end)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+// Package dom is simdjson's DOM API in Go: stage 1 indexes a document
+// with package scanner, and stage 2 walks the index once, without
+// recursion, into a tape of 64-bit words and a string buffer, the layout
+// of backends.Tape. Navigating the tape afterwards parses nothing: every
+// container records where it ends, so skipping one is a single jump.
+package dom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/number"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// The errors of simdjson's DOM parser
+var (
+	ErrEmpty         = errors.New("dom: empty document")
+	ErrUTF8          = errors.New("dom: invalid UTF-8")
+	ErrTape          = errors.New("dom: invalid JSON structure")
+	ErrDepth         = errors.New("dom: document too deep")
+	ErrString        = errors.New("dom: invalid string")
+	ErrNumber        = errors.New("dom: invalid number")
+	ErrAtom          = errors.New("dom: invalid true, false or null")
+	ErrIncorrectType = errors.New("dom: incorrect type")
+	ErrNoSuchField   = errors.New("dom: no such field")
+	ErrOutOfBounds   = errors.New("dom: index out of bounds")
+)
+
+// Document is a parsed document: a backends.Tape, which documents the
+// layout, and the same for either parser
+type Document struct {
+	backends.Tape
+}
+
+// Root returns the top-level value
+func (d *Document) Root() Element { return Element{doc: d, i: 1} }
+
+// Parser holds the buffers of the index and of the documents it parses,
+// as simdjson's parser does; the zero value is ready to use
+type Parser struct {
+	doc   Document
+	idx   []uint32
+	stack []frame
+}
+
+// frame is an open container during stage 2
+type frame struct {
+	open   int
+	count  int
+	object bool
+}
+
+// Document returns the document last parsed
+func (p *Parser) Document() *Document { return &p.doc }
+
+// Parse parses data and returns its root. The document is the parser's
+// and valid until the next call.
+func (p *Parser) Parse(data []byte) (Element, error) {
+	if !scanner.ValidUTF8(data) {
+		return Element{}, ErrUTF8
+	}
+	var err error
+	if p.idx, err = scanner.Index(data, p.idx[:0]); err != nil {
+		return Element{}, fmt.Errorf("%w: %v", ErrTape, err)
+	}
+	if len(p.idx) == 0 {
+		return Element{}, ErrEmpty
+	}
+	if err := p.build(data); err != nil {
+		return Element{}, err
+	}
+	return p.doc.Root(), nil
+}
+
+// errorAt returns err at the offset of structural character i
+func (p *Parser) errorAt(err error, i int) error {
+	if i >= len(p.idx) {
+		return fmt.Errorf("%w at the end of the document", err)
+	}
+	return fmt.Errorf("%w at offset %d", err, p.idx[i])
+}
+
+// build is stage 2: one pass over the structural characters, each value
+// appended to the tape, each container open on the stack until it ends
+func (p *Parser) build(data []byte) error {
+	d, idx := &p.doc, p.idx
+	d.Words = append(d.Words[:0], 'r'<<56)
+	d.Strings = d.Strings[:0]
+	p.stack = p.stack[:0]
+	i := 0
+	// char returns the structural character at i, 0 past the end
+	char := func() byte {
+		if i >= len(idx) {
+			return 0
+		}
+		return data[idx[i]]
+	}
+
+value:
+	switch c := char(); {
+	case c == '{' || c == '[':
+		if len(p.stack) == backends.DefaultMaxDepth {
+			return p.errorAt(ErrDepth, i)
+		}
+		p.stack = append(p.stack, frame{open: len(d.Words), object: c == '{'})
+		d.Words = append(d.Words, uint64(c)<<56)
+		i++
+		switch {
+		case c == '{' && char() == '}', c == '[' && char() == ']':
+			goto end
+		case c == '{':
+			goto key
+		}
+		goto value
+	case c == '"':
+		if err := p.string(data, i); err != nil {
+			return err
+		}
+	case c == 0 || c == ',' || c == ':' || c == '}' || c == ']':
+		return p.errorAt(ErrTape, i)
+	default:
+		if err := p.scalar(data, i); err != nil {
+			return err
+		}
+	}
+	i++
+
+next:
+	// After a value: the end of the document, or a separator or end in
+	// the enclosing container
+	if len(p.stack) == 0 {
+		if i != len(idx) {
+			return p.errorAt(ErrTape, i)
+		}
+		d.Words[0] |= uint64(len(d.Words))
+		d.Words = append(d.Words, 'r'<<56)
+		return nil
+	}
+	p.stack[len(p.stack)-1].count++
+	switch c, top := char(), p.stack[len(p.stack)-1]; {
+	case c == ',' && top.object:
+		i++
+		goto key
+	case c == ',':
+		i++
+		goto value
+	case c == '}' && top.object, c == ']' && !top.object:
+		goto end
+	}
+	return p.errorAt(ErrTape, i)
+
+key:
+	if char() != '"' {
+		return p.errorAt(ErrTape, i)
+	}
+	if err := p.string(data, i); err != nil {
+		return err
+	}
+	i++
+	if char() != ':' {
+		return p.errorAt(ErrTape, i)
+	}
+	i++
+	goto value
+
+end:
+	// At the closing bracket of the top container
+	top := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	d.Close(top.open, char(), top.count)
+	i++
+	goto next
+}
+
+// copyPlain is simd.CopyPlain as a byte loop, for the generic
+// implementation
+func copyPlain(dst, src []byte) int {
+	for i, c := range src {
+		if c == '\\' || c == '"' || c < 0x20 {
+			return i
+		}
+		dst[i] = c
+	}
+	return len(src)
+}
+
+// string appends the string at structural character i to the string
+// buffer, unescaped, and its word to the tape. It ends before the next
+// structural character, so the plain copy stops within those bytes.
+func (p *Parser) string(data []byte, i int) error {
+	d := &p.doc
+	off := int(p.idx[i])
+	end := len(data)
+	if i+1 < len(p.idx) {
+		end = int(p.idx[i+1])
+	}
+	src := data[off+1 : end]
+	start := len(d.Strings)
+	d.Strings = slices.Grow(append(d.Strings, 0, 0, 0, 0), len(src)+1)
+	plain := simd.CopyPlain
+	if plain == nil {
+		plain = copyPlain
+	}
+	n := plain(d.Strings[start+4:start+4+len(src)], src)
+	if n < len(src) && src[n] == '"' {
+		d.Strings = d.Strings[:start+4+n]
+	} else {
+		// Escapes, or control characters that the decoder reports
+		s, err := backends.NewDecoder(data[off:end], backends.DecodeOptions{}).String()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrString, err)
+		}
+		d.Strings = append(d.Strings[:start+4], s...)
+	}
+	binary.LittleEndian.PutUint32(d.Strings[start:], uint32(len(d.Strings)-start-4))
+	d.Strings = append(d.Strings, 0)
+	d.Words = append(d.Words, '"'<<56|uint64(start))
+	return nil
+}
+
+// delimited reports whether the scalar at off, n bytes long, ends where
+// a value may end
+func delimited(data []byte, off, n int) bool {
+	if off+n == len(data) {
+		return true
+	}
+	switch data[off+n] {
+	case ' ', '\t', '\n', '\r', ',', ']', '}':
+		return true
+	}
+	return false
+}
+
+// scalar appends the number or literal at structural character i. Like
+// simdjson, integers are int64, or uint64 when too large, and everything
+// else is a double.
+func (p *Parser) scalar(data []byte, i int) error {
+	d := &p.doc
+	off := int(p.idx[i])
+	for _, lit := range [...]string{"true", "false", "null"} {
+		if data[off] == lit[0] {
+			if len(data)-off < len(lit) || string(data[off:off+len(lit)]) != lit || !delimited(data, off, len(lit)) {
+				return p.errorAt(ErrAtom, i)
+			}
+			d.Words = append(d.Words, uint64(lit[0])<<56)
+			return nil
+		}
+	}
+	num, n, err := number.Parse(data[off:])
+	if err != nil || !delimited(data, off, n) {
+		return p.errorAt(ErrNumber, i)
+	}
+	if num.Kind == number.Int {
+		d.Words = append(d.Words, 'l'<<56, uint64(num.Int))
+		return nil
+	}
+	if bytes.IndexAny(data[off:off+n], "-.eE") < 0 {
+		if u, err := strconv.ParseUint(string(data[off:off+n]), 10, 64); err == nil {
+			d.Words = append(d.Words, 'u'<<56, u)
+			return nil
+		}
+	}
+	d.Words = append(d.Words, 'd'<<56, math.Float64bits(num.Float))
+	return nil
+}
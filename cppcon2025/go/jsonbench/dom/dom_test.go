@@ -0,0 +1,232 @@
+package dom_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/dom"
+)
+
+// randomValue returns a value of up to depth levels, with keys and
+// strings that need escaping and numbers of every tape kind
+func randomValue(r *rand.Rand, depth int) interface{} {
+	switch k := r.Intn(8); {
+	case depth > 0 && k == 0:
+		a := make([]interface{}, r.Intn(5))
+		for i := range a {
+			a[i] = randomValue(r, depth-1)
+		}
+		return a
+	case depth > 0 && k <= 2:
+		o := map[string]interface{}{}
+		for n := r.Intn(6); n > 0; n-- {
+			o[randomString(r)] = randomValue(r, depth-1)
+		}
+		return o
+	case k == 3:
+		return float64(r.Int63n(1e6)) / []float64{1, -10, 1e3, 1e-20}[r.Intn(4)]
+	case k == 4:
+		return []interface{}{r.Int63(), -r.Int63(), r.Uint64()}[r.Intn(3)]
+	case k == 5:
+		return []interface{}{true, false, nil}[r.Intn(3)]
+	}
+	return randomString(r)
+}
+
+func randomString(r *rand.Rand) string {
+	var b strings.Builder
+	for n := r.Intn(12); n > 0; n-- {
+		b.WriteString([]string{"a", "id", `"`, `\`, "\t", "é", "😀", " "}[r.Intn(8)])
+	}
+	return b.String()
+}
+
+// mutate returns a copy of doc with one byte replaced, inserted or
+// deleted, or cut short
+func mutate(r *rand.Rand, doc []byte) []byte {
+	if len(doc) == 0 {
+		return doc
+	}
+	out := append([]byte(nil), doc...)
+	i := r.Intn(len(out))
+	c := `{}[]",:\ 0123456789.-+eEtrufalsn`[r.Intn(32)]
+	switch r.Intn(4) {
+	case 0:
+		out[i] = c
+	case 1:
+		out = append(out[:i], append([]byte{c}, out[i:]...)...)
+	case 2:
+		out = append(out[:i], out[i+1:]...)
+	default:
+		out = out[:i]
+	}
+	return out
+}
+
+// walk reads all of e into the values encoding/json decodes to, checking
+// the lengths of the containers on the way
+func walk(e dom.Element) (interface{}, error) {
+	switch e.Type() {
+	case dom.ObjectType:
+		o, _ := e.GetObject()
+		m := map[string]interface{}{}
+		n := 0
+		var err error
+		o.Each(func(key []byte, v dom.Element) bool {
+			n++
+			m[string(key)], err = walk(v)
+			return err == nil
+		})
+		if err == nil && n != o.Len() {
+			err = fmt.Errorf("Len() = %d, want %d", o.Len(), n)
+		}
+		return m, err
+	case dom.ArrayType:
+		arr, _ := e.GetArray()
+		a := []interface{}{}
+		var err error
+		arr.Each(func(v dom.Element) bool {
+			var x interface{}
+			x, err = walk(v)
+			a = append(a, x)
+			return err == nil
+		})
+		if err == nil && len(a) != arr.Len() {
+			err = fmt.Errorf("Len() = %d, want %d", arr.Len(), len(a))
+		}
+		return a, err
+	case dom.StringType:
+		return e.GetString()
+	case dom.Int64Type, dom.Uint64Type, dom.DoubleType:
+		return e.GetDouble()
+	case dom.BoolType:
+		return e.GetBool()
+	case dom.NullType:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("invalid type: %v", e.Err())
+}
+
+// outOfRange reports whether dom rejected valid JSON for a number
+// beyond the float64 range, as simdjson does; encoding/json finds it
+// valid but refuses to decode it
+func outOfRange(data []byte, err error) bool {
+	var v interface{}
+	return errors.Is(err, dom.ErrNumber) && json.Unmarshal(data, &v) != nil
+}
+
+// check parses data and compares it with encoding/json: the same
+// validity, invalid UTF-8 and outOfRange aside, and the same values
+func check(t *testing.T, p *dom.Parser, data []byte) {
+	t.Helper()
+	root, err := p.Parse(data)
+	if !utf8.Valid(data) {
+		if !errors.Is(err, dom.ErrUTF8) {
+			t.Fatalf("Parse(%q) error = %v, want ErrUTF8", data, err)
+		}
+		return
+	}
+	if valid := json.Valid(data); (err == nil) != valid && !outOfRange(data, err) {
+		t.Fatalf("Parse(%q) error = %v, encoding/json says valid = %v", data, err, valid)
+	}
+	var want interface{}
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("Parse(%q) succeeds, encoding/json: %v", data, err)
+	}
+	got, err := walk(root)
+	if err != nil {
+		t.Fatalf("walking %q: %v", data, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Parse(%q) = %v, want %v", data, got, want)
+	}
+}
+
+// TestParse parses random documents and 20000 mutations of them with
+// one parser, so every document reuses the buffers of the last
+func TestParse(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var p dom.Parser
+	for i := 0; i < 5000; i++ {
+		doc, err := json.MarshalIndent(randomValue(r, 4), "", []string{"", " ", "\t"}[r.Intn(3)])
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, &p, doc)
+		for j := 0; j < 4; j++ {
+			check(t, &p, mutate(r, doc))
+		}
+	}
+}
+
+// TestChain looks a value up through a chain of lookups, and checks that
+// an error anywhere in the chain comes out at its end
+func TestChain(t *testing.T) {
+	var p dom.Parser
+	root, err := p.Parse([]byte(`{"statuses":[{"user":{"id":18446744073709551615}}],"n":-1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := root.Field("statuses").At(0).Field("user").Field("id").GetUint64(); err != nil || id != 1<<64-1 {
+		t.Errorf("id = %d, %v, want %d", id, err, uint64(1<<64-1))
+	}
+	for _, c := range []struct {
+		e    dom.Element
+		want error
+	}{
+		{root.Field("missing").At(0).Field("id"), dom.ErrNoSuchField},
+		{root.Field("statuses").At(1).Field("user"), dom.ErrOutOfBounds},
+		{root.Field("n").Field("user"), dom.ErrIncorrectType},
+	} {
+		if _, err := c.e.GetUint64(); !errors.Is(err, c.want) {
+			t.Errorf("error = %v, want %v", err, c.want)
+		}
+	}
+	if _, err := root.Field("n").GetUint64(); !errors.Is(err, dom.ErrIncorrectType) {
+		t.Errorf("GetUint64 of -1: error = %v, want ErrIncorrectType", err)
+	}
+}
+
+// FuzzParse parses arbitrary bytes: the parser may not panic, and must
+// agree with encoding/json.Valid on every valid UTF-8 input but those
+// nested deeper than backends.DefaultMaxDepth and outOfRange numbers.
+//
+//	go test -fuzz=FuzzParse
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{
+		`{}`,
+		`[]`,
+		`null`,
+		`{"user":{"id":1,"screen_name":"simdjson","verified":true}}`,
+		`[1.5e300, -0, 18446744073709551616, "é😀"]`,
+		`[[[[[[[[[[]]]]]]]]]]`,
+		`{"a":`,
+		`"\x"`,
+		`{"a":1,"a":2}`,
+		`"\ud800"`,
+		`[tru]`,
+		`[1.]`,
+		`1e400`,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var p dom.Parser
+		_, err := p.Parse(data)
+		if !utf8.Valid(data) || errors.Is(err, dom.ErrDepth) || outOfRange(data, err) {
+			return
+		}
+		if want := json.Valid(data); (err == nil) != want {
+			t.Fatalf("Parse(%q) error = %v, encoding/json.Valid = %v", data, err, want)
+		}
+	})
+}
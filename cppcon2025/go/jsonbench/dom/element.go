@@ -0,0 +1,270 @@
+package dom
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+)
+
+// Type is the JSON type of an element
+type Type uint8
+
+const (
+	InvalidType Type = iota
+	ObjectType
+	ArrayType
+	StringType
+	Int64Type
+	Uint64Type
+	DoubleType
+	BoolType
+	NullType
+)
+
+func (t Type) String() string {
+	return [...]string{"invalid", "object", "array", "string", "int64", "uint64", "double", "boolean", "null"}[t]
+}
+
+// Element is a value on the tape of a document. As with simdjson_result,
+// an Element can carry the error of the lookup that led to it, so that
+// a chain of lookups needs one check, at its end:
+//
+//	n, err := root.Field("statuses").At(0).Field("user").Field("id").GetUint64()
+type Element struct {
+	doc *Document
+	i   int
+	err error
+}
+
+// Err returns the error of a lookup in the chain that led to e
+func (e Element) Err() error { return e.err }
+
+func (e Element) word() uint64 { return e.doc.Words[e.i] }
+
+func (e Element) kind() byte {
+	if e.err != nil || e.doc == nil {
+		return 0
+	}
+	return byte(e.word() >> 56)
+}
+
+// Type returns the type of e, InvalidType for an error
+func (e Element) Type() Type {
+	switch e.kind() {
+	case '{':
+		return ObjectType
+	case '[':
+		return ArrayType
+	case '"':
+		return StringType
+	case 'l':
+		return Int64Type
+	case 'u':
+		return Uint64Type
+	case 'd':
+		return DoubleType
+	case 't', 'f':
+		return BoolType
+	case 'n':
+		return NullType
+	}
+	return InvalidType
+}
+
+// typeError returns the error for e used as want
+func (e Element) typeError(want Type) error {
+	if e.err != nil {
+		return e.err
+	}
+	return fmt.Errorf("%w: %s, not %s", ErrIncorrectType, e.Type(), want)
+}
+
+// GetObject returns e as an object
+func (e Element) GetObject() (Object, error) {
+	if e.kind() != '{' {
+		return Object{}, e.typeError(ObjectType)
+	}
+	return Object{e.doc, e.i}, nil
+}
+
+// GetArray returns e as an array
+func (e Element) GetArray() (Array, error) {
+	if e.kind() != '[' {
+		return Array{}, e.typeError(ArrayType)
+	}
+	return Array{e.doc, e.i}, nil
+}
+
+// Field is GetObject().Field(key), keeping the error in the chain
+func (e Element) Field(key string) Element {
+	o, err := e.GetObject()
+	if err != nil {
+		return Element{err: err}
+	}
+	return o.Field(key)
+}
+
+// At is GetArray().At(i), keeping the error in the chain
+func (e Element) At(i int) Element {
+	a, err := e.GetArray()
+	if err != nil {
+		return Element{err: err}
+	}
+	return a.At(i)
+}
+
+// GetStringBytes returns the string e in place, valid as long as the
+// document
+func (e Element) GetStringBytes() ([]byte, error) {
+	if e.kind() != '"' {
+		return nil, e.typeError(StringType)
+	}
+	return e.doc.StringAt(e.i), nil
+}
+
+// GetString returns the string e
+func (e Element) GetString() (string, error) {
+	b, err := e.GetStringBytes()
+	return string(b), err
+}
+
+// GetInt64 returns the number e as an int64, if it is one exactly
+func (e Element) GetInt64() (int64, error) {
+	switch e.kind() {
+	case 'l':
+		return int64(e.doc.Words[e.i+1]), nil
+	case 'u':
+		if v := e.doc.Words[e.i+1]; v <= math.MaxInt64 {
+			return int64(v), nil
+		}
+	}
+	return 0, e.typeError(Int64Type)
+}
+
+// GetUint64 returns the number e as a uint64, if it is one exactly
+func (e Element) GetUint64() (uint64, error) {
+	switch e.kind() {
+	case 'u':
+		return e.doc.Words[e.i+1], nil
+	case 'l':
+		if v := int64(e.doc.Words[e.i+1]); v >= 0 {
+			return uint64(v), nil
+		}
+	}
+	return 0, e.typeError(Uint64Type)
+}
+
+// GetDouble returns the number e as a float64, integers included
+func (e Element) GetDouble() (float64, error) {
+	switch e.kind() {
+	case 'l':
+		return float64(int64(e.doc.Words[e.i+1])), nil
+	case 'u':
+		return float64(e.doc.Words[e.i+1]), nil
+	case 'd':
+		return math.Float64frombits(e.doc.Words[e.i+1]), nil
+	}
+	return 0, e.typeError(DoubleType)
+}
+
+// GetBool returns the boolean e
+func (e Element) GetBool() (bool, error) {
+	switch e.kind() {
+	case 't':
+		return true, nil
+	case 'f':
+		return false, nil
+	}
+	return false, e.typeError(BoolType)
+}
+
+// IsNull reports whether e is null
+func (e Element) IsNull() bool { return e.kind() == 'n' }
+
+// count returns the number of fields or elements of the container at i,
+// counting them when the count on the tape has saturated
+func count(d *Document, i int, end byte) int {
+	if n := d.Count(i); n < backends.TapeMaxCount {
+		return n
+	}
+	n := 0
+	for j := i + 1; byte(d.Words[j]>>56) != end; j = d.Next(j) {
+		n++
+		if end == '}' {
+			j++
+		}
+	}
+	return n
+}
+
+// Object is an object on the tape
+type Object struct {
+	doc *Document
+	i   int
+}
+
+// Len returns the number of fields of o
+func (o Object) Len() int { return count(o.doc, o.i, '}') }
+
+// Field returns the value of the first field key, comparing the keys
+// one by one as simdjson does; keys that repeat keep every value
+func (o Object) Field(key string) Element {
+	for j := o.i + 1; byte(o.doc.Words[j]>>56) != '}'; {
+		k, _ := Element{doc: o.doc, i: j}.GetStringBytes()
+		v := Element{doc: o.doc, i: j + 1}
+		if string(k) == key {
+			return v
+		}
+		j = o.doc.Next(j + 1)
+	}
+	return Element{err: fmt.Errorf("%w %q", ErrNoSuchField, key)}
+}
+
+// Each calls fn with every key and value of o, in document order, until
+// it returns false. The key is valid as long as the document.
+func (o Object) Each(fn func(key []byte, v Element) bool) {
+	for j := o.i + 1; byte(o.doc.Words[j]>>56) != '}'; {
+		k, _ := Element{doc: o.doc, i: j}.GetStringBytes()
+		v := Element{doc: o.doc, i: j + 1}
+		if !fn(k, v) {
+			return
+		}
+		j = o.doc.Next(j + 1)
+	}
+}
+
+// Array is an array on the tape
+type Array struct {
+	doc *Document
+	i   int
+}
+
+// Len returns the number of elements of a
+func (a Array) Len() int { return count(a.doc, a.i, ']') }
+
+// At returns element i of a, skipping the elements before it one jump
+// each
+func (a Array) At(i int) Element {
+	n := 0
+	for j := a.i + 1; byte(a.doc.Words[j]>>56) != ']'; n++ {
+		v := Element{doc: a.doc, i: j}
+		if n == i {
+			return v
+		}
+		j = a.doc.Next(j)
+	}
+	return Element{err: fmt.Errorf("%w: %d of %d", ErrOutOfBounds, i, n)}
+}
+
+// Each calls fn with every element of a, in order, until it returns
+// false
+func (a Array) Each(fn func(v Element) bool) {
+	for j := a.i + 1; byte(a.doc.Words[j]>>56) != ']'; {
+		v := Element{doc: a.doc, i: j}
+		if !fn(v) {
+			return
+		}
+		j = a.doc.Next(j)
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench
+
+go 1.22
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/gorilla/websocket v1.5.3
+	go.mongodb.org/mongo-driver/v2 v2.8.2
+	golang.org/x/sys v0.30.0
+	google.golang.org/protobuf v1.36.6
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,107 @@
+package number
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// The powers of ten of the Eisel–Lemire table, which covers every
+// float64 with a significand of up to 19 digits
+const (
+	minExp10 = -348
+	maxExp10 = 347
+)
+
+// powers holds the 128-bit significand of each power of ten, high word
+// first, normalized so that its top bit is set and rounded down. It is
+// computed at startup rather than written out, in about half a
+// millisecond of math/big.
+var powers = func() (t [maxExp10 - minExp10 + 1][2]uint64) {
+	ten := big.NewInt(10)
+	for q := minExp10; q <= maxExp10; q++ {
+		var v big.Int
+		if q >= 0 {
+			v.Exp(ten, big.NewInt(int64(q)), nil)
+			if n := v.BitLen(); n > 128 {
+				v.Rsh(&v, uint(n-128))
+			} else {
+				v.Lsh(&v, uint(128-n))
+			}
+		} else {
+			// 2^k / 10^-q with k chosen for a quotient of 128 bits
+			var d big.Int
+			d.Exp(ten, big.NewInt(int64(-q)), nil)
+			v.Lsh(big.NewInt(1), uint(d.BitLen()+127))
+			v.Quo(&v, &d)
+		}
+		var lo big.Int
+		lo.And(&v, new(big.Int).SetUint64(math.MaxUint64))
+		t[q-minExp10] = [2]uint64{v.Rsh(&v, 64).Uint64(), lo.Uint64()}
+	}
+	return t
+}()
+
+// eiselLemire returns the float64 nearest to man × 10^exp10, or false in
+// the rare cases where the 128-bit product cannot tell which way to
+// round, and for subnormals, infinities and exponents beyond the table.
+// It is the algorithm of Daniel Lemire's "Number Parsing at a Gigabyte
+// per Second", also behind strconv.ParseFloat and simdjson.
+func eiselLemire(man uint64, exp10 int, neg bool) (float64, bool) {
+	if man == 0 {
+		if neg {
+			return math.Copysign(0, -1), true
+		}
+		return 0, true
+	}
+	if exp10 < minExp10 || exp10 > maxExp10 {
+		return 0, false
+	}
+
+	// Normalize the significand, and estimate the binary exponent with
+	// log2(10) ≈ 217706 / 2^16
+	clz := bits.LeadingZeros64(man)
+	man <<= uint(clz)
+	exp2 := uint64(217706*exp10>>16+64+1023) - uint64(clz)
+
+	// The high 64 bits of the power are enough unless the product's low
+	// bits are all ones, when a carry from the low 64 could change it
+	pow := powers[exp10-minExp10]
+	hi, lo := bits.Mul64(man, pow[0])
+	if hi&0x1FF == 0x1FF && lo+man < man {
+		yHi, yLo := bits.Mul64(man, pow[1])
+		mergedHi, mergedLo := hi, lo+yHi
+		if mergedLo < lo {
+			mergedHi++
+		}
+		if mergedHi&0x1FF == 0x1FF && mergedLo+1 == 0 && yLo+man < man {
+			return 0, false
+		}
+		hi, lo = mergedHi, mergedLo
+	}
+
+	// Keep 54 bits, one more than the significand, to round with
+	msb := hi >> 63
+	mantissa := hi >> (msb + 9)
+	exp2 -= 1 ^ msb
+
+	// Exactly halfway between two floats: strconv breaks the tie
+	if lo == 0 && hi&0x1FF == 0 && mantissa&3 == 1 {
+		return 0, false
+	}
+	mantissa += mantissa & 1
+	mantissa >>= 1
+	if mantissa>>53 > 0 {
+		mantissa >>= 1
+		exp2++
+	}
+	// Zero, or an underflow, is a subnormal; 0x7FF and above is infinite
+	if exp2-1 >= 0x7FF-1 {
+		return 0, false
+	}
+	b := exp2<<52 | mantissa&(1<<52-1)
+	if neg {
+		b |= 1 << 63
+	}
+	return math.Float64frombits(b), true
+}
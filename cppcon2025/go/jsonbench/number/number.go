@@ -0,0 +1,218 @@
+// Package number parses JSON numbers the way simdjson does. Digits are
+// converted 8 at a time with SWAR arithmetic (SIMD within a register)
+// into a 64-bit decimal significand and an exponent; the common cases
+// then take an exact floating-point multiplication (Clinger's fast path)
+// or the Eisel–Lemire algorithm, one 128-bit multiplication by a table
+// entry, and only the rare rest goes to strconv.
+package number
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+)
+
+var (
+	errSyntax   = errors.New("invalid number")
+	errFraction = errors.New("expected digit after decimal point")
+	errExponent = errors.New("expected digit in exponent")
+	errRange    = errors.New("number out of float64 range")
+)
+
+// Kind tells which field of a Number holds its value
+type Kind uint8
+
+const (
+	Float Kind = iota
+	Int
+)
+
+// Number is a parsed JSON number: integers that fit an int64 stay exact,
+// everything else is a float64
+type Number struct {
+	Kind  Kind
+	Int   int64
+	Float float64
+}
+
+// literal is the decimal form of a number: an integer significand of at
+// most 19 digits (more are flagged as long) and a power of ten
+type literal struct {
+	neg   bool
+	man   uint64
+	exp10 int
+	// isInt is set without a fraction or an exponent
+	isInt bool
+	// long is set when the significand has more than 19 digits, which
+	// may overflow man
+	long bool
+}
+
+// eightDigits reports whether the 8 bytes of chunk, loaded as a little
+// endian word, are all ASCII digits
+func eightDigits(chunk uint64) bool {
+	return chunk&0xF0F0F0F0F0F0F0F0 == 0x3030303030303030 &&
+		(chunk+0x0606060606060606)&0xF0F0F0F0F0F0F0F0 == 0x3030303030303030
+}
+
+// parseEightDigits converts 8 ASCII digits, the first one in the low
+// byte, by combining neighbouring digits, then pairs, then quads, in
+// three multiplications instead of eight
+func parseEightDigits(chunk uint64) uint64 {
+	chunk -= 0x3030303030303030
+	chunk = (chunk*10 + chunk>>8) & 0x00FF00FF00FF00FF
+	chunk = (chunk*100 + chunk>>16) & 0x0000FFFF0000FFFF
+	return (chunk*10000 + chunk>>32) & 0xFFFFFFFF
+}
+
+// digits accumulates the digits of data from i into man, 8 at a time
+// while it can, and returns where they end; man wraps around past 19
+// digits, which the caller checks
+func digits(data []byte, i int, man uint64) (uint64, int) {
+	for i+8 <= len(data) {
+		chunk := binary.LittleEndian.Uint64(data[i:])
+		if !eightDigits(chunk) {
+			break
+		}
+		man = man*100000000 + parseEightDigits(chunk)
+		i += 8
+	}
+	for i < len(data) && data[i]-'0' <= 9 {
+		man = man*10 + uint64(data[i]-'0')
+		i++
+	}
+	return man, i
+}
+
+// scan reads the number at the start of data into l and returns its
+// length
+func scan(data []byte, l *literal) (int, error) {
+	i := 0
+	if i < len(data) && data[i] == '-' {
+		l.neg = true
+		i++
+	}
+	start := i
+	switch {
+	case i < len(data) && data[i] == '0':
+		i++
+	case i < len(data) && data[i]-'1' <= 8:
+		l.man, i = digits(data, i, 0)
+	default:
+		return i, errSyntax
+	}
+	count := i - start
+	l.isInt = true
+	if i < len(data) && data[i] == '.' {
+		i++
+		frac := i
+		l.man, i = digits(data, i, l.man)
+		if i == frac {
+			return i, errFraction
+		}
+		l.exp10 = frac - i
+		count += i - frac
+		l.isInt = false
+	}
+	if count > 19 {
+		// Leading zeros, as in 0.000123, are not significant
+		for j := start; j < i && (data[j] == '0' || data[j] == '.'); j++ {
+			if data[j] == '0' {
+				count--
+			}
+		}
+		l.long = count > 19
+	}
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		negExp := false
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			negExp = data[i] == '-'
+			i++
+		}
+		if i >= len(data) || data[i]-'0' > 9 {
+			return i, errExponent
+		}
+		exp := 0
+		for ; i < len(data) && data[i]-'0' <= 9; i++ {
+			// Beyond any float64, but not so far as to overflow
+			if exp < 100000 {
+				exp = exp*10 + int(data[i]-'0')
+			}
+		}
+		if negExp {
+			exp = -exp
+		}
+		l.exp10 += exp
+		l.isInt = false
+	}
+	return i, nil
+}
+
+// exactPowers are the powers of ten a float64 holds exactly
+var exactPowers = [...]float64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11,
+	1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22,
+}
+
+// float converts l, the literal data[:n], to the nearest float64
+func (l *literal) float(data []byte) (float64, error) {
+	if !l.long {
+		// Both the significand and the power are exact, so one rounding
+		// of their product or quotient is correct
+		if l.man <= 1<<53 && -22 <= l.exp10 && l.exp10 <= 22 {
+			f := float64(l.man)
+			if l.exp10 < 0 {
+				f /= exactPowers[-l.exp10]
+			} else {
+				f *= exactPowers[l.exp10]
+			}
+			if l.neg {
+				f = -f
+			}
+			return f, nil
+		}
+		if f, ok := eiselLemire(l.man, l.exp10, l.neg); ok {
+			return f, nil
+		}
+	}
+	f, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return 0, errRange
+	}
+	return f, nil
+}
+
+// ParseFloat parses the JSON number at the start of data into the
+// nearest float64, as encoding/json does for an interface{}, and returns
+// its length
+func ParseFloat(data []byte) (float64, int, error) {
+	var l literal
+	n, err := scan(data, &l)
+	if err != nil {
+		return 0, n, err
+	}
+	f, err := l.float(data[:n])
+	return f, n, err
+}
+
+// Parse parses the JSON number at the start of data, keeping integers
+// that fit an int64 exact, and returns its length
+func Parse(data []byte) (Number, int, error) {
+	var l literal
+	n, err := scan(data, &l)
+	if err != nil {
+		return Number{}, n, err
+	}
+	if l.isInt && !l.long {
+		switch {
+		case l.man <= math.MaxInt64 && !l.neg:
+			return Number{Kind: Int, Int: int64(l.man)}, n, nil
+		case l.man <= 1<<63 && l.neg:
+			return Number{Kind: Int, Int: -int64(l.man)}, n, nil
+		}
+	}
+	f, err := l.float(data[:n])
+	return Number{Kind: Float, Float: f}, n, err
+}
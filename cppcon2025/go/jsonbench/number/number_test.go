@@ -0,0 +1,107 @@
+package number
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// randomLiteral returns a JSON number of one of the shapes the parser
+// tells apart: a float64 printed shortest or with extra digits, an
+// integer around the int64 limits, or random digits with a long
+// significand, leading zeros and far exponents
+func randomLiteral(r *rand.Rand) string {
+	switch r.Intn(4) {
+	case 0:
+		f := math.Float64frombits(r.Uint64())
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			f = 0
+		}
+		return strconv.FormatFloat(f, "eEfg"[r.Intn(4)], r.Intn(25)-1, 64)
+	case 1:
+		n := r.Int63() >> r.Intn(63)
+		if r.Intn(2) == 0 {
+			return strconv.FormatInt(-n, 10)
+		}
+		return strconv.FormatUint(uint64(n)+uint64(r.Intn(3))<<63, 10)
+	}
+	var b strings.Builder
+	if r.Intn(2) == 0 {
+		b.WriteByte('-')
+	}
+	b.WriteString("0123456789"[1+r.Intn(9):][:1])
+	for n := r.Intn(30); n > 0; n-- {
+		b.WriteByte('0' + byte(r.Intn(10)))
+	}
+	if r.Intn(2) == 0 {
+		b.WriteString("." + strings.Repeat("0", r.Intn(20)))
+		for n := 1 + r.Intn(25); n > 0; n-- {
+			b.WriteByte('0' + byte(r.Intn(10)))
+		}
+	}
+	if r.Intn(2) == 0 {
+		b.WriteString("eE"[r.Intn(2):][:1] + []string{"", "+", "-"}[r.Intn(3)] + strconv.Itoa(r.Intn(400)))
+	}
+	return b.String()
+}
+
+// TestParseFloat compares ParseFloat with strconv bit for bit, and the
+// length it reads past the number
+func TestParseFloat(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	n := 500000
+	if testing.Short() {
+		n = 20000
+	}
+	for i := 0; i < n; i++ {
+		lit := randomLiteral(r)
+		want, werr := strconv.ParseFloat(lit, 64)
+		got, length, err := ParseFloat([]byte(lit + ",1"))
+		if (err != nil) != (werr != nil) {
+			t.Fatalf("ParseFloat(%q): error %v, strconv %v", lit, err, werr)
+		}
+		if err != nil {
+			continue
+		}
+		if math.Float64bits(got) != math.Float64bits(want) || length != len(lit) {
+			t.Fatalf("ParseFloat(%q) = %v, %d, want %v, %d", lit, got, length, want, len(lit))
+		}
+	}
+}
+
+// TestParse checks that integers in the int64 range stay exact and the
+// rest become the float64 of ParseFloat
+func TestParse(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 100000; i++ {
+		lit := randomLiteral(r)
+		got, _, err := Parse([]byte(lit))
+		if err != nil {
+			continue
+		}
+		if want, err := strconv.ParseInt(lit, 10, 64); err == nil {
+			if got.Kind != Int || got.Int != want {
+				t.Fatalf("Parse(%q) = %+v, want the int %d", lit, got, want)
+			}
+			continue
+		}
+		want, _, _ := ParseFloat([]byte(lit))
+		if got.Kind != Float || math.Float64bits(got.Float) != math.Float64bits(want) {
+			t.Fatalf("Parse(%q) = %+v, want the float %v", lit, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, lit := range []string{"", "-", "+1", ".5", "1.", "1.e5", "1e", "1e+", "-x", "e5", "1e999", "-1e400"} {
+		if _, _, err := ParseFloat([]byte(lit)); err == nil {
+			t.Errorf("ParseFloat(%q) succeeds", lit)
+		}
+	}
+	// A leading zero ends the number, which the caller then rejects
+	if _, n, err := ParseFloat([]byte("012")); err != nil || n != 1 {
+		t.Errorf("ParseFloat(\"012\") reads %d bytes, error %v; want 1 byte", n, err)
+	}
+}
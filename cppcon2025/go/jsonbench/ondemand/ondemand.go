@@ -0,0 +1,175 @@
+// Package ondemand is simdjson's On-Demand API in Go, on top of the
+// structural index of package scanner. Iterate indexes a document in one
+// pass; the values are then parsed only when they are asked for, in
+// document order, by a single iterator moving forward over the index:
+//
+//	doc, err := parser.Iterate(data)
+//	statuses := doc.GetObject().Field("statuses").GetArray()
+//	for statuses.Next() {
+//		user := statuses.Value().GetObject().Field("user").GetObject()
+//		id, err := user.Field("id").GetUint64()
+//		...
+//	}
+//	err = statuses.Err()
+//
+// As with simdjson_result, each step carries the error of the ones
+// before it, so a chain needs one check, at its end. An Object, an Array
+// or a Value is only valid while the iterator has not moved past it;
+// using one afterwards returns ErrOutOfOrder. Like simdjson, the parser
+// validates UTF-8 and the structure it walks, but not the values it
+// skips.
+package ondemand
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+)
+
+// The errors of simdjson that an On-Demand caller can see
+var (
+	ErrEmpty         = errors.New("ondemand: empty document")
+	ErrUTF8          = errors.New("ondemand: invalid UTF-8")
+	ErrStructure     = errors.New("ondemand: invalid JSON structure")
+	ErrIncorrectType = errors.New("ondemand: incorrect type")
+	ErrNoSuchField   = errors.New("ondemand: no such field")
+	ErrOutOfOrder    = errors.New("ondemand: value used out of order")
+	ErrNumber        = errors.New("ondemand: invalid number")
+)
+
+// iterator is the one cursor of a document over its structural index
+type iterator struct {
+	data []byte
+	idx  []uint32
+	// pos is the index of the next structural character
+	pos int
+	// open holds the ids of the containers entered and not yet left,
+	// innermost last; lastID is the id of the most recent
+	open   []uint32
+	lastID uint32
+}
+
+// peek returns the structural character at pos, or 0 past the end
+func (it *iterator) peek() byte {
+	if it.pos >= len(it.idx) {
+		return 0
+	}
+	return it.data[it.idx[it.pos]]
+}
+
+// errorf returns err at the offset of the structural character at pos
+func (it *iterator) errorf(err error) error {
+	if it.pos >= len(it.idx) {
+		return fmt.Errorf("%w at the end of the document", err)
+	}
+	return fmt.Errorf("%w at offset %d", err, it.idx[it.pos])
+}
+
+// enter moves past the opening bracket at pos and returns the id of the
+// container
+func (it *iterator) enter() uint32 {
+	it.pos++
+	it.lastID++
+	it.open = append(it.open, it.lastID)
+	return it.lastID
+}
+
+// live reports whether the container id entered at depth is still open
+func (it *iterator) live(id uint32, depth int) bool {
+	return len(it.open) >= depth && it.open[depth-1] == id
+}
+
+// leave moves past the structural characters of the containers open
+// below depth, which a caller entered but did not finish
+func (it *iterator) leave(depth int) error {
+	for len(it.open) > depth {
+		switch it.peek() {
+		case 0:
+			return it.errorf(ErrStructure)
+		case '{', '[':
+			it.lastID++
+			it.open = append(it.open, it.lastID)
+		case '}', ']':
+			it.open = it.open[:len(it.open)-1]
+		}
+		it.pos++
+	}
+	return nil
+}
+
+// skip moves past the value at pos
+func (it *iterator) skip() error {
+	switch it.peek() {
+	case 0, ',', ':', '}', ']':
+		return it.errorf(ErrStructure)
+	case '{', '[':
+		depth := 0
+		for {
+			switch it.peek() {
+			case 0:
+				return it.errorf(ErrStructure)
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			it.pos++
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+	it.pos++
+	return nil
+}
+
+// Parser holds the buffers of the documents it iterates, as simdjson's
+// parser does; the zero value is ready to use
+type Parser struct {
+	doc Document
+}
+
+// Iterate indexes data and returns the document positioned at its root
+// value. The document and its values are valid until the next call, and
+// data must not change meanwhile.
+func (p *Parser) Iterate(data []byte) (*Document, error) {
+	if !scanner.ValidUTF8(data) {
+		return nil, ErrUTF8
+	}
+	idx, err := scanner.Index(data, p.doc.it.idx[:0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStructure, err)
+	}
+	if len(idx) == 0 {
+		return nil, ErrEmpty
+	}
+	p.doc = Document{it: iterator{data: data, idx: idx, open: p.doc.it.open[:0]}}
+	p.doc.Value = Value{it: &p.doc.it}
+	return &p.doc, nil
+}
+
+// Document is the root of an iterated document. It is its root Value,
+// and, like simdjson's document, looks up the fields of a root object
+// directly.
+type Document struct {
+	Value
+	it      iterator
+	root    *Object
+	started bool
+}
+
+// Field is GetObject().Field(name), with the root object kept between
+// calls, as doc["statuses"] does in simdjson
+func (d *Document) Field(name string) Value {
+	if !d.started {
+		d.root, d.started = d.GetObject(), true
+	}
+	return d.root.Field(name)
+}
+
+// AtEnd finishes the containers the caller entered and reports whether
+// nothing follows the root value, as at_end does in simdjson
+func (d *Document) AtEnd() bool {
+	return d.it.leave(0) == nil && d.it.pos == len(d.it.idx)
+}
@@ -0,0 +1,180 @@
+package ondemand_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/ondemand"
+)
+
+// randomValue returns a value of up to depth levels, with keys and
+// strings that need escaping
+func randomValue(r *rand.Rand, depth int) interface{} {
+	switch k := r.Intn(7); {
+	case depth > 0 && k == 0:
+		a := make([]interface{}, r.Intn(5))
+		for i := range a {
+			a[i] = randomValue(r, depth-1)
+		}
+		return a
+	case depth > 0 && k <= 2:
+		o := map[string]interface{}{}
+		for n := r.Intn(6); n > 0; n-- {
+			o[randomString(r)] = randomValue(r, depth-1)
+		}
+		return o
+	case k == 3:
+		return float64(r.Int63n(1e6)) / []float64{1, 10, 1e3, 1e-20}[r.Intn(4)]
+	case k == 4:
+		return []interface{}{true, false, nil}[r.Intn(3)]
+	}
+	return randomString(r)
+}
+
+func randomString(r *rand.Rand) string {
+	var b strings.Builder
+	for n := r.Intn(12); n > 0; n-- {
+		b.WriteString([]string{"a", "id", `"`, `\`, "\t", "é", "😀", " "}[r.Intn(8)])
+	}
+	return b.String()
+}
+
+// walk reads all of v into the values encoding/json decodes to
+func walk(v ondemand.Value) (interface{}, error) {
+	t, err := v.Type()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case ondemand.ObjectType:
+		m := map[string]interface{}{}
+		o := v.GetObject()
+		for o.Next() {
+			// Keys are as written; unescape them like the strings
+			var key string
+			if err := json.Unmarshal([]byte(`"`+o.Key()+`"`), &key); err != nil {
+				return nil, err
+			}
+			if m[key], err = walk(o.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return m, o.Err()
+	case ondemand.ArrayType:
+		a := []interface{}{}
+		arr := v.GetArray()
+		for arr.Next() {
+			e, err := walk(arr.Value())
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, e)
+		}
+		return a, arr.Err()
+	case ondemand.StringType:
+		return v.GetString()
+	case ondemand.NumberType:
+		return v.GetDouble()
+	case ondemand.BoolType:
+		return v.GetBool()
+	case ondemand.NullType:
+		if null, err := v.IsNull(); err != nil || !null {
+			return nil, fmt.Errorf("not null: %v", err)
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("invalid type %v", t)
+}
+
+// iterate walks the whole document data
+func iterate(p *ondemand.Parser, data []byte) (interface{}, error) {
+	doc, err := p.Iterate(data)
+	if err != nil {
+		return nil, err
+	}
+	v, err := walk(doc.Value)
+	if err == nil && !doc.AtEnd() {
+		err = fmt.Errorf("data after the root value")
+	}
+	return v, err
+}
+
+// TestWalk reads random documents, and their truncations, in full and
+// compares them with encoding/json
+func TestWalk(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var p ondemand.Parser
+	for i := 0; i < 5000; i++ {
+		doc, err := json.MarshalIndent(randomValue(r, 4), "", []string{"", " ", "\t"}[r.Intn(3)])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, data := range [][]byte{doc, doc[:r.Intn(len(doc)+1)]} {
+			var want interface{}
+			werr := json.Unmarshal(data, &want)
+			got, err := iterate(&p, data)
+			if (err == nil) != (werr == nil) {
+				t.Fatalf("%q: error %v, encoding/json %v", data, err, werr)
+			}
+			if err == nil && !reflect.DeepEqual(got, want) {
+				t.Fatalf("%q: %v, want %v", data, got, want)
+			}
+		}
+	}
+}
+
+// TestField looks the fields of root objects up in random order, each
+// lookup wrapping around past the end when the key came before
+func TestField(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	var p ondemand.Parser
+	for i := 0; i < 2000; i++ {
+		obj := map[string]interface{}{}
+		for n := r.Intn(8); n > 0; n-- {
+			obj[fmt.Sprintf("k%d", r.Intn(10))] = randomValue(r, 2)
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want map[string]interface{}
+		json.Unmarshal(data, &want)
+		doc, err := p.Iterate(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range r.Perm(10) {
+			key := fmt.Sprintf("k%d", k)
+			got, err := walk(doc.Field(key))
+			w, ok := want[key]
+			switch {
+			case !ok && err == nil:
+				t.Fatalf("%s: Field(%q) = %v, want no such field", data, key, got)
+			case ok && err != nil:
+				t.Fatalf("%s: Field(%q): %v", data, key, err)
+			case ok && !reflect.DeepEqual(got, w):
+				t.Fatalf("%s: Field(%q) = %v, want %v", data, key, got, w)
+			}
+		}
+	}
+}
+
+func TestOutOfOrder(t *testing.T) {
+	var p ondemand.Parser
+	doc, err := p.Iterate([]byte(`{"a":[1,2],"b":3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := doc.Field("a")
+	if _, err := doc.Field("b").GetUint64(); err != nil {
+		t.Fatal(err)
+	}
+	if arr := a.GetArray(); arr.Next() || !errors.Is(arr.Err(), ondemand.ErrOutOfOrder) {
+		t.Errorf("reading a value the iterator moved past: error %v, want ErrOutOfOrder", arr.Err())
+	}
+}
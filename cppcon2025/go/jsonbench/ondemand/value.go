@@ -0,0 +1,476 @@
+package ondemand
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/number"
+)
+
+// Type is the JSON type of a value, as told by its first byte
+type Type uint8
+
+const (
+	InvalidType Type = iota
+	ObjectType
+	ArrayType
+	StringType
+	NumberType
+	BoolType
+	NullType
+)
+
+func (t Type) String() string {
+	return [...]string{"invalid", "object", "array", "string", "number", "boolean", "null"}[t]
+}
+
+// Value is a value not parsed yet. Its getters parse it and move the
+// iterator past it; only one of them can be called.
+type Value struct {
+	it  *iterator
+	pos int
+	err error
+}
+
+// start returns the offset of v once it checks that the iterator is at v
+func (v Value) start() (uint32, error) {
+	switch {
+	case v.err != nil:
+		return 0, v.err
+	case v.it.pos != v.pos:
+		return 0, ErrOutOfOrder
+	case v.pos >= len(v.it.idx):
+		// A document cut after a colon
+		return 0, v.it.errorf(ErrStructure)
+	}
+	return v.it.idx[v.pos], nil
+}
+
+// Err returns the error of a step in the chain that led to v
+func (v Value) Err() error { return v.err }
+
+// Type returns the type of v without consuming it
+func (v Value) Type() (Type, error) {
+	off, err := v.start()
+	if err != nil {
+		return InvalidType, err
+	}
+	switch c := v.it.data[off]; {
+	case c == '{':
+		return ObjectType, nil
+	case c == '[':
+		return ArrayType, nil
+	case c == '"':
+		return StringType, nil
+	case c == '-' || c-'0' <= 9:
+		return NumberType, nil
+	case c == 't' || c == 'f':
+		return BoolType, nil
+	case c == 'n':
+		return NullType, nil
+	}
+	return InvalidType, v.it.errorf(ErrStructure)
+}
+
+// GetObject enters the object v
+func (v Value) GetObject() *Object {
+	off, err := v.start()
+	if err != nil {
+		return &Object{err: err}
+	}
+	if v.it.data[off] != '{' {
+		return &Object{err: v.it.errorf(ErrIncorrectType)}
+	}
+	id := v.it.enter()
+	return &Object{it: v.it, id: id, depth: len(v.it.open), first: v.it.pos, value: -1}
+}
+
+// GetArray enters the array v
+func (v Value) GetArray() *Array {
+	off, err := v.start()
+	if err != nil {
+		return &Array{err: err}
+	}
+	if v.it.data[off] != '[' {
+		return &Array{err: v.it.errorf(ErrIncorrectType)}
+	}
+	id := v.it.enter()
+	return &Array{it: v.it, id: id, depth: len(v.it.open), value: -1}
+}
+
+// delimited reports whether the scalar at off, n bytes long, ends where
+// a value may end
+func (v Value) delimited(off uint32, n int) bool {
+	end := int(off) + n
+	if end == len(v.it.data) {
+		return true
+	}
+	switch v.it.data[end] {
+	case ' ', '\t', '\n', '\r', ',', ']', '}':
+		return true
+	}
+	return false
+}
+
+// GetString returns the string v, unescaped
+func (v Value) GetString() (string, error) {
+	off, err := v.start()
+	if err != nil {
+		return "", err
+	}
+	data := v.it.data
+	if data[off] != '"' {
+		return "", v.it.errorf(ErrIncorrectType)
+	}
+	// The closing quote is the first one not escaped; without a
+	// backslash before it the string is the bytes between
+	rest := data[off+1:]
+	end := bytes.IndexByte(rest, '"')
+	if end >= 0 && bytes.IndexByte(rest[:end], '\\') < 0 {
+		v.it.pos++
+		return string(rest[:end]), nil
+	}
+	s, err := backends.NewDecoder(data[off:], backends.DecodeOptions{}).String()
+	if err != nil {
+		return "", fmt.Errorf("ondemand: %w", err)
+	}
+	v.it.pos++
+	return s, nil
+}
+
+// GetUint64 returns the non-negative integer v
+func (v Value) GetUint64() (uint64, error) {
+	off, err := v.start()
+	if err != nil {
+		return 0, err
+	}
+	data := v.it.data[off:]
+	if len(data) == 0 || data[0]-'0' > 9 {
+		return 0, v.it.errorf(ErrIncorrectType)
+	}
+	var n uint64
+	i := 0
+	for ; i < len(data) && data[i]-'0' <= 9; i++ {
+		digit := uint64(data[i] - '0')
+		if n > (math.MaxUint64-digit)/10 || (i == 1 && data[0] == '0') {
+			return 0, v.it.errorf(ErrNumber)
+		}
+		n = n*10 + digit
+	}
+	if !v.delimited(off, i) {
+		return 0, v.it.errorf(ErrIncorrectType)
+	}
+	v.it.pos++
+	return n, nil
+}
+
+// number parses the number v
+func (v Value) number() (number.Number, error) {
+	off, err := v.start()
+	if err != nil {
+		return number.Number{}, err
+	}
+	if c := v.it.data[off]; c != '-' && c-'0' > 9 {
+		return number.Number{}, v.it.errorf(ErrIncorrectType)
+	}
+	num, n, err := number.Parse(v.it.data[off:])
+	if err != nil || !v.delimited(off, n) {
+		return number.Number{}, v.it.errorf(ErrNumber)
+	}
+	v.it.pos++
+	return num, nil
+}
+
+// GetInt64 returns the integer v
+func (v Value) GetInt64() (int64, error) {
+	num, err := v.number()
+	if err != nil {
+		return 0, err
+	}
+	if num.Kind != number.Int {
+		return 0, fmt.Errorf("%w: %g is not an int64", ErrIncorrectType, num.Float)
+	}
+	return num.Int, nil
+}
+
+// GetDouble returns the number v as a float64, integers included
+func (v Value) GetDouble() (float64, error) {
+	num, err := v.number()
+	if err != nil {
+		return 0, err
+	}
+	if num.Kind == number.Int {
+		return float64(num.Int), nil
+	}
+	return num.Float, nil
+}
+
+// literal consumes v if it is lit
+func (v Value) literal(lit string) (bool, error) {
+	off, err := v.start()
+	if err != nil {
+		return false, err
+	}
+	if !bytes.HasPrefix(v.it.data[off:], []byte(lit)) || !v.delimited(off, len(lit)) {
+		return false, nil
+	}
+	v.it.pos++
+	return true, nil
+}
+
+// GetBool returns the boolean v
+func (v Value) GetBool() (bool, error) {
+	if ok, err := v.literal("true"); ok || err != nil {
+		return ok, err
+	}
+	if ok, err := v.literal("false"); ok || err != nil {
+		return false, err
+	}
+	return false, v.it.errorf(ErrIncorrectType)
+}
+
+// IsNull consumes v if it is null and reports whether it was
+func (v Value) IsNull() (bool, error) {
+	return v.literal("null")
+}
+
+// RawJSON returns the text of v, which it checks only for structure
+func (v Value) RawJSON() ([]byte, error) {
+	off, err := v.start()
+	if err != nil {
+		return nil, err
+	}
+	if err := v.it.skip(); err != nil {
+		return nil, err
+	}
+	end := len(v.it.data)
+	if v.it.pos < len(v.it.idx) {
+		end = int(v.it.idx[v.it.pos])
+	}
+	if c := v.it.data[off]; c == '{' || c == '[' {
+		end = int(v.it.idx[v.it.pos-1]) + 1
+	}
+	return bytes.TrimRight(v.it.data[off:end], " \t\n\r"), nil
+}
+
+// Object is an object entered by GetObject
+type Object struct {
+	it *iterator
+	id uint32
+	// depth is the number of containers open inside o, o included
+	depth int
+	// first is the index of the first key, or of the closing brace;
+	// value is that of the value last returned, -1 before the first
+	first, value int
+	key          []byte
+	done         bool
+	err          error
+}
+
+// Err returns the error of a step in the chain that led to o, or of its
+// iteration
+func (o *Object) Err() error { return o.err }
+
+// next brings the iterator back to the members of o, past the value
+// last returned, and returns the next key, nil at the end of o
+func (o *Object) next() ([]byte, error) {
+	it := o.it
+	if !it.live(o.id, o.depth) {
+		return nil, ErrOutOfOrder
+	}
+	if err := it.leave(o.depth); err != nil {
+		return nil, err
+	}
+	if it.pos == o.value {
+		if err := it.skip(); err != nil {
+			return nil, err
+		}
+	}
+	return o.nextKey()
+}
+
+// nextKey moves past the comma after a member, unless pos is at the
+// first, and returns the key at pos, or nil at the closing brace
+func (o *Object) nextKey() ([]byte, error) {
+	it := o.it
+	if it.pos != o.first {
+		switch it.peek() {
+		case ',':
+			it.pos++
+			if it.peek() == '}' {
+				return nil, it.errorf(ErrStructure)
+			}
+		case '}':
+		default:
+			return nil, it.errorf(ErrStructure)
+		}
+	}
+	return o.peekKey()
+}
+
+// peekKey returns the key at pos, or nil at the closing brace
+func (o *Object) peekKey() ([]byte, error) {
+	it := o.it
+	switch it.peek() {
+	case '}':
+		return nil, nil
+	case '"':
+	default:
+		return nil, it.errorf(ErrStructure)
+	}
+	if it.pos+1 >= len(it.idx) || it.data[it.idx[it.pos+1]] != ':' {
+		return nil, it.errorf(ErrStructure)
+	}
+	// The key ends at the last quote before the colon
+	key := it.data[it.idx[it.pos]+1 : it.idx[it.pos+1]]
+	key = bytes.TrimRight(key, " \t\n\r")
+	return key[:len(key)-1], nil
+}
+
+// Field returns the value of the field name. Like operator[] in
+// simdjson, it looks forward from the last field returned, then wraps
+// around to the fields before; in document order every lookup compares
+// only the next key. Keys are compared as written, escapes included.
+func (o *Object) Field(name string) Value {
+	if o.err != nil {
+		return Value{err: o.err}
+	}
+	if o.done {
+		return Value{err: fmt.Errorf("%w %q", ErrNoSuchField, name)}
+	}
+	it := o.it
+	key, err := o.next()
+	if err != nil {
+		o.err = err
+		return Value{err: err}
+	}
+	from, wrapped := it.pos, false
+	for {
+		if key == nil {
+			if wrapped || from == o.first {
+				return Value{err: fmt.Errorf("%w %q", ErrNoSuchField, name)}
+			}
+			it.pos, wrapped = o.first, true
+		} else {
+			it.pos += 2
+			if string(key) == name {
+				o.value = it.pos
+				return Value{it: it, pos: it.pos}
+			}
+			if err := it.skip(); err != nil {
+				o.err = err
+				return Value{err: err}
+			}
+		}
+		if key, err = o.nextKey(); err != nil {
+			o.err = err
+			return Value{err: err}
+		}
+		if wrapped && it.pos == from {
+			// Back before the comma that led to the key the search began
+			// at, where the next lookup expects to be
+			if it.data[it.idx[it.pos-1]] == ',' {
+				it.pos--
+			}
+			return Value{err: fmt.Errorf("%w %q", ErrNoSuchField, name)}
+		}
+	}
+}
+
+// Next moves to the next field of o, in document order, and reports
+// whether there is one; at the end of o, or on an error, it returns
+// false and Err tells which
+func (o *Object) Next() bool {
+	if o.err != nil || o.done {
+		return false
+	}
+	key, err := o.next()
+	switch {
+	case err != nil:
+		o.err = err
+		return false
+	case key == nil:
+		o.it.pos++
+		o.it.open = o.it.open[:o.depth-1]
+		o.done = true
+		return false
+	}
+	o.key = key
+	o.it.pos += 2
+	o.value = o.it.pos
+	return true
+}
+
+// Key returns the key of the current field as written, escapes included
+func (o *Object) Key() string { return string(o.key) }
+
+// Value returns the value of the current field
+func (o *Object) Value() Value { return Value{it: o.it, pos: o.value} }
+
+// Array is an array entered by GetArray
+type Array struct {
+	it    *iterator
+	id    uint32
+	depth int
+	// value is the index of the element last returned, -1 before the
+	// first
+	value int
+	done  bool
+	err   error
+}
+
+// Err returns the error of a step in the chain that led to a, or of its
+// iteration
+func (a *Array) Err() error { return a.err }
+
+// Next moves to the next element of a and reports whether there is one;
+// at the end of a, or on an error, it returns false and Err tells which
+func (a *Array) Next() bool {
+	if a.err != nil || a.done {
+		return false
+	}
+	it := a.it
+	if !it.live(a.id, a.depth) {
+		a.err = ErrOutOfOrder
+		return false
+	}
+	if err := it.leave(a.depth); err != nil {
+		a.err = err
+		return false
+	}
+	if a.value >= 0 {
+		if it.pos == a.value {
+			if err := it.skip(); err != nil {
+				a.err = err
+				return false
+			}
+		}
+		if c := it.peek(); c != ',' && c != ']' {
+			a.err = it.errorf(ErrStructure)
+			return false
+		} else if c == ',' {
+			it.pos++
+		}
+	}
+	switch it.peek() {
+	case ']':
+		if a.value >= 0 && it.data[it.idx[it.pos-1]] == ',' {
+			a.err = it.errorf(ErrStructure)
+			return false
+		}
+		it.pos++
+		it.open = it.open[:a.depth-1]
+		a.done = true
+		return false
+	case 0, ',', ':', '}':
+		a.err = it.errorf(ErrStructure)
+		return false
+	}
+	a.value = it.pos
+	return true
+}
+
+// Value returns the current element
+func (a *Array) Value() Value { return Value{it: a.it, pos: a.value} }
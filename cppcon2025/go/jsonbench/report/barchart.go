@@ -0,0 +1,73 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+)
+
+const BarChartWidth = 40
+
+// BaselineBackend is the backend other results are normalized to
+const BaselineBackend = "encoding/json"
+
+// PrintBarChart draws the throughput of each result as a bar, relative to
+// encoding/json (1.0x) when it is among the results
+func PrintBarChart(w io.Writer, results []bench.Result) {
+	if len(results) == 0 {
+		return
+	}
+	base, max := 0.0, 0.0
+	nameWidth := 0
+	for _, r := range results {
+		if r.Backend == BaselineBackend {
+			base = r.MBPerSec
+		}
+		if r.MBPerSec > max {
+			max = r.MBPerSec
+		}
+		if len(r.Backend) > nameWidth {
+			nameWidth = len(r.Backend)
+		}
+	}
+	if max == 0 {
+		return
+	}
+	for _, r := range results {
+		n := int(r.MBPerSec/max*BarChartWidth + 0.5)
+		fmt.Fprintf(w, "  %-*s |%-*s|", nameWidth, r.Backend, BarChartWidth, strings.Repeat("#", n))
+		if base > 0 {
+			fmt.Fprintf(w, " %5.2fx", r.MBPerSec/base)
+		} else {
+			fmt.Fprintf(w, " %8.2f MB/s", r.MBPerSec)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// SpeedupSummary returns a one-line summary such as "handrolled 1.8×,
+// tape 1.3× vs encoding/json", fastest first, for the slides. It is
+// empty when baseline is not among the results.
+func SpeedupSummary(results []bench.Result, baseline string) string {
+	base := 0.0
+	var others []bench.Result
+	for _, r := range results {
+		if r.Backend == baseline {
+			base = r.MBPerSec
+		} else {
+			others = append(others, r)
+		}
+	}
+	if base == 0 || len(others) == 0 {
+		return ""
+	}
+	sort.SliceStable(others, func(i, j int) bool { return others[i].MBPerSec > others[j].MBPerSec })
+	parts := make([]string, len(others))
+	for i, r := range others {
+		parts[i] = fmt.Sprintf("%s %.1f×", r.Backend, r.MBPerSec/base)
+	}
+	return strings.Join(parts, ", ") + " vs " + baseline
+}
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Dashboard draws live throughput bars, one line per backend, redrawn in
+// place with ANSI escapes on each Draw. The bars are scaled to the
+// fastest backend so far, which is marked as the leader.
+type Dashboard struct {
+	w      io.Writer
+	names  []string
+	speeds []float64
+	errs   []error
+	width  int
+	lines  int
+}
+
+// NewDashboard returns a dashboard for the named backends with bars of
+// width cells
+func NewDashboard(w io.Writer, names []string, width int) *Dashboard {
+	return &Dashboard{
+		w:      w,
+		names:  names,
+		speeds: make([]float64, len(names)),
+		errs:   make([]error, len(names)),
+		width:  width,
+	}
+}
+
+// Set records the current throughput of backend i
+func (d *Dashboard) Set(i int, mbPerSec float64) { d.speeds[i] = mbPerSec }
+
+// Fail shows err in place of the bar of backend i
+func (d *Dashboard) Fail(i int, err error) { d.errs[i] = err }
+
+// Draw redraws the bars below a status line, over the previous drawing
+func (d *Dashboard) Draw(status string) {
+	var b strings.Builder
+	if d.lines > 0 {
+		fmt.Fprintf(&b, "\033[%dA", d.lines)
+	}
+	nameWidth, leader := 0, -1
+	for i, name := range d.names {
+		if len(name) > nameWidth {
+			nameWidth = len(name)
+		}
+		if d.errs[i] == nil && (leader < 0 || d.speeds[i] > d.speeds[leader]) {
+			leader = i
+		}
+	}
+	fmt.Fprintf(&b, "\r\033[K%s\n", status)
+	for i, name := range d.names {
+		fmt.Fprintf(&b, "\r\033[K  %-*s ", nameWidth, name)
+		switch {
+		case d.errs[i] != nil:
+			fmt.Fprintf(&b, "failed: %v", d.errs[i])
+		default:
+			n := 0
+			if leader >= 0 && d.speeds[leader] > 0 {
+				n = int(d.speeds[i]/d.speeds[leader]*float64(d.width) + 0.5)
+			}
+			fmt.Fprintf(&b, "%s%s %8.1f MB/s", strings.Repeat("█", n), strings.Repeat("░", d.width-n), d.speeds[i])
+			if i == leader && d.speeds[i] > 0 {
+				b.WriteString("  ◀ leader")
+			}
+		}
+		b.WriteByte('\n')
+	}
+	d.lines = len(d.names) + 1
+	io.WriteString(d.w, b.String())
+}
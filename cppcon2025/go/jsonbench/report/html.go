@@ -0,0 +1,125 @@
+//go:build !tinygo
+
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+)
+
+// reportBar is one bar of a chart
+type reportBar struct {
+	Label    string
+	MBPerSec float64
+	Y        int
+	Width    float64
+}
+
+// reportChart is the chart for one dataset
+type reportChart struct {
+	Dataset string
+	Height  int
+	Bars    []reportBar
+}
+
+const (
+	barHeight  = 28
+	barSpacing = 8
+	labelWidth = 260
+	chartWidth = 900
+)
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+h2 { font-weight: normal; }
+.bar { fill: #2a6ebb; }
+.label, .value { font-size: 14px; dominant-baseline: middle; }
+.label { text-anchor: end; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.GoVersion}}, throughput in MB/s (higher is better).</p>
+{{range .Charts}}
+<h2>{{.Dataset}}</h2>
+<svg width="` + fmt.Sprint(chartWidth) + `" height="{{.Height}}" xmlns="http://www.w3.org/2000/svg">
+{{range .Bars}}<text class="label" x="{{$.LabelWidth}}" y="{{.Y}}" dy="14">{{.Label}}</text>
+<rect class="bar" x="{{$.BarX}}" y="{{.Y}}" width="{{printf "%.1f" .Width}}" height="` + fmt.Sprint(barHeight) + `"/>
+<text class="value" x="{{$.BarX}}" dx="{{printf "%.1f" .Width}}" y="{{.Y}}" dy="14"> {{printf "%.1f" .MBPerSec}}</text>
+{{end}}</svg>
+{{end}}
+</body>
+</html>
+`))
+
+// HTML renders the results of one or more bench result files
+// as a self-contained HTML page; with several files, each bar is labelled
+// with the name of its file too
+func HTML(w io.Writer, title string, files []bench.ResultFile, names []string) error {
+	var datasets []string
+	bars := map[string][]reportBar{}
+	var versions []string
+	for i, rf := range files {
+		if rf.Environment != nil && !containsString(versions, rf.Environment.Runtime) {
+			versions = append(versions, rf.Environment.Runtime)
+		}
+		for _, r := range rf.Results {
+			label := r.Backend
+			if len(files) > 1 {
+				label += " (" + names[i] + ")"
+			}
+			if _, seen := bars[r.Dataset]; !seen {
+				datasets = append(datasets, r.Dataset)
+			}
+			bars[r.Dataset] = append(bars[r.Dataset], reportBar{Label: label, MBPerSec: r.MBPerSec})
+		}
+	}
+
+	barX := labelWidth + 10
+	var charts []reportChart
+	for _, dataset := range datasets {
+		list := bars[dataset]
+		max := 0.0
+		for _, b := range list {
+			if b.MBPerSec > max {
+				max = b.MBPerSec
+			}
+		}
+		for i := range list {
+			list[i].Y = i * (barHeight + barSpacing)
+			if max > 0 {
+				list[i].Width = list[i].MBPerSec / max * float64(chartWidth-barX-80)
+			}
+		}
+		charts = append(charts, reportChart{
+			Dataset: dataset,
+			Height:  len(list) * (barHeight + barSpacing),
+			Bars:    list,
+		})
+	}
+	return reportTemplate.Execute(w, map[string]interface{}{
+		"Title":      title,
+		"GoVersion":  strings.Join(versions, ", "),
+		"Charts":     charts,
+		"LabelWidth": labelWidth,
+		"BarX":       barX,
+	})
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bench"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/resultschema"
+)
+
+// formatSpeed rounds a throughput the way it is said on stage: whole
+// MB/s below 1 GB/s, tenths of a GB/s above
+func formatSpeed(mbPerSec float64) string {
+	if mbPerSec >= 1000 {
+		return fmt.Sprintf("%.1f GB/s", mbPerSec/1000)
+	}
+	return fmt.Sprintf("%.0f MB/s", mbPerSec)
+}
+
+// SpeakerNotes turns a result file into one sentence per dataset, such
+// as "encoding/json parses twitter.json at 120 MB/s; with sonic that
+// rises to 1.1 GB/s (9.2×)", the other backends fastest first, and a
+// last sentence saying where the numbers were measured. Datasets without
+// the baseline get their backends fastest first.
+func SpeakerNotes(rf bench.ResultFile, baseline string) []string {
+	var datasets []string
+	byDataset := map[string][]bench.Result{}
+	for _, r := range rf.Results {
+		if _, ok := byDataset[r.Dataset]; !ok {
+			datasets = append(datasets, r.Dataset)
+		}
+		byDataset[r.Dataset] = append(byDataset[r.Dataset], r)
+	}
+
+	var notes []string
+	for _, dataset := range datasets {
+		base := 0.0
+		var others []bench.Result
+		for _, r := range byDataset[dataset] {
+			if r.Backend == baseline {
+				base = r.MBPerSec
+			} else {
+				others = append(others, r)
+			}
+		}
+		sort.SliceStable(others, func(i, j int) bool { return others[i].MBPerSec > others[j].MBPerSec })
+		if base == 0 {
+			parts := make([]string, len(others))
+			for i, r := range others {
+				parts[i] = fmt.Sprintf("%s at %s", r.Backend, formatSpeed(r.MBPerSec))
+			}
+			notes = append(notes, fmt.Sprintf("On %s: %s.", dataset, strings.Join(parts, ", ")))
+			continue
+		}
+		note := fmt.Sprintf("%s parses %s at %s", baseline, dataset, formatSpeed(base))
+		for i, r := range others {
+			ratio := r.MBPerSec / base
+			switch {
+			case i == 0 && ratio >= 1:
+				note += fmt.Sprintf("; with %s that rises to %s (%.1f×)", r.Backend, formatSpeed(r.MBPerSec), ratio)
+			case ratio >= 1:
+				note += fmt.Sprintf(", %s reaches %s (%.1f×)", r.Backend, formatSpeed(r.MBPerSec), ratio)
+			default:
+				note += fmt.Sprintf("; %s is slower, at %s (%.1f×)", r.Backend, formatSpeed(r.MBPerSec), ratio)
+			}
+		}
+		notes = append(notes, note+".")
+	}
+
+	var env resultschema.Environment
+	if rf.Environment != nil {
+		env = *rf.Environment
+	}
+	var where []string
+	for _, s := range []string{env.CPU, env.OS + "/" + env.Arch, env.Runtime} {
+		if s != "" && s != "/" {
+			where = append(where, s)
+		}
+	}
+	if len(where) > 0 {
+		note := "Measured on " + strings.Join(where, ", ")
+		if rf.Time != nil {
+			note += " on " + rf.Time.Format("2 January 2006")
+		}
+		notes = append(notes, note+".")
+	}
+	return notes
+}
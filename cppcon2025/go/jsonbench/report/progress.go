@@ -0,0 +1,66 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ProgressBar draws the progress of a run with an estimated time left on
+// a single line, redrawn in place. It is meant for stderr, so that the
+// results on stdout stay clean when redirected; Clear removes the line
+// before printing to a terminal shared with it.
+type ProgressBar struct {
+	w           io.Writer
+	done, total int
+	start       time.Time
+	label       string
+	drawn       bool
+}
+
+// progressWidth is the number of cells of the bar
+const progressWidth = 30
+
+// NewProgressBar returns a bar for total steps, started now
+func NewProgressBar(w io.Writer, total int) *ProgressBar {
+	return &ProgressBar{w: w, total: total, start: time.Now()}
+}
+
+// Start shows what the current step is working on
+func (p *ProgressBar) Start(label string) {
+	p.label = label
+	p.draw()
+}
+
+// Step marks the current step done
+func (p *ProgressBar) Step() {
+	if p.done < p.total {
+		p.done++
+	}
+	p.draw()
+}
+
+// Clear erases the bar until the next Start or Step
+func (p *ProgressBar) Clear() {
+	if p.drawn {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.drawn = false
+	}
+}
+
+func (p *ProgressBar) draw() {
+	p.Clear()
+	n := 0
+	if p.total > 0 {
+		n = p.done * progressWidth / p.total
+	}
+	eta := "ETA --"
+	if p.done > 0 {
+		elapsed := time.Since(p.start)
+		left := elapsed / time.Duration(p.done) * time.Duration(p.total-p.done)
+		eta = "ETA " + left.Round(time.Second).String()
+	}
+	fmt.Fprintf(p.w, "[%s%s] %d/%d %s  %s", strings.Repeat("#", n), strings.Repeat(".", progressWidth-n), p.done, p.total, eta, p.label)
+	p.drawn = true
+}
@@ -0,0 +1,258 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"html"
+	"strings"
+)
+
+// A Snippet is a region of a Go source marked for the slides. The region
+// starts after a line comment
+//
+//	// snippet:name [backend]
+//
+// and ends before the next comment with the same name. The optional
+// backend names the backend whose throughput the slide quotes next to the
+// code.
+type Snippet struct {
+	Name    string
+	Backend string
+	File    string
+	Line    int // of the first line of Source
+	Source  string
+}
+
+const snippetMarker = "// snippet:"
+
+// ExtractSnippets returns the snippets marked in src, with the common
+// indentation of their lines removed
+func ExtractSnippets(file string, src []byte) ([]Snippet, error) {
+	var list []Snippet
+	var open *Snippet
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(src))
+	s.Buffer(nil, len(src)+1)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, snippetMarker) {
+			if open != nil {
+				lines = append(lines, line)
+			}
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(trimmed, snippetMarker))
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s:%d: snippet marker without a name", file, n)
+		}
+		if open == nil {
+			open = &Snippet{Name: fields[0], File: file, Line: n + 1}
+			if len(fields) > 1 {
+				open.Backend = fields[1]
+			}
+			lines = lines[:0]
+			continue
+		}
+		if fields[0] != open.Name {
+			return nil, fmt.Errorf("%s:%d: snippet %s starts inside snippet %s", file, n, fields[0], open.Name)
+		}
+		open.Source = dedent(lines)
+		list = append(list, *open)
+		open = nil
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, fmt.Errorf("%s:%d: snippet %s is not closed", file, open.Line-1, open.Name)
+	}
+	return list, nil
+}
+
+// dedent joins lines, expanding tabs to four spaces and removing the
+// indentation all non-blank lines share and the blank lines around them
+func dedent(lines []string) string {
+	common := -1
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(line, "\t", "    ")
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		indent := len(lines[i]) - len(strings.TrimLeft(lines[i], " "))
+		if common < 0 || indent < common {
+			common = indent
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		if len(line) >= common && common > 0 {
+			line = line[common:]
+		}
+		b.WriteString(strings.TrimRight(line, " "))
+		b.WriteByte('\n')
+	}
+	return strings.TrimLeft(b.String(), "\n")
+}
+
+// span is a run of source text and its highlighting class, empty for
+// plain text
+type span struct {
+	class, text string
+}
+
+// highlight splits src into lines of spans classified by the Go scanner:
+// keywords, strings, comments and numbers
+func highlight(src string) [][]span {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	// The snippet is a fragment, so errors are expected and ignored
+	s.Init(file, []byte(src), func(token.Position, string) {}, scanner.ScanComments)
+	var spans []span
+	end := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		offset := file.Offset(pos)
+		text := tok.String()
+		if lit != "" {
+			text = lit
+		}
+		if offset < end || offset+len(text) > len(src) {
+			continue
+		}
+		if offset > end {
+			spans = append(spans, span{"", src[end:offset]})
+		}
+		class := ""
+		switch {
+		case tok.IsKeyword():
+			class = "kw"
+		case tok == token.STRING || tok == token.CHAR:
+			class = "str"
+		case tok == token.COMMENT:
+			class = "com"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = "num"
+		}
+		spans = append(spans, span{class, text})
+		end = offset + len(text)
+	}
+	if end < len(src) {
+		spans = append(spans, span{"", src[end:]})
+	}
+
+	// Split multi-line spans, such as raw strings and the gaps between
+	// tokens, at the line ends
+	lines := [][]span{nil}
+	for _, sp := range spans {
+		for i, part := range strings.Split(sp.text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], span{sp.class, part})
+			}
+		}
+	}
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// DefaultSnippetCSS styles the highlighting classes of the snippets
+const DefaultSnippetCSS = `
+.snippet { font-family: Menlo, Consolas, monospace; font-size: 24px; background: #fafafa; color: #333; fill: #333; }
+.snippet .kw { color: #a626a4; fill: #a626a4; font-weight: bold; }
+.snippet .str { color: #50a14f; fill: #50a14f; }
+.snippet .com { color: #a0a1a7; fill: #a0a1a7; font-style: italic; }
+.snippet .num { color: #986801; fill: #986801; }
+.snippet .caption { font-family: Helvetica, Arial, sans-serif; color: #2a6ebb; fill: #2a6ebb; }
+`
+
+// SnippetHTML renders s as a highlighted <figure> fragment to paste into
+// an HTML slide, with caption, such as the current throughput of its
+// backend, below the code unless empty
+func SnippetHTML(s Snippet, css, caption string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<style>%s</style>\n", css)
+	fmt.Fprintf(&b, "<figure class=\"snippet\" id=\"snippet-%s\">\n<pre><code>", html.EscapeString(s.Name))
+	for _, line := range highlight(s.Source) {
+		for _, sp := range line {
+			if sp.class == "" {
+				b.WriteString(html.EscapeString(sp.text))
+			} else {
+				fmt.Fprintf(&b, `<span class="%s">%s</span>`, sp.class, html.EscapeString(sp.text))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("</code></pre>\n")
+	if caption != "" {
+		fmt.Fprintf(&b, "<figcaption class=\"caption\">%s</figcaption>\n", html.EscapeString(caption))
+	}
+	b.WriteString("</figure>\n")
+	return b.String()
+}
+
+// SnippetSVG renders s as a standalone SVG image sized to the code, for
+// slide tools that only take images
+func SnippetSVG(s Snippet, css, caption string) string {
+	const (
+		fontSize   = 24
+		lineHeight = 32
+		charWidth  = 0.6 * fontSize
+		margin     = 24
+	)
+	lines := highlight(s.Source)
+	columns := len(caption)
+	for _, line := range lines {
+		n := 0
+		for _, sp := range line {
+			n += len([]rune(sp.text))
+		}
+		if n > columns {
+			columns = n
+		}
+	}
+	rows := len(lines)
+	if caption != "" {
+		rows += 2
+	}
+	width := int(float64(columns)*charWidth) + 2*margin
+	height := rows*lineHeight + 2*margin
+	var w svgWriter
+	w.printf(`<svg xmlns="http://www.w3.org/2000/svg" class="snippet" viewBox="0 0 %d %d" width="%d" height="%d">`+"\n",
+		width, height, width, height)
+	w.printf("<style>%s</style>\n", css)
+	w.printf(`<rect width="100%%" height="100%%" style="fill: #fafafa"/>` + "\n")
+	for i, line := range lines {
+		w.printf(`<text x="%d" y="%d" xml:space="preserve">`, margin, margin+(i+1)*lineHeight-8)
+		for _, sp := range line {
+			if sp.class == "" {
+				w.printf("%s", html.EscapeString(sp.text))
+			} else {
+				w.printf(`<tspan class="%s">%s</tspan>`, sp.class, html.EscapeString(sp.text))
+			}
+		}
+		w.printf("</text>\n")
+	}
+	if caption != "" {
+		w.printf(`<text class="caption" x="%d" y="%d">%s</text>`+"\n", margin, margin+(len(lines)+2)*lineHeight-8, html.EscapeString(caption))
+	}
+	w.printf("</svg>\n")
+	return w.String()
+}
@@ -0,0 +1,250 @@
+// Package report renders result files: an HTML page, SVG charts sized for
+// slides and bar charts for the terminal.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Charts are sized for 16:9 slides
+const (
+	slideWidth  = 1600
+	slideHeight = 900
+	plotLeft    = 140
+	plotRight   = 1560
+	plotTop     = 120
+	plotBottom  = 780
+)
+
+// DefaultChartCSS styles the classes used in the charts; -css replaces it
+const DefaultChartCSS = `
+text { font-family: Helvetica, Arial, sans-serif; font-size: 24px; fill: #333; }
+.title { font-size: 40px; }
+.axis { stroke: #333; stroke-width: 2; }
+.grid { stroke: #ddd; stroke-width: 1; }
+.s0 { fill: #2a6ebb; stroke: #2a6ebb; }
+.s1 { fill: #e07b00; stroke: #e07b00; }
+.s2 { fill: #3a9f3a; stroke: #3a9f3a; }
+.s3 { fill: #c0392b; stroke: #c0392b; }
+.s4 { fill: #8e44ad; stroke: #8e44ad; }
+.s5 { fill: #7f8c8d; stroke: #7f8c8d; }
+path.line { fill: none; stroke-width: 4; }
+`
+
+// Series is one backend's values, in the order of the chart's categories
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+// niceCeiling rounds v up to 1, 2 or 5 times a power of ten
+func niceCeiling(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	p := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if m*p >= v {
+			return m * p
+		}
+	}
+	return 10 * p
+}
+
+type svgWriter struct {
+	strings.Builder
+}
+
+func (w *svgWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.Builder, format, args...)
+}
+
+// begin writes the document header, the title and the y axis from 0 to max
+func (w *svgWriter) begin(title, css, unit string, max float64) {
+	w.printf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`+"\n",
+		slideWidth, slideHeight, slideWidth, slideHeight)
+	w.printf("<style>%s</style>\n", css)
+	w.printf(`<text class="title" x="%d" y="70">%s</text>`+"\n", plotLeft, html.EscapeString(title))
+	const ticks = 5
+	for i := 0; i <= ticks; i++ {
+		y := plotBottom - float64(i)*(plotBottom-plotTop)/ticks
+		w.printf(`<line class="grid" x1="%d" y1="%.1f" x2="%d" y2="%.1f"/>`+"\n", plotLeft, y, plotRight, y)
+		w.printf(`<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%g</text>`+"\n",
+			plotLeft-15, y, max*float64(i)/ticks)
+	}
+	w.printf(`<text x="40" y="%d" transform="rotate(-90 40 %d)" text-anchor="middle">%s</text>`+"\n",
+		(plotTop+plotBottom)/2, (plotTop+plotBottom)/2, html.EscapeString(unit))
+	w.printf(`<line class="axis" x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", plotLeft, plotBottom, plotRight, plotBottom)
+	w.printf(`<line class="axis" x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", plotLeft, plotTop, plotLeft, plotBottom)
+}
+
+// legend lists the series in the top right corner
+func (w *svgWriter) legend(list []Series) {
+	for i, s := range list {
+		y := plotTop + 10 + i*36
+		w.printf(`<rect class="s%d" x="%d" y="%d" width="24" height="24"/>`+"\n", i%6, plotRight-360, y)
+		w.printf(`<text x="%d" y="%d" dominant-baseline="middle">%s</text>`+"\n", plotRight-325, y+12, html.EscapeString(s.Name))
+	}
+}
+
+// BarChartSVG draws one group of bars per category, one bar per series
+func BarChartSVG(title, css, unit string, categories []string, list []Series) string {
+	max := 0.0
+	for _, s := range list {
+		for _, v := range s.Values {
+			max = math.Max(max, v)
+		}
+	}
+	max = niceCeiling(max)
+	var w svgWriter
+	w.begin(title, css, unit, max)
+	groupWidth := float64(plotRight-plotLeft) / float64(len(categories))
+	barWidth := groupWidth * 0.8 / float64(len(list))
+	for c, category := range categories {
+		x0 := plotLeft + float64(c)*groupWidth + groupWidth*0.1
+		for i, s := range list {
+			if c >= len(s.Values) {
+				continue
+			}
+			h := s.Values[c] / max * (plotBottom - plotTop)
+			w.printf(`<rect class="s%d" x="%.1f" y="%.1f" width="%.1f" height="%.1f"/>`+"\n",
+				i%6, x0+float64(i)*barWidth, plotBottom-h, barWidth*0.9, h)
+		}
+		w.printf(`<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n",
+			x0+groupWidth*0.4, plotBottom+40, html.EscapeString(category))
+	}
+	w.legend(list)
+	w.printf("</svg>\n")
+	return w.String()
+}
+
+// StackedBarSVG draws one bar per category, stacking the series' values
+// from the bottom up, as in a breakdown of time by stage
+func StackedBarSVG(title, css, unit string, categories []string, list []Series) string {
+	max := 0.0
+	for c := range categories {
+		total := 0.0
+		for _, s := range list {
+			if c < len(s.Values) {
+				total += s.Values[c]
+			}
+		}
+		max = math.Max(max, total)
+	}
+	max = niceCeiling(max)
+	var w svgWriter
+	w.begin(title, css, unit, max)
+	groupWidth := float64(plotRight-plotLeft) / float64(len(categories))
+	for c, category := range categories {
+		x0 := plotLeft + float64(c)*groupWidth + groupWidth*0.2
+		y := float64(plotBottom)
+		for i, s := range list {
+			if c >= len(s.Values) {
+				continue
+			}
+			h := s.Values[c] / max * (plotBottom - plotTop)
+			y -= h
+			w.printf(`<rect class="s%d" x="%.1f" y="%.1f" width="%.1f" height="%.1f"/>`+"\n",
+				i%6, x0, y, groupWidth*0.4, h)
+		}
+		w.printf(`<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n",
+			x0+groupWidth*0.2, plotBottom+40, html.EscapeString(category))
+	}
+	w.legend(list)
+	w.printf("</svg>\n")
+	return w.String()
+}
+
+// LineChartSVG draws one line per series over xs, on a logarithmic x axis
+func LineChartSVG(title, css, unit string, xs []float64, list []Series) string {
+	max := 0.0
+	for _, s := range list {
+		for _, v := range s.Values {
+			max = math.Max(max, v)
+		}
+	}
+	max = niceCeiling(max)
+	var w svgWriter
+	w.begin(title, css, unit, max)
+	lo, hi := math.Log10(xs[0]), math.Log10(xs[len(xs)-1])
+	if hi == lo {
+		hi = lo + 1
+	}
+	xpos := func(x float64) float64 {
+		return plotLeft + (math.Log10(x)-lo)/(hi-lo)*(plotRight-plotLeft)
+	}
+	for _, x := range xs {
+		w.printf(`<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", xpos(x), plotBottom+40, formatBytes(x))
+	}
+	for i, s := range list {
+		var path strings.Builder
+		for j, v := range s.Values {
+			cmd := "L"
+			if j == 0 {
+				cmd = "M"
+			}
+			fmt.Fprintf(&path, "%s%.1f,%.1f ", cmd, xpos(xs[j]), plotBottom-v/max*(plotBottom-plotTop))
+		}
+		w.printf(`<path class="line s%d" d="%s"/>`+"\n", i%6, strings.TrimSpace(path.String()))
+	}
+	w.legend(list)
+	w.printf("</svg>\n")
+	return w.String()
+}
+
+// formatBytes prints a byte count with a binary unit
+func formatBytes(n float64) string {
+	for _, u := range []string{"B", "KB", "MB", "GB"} {
+		if n < 1024 {
+			return strconv.FormatFloat(n, 'f', -1, 64) + " " + u
+		}
+		n = math.Round(n/1024*10) / 10
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64) + " TB"
+}
+
+// ReadSweep reads the table printed by the sweep command
+func ReadSweep(path string) ([]float64, []Series, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	var xs []float64
+	var list []Series
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Split(s.Text(), "\t")
+		if strings.HasPrefix(fields[0], "# bytes") {
+			for _, name := range fields[1:] {
+				list = append(list, Series{Name: name})
+			}
+			continue
+		}
+		if strings.HasPrefix(fields[0], "#") || len(fields) != len(list)+1 {
+			continue
+		}
+		x, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		xs = append(xs, x)
+		for i := range list {
+			v, err := strconv.ParseFloat(fields[i+1], 64)
+			if err != nil {
+				return nil, nil, err
+			}
+			list[i].Values = append(list[i].Values, v)
+		}
+	}
+	if len(xs) == 0 {
+		return nil, nil, fmt.Errorf("%s: no sweep rows", path)
+	}
+	return xs, list, s.Err()
+}
@@ -0,0 +1,60 @@
+package resultschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// JSONSchema describes File as a JSON Schema (draft 2020-12) for the
+// benchmarks written in other languages. It is derived from the struct
+// tags, so it cannot drift from the Go types.
+func JSONSchema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(File{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = "https://simdjson.github.io/simdjson_talks/result.schema.json"
+	schema["title"] = "Benchmark result file"
+	props := schema["properties"].(map[string]interface{})
+	props["schema_version"] = map[string]interface{}{"const": Version}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaFor(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Uint, reflect.Uint64, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64, reflect.Float32:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Struct:
+		props := map[string]interface{}{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			props[name] = schemaFor(f.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		return map[string]interface{}{"type": "object", "properties": props, "required": required}
+	}
+	return map[string]interface{}{}
+}
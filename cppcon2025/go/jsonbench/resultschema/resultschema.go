@@ -0,0 +1,126 @@
+// Package resultschema defines the result format shared by every benchmark
+// in the talks repository, so that numbers from Go, C++, Rust and Python
+// programs can be read by the same tools.
+package resultschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// Version is written in every file as schema_version. It changes only when
+// a field is removed or changes meaning; new optional fields keep it.
+const Version = 1
+
+// File is one benchmark run. Time and Environment are nil in files that
+// do not record them.
+type File struct {
+	SchemaVersion int          `json:"schema_version"`
+	Language      string       `json:"language"`
+	Time          *time.Time   `json:"time,omitempty"`
+	Commit        string       `json:"commit,omitempty"`
+	Environment   *Environment `json:"environment,omitempty"`
+	Results       []Result     `json:"results"`
+}
+
+// Environment describes where a run was made
+type Environment struct {
+	Hostname string `json:"hostname,omitempty"`
+	OS       string `json:"os,omitempty"`
+	Arch     string `json:"arch,omitempty"`
+	CPU      string `json:"cpu,omitempty"`
+	NumCPU   int    `json:"num_cpu,omitempty"`
+	// Runtime is the compiler or runtime version, such as go1.25.0
+	Runtime string `json:"runtime,omitempty"`
+	// Fingerprint is a short hash identifying the machine, so runs from
+	// the same machine can be grouped
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// Result is the throughput of one backend on one dataset
+type Result struct {
+	Dataset    string  `json:"dataset"`
+	Backend    string  `json:"backend"`
+	Bytes      int     `json:"bytes,omitempty"`
+	Iterations int     `json:"iterations,omitempty"`
+	MBPerSec   float64 `json:"mb_per_s"`
+	// Stats summarizes repeated measurements; MBPerSec is their median
+	Stats *Stats `json:"stats,omitempty"`
+	// Counters holds hardware event counts over the timed loop
+	Counters *Counters `json:"counters,omitempty"`
+}
+
+// Stats summarizes repeated throughput measurements
+type Stats struct {
+	Samples        int     `json:"samples"`
+	MinMBPerSec    float64 `json:"min_mb_per_s"`
+	MedianMBPerSec float64 `json:"median_mb_per_s"`
+	MaxMBPerSec    float64 `json:"max_mb_per_s"`
+}
+
+// NewStats summarizes samples, which must not be empty
+func NewStats(samples []float64) *Stats {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return &Stats{Samples: n, MinMBPerSec: sorted[0], MedianMBPerSec: median, MaxMBPerSec: sorted[n-1]}
+}
+
+// Counters are hardware event counts
+type Counters struct {
+	Cycles          float64 `json:"cycles"`
+	Instructions    float64 `json:"instructions"`
+	Branches        float64 `json:"branches"`
+	BranchMisses    float64 `json:"branch_misses"`
+	CacheReferences float64 `json:"cache_references"`
+	CacheMisses     float64 `json:"cache_misses"`
+}
+
+// IPC is the number of instructions retired per cycle
+func (c Counters) IPC() float64 { return ratio(c.Instructions, c.Cycles) }
+
+// CacheMissRate is the fraction of cache references that missed
+func (c Counters) CacheMissRate() float64 { return ratio(c.CacheMisses, c.CacheReferences) }
+
+// BranchMissRate is the fraction of branches that were mispredicted
+func (c Counters) BranchMissRate() float64 { return ratio(c.BranchMisses, c.Branches) }
+
+func ratio(x, y float64) float64 {
+	if y == 0 {
+		return 0
+	}
+	return x / y
+}
+
+// Write saves f as indented JSON, filling in the schema version
+func Write(path string, f File) error {
+	f.SchemaVersion = Version
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Read loads a result file and rejects versions newer than this package
+func Read(path string) (File, error) {
+	var f File
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, err
+	}
+	if f.SchemaVersion > Version {
+		return f, fmt.Errorf("schema version %d is newer than supported version %d", f.SchemaVersion, Version)
+	}
+	return f, nil
+}
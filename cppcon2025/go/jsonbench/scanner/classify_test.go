@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// TestClassifiers compares the masks of the SWAR and vector classifiers
+// with those of the table lookup, on blocks of random bytes and of bytes
+// that differ from a structural character in one bit
+func TestClassifiers(t *testing.T) {
+	special := []byte("\\\" \t\n\r{}[]:,")
+	var near []byte
+	for _, c := range special {
+		for bit := 0; bit < 8; bit++ {
+			near = append(near, c, c^1<<bit)
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	data := make([]byte, 64*chunkBlocks)
+	for i := range data {
+		if i < len(data)/2 {
+			data[i] = byte(r.Intn(256))
+		} else {
+			data[i] = near[r.Intn(len(near))]
+		}
+	}
+	want := make([]uint64, 4*chunkBlocks)
+	classifyGeneric(data, want)
+	supported := map[string]bool{}
+	for _, impl := range simd.Implementations() {
+		supported[impl.Name] = impl.Supported
+	}
+	for name, classify := range classifiers {
+		if !supported[name] {
+			continue
+		}
+		got := make([]uint64, 4*chunkBlocks)
+		classify(data, got)
+		if !slices.Equal(got, want) {
+			for i := range got {
+				if got[i] != want[i] {
+					t.Fatalf("%s: block %d, mask %d: %#x, want %#x", name, i/4, i%4, got[i], want[i])
+				}
+			}
+		}
+	}
+}
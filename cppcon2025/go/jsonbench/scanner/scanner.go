@@ -0,0 +1,226 @@
+// Package scanner is stage 1 of simdjson in Go: it finds the structural
+// characters of a JSON document 64 bytes at a time. A kernel (AVX-512,
+// AVX2 or NEON in assembly, or SWAR in Go) classifies each byte of a block
+// into four bitmasks, backslashes, quotes, whitespace and operators; the
+// rest is arithmetic on those masks, branch-free and the same on every
+// platform: which quotes are escaped, which bytes are inside strings, and
+// which bytes start a scalar. The bit tricks are those of package
+// bitutil. The kernels follow the implementation package simd has
+// selected.
+package scanner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bitutil"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// classifiers holds the classifier of each implementation of package
+// simd that has one. A classifier fills four masks per 64-byte block of
+// data (whose length is a multiple of 64): the backslashes, the quotes,
+// the whitespace and the operators ({ } [ ] : ,), bit i standing for
+// byte i of the block.
+var classifiers = map[string]func(data []byte, masks []uint64){
+	"SWAR": classifySWAR,
+}
+
+// classifier returns the classifier of the active implementation
+func classifier() func([]byte, []uint64) {
+	if c, ok := classifiers[simd.Active().Name]; ok {
+		return c
+	}
+	return classifyGeneric
+}
+
+// Name returns the implementation Index runs, such as "AVX2" or "SWAR",
+// and "generic" for the byte-at-a-time classifier
+func Name() string {
+	if _, ok := classifiers[simd.Active().Name]; ok {
+		return simd.Active().Name
+	}
+	return "generic"
+}
+
+// chunkBlocks is the number of blocks classified per kernel call, so that
+// their masks fit on the stack
+const chunkBlocks = 64
+
+// Index appends to idx the offset of every structural character of data:
+// every operator outside strings, every opening quote and the first byte
+// of every other scalar (true, 12.5), simdjson's pseudo-structural
+// characters. It checks only that strings are closed; on valid JSON the
+// offsets are those of backends.StructuralIndex.
+func Index(data []byte, idx []uint32) ([]uint32, error) {
+	return index(data, idx, classifier(), 0)
+}
+
+// IndexPrefetch is Index issuing software prefetches distance bytes ahead
+// of the block being classified, one chunk at a time, with
+// simd.Prefetch. Hardware prefetchers follow a sequential scan well, so
+// whether it helps is for the prefetch experiment to find out.
+func IndexPrefetch(data []byte, idx []uint32, distance int) ([]uint32, error) {
+	return index(data, idx, classifier(), distance)
+}
+
+func index(data []byte, idx []uint32, classify func([]byte, []uint64), distance int) ([]uint32, error) {
+	var masks [4 * chunkBlocks]uint64
+	var s state
+	full := len(data) &^ 63
+	for start := 0; start < full; start += chunkBlocks * 64 {
+		end := min(start+chunkBlocks*64, full)
+		if distance > 0 && end+distance <= len(data) {
+			simd.Prefetch(data[start+distance : end+distance])
+		}
+		n := (end - start) / 64
+		classify(data[start:end], masks[:4*n])
+		for b := 0; b < n; b++ {
+			idx = s.block(masks[4*b:4*b+4], uint32(start+64*b), idx)
+		}
+	}
+	if full < len(data) {
+		// The last partial block, padded with whitespace
+		var tail [64]byte
+		for i := range tail {
+			tail[i] = ' '
+		}
+		copy(tail[:], data[full:])
+		classify(tail[:], masks[:4])
+		idx = s.block(masks[:4], uint32(full), idx)
+	}
+	if s.inString != 0 {
+		// Nothing after the opening quote of the open string was indexed
+		return idx, fmt.Errorf("unterminated string at offset %d", idx[len(idx)-1])
+	}
+	return idx, nil
+}
+
+// state carries what a block needs to know of the previous ones, one bit
+// each: whether its first byte is escaped, whether it starts inside a
+// string (all ones then), and whether its first byte follows a scalar
+type state struct {
+	escaped  uint64
+	inString uint64
+	scalar   uint64
+}
+
+// block appends the structural offsets of the block at base, given its
+// masks, to idx
+func (s *state) block(m []uint64, base uint32, idx []uint32) []uint32 {
+	backslash, quote, whitespace, op := m[0], m[1], m[2], m[3]
+	quote &^= s.escapes(backslash)
+
+	// A byte is inside a string when an odd number of quotes, itself
+	// included, precede it; the closing quote and the bytes between form
+	// the tail of the string
+	inString := bitutil.PrefixXor(quote) ^ s.inString
+	s.inString = uint64(int64(inString) >> 63)
+	stringTail := inString ^ quote
+
+	// A scalar starts at a byte that is neither whitespace nor an
+	// operator and does not follow such a byte, quotes aside
+	scalar := ^(op | whitespace)
+	nonQuote := scalar &^ quote
+	follows := nonQuote<<1 | s.scalar
+	s.scalar = nonQuote >> 63
+
+	return bitutil.AppendSetBits(idx, base, (op|scalar&^follows)&^stringTail)
+}
+
+// oddBits has the odd bit positions set
+const oddBits = 0xAAAAAAAAAAAAAAAA
+
+// escapes returns the bytes escaped by a backslash: those ending a run of
+// backslashes of odd length. Subtracting the run starts from their ends
+// marks, with one carry per run, whether each run starts on an even or
+// an odd bit; the parity of its end then tells its length.
+func (s *state) escapes(backslash uint64) uint64 {
+	if backslash == 0 {
+		escaped := s.escaped
+		s.escaped = 0
+		return escaped
+	}
+	// A backslash escaped by the previous block starts no run
+	potential := backslash &^ s.escaped
+	code := ((potential<<1 | oddBits) - potential) ^ oddBits
+	escaped := code ^ (backslash | s.escaped)
+	s.escaped = (code & backslash) >> 63
+	return escaped
+}
+
+// ValidUTF8 reports whether data is valid UTF-8. It crosses runs of
+// ASCII with the ASCII kernel of package simd and decodes the other
+// runes one by one; simdjson checks those with vectors too. The generic
+// implementation leaves it all to utf8.Valid.
+func ValidUTF8(data []byte) bool {
+	ascii := simd.ASCII
+	if ascii == nil {
+		return utf8.Valid(data)
+	}
+	for len(data) > 0 {
+		data = data[ascii(data):]
+		for len(data) > 0 && data[0] >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(data)
+			if r == utf8.RuneError && size == 1 {
+				return false
+			}
+			data = data[size:]
+		}
+	}
+	return true
+}
+
+// classifySWAR classifies 8 bytes at a time, in a uint64. Bit 5 set
+// turns '[' and ']' into '{' and '}', as in the vector kernels.
+func classifySWAR(data []byte, masks []uint64) {
+	for b := 0; b < len(data)/64; b++ {
+		var backslash, quote, whitespace, op uint64
+		for i := 0; i < 64; i += 8 {
+			v := binary.LittleEndian.Uint64(data[64*b+i:])
+			backslash |= bitutil.MoveMask(bitutil.Equal(v, '\\')) << i
+			quote |= bitutil.MoveMask(bitutil.Equal(v, '"')) << i
+			whitespace |= bitutil.MoveMask(bitutil.Equal(v, ' ')|bitutil.Equal(v, '\t')|bitutil.Equal(v, '\n')|bitutil.Equal(v, '\r')) << i
+			lower := v | 0x20*bitutil.Ones
+			op |= bitutil.MoveMask(bitutil.Equal(lower, '{')|bitutil.Equal(lower, '}')|bitutil.Equal(v, ':')|bitutil.Equal(v, ',')) << i
+		}
+		masks[4*b], masks[4*b+1], masks[4*b+2], masks[4*b+3] = backslash, quote, whitespace, op
+	}
+}
+
+// Byte classes of classifyGeneric
+const (
+	classBackslash = 1 + iota
+	classQuote
+	classWhitespace
+	classOp
+)
+
+var classes = [256]uint8{
+	'\\': classBackslash,
+	'"':  classQuote,
+	' ':  classWhitespace,
+	'\t': classWhitespace,
+	'\n': classWhitespace,
+	'\r': classWhitespace,
+	'{':  classOp,
+	'}':  classOp,
+	'[':  classOp,
+	']':  classOp,
+	':':  classOp,
+	',':  classOp,
+}
+
+// classifyGeneric classifies a byte at a time, with a table lookup
+func classifyGeneric(data []byte, masks []uint64) {
+	for b := 0; b < len(data)/64; b++ {
+		var m [4]uint64
+		for i, c := range data[64*b : 64*b+64] {
+			if class := classes[c]; class != 0 {
+				m[class-1] |= 1 << i
+			}
+		}
+		copy(masks[4*b:], m[:])
+	}
+}
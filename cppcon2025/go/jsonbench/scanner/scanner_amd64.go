@@ -0,0 +1,19 @@
+//go:build !tinygo
+
+package scanner
+
+// classifyAVX512 classifies 64 bytes per instruction, comparing into
+// mask registers
+//
+//go:noescape
+func classifyAVX512(data []byte, masks []uint64)
+
+// classifyAVX2 classifies 32 bytes per instruction
+//
+//go:noescape
+func classifyAVX2(data []byte, masks []uint64)
+
+func init() {
+	classifiers["AVX-512"] = classifyAVX512
+	classifiers["AVX2"] = classifyAVX2
+}
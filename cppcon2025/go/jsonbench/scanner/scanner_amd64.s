@@ -0,0 +1,125 @@
+//go:build !tinygo
+
+#include "textflag.h"
+
+// The bytes broadcast into the registers: '\\', '"', 0x20, '{', '}',
+// ':' and ','
+DATA consts<>+0(SB)/8, $0x002c3a7d7b20225c
+GLOBL consts<>(SB), RODATA|NOPTR, $8
+
+// wsTable holds, at the index of each whitespace byte's low nibble, that
+// byte, and 0xFF elsewhere
+DATA wsTable<>+0(SB)/8, $0xffffffffffffff20
+DATA wsTable<>+8(SB)/8, $0xffff0dffff0a09ff
+GLOBL wsTable<>(SB), RODATA|NOPTR, $16
+
+// CLASSIFY sets the four masks of the 32 bytes at off(SI). A byte is an
+// operator when it is ':' or ',', or when it is '{' or '}' with bit 5
+// set, which turns '[' and ']' into them.
+#define CLASSIFY(off, bs, quote, ws, op) \
+	VMOVDQU off(SI), Y0; \
+	VPCMPEQB Y0, Y1, Y4; \
+	VPMOVMSKB Y4, bs; \
+	VPCMPEQB Y0, Y2, Y4; \
+	VPMOVMSKB Y4, quote; \
+	VPSHUFB Y0, Y3, Y4; \
+	VPCMPEQB Y0, Y4, Y4; \
+	VPMOVMSKB Y4, ws; \
+	VPOR Y0, Y8, Y5; \
+	VPCMPEQB Y5, Y9, Y6; \
+	VPCMPEQB Y5, Y10, Y7; \
+	VPOR Y6, Y7, Y6; \
+	VPCMPEQB Y0, Y11, Y7; \
+	VPOR Y7, Y6, Y6; \
+	VPCMPEQB Y0, Y12, Y7; \
+	VPOR Y7, Y6, Y6; \
+	VPMOVMSKB Y6, op
+
+// func classifyAVX2(data []byte, masks []uint64)
+TEXT ·classifyAVX2(SB), NOSPLIT, $0-48
+	MOVQ data_base+0(FP), SI
+	MOVQ data_len+8(FP), R9
+	MOVQ masks_base+24(FP), DI
+	SHRQ $6, R9
+	JZ done
+
+	VPBROADCASTB consts<>+0(SB), Y1
+	VPBROADCASTB consts<>+1(SB), Y2
+	VBROADCASTI128 wsTable<>(SB), Y3
+	VPBROADCASTB consts<>+2(SB), Y8
+	VPBROADCASTB consts<>+3(SB), Y9
+	VPBROADCASTB consts<>+4(SB), Y10
+	VPBROADCASTB consts<>+5(SB), Y11
+	VPBROADCASTB consts<>+6(SB), Y12
+
+loop:
+	CLASSIFY(0, AX, BX, CX, DX)
+	CLASSIFY(32, R10, R11, R12, R13)
+
+	// The second half goes in the high 32 bits
+	SHLQ $32, R10
+	ORQ R10, AX
+	SHLQ $32, R11
+	ORQ R11, BX
+	SHLQ $32, R12
+	ORQ R12, CX
+	SHLQ $32, R13
+	ORQ R13, DX
+	MOVQ AX, 0(DI)
+	MOVQ BX, 8(DI)
+	MOVQ CX, 16(DI)
+	MOVQ DX, 24(DI)
+	ADDQ $64, SI
+	ADDQ $32, DI
+	DECQ R9
+	JNZ loop
+	VZEROUPPER
+
+done:
+	RET
+
+// func classifyAVX512(data []byte, masks []uint64)
+TEXT ·classifyAVX512(SB), NOSPLIT, $0-48
+	MOVQ data_base+0(FP), SI
+	MOVQ data_len+8(FP), R9
+	MOVQ masks_base+24(FP), DI
+	SHRQ $6, R9
+	JZ zdone
+
+	VPBROADCASTB consts<>+0(SB), Z1
+	VPBROADCASTB consts<>+1(SB), Z2
+	VBROADCASTI32X4 wsTable<>(SB), Z3
+	VPBROADCASTB consts<>+2(SB), Z8
+	VPBROADCASTB consts<>+3(SB), Z9
+	VPBROADCASTB consts<>+4(SB), Z10
+	VPBROADCASTB consts<>+5(SB), Z11
+	VPBROADCASTB consts<>+6(SB), Z12
+
+zloop:
+	// The comparisons of CLASSIFY over the whole block, each into a mask
+	// register that is already the mask of the block
+	VMOVDQU8 (SI), Z0
+	VPCMPEQB Z1, Z0, K1
+	KMOVQ K1, 0(DI)
+	VPCMPEQB Z2, Z0, K1
+	KMOVQ K1, 8(DI)
+	VPSHUFB Z0, Z3, Z4
+	VPCMPEQB Z0, Z4, K1
+	KMOVQ K1, 16(DI)
+	VPORQ Z0, Z8, Z5
+	VPCMPEQB Z9, Z5, K1
+	VPCMPEQB Z10, Z5, K2
+	KORQ K1, K2, K1
+	VPCMPEQB Z11, Z0, K2
+	KORQ K1, K2, K1
+	VPCMPEQB Z12, Z0, K2
+	KORQ K1, K2, K1
+	KMOVQ K1, 24(DI)
+	ADDQ $64, SI
+	ADDQ $32, DI
+	DECQ R9
+	JNZ zloop
+	VZEROUPPER
+
+zdone:
+	RET
@@ -0,0 +1,10 @@
+//go:build !tinygo
+
+package scanner
+
+// classifyNEON classifies 16 bytes per instruction
+//
+//go:noescape
+func classifyNEON(data []byte, masks []uint64)
+
+func init() { classifiers["NEON"] = classifyNEON }
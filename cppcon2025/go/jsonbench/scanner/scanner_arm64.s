@@ -0,0 +1,112 @@
+//go:build !tinygo
+
+#include "textflag.h"
+
+// wsTable holds, at the index of each whitespace byte's low nibble, that
+// byte, and 0xFF elsewhere
+DATA wsTable<>+0(SB)/8, $0xffffffffffffff20
+DATA wsTable<>+8(SB)/8, $0xffff0dffff0a09ff
+GLOBL wsTable<>(SB), RODATA|NOPTR, $16
+
+// weights holds the bit of each byte within its half of a register
+DATA weights<>+0(SB)/8, $0x8040201008040201
+DATA weights<>+8(SB)/8, $0x8040201008040201
+GLOBL weights<>(SB), RODATA|NOPTR, $16
+
+// NEON has no movemask: MOVEMASK keeps the bit of each byte of the four
+// compare results in V4-V7 and adds adjacent bytes three times, which
+// leaves the 64-bit mask of the block in V4.D[0], and stores it at R0
+#define MOVEMASK \
+	VAND V31.B16, V4.B16, V4.B16; \
+	VAND V31.B16, V5.B16, V5.B16; \
+	VAND V31.B16, V6.B16, V6.B16; \
+	VAND V31.B16, V7.B16, V7.B16; \
+	VADDP V5.B16, V4.B16, V4.B16; \
+	VADDP V7.B16, V6.B16, V6.B16; \
+	VADDP V6.B16, V4.B16, V4.B16; \
+	VADDP V4.B16, V4.B16, V4.B16; \
+	VMOV V4.D[0], R7; \
+	MOVD.P R7, 8(R0)
+
+// WS compares the bytes of in with the entry of their low nibble
+#define WS(in, out) \
+	VAND V19.B16, in.B16, out.B16; \
+	VTBL out.B16, [V18.B16], out.B16; \
+	VCMEQ in.B16, out.B16, out.B16
+
+// OP finds the operators of in. A byte is one when it is ':' or ',', or
+// when it is '{' or '}' with bit 5 set, which turns '[' and ']' into
+// them.
+#define OP(in, out) \
+	VORR V20.B16, in.B16, V25.B16; \
+	VCMEQ V21.B16, V25.B16, out.B16; \
+	VCMEQ V22.B16, V25.B16, V26.B16; \
+	VORR V26.B16, out.B16, out.B16; \
+	VCMEQ V23.B16, in.B16, V26.B16; \
+	VORR V26.B16, out.B16, out.B16; \
+	VCMEQ V24.B16, in.B16, V26.B16; \
+	VORR V26.B16, out.B16, out.B16
+
+// func classifyNEON(data []byte, masks []uint64)
+TEXT ·classifyNEON(SB), NOSPLIT, $0-48
+	MOVD data_base+0(FP), R1
+	MOVD data_len+8(FP), R2
+	MOVD masks_base+24(FP), R0
+	LSR $6, R2, R2
+	CBZ R2, done
+
+	// V16 = '\\', V17 = '"', V18 = wsTable, V19 = 0x0F, V20 = 0x20,
+	// V21 = '{', V22 = '}', V23 = ':', V24 = ',', V31 = weights
+	MOVD $0x5c, R4
+	VDUP R4, V16.B16
+	MOVD $0x22, R4
+	VDUP R4, V17.B16
+	MOVD $wsTable<>(SB), R4
+	VLD1 (R4), [V18.B16]
+	MOVD $0x0f, R4
+	VDUP R4, V19.B16
+	MOVD $0x20, R4
+	VDUP R4, V20.B16
+	MOVD $0x7b, R4
+	VDUP R4, V21.B16
+	MOVD $0x7d, R4
+	VDUP R4, V22.B16
+	MOVD $0x3a, R4
+	VDUP R4, V23.B16
+	MOVD $0x2c, R4
+	VDUP R4, V24.B16
+	MOVD $weights<>(SB), R4
+	VLD1 (R4), [V31.B16]
+
+loop:
+	VLD1.P 64(R1), [V0.B16, V1.B16, V2.B16, V3.B16]
+
+	VCMEQ V16.B16, V0.B16, V4.B16
+	VCMEQ V16.B16, V1.B16, V5.B16
+	VCMEQ V16.B16, V2.B16, V6.B16
+	VCMEQ V16.B16, V3.B16, V7.B16
+	MOVEMASK
+
+	VCMEQ V17.B16, V0.B16, V4.B16
+	VCMEQ V17.B16, V1.B16, V5.B16
+	VCMEQ V17.B16, V2.B16, V6.B16
+	VCMEQ V17.B16, V3.B16, V7.B16
+	MOVEMASK
+
+	WS(V0, V4)
+	WS(V1, V5)
+	WS(V2, V6)
+	WS(V3, V7)
+	MOVEMASK
+
+	OP(V0, V4)
+	OP(V1, V5)
+	OP(V2, V6)
+	OP(V3, V7)
+	MOVEMASK
+
+	SUB $1, R2
+	CBNZ R2, loop
+
+done:
+	RET
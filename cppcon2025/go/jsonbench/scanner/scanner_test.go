@@ -0,0 +1,134 @@
+package scanner_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/backends"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/scanner"
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/simd"
+)
+
+// randomValue returns a value of up to depth levels whose strings are
+// full of quotes, backslashes and multi-byte runes, so that the escapes
+// and the ends of strings fall anywhere in a block
+func randomValue(r *rand.Rand, depth int) interface{} {
+	switch k := r.Intn(8); {
+	case depth > 0 && k == 0:
+		a := make([]interface{}, r.Intn(6))
+		for i := range a {
+			a[i] = randomValue(r, depth-1)
+		}
+		return a
+	case depth > 0 && k == 1:
+		o := map[string]interface{}{}
+		for n := r.Intn(6); n > 0; n-- {
+			o[randomString(r)] = randomValue(r, depth-1)
+		}
+		return o
+	case k == 2:
+		return r.NormFloat64() * 1e6
+	case k == 3:
+		return r.Int63()
+	case k == 4:
+		return []interface{}{true, false, nil}[r.Intn(3)]
+	}
+	return randomString(r)
+}
+
+func randomString(r *rand.Rand) string {
+	var b strings.Builder
+	for n := r.Intn(80); n > 0; n-- {
+		b.WriteString([]string{`"`, `\`, `\\`, "\n", "é", "😀", " ", "{", ","}[r.Intn(9)])
+		if r.Intn(2) == 0 {
+			b.WriteString("abcdefghijklmnop"[:r.Intn(17)])
+		}
+	}
+	return b.String()
+}
+
+// randomDocs returns n documents, indented with random whitespace
+func randomDocs(n int) [][]byte {
+	r := rand.New(rand.NewSource(1))
+	var docs [][]byte
+	for len(docs) < n {
+		compact, err := json.Marshal(randomValue(r, 5))
+		if err != nil {
+			panic(err)
+		}
+		var doc bytes.Buffer
+		json.Indent(&doc, compact, strings.Repeat(" ", r.Intn(3)), []string{"", " ", "\t", "\r\n  "}[r.Intn(4)])
+		docs = append(docs, doc.Bytes())
+	}
+	return docs
+}
+
+// forEachImplementation runs f with every supported implementation of
+// package simd active in turn
+func forEachImplementation(t *testing.T, f func(t *testing.T)) {
+	defer simd.Select(simd.Active().Name)
+	for _, impl := range simd.Implementations() {
+		if !impl.Supported {
+			continue
+		}
+		if err := simd.Select(impl.Name); err != nil {
+			t.Fatal(err)
+		}
+		t.Run(impl.Name, f)
+	}
+}
+
+// TestIndex compares the index of every implementation with the one of
+// backends.StructuralIndex, byte by byte
+func TestIndex(t *testing.T) {
+	docs := randomDocs(3000)
+	forEachImplementation(t, func(t *testing.T) {
+		for _, doc := range docs {
+			want, err := backends.StructuralIndex(doc, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := scanner.Index(doc, nil)
+			if err != nil {
+				t.Fatalf("%s: %q", err, doc)
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("%s: index %v, want %v of %q", scanner.Name(), got, want, doc)
+			}
+		}
+	})
+}
+
+func TestIndexUnterminated(t *testing.T) {
+	forEachImplementation(t, func(t *testing.T) {
+		for _, doc := range []string{`"`, `["a\"]`, `{"a":"` + strings.Repeat("b", 100), `"\\\"`} {
+			if _, err := scanner.Index([]byte(doc), nil); err == nil {
+				t.Errorf("Index(%#q) succeeds with an open string", doc)
+			}
+		}
+	})
+}
+
+func TestValidUTF8(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var inputs [][]byte
+	for _, doc := range randomDocs(300) {
+		inputs = append(inputs, doc)
+		// Break a byte: often invalid, sometimes not
+		broken := bytes.Clone(doc)
+		broken[r.Intn(len(broken))] = byte(0x80 + r.Intn(0x80))
+		inputs = append(inputs, broken)
+	}
+	forEachImplementation(t, func(t *testing.T) {
+		for _, data := range inputs {
+			if got, want := scanner.ValidUTF8(data), utf8.Valid(data); got != want {
+				t.Fatalf("ValidUTF8(%q) = %v, want %v", data, got, want)
+			}
+		}
+	})
+}
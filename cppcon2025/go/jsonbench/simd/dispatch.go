@@ -0,0 +1,77 @@
+package simd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Implementation is one set of kernels, for one instruction set
+type Implementation struct {
+	Name        string
+	Description string
+	// Supported reports whether the CPU, and the OS for the wider
+	// registers, can run the kernels
+	Supported bool
+	// Width is the number of bytes each kernel checks per instruction
+	Width int
+
+	copyPlain      func(dst, src []byte) int
+	skipWhitespace func(data []byte) int
+	ascii          func(data []byte) int
+}
+
+// implementations lists those of this platform, best first, then the
+// portable ones
+var implementations = append(archImplementations(), swar, Implementation{
+	Name:        "generic",
+	Description: "portable Go, a byte at a time",
+	Supported:   true,
+	Width:       1,
+})
+
+var active Implementation
+
+// Implementations returns every implementation of this platform, best
+// first
+func Implementations() []Implementation {
+	return append([]Implementation(nil), implementations...)
+}
+
+// Active returns the implementation in use
+func Active() Implementation { return active }
+
+// Select makes the named implementation active, ignoring case, as
+// simdjson's SIMDJSON_FORCE_IMPLEMENTATION does; it must not run while
+// a kernel does
+func Select(name string) error {
+	for _, impl := range implementations {
+		if !strings.EqualFold(impl.Name, name) {
+			continue
+		}
+		if !impl.Supported {
+			return fmt.Errorf("simd: this CPU does not support %s", impl.Name)
+		}
+		use(impl)
+		return nil
+	}
+	return fmt.Errorf("simd: unknown implementation %q", name)
+}
+
+func use(impl Implementation) {
+	active = impl
+	CopyPlain, SkipWhitespace, ASCII = impl.copyPlain, impl.skipWhitespace, impl.ascii
+	Name = ""
+	if impl.copyPlain != nil {
+		Name = impl.Name
+	}
+}
+
+// The best supported implementation is active from the start
+func init() {
+	for _, impl := range implementations {
+		if impl.Supported {
+			use(impl)
+			return
+		}
+	}
+}
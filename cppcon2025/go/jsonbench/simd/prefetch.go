@@ -0,0 +1,13 @@
+//go:build !tinygo && (amd64 || arm64)
+
+package simd
+
+// PrefetchSupported reports whether Prefetch issues prefetches
+const PrefetchSupported = true
+
+// Prefetch hints the CPU to bring every cache line of data into the L1
+// cache, with PREFETCHT0 on x86 and PRFM PLDL1KEEP on ARM, and returns at
+// once; it loads nothing and cannot fault
+//
+//go:noescape
+func Prefetch(data []byte)
@@ -0,0 +1,19 @@
+//go:build !tinygo
+
+#include "textflag.h"
+
+// func Prefetch(data []byte)
+TEXT ·Prefetch(SB), NOSPLIT, $0-24
+	MOVQ data_base+0(FP), SI
+	MOVQ data_len+8(FP), CX
+	ADDQ SI, CX
+
+loop:
+	CMPQ SI, CX
+	JCC done
+	PREFETCHT0 (SI)
+	ADDQ $64, SI
+	JMP loop
+
+done:
+	RET
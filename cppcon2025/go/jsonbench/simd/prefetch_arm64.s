@@ -0,0 +1,19 @@
+//go:build !tinygo
+
+#include "textflag.h"
+
+// func Prefetch(data []byte)
+TEXT ·Prefetch(SB), NOSPLIT, $0-24
+	MOVD data_base+0(FP), R0
+	MOVD data_len+8(FP), R1
+	ADD R0, R1, R1
+
+loop:
+	CMP R1, R0
+	BHS done
+	PRFM (R0), PLDL1KEEP
+	ADD $64, R0
+	B loop
+
+done:
+	RET
@@ -0,0 +1,9 @@
+//go:build tinygo || !(amd64 || arm64)
+
+package simd
+
+// PrefetchSupported reports whether Prefetch issues prefetches
+const PrefetchSupported = false
+
+// Prefetch does nothing without the assembly stubs
+func Prefetch(data []byte) {}
@@ -0,0 +1,29 @@
+// Package simd holds the kernels of jsonbench, in assembly and in SWAR
+// Go, and chooses, once at startup, the implementation that suits the
+// CPU best: AVX-512, AVX2, NEON or SWAR, as simdjson does. Keeping them
+// apart gives the assembly a package of its own and puts the dispatch by
+// CPU feature in one place, shared by the scanner, dom and the harness.
+package simd
+
+// The kernels of the active implementation. They are nil for the generic
+// one, whose callers keep their own loops; Name is then empty, and
+// otherwise names the instruction set, such as "AVX2" or "NEON".
+var (
+	// CopyPlain copies the leading bytes of src that a JSON string can
+	// hold unescaped (everything but '\\', '"' and control characters)
+	// to dst, which must be at least as long as src, and returns their
+	// number.
+	CopyPlain func(dst, src []byte) int
+
+	// SkipWhitespace returns the number of leading JSON whitespace bytes
+	// (space, tab, line feed, carriage return) of data. Their low nibbles
+	// differ, so one table lookup per byte, indexed by the low nibble,
+	// tells them apart from every other byte, as in simdjson.
+	SkipWhitespace func(data []byte) int
+
+	// ASCII returns the number of leading ASCII bytes of data, the fast
+	// path of UTF-8 validation.
+	ASCII func(data []byte) int
+)
+
+var Name string
@@ -0,0 +1,59 @@
+//go:build !tinygo
+
+package simd
+
+import "golang.org/x/sys/cpu"
+
+// copyPlainAVX2 is CopyPlain 32 bytes at a time; the tail shorter than a
+// register is copied byte by byte
+//
+//go:noescape
+func copyPlainAVX2(dst, src []byte) int
+
+// skipWhitespaceAVX2 is SkipWhitespace 32 bytes at a time
+//
+//go:noescape
+func skipWhitespaceAVX2(data []byte) int
+
+// asciiAVX2 is ASCII 32 bytes at a time
+//
+//go:noescape
+func asciiAVX2(data []byte) int
+
+// copyPlainAVX512 is CopyPlain 64 bytes at a time, comparing into mask
+// registers
+//
+//go:noescape
+func copyPlainAVX512(dst, src []byte) int
+
+// skipWhitespaceAVX512 is SkipWhitespace 64 bytes at a time
+//
+//go:noescape
+func skipWhitespaceAVX512(data []byte) int
+
+// asciiAVX512 is ASCII 64 bytes at a time
+//
+//go:noescape
+func asciiAVX512(data []byte) int
+
+// archImplementations checks the CPU with x/sys/cpu, which also checks
+// that the OS saves the YMM and ZMM registers
+func archImplementations() []Implementation {
+	return []Implementation{{
+		Name:           "AVX-512",
+		Description:    "AVX-512BW, compares into 64-bit mask registers",
+		Supported:      cpu.X86.HasAVX512F && cpu.X86.HasAVX512BW,
+		Width:          64,
+		copyPlain:      copyPlainAVX512,
+		skipWhitespace: skipWhitespaceAVX512,
+		ascii:          asciiAVX512,
+	}, {
+		Name:           "AVX2",
+		Description:    "AVX2, 32-byte registers and VPMOVMSKB",
+		Supported:      cpu.X86.HasAVX2,
+		Width:          32,
+		copyPlain:      copyPlainAVX2,
+		skipWhitespace: skipWhitespaceAVX2,
+		ascii:          asciiAVX2,
+	}}
+}
@@ -0,0 +1,320 @@
+//go:build !tinygo
+
+#include "textflag.h"
+
+DATA backslash<>+0(SB)/1, $0x5c
+GLOBL backslash<>(SB), RODATA|NOPTR, $1
+DATA quote<>+0(SB)/1, $0x22
+GLOBL quote<>(SB), RODATA|NOPTR, $1
+DATA control<>+0(SB)/1, $0xe0
+GLOBL control<>(SB), RODATA|NOPTR, $1
+DATA space<>+0(SB)/1, $0x20
+GLOBL space<>(SB), RODATA|NOPTR, $1
+
+// func copyPlainAVX2(dst, src []byte) int
+TEXT ·copyPlainAVX2(SB), NOSPLIT, $0-56
+	MOVQ dst_base+0(FP), DI
+	MOVQ src_base+24(FP), SI
+	MOVQ src_len+32(FP), CX
+	XORQ AX, AX
+
+	// Short strings never touch the YMM registers
+	CMPQ CX, $32
+	JCS tail
+
+	// Y1 = '\\', Y2 = '"', Y3 = 0xE0 (no bit of it is set in a control
+	// character), Y6 = 0
+	VPBROADCASTB backslash<>(SB), Y1
+	VPBROADCASTB quote<>(SB), Y2
+	VPBROADCASTB control<>(SB), Y3
+	VPXOR X6, X6, X6
+
+loop:
+	VMOVDQU (SI)(AX*1), Y0
+
+	// dst is as long as src, so the whole register can be stored before
+	// looking for the first byte to stop at
+	VMOVDQU Y0, (DI)(AX*1)
+	VPCMPEQB Y0, Y1, Y4
+	VPCMPEQB Y0, Y2, Y5
+	VPOR Y4, Y5, Y4
+	VPAND Y0, Y3, Y5
+	VPCMPEQB Y5, Y6, Y5
+	VPOR Y4, Y5, Y4
+	VPMOVMSKB Y4, DX
+	TESTL DX, DX
+	JNZ found
+	ADDQ $32, AX
+	MOVQ CX, DX
+	SUBQ AX, DX
+	CMPQ DX, $32
+	JCC loop
+	VZEROUPPER
+
+tail:
+	CMPQ AX, CX
+	JCC done
+	MOVBLZX (SI)(AX*1), DX
+	CMPB DL, $0x5c
+	JEQ done
+	CMPB DL, $0x22
+	JEQ done
+	CMPB DL, $0x20
+	JCS done
+	MOVB DL, (DI)(AX*1)
+	INCQ AX
+	JMP tail
+
+found:
+	VZEROUPPER
+	BSFL DX, DX
+	ADDQ DX, AX
+
+done:
+	MOVQ AX, ret+48(FP)
+	RET
+
+// wsTable holds, at the index of each whitespace byte's low nibble, that
+// byte, and 0xFF elsewhere
+DATA wsTable<>+0(SB)/8, $0xffffffffffffff20
+DATA wsTable<>+8(SB)/8, $0xffff0dffff0a09ff
+GLOBL wsTable<>(SB), RODATA|NOPTR, $16
+
+// func skipWhitespaceAVX2(data []byte) int
+TEXT ·skipWhitespaceAVX2(SB), NOSPLIT, $0-32
+	MOVQ data_base+0(FP), SI
+	MOVQ data_len+8(FP), CX
+	XORQ AX, AX
+	CMPQ CX, $32
+	JCS wstail
+	VBROADCASTI128 wsTable<>(SB), Y1
+
+wsloop:
+	// VPSHUFB looks up the low nibble of each byte and yields 0 for the
+	// bytes with their high bit set; a byte is whitespace when it equals
+	// what it looks up
+	VMOVDQU (SI)(AX*1), Y0
+	VPSHUFB Y0, Y1, Y2
+	VPCMPEQB Y0, Y2, Y2
+	VPMOVMSKB Y2, DX
+	NOTL DX
+	TESTL DX, DX
+	JNZ wsfound
+	ADDQ $32, AX
+	MOVQ CX, DX
+	SUBQ AX, DX
+	CMPQ DX, $32
+	JCC wsloop
+	VZEROUPPER
+
+wstail:
+	CMPQ AX, CX
+	JCC wsdone
+	MOVBLZX (SI)(AX*1), DX
+	CMPB DL, $0x20
+	JEQ wsnext
+	CMPB DL, $0x09
+	JEQ wsnext
+	CMPB DL, $0x0a
+	JEQ wsnext
+	CMPB DL, $0x0d
+	JNE wsdone
+
+wsnext:
+	INCQ AX
+	JMP wstail
+
+wsfound:
+	VZEROUPPER
+	BSFL DX, DX
+	ADDQ DX, AX
+
+wsdone:
+	MOVQ AX, ret+24(FP)
+	RET
+
+// func asciiAVX2(data []byte) int
+TEXT ·asciiAVX2(SB), NOSPLIT, $0-32
+	MOVQ data_base+0(FP), SI
+	MOVQ data_len+8(FP), CX
+	XORQ AX, AX
+	CMPQ CX, $32
+	JCS atail
+
+aloop:
+	// VPMOVMSKB gathers the high bits, set in every non-ASCII byte
+	VMOVDQU (SI)(AX*1), Y0
+	VPMOVMSKB Y0, DX
+	TESTL DX, DX
+	JNZ afound
+	ADDQ $32, AX
+	MOVQ CX, DX
+	SUBQ AX, DX
+	CMPQ DX, $32
+	JCC aloop
+	VZEROUPPER
+
+atail:
+	CMPQ AX, CX
+	JCC adone
+	MOVBLZX (SI)(AX*1), DX
+	TESTB $0x80, DL
+	JNZ adone
+	INCQ AX
+	JMP atail
+
+afound:
+	VZEROUPPER
+	BSFL DX, DX
+	ADDQ DX, AX
+
+adone:
+	MOVQ AX, ret+24(FP)
+	RET
+
+// func copyPlainAVX512(dst, src []byte) int
+TEXT ·copyPlainAVX512(SB), NOSPLIT, $0-56
+	MOVQ dst_base+0(FP), DI
+	MOVQ src_base+24(FP), SI
+	MOVQ src_len+32(FP), CX
+	XORQ AX, AX
+	CMPQ CX, $64
+	JCS ztail
+
+	// Z1 = '\\', Z2 = '"', Z3 = 0x20, below which bytes are control
+	// characters
+	VPBROADCASTB backslash<>(SB), Z1
+	VPBROADCASTB quote<>(SB), Z2
+	VPBROADCASTB space<>(SB), Z3
+
+zloop:
+	VMOVDQU8 (SI)(AX*1), Z0
+	VMOVDQU8 Z0, (DI)(AX*1)
+	VPCMPEQB Z1, Z0, K1
+	VPCMPEQB Z2, Z0, K2
+	KORQ K1, K2, K1
+	VPCMPUB $1, Z3, Z0, K2
+	KORQ K1, K2, K1
+	KMOVQ K1, DX
+	TESTQ DX, DX
+	JNZ zfound
+	ADDQ $64, AX
+	MOVQ CX, DX
+	SUBQ AX, DX
+	CMPQ DX, $64
+	JCC zloop
+	VZEROUPPER
+
+ztail:
+	CMPQ AX, CX
+	JCC zdone
+	MOVBLZX (SI)(AX*1), DX
+	CMPB DL, $0x5c
+	JEQ zdone
+	CMPB DL, $0x22
+	JEQ zdone
+	CMPB DL, $0x20
+	JCS zdone
+	MOVB DL, (DI)(AX*1)
+	INCQ AX
+	JMP ztail
+
+zfound:
+	VZEROUPPER
+	BSFQ DX, DX
+	ADDQ DX, AX
+
+zdone:
+	MOVQ AX, ret+48(FP)
+	RET
+
+// func skipWhitespaceAVX512(data []byte) int
+TEXT ·skipWhitespaceAVX512(SB), NOSPLIT, $0-32
+	MOVQ data_base+0(FP), SI
+	MOVQ data_len+8(FP), CX
+	XORQ AX, AX
+	CMPQ CX, $64
+	JCS zwstail
+	VBROADCASTI32X4 wsTable<>(SB), Z1
+
+zwsloop:
+	// The lookup of skipWhitespaceAVX2, comparing into a mask register
+	VMOVDQU8 (SI)(AX*1), Z0
+	VPSHUFB Z0, Z1, Z2
+	VPCMPEQB Z0, Z2, K1
+	KMOVQ K1, DX
+	NOTQ DX
+	TESTQ DX, DX
+	JNZ zwsfound
+	ADDQ $64, AX
+	MOVQ CX, DX
+	SUBQ AX, DX
+	CMPQ DX, $64
+	JCC zwsloop
+	VZEROUPPER
+
+zwstail:
+	CMPQ AX, CX
+	JCC zwsdone
+	MOVBLZX (SI)(AX*1), DX
+	CMPB DL, $0x20
+	JEQ zwsnext
+	CMPB DL, $0x09
+	JEQ zwsnext
+	CMPB DL, $0x0a
+	JEQ zwsnext
+	CMPB DL, $0x0d
+	JNE zwsdone
+
+zwsnext:
+	INCQ AX
+	JMP zwstail
+
+zwsfound:
+	VZEROUPPER
+	BSFQ DX, DX
+	ADDQ DX, AX
+
+zwsdone:
+	MOVQ AX, ret+24(FP)
+	RET
+
+// func asciiAVX512(data []byte) int
+TEXT ·asciiAVX512(SB), NOSPLIT, $0-32
+	MOVQ data_base+0(FP), SI
+	MOVQ data_len+8(FP), CX
+	XORQ AX, AX
+	CMPQ CX, $64
+	JCS zatail
+
+zaloop:
+	// VPMOVB2M moves the high bits into a mask register
+	VMOVDQU8 (SI)(AX*1), Z0
+	VPMOVB2M Z0, K1
+	KMOVQ K1, DX
+	TESTQ DX, DX
+	JNZ zafound
+	ADDQ $64, AX
+	MOVQ CX, DX
+	SUBQ AX, DX
+	CMPQ DX, $64
+	JCC zaloop
+	VZEROUPPER
+
+zatail:
+	CMPQ AX, CX
+	JCC zadone
+	MOVBLZX (SI)(AX*1), DX
+	TESTB $0x80, DL
+	JNZ zadone
+	INCQ AX
+	JMP zatail
+
+zafound:
+	VZEROUPPER
+	BSFQ DX, DX
+	ADDQ DX, AX
+
+zadone:
+	MOVQ AX, ret+24(FP)
+	RET
@@ -0,0 +1,32 @@
+//go:build !tinygo
+
+package simd
+
+// copyPlainNEON is CopyPlain 16 bytes at a time; the tail shorter than a
+// register is copied byte by byte
+//
+//go:noescape
+func copyPlainNEON(dst, src []byte) int
+
+// skipWhitespaceNEON is SkipWhitespace 16 bytes at a time
+//
+//go:noescape
+func skipWhitespaceNEON(data []byte) int
+
+// asciiNEON is ASCII 16 bytes at a time
+//
+//go:noescape
+func asciiNEON(data []byte) int
+
+// NEON is part of every ARMv8-A core
+func archImplementations() []Implementation {
+	return []Implementation{{
+		Name:           "NEON",
+		Description:    "NEON, 16-byte registers",
+		Supported:      true,
+		Width:          16,
+		copyPlain:      copyPlainNEON,
+		skipWhitespace: skipWhitespaceNEON,
+		ascii:          asciiNEON,
+	}}
+}
@@ -0,0 +1,189 @@
+//go:build !tinygo
+
+#include "textflag.h"
+
+// func copyPlainNEON(dst, src []byte) int
+TEXT ·copyPlainNEON(SB), NOSPLIT, $0-56
+	MOVD dst_base+0(FP), R0
+	MOVD src_base+24(FP), R1
+	MOVD src_len+32(FP), R2
+	MOVD $0, R3
+
+	// V1 = '\\', V2 = '"', V3 = 0xE0 (no bit of it is set in a control
+	// character), V4 = 0
+	MOVD $0x5c, R4
+	VDUP R4, V1.B16
+	MOVD $0x22, R4
+	VDUP R4, V2.B16
+	MOVD $0xe0, R4
+	VDUP R4, V3.B16
+	VEOR V4.B16, V4.B16, V4.B16
+
+loop:
+	SUB R3, R2, R4
+	CMP $16, R4
+	BLT tail
+	ADD R1, R3, R5
+	VLD1 (R5), [V0.B16]
+
+	// dst is as long as src, so the whole register can be stored before
+	// looking for the first byte to stop at
+	ADD R0, R3, R6
+	VST1 [V0.B16], (R6)
+	VCMEQ V1.B16, V0.B16, V5.B16
+	VCMEQ V2.B16, V0.B16, V6.B16
+	VORR V5.B16, V6.B16, V5.B16
+	VAND V3.B16, V0.B16, V6.B16
+	VCMEQ V4.B16, V6.B16, V6.B16
+	VORR V5.B16, V6.B16, V5.B16
+	VMOV V5.D[0], R7
+	VMOV V5.D[1], R8
+	ORR R7, R8, R9
+	CBNZ R9, found
+	ADD $16, R3
+	B loop
+
+found:
+	// The first set byte of the two halves, low half first
+	CBNZ R7, low
+	ADD $8, R3
+	MOVD R8, R7
+
+low:
+	RBIT R7, R7
+	CLZ R7, R7
+	ADD R7>>3, R3, R3
+	B done
+
+tail:
+	CMP R2, R3
+	BGE done
+	MOVBU (R1)(R3), R4
+	CMP $0x5c, R4
+	BEQ done
+	CMP $0x22, R4
+	BEQ done
+	CMP $0x20, R4
+	BLO done
+	MOVB R4, (R0)(R3)
+	ADD $1, R3
+	B tail
+
+done:
+	MOVD R3, ret+48(FP)
+	RET
+
+// wsTable holds, at the index of each whitespace byte's low nibble, that
+// byte, and 0xFF elsewhere
+DATA wsTable<>+0(SB)/8, $0xffffffffffffff20
+DATA wsTable<>+8(SB)/8, $0xffff0dffff0a09ff
+GLOBL wsTable<>(SB), RODATA|NOPTR, $16
+
+// func skipWhitespaceNEON(data []byte) int
+TEXT ·skipWhitespaceNEON(SB), NOSPLIT, $0-32
+	MOVD data_base+0(FP), R1
+	MOVD data_len+8(FP), R2
+	MOVD $0, R3
+	MOVD $wsTable<>(SB), R4
+	VLD1 (R4), [V1.B16]
+	MOVD $0x0f, R4
+	VDUP R4, V3.B16
+
+wsloop:
+	SUB R3, R2, R4
+	CMP $16, R4
+	BLT wstail
+	ADD R1, R3, R5
+	VLD1 (R5), [V0.B16]
+
+	// A byte is whitespace when it equals the entry of its low nibble
+	VAND V3.B16, V0.B16, V2.B16
+	VTBL V2.B16, [V1.B16], V2.B16
+	VCMEQ V0.B16, V2.B16, V2.B16
+	VMOV V2.D[0], R7
+	VMOV V2.D[1], R8
+	MVN R7, R7
+	MVN R8, R8
+	ORR R7, R8, R9
+	CBNZ R9, wsfound
+	ADD $16, R3
+	B wsloop
+
+wsfound:
+	CBNZ R7, wslow
+	ADD $8, R3
+	MOVD R8, R7
+
+wslow:
+	RBIT R7, R7
+	CLZ R7, R7
+	ADD R7>>3, R3, R3
+	B wsdone
+
+wstail:
+	CMP R2, R3
+	BGE wsdone
+	MOVBU (R1)(R3), R4
+	CMP $0x20, R4
+	BEQ wsnext
+	CMP $0x09, R4
+	BEQ wsnext
+	CMP $0x0a, R4
+	BEQ wsnext
+	CMP $0x0d, R4
+	BNE wsdone
+
+wsnext:
+	ADD $1, R3
+	B wstail
+
+wsdone:
+	MOVD R3, ret+24(FP)
+	RET
+
+// func asciiNEON(data []byte) int
+TEXT ·asciiNEON(SB), NOSPLIT, $0-32
+	MOVD data_base+0(FP), R1
+	MOVD data_len+8(FP), R2
+	MOVD $0, R3
+	MOVD $0x8080808080808080, R9
+
+aloop:
+	SUB R3, R2, R4
+	CMP $16, R4
+	BLT atail
+	ADD R1, R3, R5
+	VLD1 (R5), [V0.B16]
+
+	// The high bits of the two halves, set in every non-ASCII byte
+	VMOV V0.D[0], R7
+	VMOV V0.D[1], R8
+	AND R9, R7, R7
+	AND R9, R8, R8
+	ORR R7, R8, R10
+	CBNZ R10, afound
+	ADD $16, R3
+	B aloop
+
+afound:
+	CBNZ R7, alow
+	ADD $8, R3
+	MOVD R8, R7
+
+alow:
+	RBIT R7, R7
+	CLZ R7, R7
+	ADD R7>>3, R3, R3
+	B adone
+
+atail:
+	CMP R2, R3
+	BGE adone
+	MOVBU (R1)(R3), R4
+	TBNZ $7, R4, adone
+	ADD $1, R3
+	B atail
+
+adone:
+	MOVD R3, ret+24(FP)
+	RET
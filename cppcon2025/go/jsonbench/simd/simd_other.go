@@ -0,0 +1,6 @@
+//go:build tinygo || !(amd64 || arm64)
+
+package simd
+
+// Only the generic implementation runs here
+func archImplementations() []Implementation { return nil }
@@ -0,0 +1,110 @@
+package simd
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// The byte loops every kernel must agree with
+
+func copyPlainRef(dst, src []byte) int {
+	i := 0
+	for i < len(src) && src[i] != '\\' && src[i] != '"' && src[i] >= 0x20 {
+		dst[i] = src[i]
+		i++
+	}
+	return i
+}
+
+func skipWhitespaceRef(data []byte) int {
+	i := 0
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+func asciiRef(data []byte) int {
+	i := 0
+	for i < len(data) && data[i] < 0x80 {
+		i++
+	}
+	return i
+}
+
+// kernel is one of the kernels of an implementation, with the bytes that
+// continue and end its run
+type kernel struct {
+	name string
+	run  func(impl Implementation, data []byte) int
+	ref  func(data []byte) int
+	pass func(r *rand.Rand) byte
+}
+
+var kernels = []kernel{
+	{"CopyPlain",
+		func(impl Implementation, data []byte) int {
+			dst := make([]byte, len(data))
+			n := impl.copyPlain(dst, data)
+			// A wrong copy reports as a wrong length
+			if n <= len(data) && !bytes.Equal(dst[:n], data[:n]) {
+				return -1
+			}
+			return n
+		},
+		func(data []byte) int { return copyPlainRef(make([]byte, len(data)), data) },
+		func(r *rand.Rand) byte {
+			for {
+				if c := byte(r.Intn(256)); c != '\\' && c != '"' && c >= 0x20 {
+					return c
+				}
+			}
+		}},
+	{"SkipWhitespace",
+		func(impl Implementation, data []byte) int { return impl.skipWhitespace(data) },
+		skipWhitespaceRef,
+		func(r *rand.Rand) byte { return " \t\n\r"[r.Intn(4)] }},
+	{"ASCII",
+		func(impl Implementation, data []byte) int { return impl.ascii(data) },
+		asciiRef,
+		func(r *rand.Rand) byte { return byte(r.Intn(0x80)) }},
+}
+
+// TestKernels runs the kernels of every supported implementation on runs
+// of every length up to two registers, at every alignment, ended by a
+// random byte at every position, and on random bytes
+func TestKernels(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, impl := range Implementations() {
+		if !impl.Supported || impl.copyPlain == nil {
+			continue
+		}
+		for _, k := range kernels {
+			buf := make([]byte, 3*impl.Width+1)
+			check := func(data []byte) {
+				if got, want := k.run(impl, data), k.ref(data); got != want {
+					t.Fatalf("%s %s(%q) = %d, want %d", impl.Name, k.name, data, got, want)
+				}
+			}
+			for n := 0; n <= 2*impl.Width; n++ {
+				for off := 0; off < impl.Width; off++ {
+					data := buf[off : off+n]
+					for end := 0; end <= n; end++ {
+						for i := range data {
+							data[i] = k.pass(r)
+						}
+						if end < n {
+							data[end] = byte(r.Intn(256))
+						}
+						check(data)
+					}
+					for i := range data {
+						data[i] = byte(r.Intn(256))
+					}
+					check(data)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package simd
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/simdjson/simdjson_talks/cppcon2025/go/jsonbench/bitutil"
+)
+
+// The SWAR kernels (SIMD within a register) check 8 bytes at a time in a
+// uint64. They need no assembly and run everywhere, between the byte
+// loops of the generic implementation and the vector kernels, with the
+// comparisons of package bitutil.
+
+// highBits has the high bit of every byte set
+const highBits = 0x80 * bitutil.Ones
+
+// copyPlainSWAR copies a word, then looks for the first byte that ends
+// the run in it: the control characters are those of the top 3 bits
+// clear
+func copyPlainSWAR(dst, src []byte) int {
+	i := 0
+	for ; i+8 <= len(src); i += 8 {
+		v := binary.LittleEndian.Uint64(src[i:])
+		binary.LittleEndian.PutUint64(dst[i:], v)
+		if stop := bitutil.Equal(v, '\\') | bitutil.Equal(v, '"') | bitutil.ZeroBytes(v&(0xE0*bitutil.Ones)); stop != 0 {
+			return i + bits.TrailingZeros64(stop)/8
+		}
+	}
+	for ; i < len(src); i++ {
+		c := src[i]
+		if c == '\\' || c == '"' || c < 0x20 {
+			return i
+		}
+		dst[i] = c
+	}
+	return i
+}
+
+// skipWhitespaceSWAR compares each word with the 4 whitespace bytes
+func skipWhitespaceSWAR(data []byte) int {
+	i := 0
+	for ; i+8 <= len(data); i += 8 {
+		v := binary.LittleEndian.Uint64(data[i:])
+		ws := bitutil.Equal(v, ' ') | bitutil.Equal(v, '\t') | bitutil.Equal(v, '\n') | bitutil.Equal(v, '\r')
+		if other := ^ws & highBits; other != 0 {
+			return i + bits.TrailingZeros64(other)/8
+		}
+	}
+	for ; i < len(data); i++ {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// asciiSWAR looks for a high bit in each word
+func asciiSWAR(data []byte) int {
+	i := 0
+	for ; i+8 <= len(data); i += 8 {
+		if high := binary.LittleEndian.Uint64(data[i:]) & highBits; high != 0 {
+			return i + bits.TrailingZeros64(high)/8
+		}
+	}
+	for i < len(data) && data[i] < 0x80 {
+		i++
+	}
+	return i
+}
+
+var swar = Implementation{
+	Name:           "SWAR",
+	Description:    "portable Go, 8 bytes at a time in a uint64",
+	Supported:      true,
+	Width:          8,
+	copyPlain:      copyPlainSWAR,
+	skipWhitespace: skipWhitespaceSWAR,
+	ascii:          asciiSWAR,
+}
@@ -2,9 +2,13 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
 	"time"
 )
 
@@ -28,9 +32,20 @@ type TwitterData struct {
 	Statuses []Status `json:"statuses"`
 }
 
+// Input and profiling flags, the latter named after their go test
+// counterparts
+var (
+	fileFlag     = flag.String("file", "twitter.json", "JSON document to parse")
+	iterFlag     = flag.Int("n", 1000, "number of iterations")
+	cpuProfile   = flag.String("cpuprofile", "", "write a CPU profile to this file")
+	traceFile    = flag.String("trace", "", "write an execution trace to this file")
+	blockProfile = flag.String("blockprofile", "", "write a goroutine blocking profile to this file")
+)
+
 // Benchmark parsing of twitter.json and report speed in GB/s
 func main() {
-	filename := "twitter.json"
+	flag.Parse()
+	filename := *fileFlag
 	file, err := os.Open(filename)
 	if err != nil {
 		fmt.Println("Error opening file:", err)
@@ -51,9 +66,51 @@ func main() {
 		return
 	}
 
+	// Profile only the benchmark loop
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			fmt.Println("Error creating CPU profile:", err)
+			return
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fmt.Println("Error starting CPU profile:", err)
+			return
+		}
+		defer pprof.StopCPUProfile()
+	}
+	if *traceFile != "" {
+		f, err := os.Create(*traceFile)
+		if err != nil {
+			fmt.Println("Error creating trace:", err)
+			return
+		}
+		defer f.Close()
+		if err := trace.Start(f); err != nil {
+			fmt.Println("Error starting trace:", err)
+			return
+		}
+		defer trace.Stop()
+	}
+	if *blockProfile != "" {
+		runtime.SetBlockProfileRate(1)
+		defer func() {
+			f, err := os.Create(*blockProfile)
+			if err != nil {
+				fmt.Println("Error creating block profile:", err)
+				return
+			}
+			defer f.Close()
+			if err := pprof.Lookup("block").WriteTo(f, 0); err != nil {
+				fmt.Println("Error writing block profile:", err)
+			}
+		}()
+	}
+
 	// Benchmark loop
-	const iterations = 1000
-	var totalBytes int64 = int64(len(bytes)) * iterations
+	iterations := *iterFlag
+	var totalBytes int64 = int64(len(bytes)) * int64(iterations)
 	start := now()
 	for i := 0; i < iterations; i++ {
 		var data TwitterData
@@ -22,11 +22,13 @@ func enumerateFields(obj interface{}) {
     // Check if the object is a struct
     if val.Kind() == reflect.Struct {
         fmt.Println("Fields of the struct:")
+        // snippet:reflect-loop
         for i := 0; i < typ.NumField(); i++ {
             field := typ.Field(i)
             value := val.Field(i)
             fmt.Printf("  Name: %s, Type: %s, Value: %v\n", field.Name, field.Type, value)
         }
+        // snippet:reflect-loop
     } else {
         fmt.Println("The object is not a struct")
     }
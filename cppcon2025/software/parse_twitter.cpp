@@ -0,0 +1,94 @@
+// The C++ counterpart of go/parse_twitter.go: the same structs, filled
+// from twitter.json with simdjson On-Demand, and the same output, so that
+// `jsonbench sidebyside` can compare the two.
+#include <simdjson.h>
+
+#include <chrono>
+#include <cstdint>
+#include <cstdio>
+#include <cstdlib>
+#include <cstring>
+#include <string>
+#include <string_view>
+#include <vector>
+
+struct TwitterUser {
+  uint64_t id;
+  std::string name;
+  std::string screen_name;
+  std::string location;
+  std::string description;
+  uint64_t followers_count;
+  uint64_t friends_count;
+  bool verified;
+  uint64_t statuses_count;
+};
+
+struct Status {
+  TwitterUser user;
+};
+
+struct TwitterData {
+  std::vector<Status> statuses;
+};
+
+// The fields are read in document order, which On-Demand rewards
+TwitterData parse(simdjson::ondemand::parser &parser,
+                  const simdjson::padded_string &json) {
+  TwitterData data;
+  simdjson::ondemand::document doc = parser.iterate(json);
+  for (simdjson::ondemand::object status : doc["statuses"]) {
+    simdjson::ondemand::object user = status["user"];
+    TwitterUser u;
+    u.id = uint64_t(user["id"]);
+    u.name = std::string_view(user["name"]);
+    u.screen_name = std::string_view(user["screen_name"]);
+    u.location = std::string_view(user["location"]);
+    u.description = std::string_view(user["description"]);
+    u.followers_count = uint64_t(user["followers_count"]);
+    u.friends_count = uint64_t(user["friends_count"]);
+    u.verified = bool(user["verified"]);
+    u.statuses_count = uint64_t(user["statuses_count"]);
+    data.statuses.push_back({std::move(u)});
+  }
+  return data;
+}
+
+// Benchmark parsing of twitter.json and report speed in MB/s; takes the
+// -file and -n flags of parse_twitter.go
+int main(int argc, char *argv[]) {
+  const char *filename = "twitter.json";
+  long iterations = 1000;
+  for (int i = 1; i + 1 < argc; i += 2) {
+    if (std::strcmp(argv[i], "-file") == 0) {
+      filename = argv[i + 1];
+    } else if (std::strcmp(argv[i], "-n") == 0) {
+      iterations = std::atol(argv[i + 1]);
+    } else {
+      std::fprintf(stderr, "usage: %s [-file twitter.json] [-n 1000]\n", argv[0]);
+      return EXIT_FAILURE;
+    }
+  }
+  try {
+    simdjson::padded_string json = simdjson::padded_string::load(filename);
+    simdjson::ondemand::parser parser;
+
+    // Warmup parse
+    parse(parser, json);
+
+    // Benchmark loop
+    auto start = std::chrono::steady_clock::now();
+    for (long i = 0; i < iterations; i++) {
+      TwitterData data = parse(parser, json);
+    }
+    std::chrono::duration<double> elapsed = std::chrono::steady_clock::now() - start;
+    double gb = double(json.size()) * double(iterations) / 1e9;
+    double seconds = elapsed.count();
+    double speed = gb / seconds * 1000; // Convert GB/s to MB/s
+    std::printf("Parsed %.2f GB in %.3f seconds (%.2f MB/s)\n", gb, seconds, speed);
+  } catch (const simdjson::simdjson_error &e) {
+    std::fprintf(stderr, "Error parsing JSON: %s\n", e.what());
+    return EXIT_FAILURE;
+  }
+  return EXIT_SUCCESS;
+}